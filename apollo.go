@@ -2,9 +2,13 @@ package apollo
 
 import (
 	"bytes"
+	"cmp"
+	"context"
+	"crypto/ed25519"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
 	"math"
 	"math/big"
@@ -12,6 +16,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger/babbage"
@@ -20,18 +25,70 @@ import (
 	"github.com/blinklabs-io/gouroboros/ledger/shelley"
 
 	"github.com/Salvionied/apollo/v2/backend"
+	"github.com/Salvionied/apollo/v2/constants"
+	"github.com/Salvionied/apollo/v2/plutusencoder"
 )
 
 const (
 	ExMemoryBuffer = 0.2
 	ExStepBuffer   = 0.2
 	StakeDeposit   = 2_000_000
+	// CollateralFallback is the collateral amount used when it cannot be
+	// computed from the protocol parameters.
+	CollateralFallback = 5_000_000
 )
 
+// BuilderConfig holds the fallback values the builder uses when the chain
+// context cannot provide the real ones. Override them with SetConfig when
+// building against a network whose parameters differ from mainnet's.
+type BuilderConfig struct {
+	// StakeDeposit is the stake key deposit used when the protocol
+	// parameters do not report one.
+	StakeDeposit int64 `json:"stake_deposit"`
+	// CollateralFallback is the collateral amount used when the protocol
+	// parameters or maximum fee are unavailable.
+	CollateralFallback int64 `json:"collateral_fallback"`
+	// MinLovelace is the amount at or above which a plain ADA-only payment
+	// skips the protocol-parameter min-UTxO check. Zero always checks.
+	MinLovelace int64 `json:"min_lovelace"`
+}
+
+// DefaultBuilderConfig returns the fallback values New uses.
+func DefaultBuilderConfig() BuilderConfig {
+	return BuilderConfig{
+		StakeDeposit:       StakeDeposit,
+		CollateralFallback: CollateralFallback,
+		MinLovelace:        constants.MinLovelace,
+	}
+}
+
 // ErrPlutusV4RequiresDijkstra reports that an operation needs a Dijkstra-era
 // transaction witness set, while Apollo currently builds Conway transactions.
 var ErrPlutusV4RequiresDijkstra = errors.New("plutus V4 requires Dijkstra transaction support; Apollo currently builds Conway transactions")
 
+// ErrTxTooLarge is matched by errors.Is when Complete rejects a transaction
+// larger than the protocol's max tx size; errors.As with TxTooLargeError
+// gives the sizes.
+var ErrTxTooLarge = errors.New("transaction exceeds max tx size")
+
+// TxTooLargeError reports a transaction whose estimated signed size exceeds
+// the protocol's max tx size. Sizes are in bytes.
+type TxTooLargeError struct {
+	Size         int
+	MaxSize      int
+	MetadataSize int
+}
+
+func (e *TxTooLargeError) Error() string {
+	return fmt.Sprintf("transaction is %d bytes, exceeds max tx size %d (metadata is %d bytes)",
+		e.Size, e.MaxSize, e.MetadataSize)
+}
+
+// Unwrap lets callers use errors.Is(err, ErrTxTooLarge).
+func (e *TxTooLargeError) Unwrap() error {
+	return ErrTxTooLarge
+}
+
 // Apollo is the main transaction builder.
 type Apollo struct {
 	Context            backend.ChainContext
@@ -43,12 +100,16 @@ type Apollo struct {
 	tx                 *conway.ConwayTransaction
 	datums             []common.Datum
 	requiredSigners    []common.Blake2b224
+	// stakeWitnessHashes holds the stake key hashes of inputs added with
+	// AddInputWithStakeWitness; Sign adds the matching stake witness.
+	stakeWitnessHashes []common.Blake2b224
 	v1scripts          []common.PlutusV1Script
 	v2scripts          []common.PlutusV2Script
 	v3scripts          []common.PlutusV3Script
 	redeemers          map[string]redeemerEntry // keyed by UTxO ref string
 	stakeRedeemers     map[string]redeemerEntry
 	mintRedeemers      map[string]redeemerEntry
+	voteRedeemers      map[string]redeemerEntry // keyed by voterKey
 	mint               []Unit
 	collaterals        []common.Utxo
 	Fee                int64
@@ -58,22 +119,30 @@ type Apollo struct {
 	totalCollateral    int64
 	referenceInputs    []shelley.ShelleyTransactionInput
 	collateralReturn   *babbage.BabbageTransactionOutput
-	// collateralOverlapRef holds the ref of an auto-selected collateral UTxO
-	// that is also allowed to serve as a regular spending input. It is set only
-	// when no dedicated (separate) collateral UTxO was available, so wallets
-	// with a single UTxO can still build script transactions. The Cardano ledger
-	// permits this overlap because collateral is consumed only on phase-2 script
-	// failure and regular inputs only on success - the two paths are mutually
-	// exclusive. When empty, collateral is reserved out of the coin-selection
-	// pool as usual.
-	collateralOverlapRef string
+	// collateralOverlapRefs holds the refs of auto-selected collateral UTxOs
+	// that are also allowed to serve as regular spending inputs. They are set
+	// when no dedicated (separate) collateral was available, so wallets with a
+	// single UTxO can still build script transactions, or when
+	// SetCollateralFromInputs is enabled. The Cardano ledger permits this
+	// overlap because collateral is consumed only on phase-2 script failure and
+	// regular inputs only on success - the two paths are mutually exclusive.
+	// When empty, collateral is reserved out of the coin-selection pool as
+	// usual.
+	collateralOverlapRefs []string
 	// collateralAutoSelected is true when setCollateral() chose the collateral
 	// inputs itself (rather than the caller pinning them via AddCollateral).
 	// Only auto-selected collateral is resized by finalizeCollateral(), so
 	// caller-pinned collateral is never silently rewritten.
 	collateralAutoSelected     bool
+	collateralFromInputs       bool
+	expectedSigners            int
+	logger                     *slog.Logger
+	ctx                        context.Context
 	nativescripts              []common.NativeScript
 	usedUtxos                  map[string]bool
+	utxoLocker                 UTxOLocker
+	utxoLockTTL                time.Duration
+	utxoLockOwner              string
 	wallet                     Wallet
 	evaluationWitnessProviders []EvaluationWitnessProvider
 	certificates               []common.CertificateWrapper
@@ -86,10 +155,50 @@ type Apollo struct {
 	collateralAmount           int64
 	scriptHashes               []string
 	changeAddress              *common.Address
+	changeSplits               []changeSplit
+	changeAssetStrategy        ChangeAssetStrategy
+	compactOversizedChange     bool
+	sweep                      bool
+	maxRedeemerExUnits         map[common.RedeemerKey]common.ExUnits
+	assetFloors                []Unit
 	estimateExUnits            bool
-	forceFee                   bool
-	coinSelector               CoinSelector
-	err                        error
+	dedupReferenceScripts      bool
+	resolveScripts             bool
+	exMemoryBuffer             float64
+	exStepBuffer               float64
+	// exUnitSafetyFactor multiplies evaluated ExUnits on top of the buffers;
+	// zero behaves like 1.
+	exUnitSafetyFactor float64
+	// utxoLoadLimit is how many UTxOs loadUtxos loads per address before
+	// coin selection (0 means all); utxoLoadCursors track the addresses with
+	// UTxOs left to load if selection falls short.
+	utxoLoadLimit   int
+	utxoLoadCursors []utxoLoadCursor
+	// dedupReferenceUtxos are the reference inputs, resolved once per
+	// Complete for DedupReferenceScripts.
+	dedupReferenceUtxos []common.Utxo
+	// metadataSizeLimit caps the encoded metadata size in bytes (0 means
+	// only the max tx size applies).
+	metadataSizeLimit int
+	// exactInputs disables coin selection so only preselected inputs are
+	// spent (CompleteExact).
+	exactInputs bool
+	// localEvaluation, when set, evaluates scripts in process with its slot
+	// configuration instead of calling Context.EvaluateTx.
+	localEvaluation *SlotConfig
+	forceFee        bool
+	coinSelector    CoinSelector
+	config          BuilderConfig
+	// loadedTx holds the top-level CBOR items of a transaction loaded with
+	// LoadTxCbor, so signing and serialization reuse its original body bytes.
+	loadedTx []cbor.RawMessage
+	// changeOutputs is the number of change outputs Complete appended after
+	// the payment outputs of tx.
+	changeOutputs int
+	// noUTxOLocks keeps Complete from reserving inputs, for the throwaway
+	// clones that ProjectedOutputCount completes.
+	noUTxOLocks bool
+	err         error
 }
 
 type redeemerEntry struct {
@@ -114,9 +223,47 @@ func New(cc backend.ChainContext) *Apollo {
 		redeemers:       make(map[string]redeemerEntry),
 		stakeRedeemers:  make(map[string]redeemerEntry),
 		mintRedeemers:   make(map[string]redeemerEntry),
+		voteRedeemers:   make(map[string]redeemerEntry),
 		withdrawals:     make(map[string]withdrawalEntry),
 		estimateExUnits: true,
+		exMemoryBuffer:  ExMemoryBuffer,
+		exStepBuffer:    ExStepBuffer,
+		config:          DefaultBuilderConfig(),
+		logger:          discardLogger,
+	}
+}
+
+// discardLogger is the default logger; it drops every record.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// SetLogger sets the logger that receives debug diagnostics from Complete():
+// selected inputs, the preliminary fee, evaluation results, each
+// fee-convergence iteration and the final balance. A nil logger restores the
+// default, which discards everything.
+func (a *Apollo) SetLogger(logger *slog.Logger) *Apollo {
+	if logger == nil {
+		logger = discardLogger
 	}
+	a.logger = logger
+	return a
+}
+
+// WithContext makes the builder's chain context requests, such as those of
+// Complete and Submit, follow the cancellation and deadline of ctx. Backends
+// that do not implement backend.ContextBinder cannot be bound, but Complete
+// still fails once ctx is done.
+func (a *Apollo) WithContext(ctx context.Context) *Apollo {
+	a.ctx = ctx
+	a.Context = backend.WithContext(ctx, a.Context)
+	return a
+}
+
+// debug emits a build diagnostic on the configured logger.
+func (a *Apollo) debug(msg string, args ...any) {
+	if a.logger == nil {
+		return
+	}
+	a.logger.Debug(msg, args...)
 }
 
 // SetWallet sets the wallet for the transaction builder.
@@ -127,7 +274,8 @@ func (a *Apollo) SetWallet(w Wallet) *Apollo {
 
 // AddEvaluationWitnessProvider registers an optional source of witnesses for
 // preliminary transaction evaluation. Registered witnesses are never added to
-// the completed transaction.
+// the completed transaction. ExternalWallet and CIP30Wallet cannot sign the
+// preliminary body, so their required signers must be covered by a provider.
 func (a *Apollo) AddEvaluationWitnessProvider(provider EvaluationWitnessProvider) *Apollo {
 	if provider != nil {
 		a.evaluationWitnessProviders = append(a.evaluationWitnessProviders, provider)
@@ -161,6 +309,65 @@ func (a *Apollo) AddPayment(payment PaymentI) *Apollo {
 	return a
 }
 
+// SetConfig replaces the builder's fallback values. Amounts must not be
+// negative and the collateral fallback must be positive.
+func (a *Apollo) SetConfig(cfg BuilderConfig) *Apollo {
+	if cfg.StakeDeposit < 0 || cfg.CollateralFallback <= 0 || cfg.MinLovelace < 0 {
+		a.setErrOnce(fmt.Errorf("SetConfig: invalid fallback values %+v", cfg))
+		return a
+	}
+	a.config = cfg
+	return a
+}
+
+// SetUTxOLoadLimit caps how many UTxOs are loaded from each input address (and
+// from the wallet address fallback) before coin selection in Complete(). On a
+// backend that pages UTxO queries (backend.UtxoPager), such as BlockFrost,
+// paging stops once n UTxOs are loaded; of those, the n with the most lovelace
+// are offered to selection. This speeds up building for addresses with very
+// many UTxOs. It is a heuristic: when the offered UTxOs cannot cover the
+// transaction, the rest are loaded a page at a time until selection succeeds
+// or every UTxO is loaded. n <= 0 removes the limit.
+func (a *Apollo) SetUTxOLoadLimit(n int) *Apollo {
+	a.utxoLoadLimit = max(n, 0)
+	return a
+}
+
+// SetMetadataSizeLimit caps the CBOR-encoded size of the transaction metadata
+// at n bytes. Complete() fails before coin selection when the metadata is
+// larger, so large files are referenced (e.g. by IPFS URI) rather than
+// embedded. n <= 0 removes the limit; the whole transaction must still fit
+// the protocol's max tx size.
+func (a *Apollo) SetMetadataSizeLimit(n int) *Apollo {
+	a.metadataSizeLimit = max(n, 0)
+	return a
+}
+
+// SetAssetFloor keeps at least minQty of an asset in the wallet. Complete()
+// fails when the payments and burns in the transaction would leave less than
+// that across the loaded UTxOs and the change. Unused tokens always return as
+// change, so the floor only constrains what the transaction sends away. The
+// asset name is hex-encoded; setting a floor again replaces the previous one.
+func (a *Apollo) SetAssetFloor(policyId, assetName string, minQty int64) *Apollo {
+	if policyId == "" || policyId == "lovelace" {
+		a.setErrOnce(errors.New("SetAssetFloor: policy ID is required"))
+		return a
+	}
+	floor := NewUnit(policyId, assetName, minQty)
+	if _, err := floor.ToValue(); err != nil {
+		a.setErrOnce(fmt.Errorf("SetAssetFloor: %w", err))
+		return a
+	}
+	for i, existing := range a.assetFloors {
+		if existing.PolicyId == floor.PolicyId && existing.Name == floor.Name {
+			a.assetFloors[i] = floor
+			return a
+		}
+	}
+	a.assetFloors = append(a.assetFloors, floor)
+	return a
+}
+
 // AddLoadedUTxOs adds UTxOs to the available pool for coin selection.
 func (a *Apollo) AddLoadedUTxOs(utxos ...common.Utxo) *Apollo {
 	a.utxos = append(a.utxos, utxos...)
@@ -173,6 +380,72 @@ func (a *Apollo) AddInput(utxo common.Utxo) *Apollo {
 	return a
 }
 
+// RequireInputWithAsset spends the UTxO at txHash#index, which must hold the
+// asset identified by the hex policy ID and hex asset name. Use it when several
+// UTxOs carry the asset and a contract cares which one is consumed.
+func (a *Apollo) RequireInputWithAsset(txHash string, index int, policyId, assetName string) (*Apollo, error) {
+	policyBytes, err := hex.DecodeString(policyId)
+	if err != nil {
+		return a, fmt.Errorf("invalid policy ID hex %q: %w", policyId, err)
+	}
+	if len(policyBytes) != common.Blake2b224Size {
+		return a, fmt.Errorf("invalid policy ID length for %q: expected %d bytes, got %d", policyId, common.Blake2b224Size, len(policyBytes))
+	}
+	name, err := hex.DecodeString(assetName)
+	if err != nil {
+		return a, fmt.Errorf("invalid asset name hex %q: %w", assetName, err)
+	}
+	utxo, err := a.UtxoFromRef(txHash, index)
+	if err != nil {
+		return a, fmt.Errorf("failed to resolve UTxO %s#%d: %w", txHash, index, err)
+	}
+	if utxo == nil || utxo.Output == nil {
+		return a, fmt.Errorf("UTxO %s#%d not found", txHash, index)
+	}
+	var policy common.Blake2b224
+	copy(policy[:], policyBytes)
+	var qty *big.Int
+	if assets := utxo.Output.Assets(); assets != nil {
+		qty = assets.Asset(policy, name)
+	}
+	if qty == nil || qty.Sign() <= 0 {
+		return a, fmt.Errorf("UTxO %s#%d does not hold asset %s.%s", txHash, index, policyId, assetName)
+	}
+	ref := utxoRef(*utxo)
+	for _, preselected := range a.preselectedUtxos {
+		if utxoRef(preselected) == ref {
+			return a, nil
+		}
+	}
+	return a.AddInput(*utxo), nil
+}
+
+// AddInputWithStakeWitness adds a specific UTxO as a transaction input and
+// marks the stake credential of its address as a required witness. Use it for
+// base-address UTxOs whose spending conditions also demand the stake key
+// signature. The stake key hash is added to the required signers, so scripts
+// see it in extra_signatories. Sign adds the stake witness only when the
+// stake key is the wallet's; for any other key, the witness must be added with
+// AddVerificationKeyWitness or the transaction is rejected.
+func (a *Apollo) AddInputWithStakeWitness(utxo common.Utxo) *Apollo {
+	cred, err := GetStakeCredentialFromAddress(utxo.Output.Address())
+	if err != nil {
+		a.setErrOnce(fmt.Errorf("AddInputWithStakeWitness: %w", err))
+		return a
+	}
+	if cred.CredType != common.CredentialTypeAddrKeyHash {
+		a.setErrOnce(errors.New("AddInputWithStakeWitness: input address stake credential is not a key hash"))
+		return a
+	}
+	hash := common.Blake2b224(cred.Credential)
+	a.preselectedUtxos = append(a.preselectedUtxos, utxo)
+	a.stakeWitnessHashes = append(a.stakeWitnessHashes, hash)
+	if !slices.Contains(a.requiredSigners, hash) {
+		a.requiredSigners = append(a.requiredSigners, hash)
+	}
+	return a
+}
+
 // AddInputAddress adds an address whose UTxOs should be used for coin selection.
 func (a *Apollo) AddInputAddress(addr common.Address) *Apollo {
 	a.inputAddresses = append(a.inputAddresses, addr)
@@ -200,18 +473,153 @@ func (a *Apollo) AddRequiredSignerStakeKey(addr common.Address) *Apollo {
 	return a
 }
 
+// ApplyNativeScriptConstraints narrows the validity interval to the
+// invalid-before and invalid-hereafter slots a native script demands and adds
+// every key hash it requires as a required signer. Only conditions that must
+// always hold are applied: the top level, all-of scripts, and any-of or n-of-k
+// scripts whose every sub-script is needed. Choosing among alternatives is
+// left to the caller.
+func (a *Apollo) ApplyNativeScriptConstraints(ns common.NativeScript) *Apollo {
+	var c nativeScriptConstraints
+	if err := c.collect(&ns); err != nil {
+		a.setErrOnce(fmt.Errorf("ApplyNativeScriptConstraints: %w", err))
+		return a
+	}
+	start, ttl := a.ValidityStart, a.Ttl
+	if c.before != nil {
+		start = max(start, *c.before)
+	}
+	if c.hereafter != nil && (ttl == 0 || *c.hereafter < ttl) {
+		ttl = *c.hereafter
+	}
+	if ttl != 0 {
+		if err := validateValidityInterval(start, ttl); err != nil {
+			a.setErrOnce(fmt.Errorf("ApplyNativeScriptConstraints: %w", err))
+			return a
+		}
+	}
+	a.ValidityStart, a.Ttl = start, ttl
+	for _, signer := range c.signers {
+		if !slices.Contains(a.requiredSigners, signer) {
+			a.requiredSigners = append(a.requiredSigners, signer)
+		}
+	}
+	return a
+}
+
+// nativeScriptConstraints accumulates the conditions every satisfying
+// transaction must meet: the latest invalid-before slot, the earliest
+// invalid-hereafter slot, and the required key hashes.
+type nativeScriptConstraints struct {
+	before    *int64
+	hereafter *int64
+	signers   []common.Blake2b224
+}
+
+func (c *nativeScriptConstraints) collect(ns *common.NativeScript) error {
+	switch s := ns.Item().(type) {
+	case *common.NativeScriptPubkey:
+		if len(s.Hash) != common.Blake2b224Size {
+			return fmt.Errorf("invalid key hash length: expected %d bytes, got %d", common.Blake2b224Size, len(s.Hash))
+		}
+		var hash common.Blake2b224
+		copy(hash[:], s.Hash)
+		c.signers = append(c.signers, hash)
+	case *common.NativeScriptAll:
+		return c.collectAll(s.Scripts)
+	case *common.NativeScriptAny:
+		if len(s.Scripts) == 1 {
+			return c.collectAll(s.Scripts)
+		}
+	case *common.NativeScriptNofK:
+		if int(s.N) == len(s.Scripts) { //nolint:gosec // compared against a slice length
+			return c.collectAll(s.Scripts)
+		}
+	case *common.NativeScriptInvalidBefore:
+		slot, err := nativeScriptSlot(s.Slot)
+		if err != nil {
+			return err
+		}
+		if c.before == nil || slot > *c.before {
+			c.before = &slot
+		}
+	case *common.NativeScriptInvalidHereafter:
+		slot, err := nativeScriptSlot(s.Slot)
+		if err != nil {
+			return err
+		}
+		if c.hereafter == nil || slot < *c.hereafter {
+			c.hereafter = &slot
+		}
+	default:
+		return fmt.Errorf("unsupported native script type %T", s)
+	}
+	return nil
+}
+
+func (c *nativeScriptConstraints) collectAll(scripts []common.NativeScript) error {
+	for i := range scripts {
+		if err := c.collect(&scripts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nativeScriptSlot(slot uint64) (int64, error) {
+	if slot > math.MaxInt64 {
+		return 0, fmt.Errorf("native script slot %d overflows int64", slot)
+	}
+	return int64(slot), nil
+}
+
 // SetTtl sets the transaction time-to-live.
 func (a *Apollo) SetTtl(ttl int64) *Apollo {
 	a.Ttl = ttl
 	return a
 }
 
+// SetDefaultValidityInterval sets the validity interval to start at the
+// current chain tip and end secondsAhead seconds later, converted to slots
+// like ValidFor. The chain context must report its tip.
+func (a *Apollo) SetDefaultValidityInterval(secondsAhead int64) (*Apollo, error) {
+	if secondsAhead <= 0 {
+		return a, fmt.Errorf("secondsAhead must be positive, got %d", secondsAhead)
+	}
+	if secondsAhead > int64(math.MaxInt64/time.Second) {
+		return a, fmt.Errorf("secondsAhead %d overflows a duration", secondsAhead)
+	}
+	start, end, err := a.intervalFor(time.Duration(secondsAhead) * time.Second)
+	if err != nil {
+		return a, err
+	}
+	if err := validateValidityInterval(start, end); err != nil {
+		return a, err
+	}
+	a.ValidityStart = start
+	a.Ttl = end
+	return a, nil
+}
+
 // SetValidityStart sets the validity start slot.
 func (a *Apollo) SetValidityStart(start int64) *Apollo {
 	a.ValidityStart = start
 	return a
 }
 
+// SetValidityInterval sets the validity start slot (invalid-before) and the
+// TTL (invalid-hereafter) together. The interval is half-open, so start must
+// be strictly less than end; an empty interval is recorded as an error.
+func (a *Apollo) SetValidityInterval(start, end int64) *Apollo {
+	if err := validateValidityInterval(start, end); err != nil {
+		a.setErrOnce(fmt.Errorf("SetValidityInterval: %w", err))
+		return a
+	}
+	a.ValidityStart = start
+	a.Ttl = end
+	return a
+}
+
 // SetFee sets a specific fee (disables fee estimation).
 func (a *Apollo) SetFee(fee int64) *Apollo {
 	a.Fee = fee
@@ -224,6 +632,18 @@ func (a *Apollo) SetFeePadding(padding int64) *Apollo {
 	return a
 }
 
+// SetExpectedSigners sets the minimum number of vkey witnesses assumed when
+// estimating the fee, for multi-signature transactions whose signers the
+// builder cannot infer from its inputs, certificates and scripts.
+func (a *Apollo) SetExpectedSigners(n int) *Apollo {
+	if n < 0 {
+		a.setErrOnce(fmt.Errorf("SetExpectedSigners: signer count must be non-negative, got %d", n))
+		return a
+	}
+	a.expectedSigners = n
+	return a
+}
+
 // SetCoinSelector sets the coin selection algorithm used by Complete to
 // choose inputs. When unset, the package default selector is used.
 func (a *Apollo) SetCoinSelector(selector CoinSelector) *Apollo {
@@ -241,6 +661,97 @@ func (a *Apollo) ForceFee(fee int64) *Apollo {
 // SetChangeAddress sets the address to receive change outputs.
 func (a *Apollo) SetChangeAddress(addr common.Address) *Apollo {
 	a.changeAddress = &addr
+	a.changeSplits = nil
+	return a
+}
+
+// SetChangeAddresses splits change across several addresses. The lovelace of
+// the change is divided in proportion to weights (one positive weight per
+// address); all native assets go to the first address so NFTs and token
+// bundles are not fragmented, and it also receives collateral return. Every
+// change output must meet its min-UTxO or Complete() fails. A single address
+// behaves like SetChangeAddress.
+func (a *Apollo) SetChangeAddresses(addrs []common.Address, weights []int) *Apollo {
+	if len(addrs) == 0 {
+		a.setErrOnce(errors.New("SetChangeAddresses: at least one address is required"))
+		return a
+	}
+	if len(weights) != len(addrs) {
+		a.setErrOnce(fmt.Errorf("SetChangeAddresses: got %d weights for %d addresses", len(weights), len(addrs)))
+		return a
+	}
+	splits := make([]changeSplit, 0, len(addrs))
+	var total uint64
+	for i, weight := range weights {
+		if weight <= 0 {
+			a.setErrOnce(fmt.Errorf("SetChangeAddresses: weight %d must be positive, got %d", i, weight))
+			return a
+		}
+		total += uint64(weight)
+		if total > math.MaxInt64 {
+			a.setErrOnce(errors.New("SetChangeAddresses: total weight overflows"))
+			return a
+		}
+		splits = append(splits, changeSplit{Address: addrs[i], Weight: weight})
+	}
+	a.SetChangeAddress(addrs[0])
+	if len(splits) > 1 {
+		a.changeSplits = splits
+	}
+	return a
+}
+
+// SweepTo makes Complete() spend every available UTxO and send everything
+// left after the fee and any payments, native assets included, to
+// destination as change. The change is split only when it would exceed the
+// max value size, and a script transaction takes its collateral from the
+// swept inputs. A UTxO load limit (SetUTxOLoadLimit) also limits the sweep.
+func (a *Apollo) SweepTo(destination common.Address) (*Apollo, error) {
+	if a.wallet == nil && len(a.inputAddresses) == 0 && len(a.utxos) == 0 {
+		return a, errors.New("SweepTo: a wallet, input address, or loaded UTxOs are required")
+	}
+	a.SetChangeAddress(destination)
+	a.sweep = true
+	a.collateralFromInputs = true
+	return a, nil
+}
+
+// ChangeAssetStrategy selects how the native assets in change are laid out
+// over change outputs.
+type ChangeAssetStrategy int
+
+const (
+	// ChangeAssetsSingleOutput keeps every asset in one change output, split
+	// only when it would exceed the protocol's max value size.
+	ChangeAssetsSingleOutput ChangeAssetStrategy = iota
+
+	// ChangeAssetsPerPolicy gives each policy its own change output.
+	ChangeAssetsPerPolicy
+
+	// ChangeAssetsSpread uses as few change outputs as the max value size
+	// allows but balances the assets evenly across them.
+	ChangeAssetsSpread
+)
+
+// SetChangeAssetStrategy sets how native assets in change are placed. Each
+// change output holds its own min-UTxO, so Complete() fails if the change
+// lovelace cannot cover them all. Weighted change (SetChangeAddresses) keeps
+// its own layout and ignores the strategy.
+func (a *Apollo) SetChangeAssetStrategy(strategy ChangeAssetStrategy) *Apollo {
+	if strategy < ChangeAssetsSingleOutput || strategy > ChangeAssetsSpread {
+		a.setErrOnce(fmt.Errorf("SetChangeAssetStrategy: unknown strategy %d", strategy))
+		return a
+	}
+	a.changeAssetStrategy = strategy
+	return a
+}
+
+// SetCompactChangeOnOversize lets Complete retry a transaction that exceeds
+// the max tx size with its change assets in as few outputs as the max value
+// size allows, instead of the layout chosen by SetChangeAssetStrategy. The
+// TxTooLargeError is returned if the transaction is still too large.
+func (a *Apollo) SetCompactChangeOnOversize(enabled bool) *Apollo {
+	a.compactOversizedChange = enabled
 	return a
 }
 
@@ -302,6 +813,29 @@ func (a *Apollo) Mint(unit Unit, redeemer *common.Datum, exUnits *common.ExUnits
 	return a
 }
 
+// MintTyped is Mint with the redeemer given as a Go struct tagged for
+// plutusencoder.
+func (a *Apollo) MintTyped(unit Unit, redeemer any, exUnits *common.ExUnits) (*Apollo, error) {
+	d, err := typedDatum("redeemer", redeemer)
+	if err != nil {
+		return a, err
+	}
+	return a.Mint(unit, d, exUnits), nil
+}
+
+// BurnAssets burns unit.Quantity of a token, given as a positive amount. It
+// adds the negative mint entry, and Complete selects UTxOs holding the tokens
+// alongside the ADA it needs. redeemer is required by a Plutus minting policy
+// and nil for a native one; its execution units are estimated.
+func (a *Apollo) BurnAssets(unit Unit, redeemer *common.Datum) *Apollo {
+	if unit.Quantity <= 0 {
+		a.setErrOnce(fmt.Errorf("BurnAssets: quantity must be positive, got %d", unit.Quantity))
+		return a
+	}
+	unit.Quantity = -unit.Quantity
+	return a.Mint(unit, redeemer, nil)
+}
+
 // AttachScript attaches a script to the witness set, deduplicating by hash.
 // It accepts NativeScript and PlutusV1Script through PlutusV3Script. Plutus V4
 // witnesses require Dijkstra-era transaction support and cause Complete to
@@ -342,12 +876,146 @@ func (a *Apollo) AttachScript(script common.Script) *Apollo {
 	return a
 }
 
+// AttachScripts attaches several scripts to the witness set at once. Scripts
+// are deduplicated by hash against each other and against scripts that were
+// already attached; see AttachScript for the accepted script types.
+func (a *Apollo) AttachScripts(scripts ...common.Script) *Apollo {
+	for _, script := range scripts {
+		a.AttachScript(script)
+	}
+	return a
+}
+
+// HasScript reports whether a script with the given hash is available to the
+// transaction, either attached to the witness set or carried as a reference
+// script by a preselected input or a reference input. Reference inputs that
+// cannot be resolved through the chain context are skipped.
+func (a *Apollo) HasScript(hash common.Blake2b224) bool {
+	if a.hasScriptHash(hash.String()) {
+		return true
+	}
+	for _, utxo := range a.preselectedUtxos {
+		if script := utxo.Output.ScriptRef(); script != nil && script.Hash() == hash {
+			return true
+		}
+	}
+	for _, refInput := range a.referenceInputs {
+		utxo, err := a.Context.UtxoByRef(refInput.TxId, refInput.OutputIndex)
+		if err != nil || utxo == nil {
+			continue
+		}
+		if script := utxo.Output.ScriptRef(); script != nil && script.Hash() == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// DedupReferenceScripts controls whether Complete() drops attached scripts
+// from the witness set when a spending input or reference input already
+// carries them as a reference script, saving their bytes and fees. A script
+// paid out as a reference script by this transaction's own outputs is not
+// available to it, so such a script stays in the witness set. Complete fails
+// when a reference input cannot be resolved.
+func (a *Apollo) DedupReferenceScripts(enabled bool) *Apollo {
+	a.dedupReferenceScripts = enabled
+	return a
+}
+
+// SetCollateralFromInputs controls whether auto-selected collateral is taken
+// from the transaction's own spending inputs. When enabled, the collateral UTxO
+// is not reserved out of coin selection, and after selection an ADA-only vkey
+// input large enough to back the collateral replaces it, so no UTxO beyond the
+// spent inputs is referenced. The collateral return hands its remainder back
+// if a script fails; on success the input is spent normally. Caller-pinned
+// collateral (AddCollateral) is never replaced.
+func (a *Apollo) SetCollateralFromInputs(enabled bool) *Apollo {
+	a.collateralFromInputs = enabled
+	return a
+}
+
 // DisableExecutionUnitsEstimation disables automatic ExUnit estimation.
 func (a *Apollo) DisableExecutionUnitsEstimation() *Apollo {
 	a.estimateExUnits = false
 	return a
 }
 
+// SetExUnitBuffers overrides the safety margins applied to evaluated execution
+// units. memPct and stepPct are fractions (0.2 adds 20%), default to
+// ExMemoryBuffer and ExStepBuffer, and must be finite and non-negative.
+func (a *Apollo) SetExUnitBuffers(memPct, stepPct float64) *Apollo {
+	if !(memPct >= 0) || math.IsInf(memPct, 0) {
+		a.setErrOnce(fmt.Errorf("SetExUnitBuffers: memory buffer must be finite and non-negative, got %v", memPct))
+		return a
+	}
+	if !(stepPct >= 0) || math.IsInf(stepPct, 0) {
+		a.setErrOnce(fmt.Errorf("SetExUnitBuffers: step buffer must be finite and non-negative, got %v", stepPct))
+		return a
+	}
+	a.exMemoryBuffer = memPct
+	a.exStepBuffer = stepPct
+	return a
+}
+
+// SetExUnitSafetyFactor multiplies evaluated execution units by factor on top
+// of the ExUnit buffers, for scripts whose cost depends on data that can grow
+// between evaluation and submission. factor must be finite and at least 1;
+// the default is 1.
+func (a *Apollo) SetExUnitSafetyFactor(factor float64) *Apollo {
+	if !(factor >= 1) || math.IsInf(factor, 0) {
+		a.setErrOnce(fmt.Errorf("SetExUnitSafetyFactor: factor must be finite and at least 1, got %v", factor))
+		return a
+	}
+	a.exUnitSafetyFactor = factor
+	return a
+}
+
+// SetMaxExUnitsPerRedeemer caps the execution units of the redeemer at key.
+// Complete() fails rather than build a transaction whose redeemer, after
+// evaluation and buffering, exceeds either limit.
+func (a *Apollo) SetMaxExUnitsPerRedeemer(key common.RedeemerKey, limit common.ExUnits) *Apollo {
+	if limit.Memory < 0 || limit.Steps < 0 {
+		a.setErrOnce(fmt.Errorf("SetMaxExUnitsPerRedeemer: negative cap mem=%d steps=%d", limit.Memory, limit.Steps))
+		return a
+	}
+	if a.maxRedeemerExUnits == nil {
+		a.maxRedeemerExUnits = make(map[common.RedeemerKey]common.ExUnits)
+	}
+	a.maxRedeemerExUnits[key] = limit
+	return a
+}
+
+// exUnitsNearLimitRatio is the share of a per-transaction execution unit
+// maximum above which ExUnitsBudgetWarnings reports a warning.
+const exUnitsNearLimitRatio = 0.9
+
+// ExUnitsBudgetWarnings reports when the redeemers of the completed
+// transaction use more than 90% of the per-transaction execution memory or
+// step maximum. Data-dependent scripts that cost more on chain than in
+// evaluation may then exceed the budget; raise SetExUnitSafetyFactor or split
+// the transaction.
+func (a *Apollo) ExUnitsBudgetWarnings() ([]string, error) {
+	if a.tx == nil {
+		return nil, errors.New("transaction not built - call Complete() first")
+	}
+	totalMem, totalSteps, err := sumRedeemerExUnits(a.tx.WitnessSet.WsRedeemers.Redeemers)
+	if err != nil {
+		return nil, err
+	}
+	maxMem, maxSteps, err := a.maxTxExUnits()
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	if maxMem > 0 && float64(totalMem) > exUnitsNearLimitRatio*float64(maxMem) {
+		warnings = append(warnings, fmt.Sprintf("execution memory %d is within 10%% of the per-transaction maximum of %d", totalMem, maxMem))
+	}
+	if maxSteps > 0 && float64(totalSteps) > exUnitsNearLimitRatio*float64(maxSteps) {
+		warnings = append(warnings, fmt.Sprintf("execution steps %d are within 10%% of the per-transaction maximum of %d", totalSteps, maxSteps))
+	}
+	return warnings, nil
+}
+
 // --- Smart Contract Methods ---
 
 // CollectFrom adds a script UTxO as input with a spending redeemer.
@@ -363,46 +1031,157 @@ func (a *Apollo) CollectFrom(utxo common.Utxo, redeemer common.Datum, exUnits co
 	return a
 }
 
-// PayToContract creates a payment to a script address with an inline datum.
-func (a *Apollo) PayToContract(addr common.Address, datum *common.Datum, lovelace int64, units ...Unit) *Apollo {
-	p := &Payment{
-		Receiver: addr,
-		Lovelace: lovelace,
-		Units:    units,
-		Datum:    datum,
-		IsInline: true,
+// CollectFromTyped is CollectFrom with the redeemer given as a Go struct
+// tagged for plutusencoder.
+func (a *Apollo) CollectFromTyped(utxo common.Utxo, redeemer any, exUnits common.ExUnits) (*Apollo, error) {
+	d, err := typedDatum("redeemer", redeemer)
+	if err != nil {
+		return a, err
 	}
-	a.payments = append(a.payments, p)
-	return a
+	return a.CollectFrom(utxo, *d, exUnits), nil
 }
 
-// PayToContractWithDatumHash creates a payment to a script address with a datum hash.
-// The datum is added to the witness set and its hash is placed in the output.
-func (a *Apollo) PayToContractWithDatumHash(addr common.Address, datum *common.Datum, lovelace int64, units ...Unit) (*Apollo, error) {
-	p := &Payment{
-		Receiver: addr,
-		Lovelace: lovelace,
-		Units:    units,
+// UpdateContractState spends a script UTxO and pays its full value back to the
+// same address with newDatum inline, the usual state-machine step. A reference
+// script on the UTxO is carried over. The spending script must already be
+// attached with AttachScript, carried by the UTxO, or available through a
+// reference input. Lovelace is raised to the output's minimum when the new
+// datum needs more; the difference comes from coin selection.
+func (a *Apollo) UpdateContractState(utxo common.Utxo, redeemer common.Datum, newDatum common.Datum, exUnits common.ExUnits) (*Apollo, error) {
+	if utxo.Output == nil {
+		return a, errors.New("UTxO has no output")
 	}
-	if datum != nil {
-		datumCbor, err := cbor.Encode(datum)
-		if err != nil {
-			return a, fmt.Errorf("failed to encode datum: %w", err)
+	addr := utxo.Output.Address()
+	switch addr.Type() {
+	case common.AddressTypeScriptKey, common.AddressTypeScriptScript,
+		common.AddressTypeScriptPointer, common.AddressTypeScriptNone:
+	default:
+		return a, fmt.Errorf("UTxO %s is not locked by a script", utxoRef(utxo))
+	}
+	scriptHash := addr.PaymentKeyHash()
+	ownScript := utxo.Output.ScriptRef()
+	if !a.HasScript(scriptHash) && (ownScript == nil || ownScript.Hash() != scriptHash) {
+		return a, fmt.Errorf("script %s for UTxO %s is neither attached nor available by reference", scriptHash, utxoRef(utxo))
+	}
+	amt := utxo.Output.Amount()
+	if amt == nil || !amt.IsUint64() {
+		return a, fmt.Errorf("UTxO %s has an invalid lovelace amount", utxoRef(utxo))
+	}
+	payment, err := NewPaymentFromValue(addr, NewValue(amt.Uint64(), CloneMultiAsset(utxo.Output.Assets())))
+	if err != nil {
+		return a, err
+	}
+	payment.Datum = &newDatum
+	payment.IsInline = true
+	if ownScript != nil {
+		if payment.ScriptRef, err = NewScriptRef(ownScript); err != nil {
+			return a, fmt.Errorf("failed to carry over reference script: %w", err)
 		}
-		hash := common.Blake2b256Hash(datumCbor)
-		p.DatumHash = hash.Bytes()
-		a.datums = append(a.datums, *datum)
 	}
-	a.payments = append(a.payments, p)
+	a.CollectFrom(utxo, redeemer, exUnits)
+	a.payments = append(a.payments, payment)
 	return a, nil
 }
 
-// resolveCredential resolves a credential from various input types.
-// Accepts: *common.Credential, common.Credential, common.Address, string (bech32), or nil (wallet fallback).
-func (a *Apollo) resolveCredential(v any) (common.Credential, error) {
-	switch val := v.(type) {
-	case *common.Credential:
-		if val != nil {
+// DatumMode selects how a datum is attached to a contract output.
+type DatumMode int
+
+const (
+	// DatumModeInline stores the datum itself in the output.
+	DatumModeInline DatumMode = iota
+
+	// DatumModeHash stores the datum hash in the output and adds the datum to
+	// the witness set.
+	DatumModeHash
+)
+
+// String returns the mode name.
+func (m DatumMode) String() string {
+	switch m {
+	case DatumModeInline:
+		return "inline"
+	case DatumModeHash:
+		return "hash"
+	default:
+		return fmt.Sprintf("DatumMode(%d)", int(m))
+	}
+}
+
+// PayToContractWithDatumOption creates a payment to a script address with the
+// datum attached as selected by mode. A nil datum pays without one.
+func (a *Apollo) PayToContractWithDatumOption(addr common.Address, datum *common.Datum, mode DatumMode, lovelace int64, units ...Unit) (*Apollo, error) {
+	p := &Payment{
+		Receiver: addr,
+		Lovelace: lovelace,
+		Units:    units,
+	}
+	switch mode {
+	case DatumModeInline:
+		p.Datum = datum
+		p.IsInline = true
+	case DatumModeHash:
+		if datum != nil {
+			datumCbor, err := cbor.Encode(datum)
+			if err != nil {
+				return a, fmt.Errorf("failed to encode datum: %w", err)
+			}
+			hash := common.Blake2b256Hash(datumCbor)
+			p.DatumHash = hash.Bytes()
+			a.datums = append(a.datums, *datum)
+		}
+	default:
+		return a, fmt.Errorf("unknown datum mode: %s", mode)
+	}
+	a.payments = append(a.payments, p)
+	return a, nil
+}
+
+// PayToContract creates a payment to a script address with an inline datum.
+func (a *Apollo) PayToContract(addr common.Address, datum *common.Datum, lovelace int64, units ...Unit) *Apollo {
+	a, err := a.PayToContractWithDatumOption(addr, datum, DatumModeInline, lovelace, units...)
+	a.setErrOnce(err)
+	return a
+}
+
+// PayToContractTyped creates a payment to a script address with datum, a Go
+// struct tagged for plutusencoder, encoded as the output's inline datum.
+func (a *Apollo) PayToContractTyped(addr common.Address, datum any, lovelace int64, units ...Unit) (*Apollo, error) {
+	d, err := typedDatum("datum", datum)
+	if err != nil {
+		return a, err
+	}
+	return a.PayToContract(addr, d, lovelace, units...), nil
+}
+
+// typedDatum encodes v, a Go struct tagged for plutusencoder, as a datum. The
+// kind names the value ("datum" or "redeemer") in errors.
+func typedDatum(kind string, v any) (*common.Datum, error) {
+	if v == nil {
+		return nil, fmt.Errorf("%s is required", kind)
+	}
+	pd, err := plutusencoder.MarshalPlutus(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", kind, err)
+	}
+	datum, err := plutusencoder.ToDatum(pd)
+	if err != nil {
+		return nil, fmt.Errorf("%s of type %T: %w", kind, v, err)
+	}
+	return &datum, nil
+}
+
+// PayToContractWithDatumHash creates a payment to a script address with a datum hash.
+// The datum is added to the witness set and its hash is placed in the output.
+func (a *Apollo) PayToContractWithDatumHash(addr common.Address, datum *common.Datum, lovelace int64, units ...Unit) (*Apollo, error) {
+	return a.PayToContractWithDatumOption(addr, datum, DatumModeHash, lovelace, units...)
+}
+
+// resolveCredential resolves a credential from various input types.
+// Accepts: *common.Credential, common.Credential, common.Address, string (bech32), or nil (wallet fallback).
+func (a *Apollo) resolveCredential(v any) (common.Credential, error) {
+	switch val := v.(type) {
+	case *common.Credential:
+		if val != nil {
 			return *val, nil
 		}
 		return a.GetStakeCredentialFromWallet()
@@ -457,6 +1236,97 @@ func (a *Apollo) PayToAddressWithReferenceScript(addr common.Address, lovelace i
 	return a, nil
 }
 
+// ValidatePayments checks every payment without building the transaction and
+// reports all problems at once, joined with errors.Join. For each *Payment it
+// checks that the receiver is on the chain context's network, that lovelace
+// and asset quantities are non-negative, that policy IDs and asset names are
+// well-formed hex, that Datum and DatumHash are not both set, and that the
+// output can be raised to its minimum lovelace. Payments are not modified.
+func (a *Apollo) ValidatePayments() error {
+	var errs []error
+	for i, payment := range a.payments {
+		for _, err := range a.validatePayment(payment) {
+			errs = append(errs, fmt.Errorf("payment %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AddPaymentsFromRecords adds one payment per record, as airdrop and batch
+// import tools need. Every record is validated like ValidatePayments; if any
+// is invalid, no payment is added and the problems of all rows are returned
+// joined with errors.Join.
+func (a *Apollo) AddPaymentsFromRecords(records []PaymentRecord) (*Apollo, error) {
+	var errs []error
+	payments := make([]PaymentI, 0, len(records))
+	for i, record := range records {
+		payment, err := NewPayment(record.Address, record.Lovelace, slices.Clone(record.Units))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("record %d: %w", i, err))
+			continue
+		}
+		if rowErrs := a.validatePayment(payment); len(rowErrs) > 0 {
+			for _, err := range rowErrs {
+				errs = append(errs, fmt.Errorf("record %d: %w", i, err))
+			}
+			continue
+		}
+		payments = append(payments, payment)
+	}
+	if len(errs) > 0 {
+		return a, errors.Join(errs...)
+	}
+	a.payments = append(a.payments, payments...)
+	return a, nil
+}
+
+func (a *Apollo) validatePayment(payment PaymentI) []error {
+	p, ok := payment.(*Payment)
+	if !ok {
+		if _, err := payment.ToTxOut(); err != nil {
+			return []error{err}
+		}
+		return nil
+	}
+	var errs []error
+	if netId, ok := addressNetworkId(p.Receiver); !ok {
+		if p.Receiver.Type() != common.AddressTypeByron {
+			errs = append(errs, errors.New("invalid receiver address"))
+		}
+	} else if a.Context != nil && netId != a.Context.NetworkId() {
+		errs = append(errs, fmt.Errorf("receiver %s is on network %d, expected %d", p.Receiver.String(), netId, a.Context.NetworkId()))
+	}
+	if p.Lovelace < 0 {
+		errs = append(errs, fmt.Errorf("negative lovelace amount: %d", p.Lovelace))
+	}
+	for _, unit := range p.Units {
+		if _, err := unit.ToValue(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if name, _ := hex.DecodeString(unit.Name); len(name) > 32 {
+			errs = append(errs, fmt.Errorf("asset name %q exceeds 32 bytes", unit.Name))
+		}
+	}
+	if p.Datum != nil && len(p.DatumHash) > 0 {
+		errs = append(errs, errors.New("both datum and datum hash are set"))
+	}
+	if len(p.DatumHash) > 0 && len(p.DatumHash) != common.Blake2b256Size {
+		errs = append(errs, fmt.Errorf("invalid datum hash length: expected %d bytes, got %d", common.Blake2b256Size, len(p.DatumHash)))
+	}
+	if len(errs) > 0 || a.Context == nil {
+		return errs
+	}
+	// Run the min-UTxO adjustment on a copy so validation leaves the payment
+	// as the caller built it.
+	probe := *p
+	probe.Units = slices.Clone(p.Units)
+	if err := probe.ensureMinUTXO(a.Context, a.config.MinLovelace); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
 // --- UTxO Consumption Methods ---
 
 // ConsumeUTxO adds a utxo as input, deducts payments, and returns remainder as change.
@@ -531,6 +1401,20 @@ func (a *Apollo) SetCertificates(certs []common.CertificateWrapper) *Apollo {
 	return a
 }
 
+// InsertCertificate inserts cert at index in the certificate list, shifting
+// later certificates back; index len(certificates) appends. The ledger applies
+// certificates in list order, so this lets dependent certificates (e.g. a
+// registration before its delegation) be placed explicitly. An out-of-range
+// index is recorded as an error.
+func (a *Apollo) InsertCertificate(index int, cert common.CertificateWrapper) *Apollo {
+	if index < 0 || index > len(a.certificates) {
+		a.setErrOnce(fmt.Errorf("InsertCertificate: index %d out of range [0, %d]", index, len(a.certificates)))
+		return a
+	}
+	a.certificates = slices.Insert(a.certificates, index, cert)
+	return a
+}
+
 // --- Stake Registration & Deregistration ---
 
 // RegisterStake creates a stake registration certificate.
@@ -756,7 +1640,18 @@ func (a *Apollo) UpdateDRep(cred common.Credential, anchor *common.GovAnchor) *A
 }
 
 // AuthorizeCommitteeHotKey adds a committee hot key authorization certificate.
-func (a *Apollo) AuthorizeCommitteeHotKey(cold common.Credential, hot common.Credential) *Apollo {
+// coldCred and hotCred can be: *common.Credential, common.Credential,
+// common.Address, string (bech32), or nil (uses wallet). The certificate
+// carries no deposit; a key-hash cold credential must sign the transaction.
+func (a *Apollo) AuthorizeCommitteeHotKey(coldCred, hotCred any) (*Apollo, error) {
+	cold, err := a.resolveCredential(coldCred)
+	if err != nil {
+		return a, fmt.Errorf("invalid committee cold credential: %w", err)
+	}
+	hot, err := a.resolveCredential(hotCred)
+	if err != nil {
+		return a, fmt.Errorf("invalid committee hot credential: %w", err)
+	}
 	cert := common.AuthCommitteeHotCertificate{
 		CertType:       uint(common.CertificateTypeAuthCommitteeHot),
 		ColdCredential: cold,
@@ -766,11 +1661,17 @@ func (a *Apollo) AuthorizeCommitteeHotKey(cold common.Credential, hot common.Cre
 		Type:        uint(common.CertificateTypeAuthCommitteeHot),
 		Certificate: &cert,
 	})
-	return a
+	return a, nil
 }
 
 // ResignCommitteeColdKey adds a committee cold key resignation certificate.
-func (a *Apollo) ResignCommitteeColdKey(cold common.Credential, anchor *common.GovAnchor) *Apollo {
+// coldCred accepts the same types as AuthorizeCommitteeHotKey. The certificate
+// carries no deposit; a key-hash cold credential must sign the transaction.
+func (a *Apollo) ResignCommitteeColdKey(coldCred any, anchor *common.GovAnchor) (*Apollo, error) {
+	cold, err := a.resolveCredential(coldCred)
+	if err != nil {
+		return a, fmt.Errorf("invalid committee cold credential: %w", err)
+	}
 	cert := common.ResignCommitteeColdCertificate{
 		CertType:       uint(common.CertificateTypeResignCommitteeCold),
 		ColdCredential: cold,
@@ -780,7 +1681,7 @@ func (a *Apollo) ResignCommitteeColdKey(cold common.Credential, anchor *common.G
 		Type:        uint(common.CertificateTypeResignCommitteeCold),
 		Certificate: &cert,
 	})
-	return a
+	return a, nil
 }
 
 // DeregisterPool adds a pool retirement certificate.
@@ -799,6 +1700,38 @@ func (a *Apollo) DeregisterPool(poolHash common.Blake2b224, epoch uint64) *Apoll
 
 // --- Withdrawals ---
 
+// WithdrawAllFromWallet withdraws the full reward balance of the wallet's
+// stake account. The wallet must implement RewardAddressProvider and the chain
+// context must implement backend.RewardBalanceProvider. A zero balance adds
+// no withdrawal. For script-based stake credentials, provide a redeemer and
+// execution units.
+func (a *Apollo) WithdrawAllFromWallet(redeemerData *common.Datum, exUnits *common.ExUnits) (*Apollo, error) {
+	if a.wallet == nil {
+		return a, errors.New("wallet is required to withdraw rewards")
+	}
+	provider, ok := a.wallet.(RewardAddressProvider)
+	if !ok {
+		return a, errors.New("wallet cannot derive a reward address")
+	}
+	rewardAddr, err := provider.RewardAddress()
+	if err != nil {
+		return a, fmt.Errorf("failed to derive reward address: %w", err)
+	}
+	rewards, ok := a.Context.(backend.RewardBalanceProvider)
+	if !ok {
+		return a, fmt.Errorf("chain context cannot report reward balances: %w", backend.ErrUnsupported)
+	}
+	balance, err := rewards.RewardBalance(rewardAddr)
+	if err != nil {
+		return a, fmt.Errorf("failed to fetch reward balance for %s: %w", rewardAddr.String(), err)
+	}
+	if balance == 0 {
+		return a, nil
+	}
+	a.AddWithdrawal(rewardAddr, balance, redeemerData, exUnits)
+	return a, a.err
+}
+
 // AddWithdrawal adds a staking reward withdrawal to the transaction.
 // For script-based withdrawals, provide a redeemer and execution units.
 func (a *Apollo) AddWithdrawal(address common.Address, amount uint64, redeemerData *common.Datum, exUnits *common.ExUnits) *Apollo {
@@ -812,12 +1745,11 @@ func (a *Apollo) AddWithdrawal(address common.Address, amount uint64, redeemerDa
 	}
 	a.withdrawals[wdKey] = withdrawalEntry{Address: address, Amount: amount}
 	if redeemerData != nil {
-		skh := address.StakeKeyHash()
-		if skh == (common.Blake2b224{}) {
-			a.setErrOnce(fmt.Errorf("withdrawal redeemer requires stake credential for %s", wdKey))
+		key, err := rewardAccountKey(address)
+		if err != nil {
+			a.setErrOnce(fmt.Errorf("withdrawal redeemer requires stake credential for %s: %w", wdKey, err))
 			return a
 		}
-		key := hex.EncodeToString(skh.Bytes())
 		entry := redeemerEntry{
 			Tag:  common.RedeemerTagReward,
 			Data: *redeemerData,
@@ -835,6 +1767,18 @@ func (a *Apollo) AddWithdrawal(address common.Address, amount uint64, redeemerDa
 	return a
 }
 
+// AddWithdrawalByCredential adds a staking reward withdrawal for a stake
+// credential, using the reward address on the chain context's network. It
+// otherwise behaves like AddWithdrawal.
+func (a *Apollo) AddWithdrawalByCredential(cred common.Credential, amount uint64, redeemerData *common.Datum, exUnits *common.ExUnits) *Apollo {
+	rewardAddr, err := RewardAddressFromCredential(cred, a.Context.NetworkId())
+	if err != nil {
+		a.setErrOnce(fmt.Errorf("failed to derive reward address: %w", err))
+		return a
+	}
+	return a.AddWithdrawal(rewardAddr, amount, redeemerData, exUnits)
+}
+
 // --- Metadata ---
 
 // SetShelleyMetadata sets transaction metadata from a key-value map.
@@ -843,6 +1787,58 @@ func (a *Apollo) SetShelleyMetadata(metadata map[uint64]any) *Apollo {
 	return a
 }
 
+// ValidateMetadata checks the metadata set on the builder for unsupported value
+// types, out-of-range integers, and text or bytes longer than 64 bytes. All
+// issues are reported at once, joined with errors.Join, so metadata can be
+// fixed before Complete() rather than one error at a time.
+func (a *Apollo) ValidateMetadata() error {
+	if a.auxiliaryData == nil {
+		return nil
+	}
+	var errs []error
+	for _, label := range slices.Sorted(maps.Keys(a.auxiliaryData.metadata)) {
+		_, labelErrs := convertMetadatum(a.auxiliaryData.metadata[label], fmt.Sprintf("metadata key %d", label))
+		errs = append(errs, labelErrs...)
+	}
+	return errors.Join(errs...)
+}
+
+// checkMetadataSize rejects metadata larger than SetMetadataSizeLimit allows,
+// or too large to fit any transaction, before any UTxOs are selected.
+func (a *Apollo) checkMetadataSize() error {
+	if a.auxiliaryData == nil {
+		return nil
+	}
+	size, err := a.metadataSize()
+	if err != nil {
+		return err
+	}
+	if a.metadataSizeLimit > 0 && size > a.metadataSizeLimit {
+		return fmt.Errorf("metadata is %d bytes, exceeds limit of %d bytes", size, a.metadataSizeLimit)
+	}
+	pp, err := a.Context.ProtocolParams()
+	if err != nil {
+		return fmt.Errorf("failed to get protocol parameters: %w", err)
+	}
+	if pp.MaxTxSize > 0 && size > pp.MaxTxSize {
+		return fmt.Errorf("metadata is %d bytes, exceeds max tx size %d", size, pp.MaxTxSize)
+	}
+	return nil
+}
+
+// metadataSize returns the CBOR-encoded size of the builder's metadata.
+func (a *Apollo) metadataSize() (int, error) {
+	md, err := a.buildMetadata()
+	if err != nil || md == nil {
+		return 0, err
+	}
+	mdBytes, err := cbor.Encode(md)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return len(mdBytes), nil
+}
+
 // SetShelleyMetadataFromJSON parses cardano-cli no-schema metadata JSON and sets it.
 func (a *Apollo) SetShelleyMetadataFromJSON(jsonData []byte) (*Apollo, error) {
 	return a.SetShelleyMetadataFromJSONWithSchema(jsonData, MetadataJSONNoSchema)
@@ -908,6 +1904,38 @@ func (a *Apollo) AddVote(voter common.Voter, actionId common.GovActionId, proced
 	return a
 }
 
+// AddScriptVote adds a vote like AddVote for a Plutus script voter, a
+// committee hot script or a DRep script, along with the redeemer its script
+// runs with. Native script voters need no redeemer and use AddVote.
+func (a *Apollo) AddScriptVote(
+	voter common.Voter,
+	actionId common.GovActionId,
+	procedure common.VotingProcedure,
+	redeemerData common.Datum,
+	exUnits *common.ExUnits,
+) *Apollo {
+	if voter.Type != common.VoterTypeConstitutionalCommitteeHotScriptHash && voter.Type != common.VoterTypeDRepScriptHash {
+		a.setErrOnce(fmt.Errorf("AddScriptVote: voter type %d is not a script voter", voter.Type))
+		return a
+	}
+	key := voterKey(voter)
+	entry := redeemerEntry{
+		Tag:  common.RedeemerTagVoting,
+		Data: redeemerData,
+	}
+	if exUnits != nil {
+		entry.ExUnits = *exUnits
+	}
+	if existing, ok := a.voteRedeemers[key]; ok && !redeemerEntriesEqual(existing, entry) {
+		a.setErrOnce(fmt.Errorf("conflicting voting redeemer for voter %s", key))
+		return a
+	}
+	a.AddVote(voter, actionId, procedure)
+	a.voteRedeemers[key] = entry
+	a.isEstimateRequired = true
+	return a
+}
+
 // AddProposal adds a Conway governance proposal procedure.
 func (a *Apollo) AddProposal(proposal conway.ConwayProposalProcedure) *Apollo {
 	proposal.PPAnchor = *cloneGovAnchor(&proposal.PPAnchor)
@@ -915,6 +1943,61 @@ func (a *Apollo) AddProposal(proposal conway.ConwayProposalProcedure) *Apollo {
 	return a
 }
 
+// ProposeGovernanceAction adds a proposal for action, locking deposit (the
+// gov_action_deposit protocol parameter) until the action is ratified, expires
+// or is dropped, when it is refunded to returnAddr, a reward address. action is
+// one of the gouroboros governance action types, such as
+// *common.TreasuryWithdrawalGovAction or *conway.ConwayParameterChangeGovAction;
+// its Type field is set to match. Complete balances the deposit.
+func (a *Apollo) ProposeGovernanceAction(
+	deposit uint64,
+	returnAddr common.Address,
+	action common.GovAction,
+	anchor common.GovAnchor,
+) *Apollo {
+	if deposit == 0 {
+		a.setErrOnce(errors.New("ProposeGovernanceAction: deposit must be positive"))
+		return a
+	}
+	if t := returnAddr.Type(); t != common.AddressTypeNoneKey && t != common.AddressTypeNoneScript {
+		a.setErrOnce(fmt.Errorf("ProposeGovernanceAction: return address %s is not a reward address", returnAddr.String()))
+		return a
+	}
+	var actionType uint
+	switch act := action.(type) {
+	case *conway.ConwayParameterChangeGovAction:
+		actionType = uint(common.GovActionTypeParameterChange)
+		act.Type = actionType
+	case *common.HardForkInitiationGovAction:
+		actionType = uint(common.GovActionTypeHardForkInitiation)
+		act.Type = actionType
+	case *common.TreasuryWithdrawalGovAction:
+		actionType = uint(common.GovActionTypeTreasuryWithdrawal)
+		act.Type = actionType
+	case *common.NoConfidenceGovAction:
+		actionType = uint(common.GovActionTypeNoConfidence)
+		act.Type = actionType
+	case *common.UpdateCommitteeGovAction:
+		actionType = uint(common.GovActionTypeUpdateCommittee)
+		act.Type = actionType
+	case *common.NewConstitutionGovAction:
+		actionType = uint(common.GovActionTypeNewConstitution)
+		act.Type = actionType
+	case *common.InfoGovAction:
+		actionType = uint(common.GovActionTypeInfo)
+		act.Type = actionType
+	default:
+		a.setErrOnce(fmt.Errorf("ProposeGovernanceAction: unsupported governance action %T", action))
+		return a
+	}
+	return a.AddProposal(conway.ConwayProposalProcedure{
+		PPDeposit:       deposit,
+		PPRewardAccount: returnAddr,
+		PPGovAction:     conway.ConwayGovAction{Type: actionType, Action: action},
+		PPAnchor:        anchor,
+	})
+}
+
 // --- Signing & Witness Methods ---
 
 // AddVerificationKeyWitness adds a VKey witness to the transaction.
@@ -922,23 +2005,107 @@ func (a *Apollo) AddVerificationKeyWitness(witness common.VkeyWitness) (*Apollo,
 	if a.tx == nil {
 		return a, errors.New("transaction not built - call Complete() first")
 	}
-	var witnesses []common.VkeyWitness
-	if existing := a.tx.WitnessSet.VkeyWitnesses.Items(); existing != nil {
-		witnesses = existing
-	}
-	witnesses = append(witnesses, witness)
-	a.tx.WitnessSet.VkeyWitnesses = cbor.NewSetType(witnesses, true)
+	a.addVkeyWitnesses(witness)
 	return a, nil
 }
 
+// addVkeyWitnesses adds witnesses to the built transaction, replacing any
+// existing witness from the same key so each key signs exactly once.
+func (a *Apollo) addVkeyWitnesses(witnesses ...common.VkeyWitness) {
+	merged := slices.Clone(a.tx.WitnessSet.VkeyWitnesses.Items())
+	for _, witness := range witnesses {
+		i := slices.IndexFunc(merged, func(w common.VkeyWitness) bool {
+			return bytes.Equal(w.Vkey, witness.Vkey)
+		})
+		if i >= 0 {
+			merged[i] = witness
+		} else {
+			merged = append(merged, witness)
+		}
+	}
+	a.tx.WitnessSet.VkeyWitnesses = cbor.NewSetType(merged, true)
+}
+
+// GetWitnessSetCbor returns the CBOR of the transaction's witness set alone,
+// for coordinators that collect witnesses from several signers.
+func (a *Apollo) GetWitnessSetCbor() ([]byte, error) {
+	if a.tx == nil {
+		return nil, errors.New("no transaction built")
+	}
+	return cbor.Encode(&a.tx.WitnessSet)
+}
+
+// SetWitnessSetCbor replaces the transaction's witness set with the one
+// encoded in witnessSetCbor, leaving the body untouched.
+func (a *Apollo) SetWitnessSetCbor(witnessSetCbor []byte) error {
+	if a.tx == nil {
+		return errors.New("transaction not built - call Complete() first")
+	}
+	if len(witnessSetCbor) == 0 {
+		return errors.New("witness set CBOR is empty")
+	}
+	var ws conway.ConwayTransactionWitnessSet
+	if _, err := cbor.Decode(witnessSetCbor, &ws); err != nil {
+		return fmt.Errorf("failed to decode witness set: %w", err)
+	}
+	a.tx.WitnessSet = ws
+	return nil
+}
+
+// AssembleTx merges the vkey witnesses of externally produced witness sets,
+// such as CIP-30 signTx results or other parties' GetWitnessSetCbor output,
+// into txCbor and returns the assembled transaction. The body and auxiliary
+// data bytes are kept as they are, so the transaction ID does not change.
+// Every witness must sign the transaction body, and a key that already
+// witnessed the transaction is not added again.
+func AssembleTx(txCbor []byte, witnessSets ...[]byte) ([]byte, error) {
+	var items []cbor.RawMessage
+	if _, err := cbor.Decode(txCbor, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	if len(items) < 3 {
+		return nil, fmt.Errorf("transaction has %d top-level items, expected at least 3", len(items))
+	}
+	var ws conway.ConwayTransactionWitnessSet
+	if _, err := cbor.Decode(items[1], &ws); err != nil {
+		return nil, fmt.Errorf("failed to decode witness set: %w", err)
+	}
+	bodyHash := common.Blake2b256Hash(items[0])
+	witnesses := slices.Clone(ws.VkeyWitnesses.Items())
+	for i, setCbor := range witnessSets {
+		var set conway.ConwayTransactionWitnessSet
+		if _, err := cbor.Decode(setCbor, &set); err != nil {
+			return nil, fmt.Errorf("witness set %d: failed to decode: %w", i, err)
+		}
+		for _, witness := range set.VkeyWitnesses.Items() {
+			if len(witness.Vkey) != ed25519.PublicKeySize ||
+				!ed25519.Verify(ed25519.PublicKey(witness.Vkey), bodyHash.Bytes(), witness.Signature) {
+				return nil, fmt.Errorf("witness set %d: witness from key %x does not sign transaction %s", i, witness.Vkey, bodyHash)
+			}
+			if !slices.ContainsFunc(witnesses, func(w common.VkeyWitness) bool {
+				return bytes.Equal(w.Vkey, witness.Vkey)
+			}) {
+				witnesses = append(witnesses, witness)
+			}
+		}
+	}
+	ws.VkeyWitnesses = cbor.NewSetType(witnesses, true)
+	wsCbor, err := cbor.Encode(&ws)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode witness set: %w", err)
+	}
+	items[1] = wsCbor
+	return cbor.Encode(items)
+}
+
 // SignWithSkey signs the transaction with a raw secret key.
 func (a *Apollo) SignWithSkey(skey []byte) (*Apollo, error) {
 	if a.tx == nil {
 		return a, errors.New("transaction not built - call Complete() first")
 	}
-	bodyCbor, err := cbor.Encode(&a.tx.Body)
+	bodyCbor, err := a.signingBodyCbor()
 	if err != nil {
-		return a, fmt.Errorf("failed to encode tx body: %w", err)
+		return a, err
 	}
 	a.tx.Body.SetCbor(bodyCbor)
 	// Hash the freshly encoded body directly; Body.Id() caches its hash and
@@ -963,7 +2130,9 @@ func (a *Apollo) SetCollateralAmount(amount int64) *Apollo {
 
 // --- Transaction Loading & Utility Methods ---
 
-// LoadTxCbor loads a transaction from hex-encoded CBOR.
+// LoadTxCbor loads a transaction from hex-encoded CBOR, keeping its body
+// bytes as they are. Use VerifyAuxDataHash to check the auxiliary data hash
+// and FixAuxDataHash to correct it.
 func (a *Apollo) LoadTxCbor(txCbor string) (*Apollo, error) {
 	txBytes, err := hex.DecodeString(txCbor)
 	if err != nil {
@@ -973,10 +2142,124 @@ func (a *Apollo) LoadTxCbor(txCbor string) (*Apollo, error) {
 	if _, err := cbor.Decode(txBytes, &tx); err != nil {
 		return a, fmt.Errorf("failed to decode transaction: %w", err)
 	}
+	var items []cbor.RawMessage
+	if _, err := cbor.Decode(txBytes, &items); err != nil {
+		return a, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	if len(items) < 3 {
+		return a, fmt.Errorf("transaction has %d top-level items, expected at least 3", len(items))
+	}
 	a.tx = &tx
+	a.loadedTx = items
+	a.changeOutputs = 0
+	a.tx.Body.SetCbor(items[0])
 	return a, nil
 }
 
+// txBodyCbor returns the CBOR that signers hash: the original body bytes of a
+// loaded transaction, which may be encoded non-canonically, or a fresh
+// encoding of a built body.
+func (a *Apollo) txBodyCbor() ([]byte, error) {
+	if a.loadedTx != nil {
+		return a.loadedTx[0], nil
+	}
+	bodyCbor, err := cbor.Encode(&a.tx.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tx body: %w", err)
+	}
+	return bodyCbor, nil
+}
+
+// signingBodyCbor returns the body CBOR to sign. A loaded body whose auxiliary
+// data hash does not match cannot be signed, since the ledger would reject it.
+func (a *Apollo) signingBodyCbor() ([]byte, error) {
+	if a.loadedTx != nil {
+		if err := a.VerifyAuxDataHash(); err != nil {
+			return nil, fmt.Errorf("cannot sign the loaded transaction: %w", err)
+		}
+	}
+	return a.txBodyCbor()
+}
+
+// VerifyAuxDataHash checks that the auxiliary data hash in the transaction
+// body matches the auxiliary data GetTxCbor serializes, the ledger's
+// "auxiliary data hash mismatch" rule.
+func (a *Apollo) VerifyAuxDataHash() error {
+	if a.tx == nil {
+		return errors.New("no transaction built")
+	}
+	want, err := a.txAuxDataHash()
+	if err != nil {
+		return err
+	}
+	got := a.tx.Body.TxAuxDataHash
+	switch {
+	case want == nil && got == nil:
+		return nil
+	case want == nil:
+		return fmt.Errorf("body has auxiliary data hash %s but the transaction has no auxiliary data", got.String())
+	case got == nil:
+		return fmt.Errorf("transaction has auxiliary data but the body has no auxiliary data hash, expected %s", want.String())
+	case *got != *want:
+		return fmt.Errorf("auxiliary data hash mismatch: body has %s, auxiliary data hashes to %s", got.String(), want.String())
+	}
+	return nil
+}
+
+// txAuxDataHash hashes the auxiliary data GetTxCbor serializes: the original
+// bytes of a loaded transaction, otherwise the encoded metadata.
+func (a *Apollo) txAuxDataHash() (*common.Blake2b256, error) {
+	var auxCbor []byte
+	if a.loadedTx != nil {
+		if len(a.loadedTx) < 4 || bytes.Equal(a.loadedTx[3], []byte{0xf6}) {
+			return nil, nil
+		}
+		auxCbor = a.loadedTx[3]
+	} else {
+		if a.tx.TxMetadata == nil {
+			return nil, nil
+		}
+		var err error
+		auxCbor, err = cbor.Encode(a.tx.TxMetadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode auxiliary data: %w", err)
+		}
+	}
+	hash := common.Blake2b256Hash(auxCbor)
+	return &hash, nil
+}
+
+// FixAuxDataHash sets the auxiliary data hash in the body to the hash of the
+// auxiliary data, re-encoding the body of a loaded transaction. It fails for
+// a transaction that already carries signatures, whose body can no longer
+// change.
+func (a *Apollo) FixAuxDataHash() error {
+	if a.tx == nil {
+		return errors.New("no transaction built")
+	}
+	if a.VerifyAuxDataHash() == nil {
+		return nil
+	}
+	if len(a.tx.WitnessSet.VkeyWitnesses.Items()) > 0 {
+		return errors.New("cannot change the auxiliary data hash of a signed transaction")
+	}
+	hash, err := a.txAuxDataHash()
+	if err != nil {
+		return err
+	}
+	a.tx.Body.TxAuxDataHash = hash
+	if a.loadedTx == nil {
+		return nil
+	}
+	bodyCbor, err := cbor.Encode(&a.tx.Body)
+	if err != nil {
+		return fmt.Errorf("failed to encode tx body: %w", err)
+	}
+	a.loadedTx[0] = bodyCbor
+	a.tx.Body.SetCbor(bodyCbor)
+	return nil
+}
+
 // Clone returns a deep copy of this Apollo builder.
 func (a *Apollo) Clone() *Apollo {
 	clone := &Apollo{
@@ -989,17 +2272,40 @@ func (a *Apollo) Clone() *Apollo {
 		ValidityStart:              a.ValidityStart,
 		totalCollateral:            a.totalCollateral,
 		collateralAmount:           a.collateralAmount,
-		collateralOverlapRef:       a.collateralOverlapRef,
+		collateralOverlapRefs:      slices.Clone(a.collateralOverlapRefs),
 		collateralAutoSelected:     a.collateralAutoSelected,
+		collateralFromInputs:       a.collateralFromInputs,
+		expectedSigners:            a.expectedSigners,
+		logger:                     a.logger,
+		ctx:                        a.ctx,
+		loadedTx:                   slices.Clone(a.loadedTx),
+		changeOutputs:              a.changeOutputs,
 		currentTreasury:            a.currentTreasury,
 		treasuryDonation:           a.treasuryDonation,
 		estimateExUnits:            a.estimateExUnits,
+		dedupReferenceScripts:      a.dedupReferenceScripts,
+		dedupReferenceUtxos:        slices.Clone(a.dedupReferenceUtxos),
+		resolveScripts:             a.resolveScripts,
+		exMemoryBuffer:             a.exMemoryBuffer,
+		exStepBuffer:               a.exStepBuffer,
+		exUnitSafetyFactor:         a.exUnitSafetyFactor,
+		utxoLoadLimit:              a.utxoLoadLimit,
+		utxoLoadCursors:            cloneUTxOLoadCursors(a.utxoLoadCursors),
+		metadataSizeLimit:          a.metadataSizeLimit,
+		changeAssetStrategy:        a.changeAssetStrategy,
+		compactOversizedChange:     a.compactOversizedChange,
+		sweep:                      a.sweep,
+		exactInputs:                a.exactInputs,
+		localEvaluation:            a.localEvaluation,
+		maxRedeemerExUnits:         maps.Clone(a.maxRedeemerExUnits),
+		config:                     a.config,
 		wallet:                     a.wallet,
 		evaluationWitnessProviders: append([]EvaluationWitnessProvider(nil), a.evaluationWitnessProviders...),
 		err:                        a.err,
 		redeemers:                  make(map[string]redeemerEntry),
 		stakeRedeemers:             make(map[string]redeemerEntry),
 		mintRedeemers:              make(map[string]redeemerEntry),
+		voteRedeemers:              make(map[string]redeemerEntry),
 		withdrawals:                make(map[string]withdrawalEntry),
 	}
 	for _, p := range a.payments {
@@ -1023,6 +2329,7 @@ func (a *Apollo) Clone() *Apollo {
 	clone.inputAddresses = append(clone.inputAddresses, a.inputAddresses...)
 	clone.datums = append(clone.datums, a.datums...)
 	clone.requiredSigners = append(clone.requiredSigners, a.requiredSigners...)
+	clone.stakeWitnessHashes = append(clone.stakeWitnessHashes, a.stakeWitnessHashes...)
 	clone.v1scripts = append(clone.v1scripts, a.v1scripts...)
 	clone.v2scripts = append(clone.v2scripts, a.v2scripts...)
 	clone.v3scripts = append(clone.v3scripts, a.v3scripts...)
@@ -1032,6 +2339,10 @@ func (a *Apollo) Clone() *Apollo {
 	clone.nativescripts = append(clone.nativescripts, a.nativescripts...)
 	clone.usedUtxos = make(map[string]bool, len(a.usedUtxos))
 	maps.Copy(clone.usedUtxos, a.usedUtxos)
+	if a.utxoLocker != nil {
+		// A clone reserves its own inputs, so it gets its own owner.
+		clone.SetUTxOLocker(a.utxoLocker, a.utxoLockTTL)
+	}
 	clone.certificates = append(clone.certificates, a.certificates...)
 	clone.scriptHashes = append(clone.scriptHashes, a.scriptHashes...)
 	clone.proposalProcedures = append(clone.proposalProcedures, a.proposalProcedures...)
@@ -1039,11 +2350,14 @@ func (a *Apollo) Clone() *Apollo {
 	maps.Copy(clone.redeemers, a.redeemers)
 	maps.Copy(clone.stakeRedeemers, a.stakeRedeemers)
 	maps.Copy(clone.mintRedeemers, a.mintRedeemers)
+	maps.Copy(clone.voteRedeemers, a.voteRedeemers)
 	maps.Copy(clone.withdrawals, a.withdrawals)
 	if a.changeAddress != nil {
 		addr := *a.changeAddress
 		clone.changeAddress = &addr
 	}
+	clone.changeSplits = slices.Clone(a.changeSplits)
+	clone.assetFloors = slices.Clone(a.assetFloors)
 	if a.collateralReturn != nil {
 		cr := *a.collateralReturn
 		clone.collateralReturn = &cr
@@ -1088,6 +2402,18 @@ func (a *Apollo) UtxoFromRef(txHash string, txIndex int) (*common.Utxo, error) {
 	return a.Context.UtxoByRef(hash, uint32(txIndex))
 }
 
+// UtxosWithDatum returns the UTxOs at address carrying a datum hash or an
+// inline datum, letting the backend skip the others where it can.
+func (a *Apollo) UtxosWithDatum(address common.Address) ([]common.Utxo, error) {
+	return backend.UtxosWithDatum(a.Context, address)
+}
+
+// UtxosWithScriptRef returns the UTxOs at address carrying a reference
+// script, letting the backend skip the others where it can.
+func (a *Apollo) UtxosWithScriptRef(address common.Address) ([]common.Utxo, error) {
+	return backend.UtxosWithScriptRef(a.Context, address)
+}
+
 // GetUsedUTxOs returns a copy of the used UTxO references.
 func (a *Apollo) GetUsedUTxOs() map[string]bool {
 	cp := make(map[string]bool, len(a.usedUtxos))
@@ -1105,16 +2431,63 @@ func (a *Apollo) GetWallet() Wallet {
 	return a.wallet
 }
 
+// CompleteExact builds the transaction from the preselected inputs alone,
+// paying exactly fee: no coin selection or fee estimation runs, so the same
+// builder calls always produce the same transaction, as off-chain batchers
+// need. It fails when the inputs cannot cover the outputs, the fee and the
+// min-UTxO of any change. Script transactions must preset their collateral with
+// AddCollateral, since auto-selection would load wallet UTxOs. Context is still
+// asked for protocol parameters, for reference inputs and datums the builder
+// has to resolve, and, unless SetLocalEvaluation is set, to evaluate scripts
+// through EvaluateTx.
+func (a *Apollo) CompleteExact(fee int64) (*Apollo, error) {
+	if fee < 0 {
+		return a, fmt.Errorf("CompleteExact: fee must be non-negative, got %d", fee)
+	}
+	if len(a.preselectedUtxos) == 0 {
+		return a, errors.New("CompleteExact: no inputs - add them with AddInput")
+	}
+	if a.hasScripts() && len(a.collaterals) == 0 {
+		return a, errors.New("CompleteExact: script transactions need collateral - add it with AddCollateral")
+	}
+	a.ForceFee(fee)
+	a.exactInputs = true
+	return a.Complete()
+}
+
 // Complete performs coin selection, fee estimation, and builds the transaction.
 func (a *Apollo) Complete() (*Apollo, error) {
 	if a.err != nil {
 		return a, a.err
 	}
+	if a.ctx != nil {
+		if err := a.ctx.Err(); err != nil {
+			return a, err
+		}
+	}
 	if a.tx != nil {
 		return a, errors.New("transaction already built - call Complete() only once")
 	}
-	if a.wallet == nil {
-		return a, errors.New("wallet is required to complete transaction")
+	if err := a.validateWalletlessBuild(); err != nil {
+		return a, err
+	}
+	if err := validateValidityInterval(a.ValidityStart, a.Ttl); err != nil {
+		return a, err
+	}
+	if err := a.resolveInputDatums(); err != nil {
+		return a, err
+	}
+	if err := a.resolveInputScripts(); err != nil {
+		return a, err
+	}
+	if err := a.resolveDedupReferenceUtxos(); err != nil {
+		return a, err
+	}
+	if err := a.normalizeMint(); err != nil {
+		return a, err
+	}
+	if err := a.checkMetadataSize(); err != nil {
+		return a, err
 	}
 
 	// Load UTxOs from input addresses if needed (must happen before collateral selection)
@@ -1138,23 +2511,21 @@ func (a *Apollo) Complete() (*Apollo, error) {
 	if err != nil {
 		return a, err
 	}
+	if err := a.checkAssetFloors(totalRequired); err != nil {
+		return a, err
+	}
+	if err := a.checkBurnHoldings(); err != nil {
+		return a, err
+	}
 
 	// Adjust for certificate deposits using protocol parameter. Only consult
 	// the backend when certificates are present, and fail closed on errors:
 	// a silently wrong deposit produces a value-non-conserving transaction.
-	stakeDeposit := int64(StakeDeposit)
-	if len(a.certificates) > 0 {
-		pp, ppErr := a.Context.ProtocolParams()
-		if ppErr != nil {
-			return a, fmt.Errorf("failed to get protocol params for certificate deposit: %w", ppErr)
-		}
-		d, dErr := strconv.ParseInt(pp.KeyDeposits, 10, 64)
-		if dErr != nil || d < 0 {
-			return a, fmt.Errorf("invalid key_deposit protocol parameter %q", pp.KeyDeposits)
-		}
-		stakeDeposit = d
+	deposits, err := a.certificateDepositParams()
+	if err != nil {
+		return a, err
 	}
-	totalRequired, err = a.adjustForCertificateDeposits(totalRequired, stakeDeposit)
+	totalRequired, err = a.adjustForCertificateDeposits(totalRequired, deposits)
 	if err != nil {
 		return a, fmt.Errorf("certificate deposit overflow: %w", err)
 	}
@@ -1200,7 +2571,7 @@ func (a *Apollo) Complete() (*Apollo, error) {
 		}
 	}
 	// Certificate deregistration refunds are implicit inputs
-	refundValue := a.certificateRefundValue(stakeDeposit)
+	refundValue := a.certificateRefundValue(deposits)
 	if refundValue.Coin > 0 {
 		totalInput, err = totalInput.Add(refundValue)
 		if err != nil {
@@ -1252,6 +2623,12 @@ func (a *Apollo) Complete() (*Apollo, error) {
 	// with a single UTxO), release the collateral for overlap - the ledger lets
 	// one UTxO be both a spending input and collateral - and retry once.
 	selectedUtxos, err := a.selectCoins(selectionTarget, totalInput)
+	for err != nil && len(a.utxoLoadCursors) > 0 {
+		if loadErr := a.loadMoreUtxos(); loadErr != nil {
+			return a, loadErr
+		}
+		selectedUtxos, err = a.selectCoins(selectionTarget, totalInput)
+	}
 	if err != nil {
 		if a.releaseCollateralForOverlap() {
 			selectedUtxos, err = a.selectCoins(selectionTarget, totalInput)
@@ -1267,11 +2644,34 @@ func (a *Apollo) Complete() (*Apollo, error) {
 		}
 	}
 
+	// Token-bearing change must hold its own min-UTxO; select more ADA when
+	// the inputs would leave it short.
+	selectedValue, err := a.sumUtxoValues(selectedUtxos)
+	if err != nil {
+		return a, err
+	}
+	available, err := totalInput.Add(selectedValue)
+	if err != nil {
+		return a, fmt.Errorf("input value overflow: %w", err)
+	}
+	extraUtxos, err := a.ensureChangeMinAda(available, selectionTarget)
+	if err != nil {
+		return a, err
+	}
+	selectedUtxos = append(selectedUtxos, extraUtxos...)
+
 	// Build inputs (explicit allocation to avoid slice aliasing)
 	allInputUtxos := make([]common.Utxo, 0, len(a.preselectedUtxos)+len(selectedUtxos))
 	allInputUtxos = append(allInputUtxos, a.preselectedUtxos...)
 	allInputUtxos = append(allInputUtxos, selectedUtxos...)
 	allInputUtxos = SortInputs(allInputUtxos)
+	a.useInputAsCollateral(allInputUtxos)
+	a.debug("selected inputs",
+		"preselected", len(a.preselectedUtxos),
+		"selected", len(selectedUtxos),
+		"collateral", len(a.collaterals),
+		"target_lovelace", selectionTarget.Coin,
+	)
 	if err := a.validateCollateral(); err != nil {
 		return a, err
 	}
@@ -1297,6 +2697,7 @@ func (a *Apollo) Complete() (*Apollo, error) {
 	if fee < 0 {
 		fee = 0
 	}
+	a.debug("preliminary fee", "fee", fee, "forced", a.forceFee)
 
 	// Compute totalInput once (it does not change across iterations).
 	totalInput, err = a.sumUtxoValues(allInputUtxos)
@@ -1332,152 +2733,578 @@ func (a *Apollo) Complete() (*Apollo, error) {
 		totalInput:         totalInput,
 		totalRequired:      totalRequired,
 		governanceRequired: governanceRequired,
-		stakeDeposit:       stakeDeposit,
+		stakeDeposit:       deposits.stake,
 		changeAddress:      a.getChangeAddress(),
+		changeSplits:       a.changeSplits,
+		assetStrategy:      a.changeAssetStrategy,
+	}
+	settled, settledFee, body, err := a.settleTransaction(allInputUtxos, baseOutputs, fee, balance)
+	var tooLarge *TxTooLargeError
+	if errors.As(err, &tooLarge) && a.compactOversizedChange && balance.assetStrategy != ChangeAssetsSingleOutput {
+		// Fewer change outputs is the one layout choice that shrinks the
+		// transaction without changing what it pays.
+		a.debug("transaction over max size, compacting change", "size", tooLarge.Size, "max_size", tooLarge.MaxSize)
+		balance.assetStrategy = ChangeAssetsSingleOutput
+		settled, settledFee, body, err = a.settleTransaction(allInputUtxos, baseOutputs, fee, balance)
 	}
-	const maxEvaluationIterations = 5
-	var previousShape string
-	seenShapes := make(map[string]struct{}, maxEvaluationIterations)
-	converged := false
-	for range maxEvaluationIterations {
-		balanced, balanceErr := a.buildBalancedOutputs(baseOutputs, fee, balance)
-		if balanceErr != nil {
-			return a, balanceErr
+	if err != nil {
+		return a, err
+	}
+	outputs, fee = settled, settledFee
+	a.changeOutputs = len(outputs) - len(baseOutputs)
+	if a.collateralReturn != nil && a.collateralReturn.Assets() != nil {
+		pp, err := a.Context.ProtocolParams()
+		if err != nil {
+			return a, fmt.Errorf("failed to load protocol parameters to check the collateral return: %w", err)
 		}
-		outputs, fee = balanced.Outputs, balanced.Fee
-		if err := a.finalizeCollateral(fee); err != nil {
+		if err := a.checkCollateralReturn(pp.CoinsPerUtxoByteValue()); err != nil {
 			return a, err
 		}
+	}
+	a.debug("final balance",
+		"inputs", len(allInputUtxos),
+		"outputs", len(outputs),
+		"input_lovelace", totalInput.Coin,
+		"fee", fee,
+	)
 
-		if a.isEstimateRequired && a.estimateExUnits {
-			units, evalErr := a.estimateExecutionUnits(allInputUtxos, outputs, fee)
-			if evalErr != nil {
-				return a, fmt.Errorf("ExUnit estimation failed: %w", evalErr)
+	if err := a.lockInputs(allInputUtxos); err != nil {
+		return a, err
+	}
+
+	// Build witness set
+	witnessSet := a.buildWitnessSet(allInputUtxos)
+
+	// Assemble transaction
+	a.tx = &conway.ConwayTransaction{
+		Body:       body,
+		WitnessSet: witnessSet,
+		TxIsValid:  true,
+	}
+
+	// Set metadata if present
+	if a.auxiliaryData != nil {
+		md, mdErr := a.buildMetadata()
+		if mdErr != nil {
+			return a, fmt.Errorf("failed to build metadata: %w", mdErr)
+		}
+		if md != nil {
+			a.tx.TxMetadata = md
+		}
+	}
+
+	return a, nil
+}
+
+// Sign signs the transaction with the wallet.
+func (a *Apollo) Sign() (*Apollo, error) {
+	if a.tx == nil {
+		return a, errors.New("transaction not built - call Complete() first")
+	}
+	if a.wallet == nil {
+		return a, errors.New("no wallet set")
+	}
+
+	// Marshal body to CBOR and set it for downstream consumers
+	bodyCbor, err := a.signingBodyCbor()
+	if err != nil {
+		return a, err
+	}
+	a.tx.Body.SetCbor(bodyCbor)
+
+	// Hash the freshly encoded body directly; Body.Id() caches its hash and
+	// SetCbor does not invalidate the cache, so it could return a stale digest
+	// if the body was mutated after a previous Id() call.
+	txHash := common.Blake2b256Hash(bodyCbor)
+
+	// Wallets that sign whole transactions, such as CIP-30 wallets, add every
+	// witness they hold, including a stake witness.
+	if signer, ok := a.wallet.(TxSigner); ok {
+		txCbor, err := a.GetTxCbor()
+		if err != nil {
+			return a, err
+		}
+		witnesses, err := signer.SignTx(txCbor)
+		if err != nil {
+			return a, fmt.Errorf("signing failed: %w", err)
+		}
+		a.addVkeyWitnesses(witnesses...)
+		return a, nil
+	}
+
+	witness, err := a.wallet.SignTxBody(txHash)
+	if err != nil {
+		return a, fmt.Errorf("signing failed: %w", err)
+	}
+
+	witnesses := []common.VkeyWitness{witness}
+
+	// Certificates, withdrawals, and marked inputs that reference the wallet's
+	// stake key credential also need a stake key witness.
+	if a.walletStakeWitnessRequired() {
+		signer, ok := a.wallet.(StakeSigner)
+		if !ok {
+			return a, errors.New("transaction requires a stake key witness but the wallet cannot sign with its stake key")
+		}
+		stakeWitness, err := signer.SignTxBodyWithStakeKey(txHash)
+		if err != nil {
+			return a, fmt.Errorf("stake key signing failed: %w", err)
+		}
+		witnesses = append(witnesses, stakeWitness)
+	}
+	a.addVkeyWitnesses(witnesses...)
+	return a, nil
+}
+
+// requiredStakeWitnesses returns the stake key hashes that must witness the
+// transaction: stake credentials of marked inputs, of key-hash certificate
+// credentials that the ledger authorizes by signature, and of withdrawals.
+func (a *Apollo) requiredStakeWitnesses() map[common.Blake2b224]struct{} {
+	required := make(map[common.Blake2b224]struct{})
+	for _, hash := range a.stakeWitnessHashes {
+		required[hash] = struct{}{}
+	}
+	for _, cert := range a.certificates {
+		if cred, ok := certificateStakeCredential(cert); ok && cred.CredType == common.CredentialTypeAddrKeyHash {
+			required[common.Blake2b224(cred.Credential)] = struct{}{}
+		}
+	}
+	for _, wd := range a.withdrawals {
+		cred, err := GetStakeCredentialFromAddress(wd.Address)
+		if err == nil && cred.CredType == common.CredentialTypeAddrKeyHash {
+			required[common.Blake2b224(cred.Credential)] = struct{}{}
+		}
+	}
+	return required
+}
+
+// committeeColdWitnesses returns the key-hash committee cold credentials of
+// committee certificates that are not already listed as required signers.
+// Their holders sign externally, so they only affect fee estimation.
+func (a *Apollo) committeeColdWitnesses() map[common.Blake2b224]struct{} {
+	required := make(map[common.Blake2b224]struct{})
+	for _, cert := range a.certificates {
+		var cold common.Credential
+		switch c := cert.Certificate.(type) {
+		case *common.AuthCommitteeHotCertificate:
+			cold = c.ColdCredential
+		case *common.ResignCommitteeColdCertificate:
+			cold = c.ColdCredential
+		default:
+			continue
+		}
+		if cold.CredType != common.CredentialTypeAddrKeyHash {
+			continue
+		}
+		hash := common.Blake2b224(cold.Credential)
+		if !slices.Contains(a.requiredSigners, hash) {
+			required[hash] = struct{}{}
+		}
+	}
+	return required
+}
+
+// walletStakeWitnessRequired reports whether the wallet's stake key must sign.
+func (a *Apollo) walletStakeWitnessRequired() bool {
+	if a.wallet == nil {
+		return false
+	}
+	stakeHash := a.wallet.StakePubKeyHash()
+	if stakeHash == (common.Blake2b224{}) {
+		return false
+	}
+	_, ok := a.requiredStakeWitnesses()[stakeHash]
+	return ok
+}
+
+// certificateStakeCredential returns the stake credential that authorizes a
+// certificate. Legacy stake registration (certificate type 0) carries no
+// witness requirement and is therefore not reported.
+func certificateStakeCredential(cert common.CertificateWrapper) (common.Credential, bool) {
+	switch c := cert.Certificate.(type) {
+	case *common.StakeDeregistrationCertificate:
+		return c.StakeCredential, true
+	case *common.StakeDelegationCertificate:
+		if c.StakeCredential == nil {
+			return common.Credential{}, false
+		}
+		return *c.StakeCredential, true
+	case *common.RegistrationCertificate:
+		return c.StakeCredential, true
+	case *common.DeregistrationCertificate:
+		return c.StakeCredential, true
+	case *common.VoteDelegationCertificate:
+		return c.StakeCredential, true
+	case *common.StakeVoteDelegationCertificate:
+		return c.StakeCredential, true
+	case *common.StakeRegistrationDelegationCertificate:
+		return c.StakeCredential, true
+	case *common.VoteRegistrationDelegationCertificate:
+		return c.StakeCredential, true
+	case *common.StakeVoteRegistrationDelegationCertificate:
+		return c.StakeCredential, true
+	default:
+		return common.Credential{}, false
+	}
+}
+
+// GetTx returns the built transaction.
+func (a *Apollo) GetTx() *conway.ConwayTransaction {
+	return a.tx
+}
+
+// ProjectedOutputCount returns the number of outputs the transaction will
+// have: payments, change (including any splits) and the collateral return,
+// which only becomes a UTxO if a script fails. Before Complete it builds a
+// clone, leaving this builder untouched and reserving no inputs, so it needs
+// the same chain access as Complete.
+func (a *Apollo) ProjectedOutputCount() (int, error) {
+	tx := a.tx
+	if tx == nil {
+		projection := a.Clone()
+		projection.noUTxOLocks = true
+		built, err := projection.Complete()
+		if err != nil {
+			return 0, fmt.Errorf("failed to project outputs: %w", err)
+		}
+		tx = built.tx
+	}
+	count := len(tx.Body.TxOutputs)
+	if tx.Body.TxCollateralReturn != nil {
+		count++
+	}
+	return count, nil
+}
+
+// OutputValueTo sums the values of every output in the built transaction,
+// including change, that pays to addr.
+func (a *Apollo) OutputValueTo(addr common.Address) (Value, error) {
+	if a.tx == nil {
+		return Value{}, errors.New("transaction not built - call Complete() first")
+	}
+	target := addr.String()
+	total := Value{}
+	for _, out := range a.tx.Body.TxOutputs {
+		if out.OutputAddress.String() != target {
+			continue
+		}
+		var err error
+		total, err = total.Add(ValueFromMaryValue(out.OutputAmount))
+		if err != nil {
+			return Value{}, fmt.Errorf("output value overflow: %w", err)
+		}
+	}
+	return total, nil
+}
+
+// ExpectedOutput is one entry of an output specification checked by
+// AssertOutputs: an output paying to Address holding at least MinValue.
+type ExpectedOutput struct {
+	Address  common.Address
+	MinValue Value
+}
+
+// AssertOutputs checks that the built transaction pays every expected output.
+// Each expectation must be met by a distinct output at its address holding at
+// least its value; expectations are assigned to outputs so that as many as
+// possible are met, whatever their order. The change outputs Complete added
+// are not checked, but every other output left unassigned is a mismatch. The
+// returned error lists every difference.
+func (a *Apollo) AssertOutputs(expected []ExpectedOutput) error {
+	if a.tx == nil {
+		return errors.New("transaction not built - call Complete() first")
+	}
+	outputs := a.tx.Body.TxOutputs[:len(a.tx.Body.TxOutputs)-a.changeOutputs]
+	fits := make([][]int, len(expected))
+	for i, exp := range expected {
+		target := exp.Address.String()
+		for j, out := range outputs {
+			if out.OutputAddress.String() == target && ValueFromMaryValue(out.OutputAmount).GreaterOrEqual(exp.MinValue) {
+				fits[i] = append(fits[i], j)
 			}
-			a.applyExecutionUnits(units, allInputUtxos)
 		}
+	}
+	assigned := assignOutputs(fits, len(outputs))
+	met := make([]bool, len(expected))
+	for _, i := range assigned {
+		if i >= 0 {
+			met[i] = true
+		}
+	}
 
-		if fee < 0 {
-			return a, fmt.Errorf("negative fee: %d", fee)
+	var diffs []string
+	for i, exp := range expected {
+		if met[i] {
+			continue
 		}
-		body, bodyErr := a.buildBody(allInputUtxos, outputs, uint64(fee)) //nolint:gosec // validated non-negative above
-		if bodyErr != nil {
-			return a, bodyErr
+		target := exp.Address.String()
+		var candidates []string
+		for j, out := range outputs {
+			if assigned[j] < 0 && out.OutputAddress.String() == target {
+				candidates = append(candidates, fmt.Sprintf("output %d holds %d lovelace", j, out.OutputAmount.Amount))
+			}
 		}
-		bodyBytes, bodyErr := cbor.Encode(&body)
-		if bodyErr != nil {
-			return a, fmt.Errorf("failed to encode evaluation shape: %w", bodyErr)
+		diff := fmt.Sprintf("expected output %d to %s with at least %d lovelace", i, target, exp.MinValue.Coin)
+		if exp.MinValue.HasAssets() {
+			diff += " and assets"
 		}
-		shape := string(bodyBytes)
-		newFee := fee
-		if !a.forceFee && a.Fee == 0 {
-			newFee, err = a.estimateFee(allInputUtxos, outputs)
-			if err != nil {
-				return a, fmt.Errorf("fee re-estimation failed: %w", err)
+		if len(candidates) == 0 {
+			diff += ": no unmatched output pays to this address"
+		} else {
+			diff += ": " + strings.Join(candidates, ", ")
+		}
+		diffs = append(diffs, diff)
+	}
+	for j, out := range outputs {
+		if assigned[j] < 0 {
+			diffs = append(diffs, fmt.Sprintf("unexpected output %d to %s with %d lovelace", j, out.OutputAddress.String(), out.OutputAmount.Amount))
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("transaction outputs do not match specification:\n  %s", strings.Join(diffs, "\n  "))
+	}
+	return nil
+}
+
+// assignOutputs finds a maximum matching of expectations to outputs, where
+// fits[i] lists the outputs expectation i accepts. It returns, for each of the
+// count outputs, the expectation assigned to it or -1.
+func assignOutputs(fits [][]int, count int) []int {
+	assigned := make([]int, count)
+	for j := range assigned {
+		assigned[j] = -1
+	}
+	var augment func(i int, visited []bool) bool
+	augment = func(i int, visited []bool) bool {
+		for _, j := range fits[i] {
+			if visited[j] {
+				continue
 			}
-			newFee += a.FeePadding
-			if newFee < 0 {
-				newFee = 0
+			visited[j] = true
+			if assigned[j] < 0 || augment(assigned[j], visited) {
+				assigned[j] = i
+				return true
 			}
 		}
-		if newFee == fee && previousShape == shape {
-			converged = true
+		return false
+	}
+	for i := range fits {
+		augment(i, make([]bool, count))
+	}
+	return assigned
+}
+
+// ComputeExactFee recomputes the fee of the completed transaction for exactly
+// witnessCount vkey witnesses instead of the estimate Complete uses, moves the
+// difference into the change output and rebuilds the body with that fee. A
+// coordinator that knows the final signer count can lock the fee this way
+// before signatures are gathered. Inputs stay fixed and FeePadding is still
+// added. It fails for a fixed fee, a signed or loaded transaction, or when the
+// change output cannot absorb the difference.
+func (a *Apollo) ComputeExactFee(witnessCount int) (int64, error) {
+	if a.tx == nil {
+		return 0, errors.New("transaction not built - call Complete() first")
+	}
+	if a.loadedTx != nil {
+		return 0, errors.New("cannot recompute the fee of a loaded transaction")
+	}
+	if witnessCount < 0 {
+		return 0, fmt.Errorf("witness count must be non-negative, got %d", witnessCount)
+	}
+	if a.forceFee || a.Fee > 0 {
+		return 0, errors.New("fee is fixed by SetFee or ForceFee")
+	}
+	if len(a.tx.WitnessSet.VkeyWitnesses.Items()) > 0 {
+		return 0, errors.New("transaction is already signed")
+	}
+	pp, err := a.Context.ProtocolParams()
+	if err != nil {
+		return 0, err
+	}
+
+	known := a.knownUtxos()
+	inputs := make([]common.Utxo, 0, len(a.tx.Body.TxInputs.Items()))
+	for _, input := range a.tx.Body.TxInputs.Items() {
+		utxo, err := a.resolveTxInput(input, known)
+		if err != nil {
+			return 0, err
+		}
+		inputs = append(inputs, utxo)
+	}
+	inputs = SortInputs(inputs)
+
+	outputs := slices.Clone(a.tx.Body.TxOutputs)
+	changeAddr := a.getChangeAddress().String()
+	changeIdx := -1
+	for i := len(outputs) - 1; i >= 0; i-- {
+		if outputs[i].Address().String() == changeAddr {
+			changeIdx = i
 			break
 		}
-		if _, seen := seenShapes[shape]; seen {
-			return a, errors.New("evaluation transaction did not converge after 5 iterations")
+	}
+	if changeIdx < 0 {
+		return 0, errors.New("no change output to absorb the fee difference")
+	}
+	prevFee := a.tx.Body.TxFee
+	if prevFee > math.MaxInt64 {
+		return 0, fmt.Errorf("fee out of range: %d", prevFee)
+	}
+	// The change output and the fee share a fixed budget; only the split moves.
+	budget := outputs[changeIdx].OutputAmount.Amount + prevFee
+	if budget < prevFee {
+		return 0, errors.New("change output and fee overflow uint64")
+	}
+
+	fee := int64(prevFee)
+	const maxIterations = 5
+	for range maxIterations {
+		if uint64(fee) > budget { //nolint:gosec // fee is non-negative
+			return 0, fmt.Errorf("change output cannot cover a fee of %d", fee)
+		}
+		outputs[changeIdx].OutputAmount.Amount = budget - uint64(fee) //nolint:gosec // fee is non-negative
+		minCoin, err := MinLovelacePostAlonzo(&outputs[changeIdx], pp.CoinsPerUtxoByteValue())
+		if err != nil {
+			return 0, fmt.Errorf("failed to compute min UTxO for change output: %w", err)
+		}
+		if outputs[changeIdx].OutputAmount.Amount < uint64(minCoin) { //nolint:gosec // min UTxO is non-negative
+			return 0, fmt.Errorf("change output falls below its min UTxO of %d lovelace with a fee of %d", minCoin, fee)
+		}
+		if err := a.finalizeCollateral(fee); err != nil {
+			return 0, err
+		}
+		body, err := a.buildBody(inputs, outputs, uint64(fee)) //nolint:gosec // fee is non-negative
+		if err != nil {
+			return 0, err
+		}
+		newFee, err := a.feeForBody(body, inputs, witnessCount, pp)
+		if err != nil {
+			return 0, err
+		}
+		newFee += a.FeePadding
+		if newFee < 0 {
+			newFee = 0
+		}
+		if newFee == fee {
+			a.tx.Body = body
+			return fee, nil
 		}
-		seenShapes[shape] = struct{}{}
-		previousShape = shape
 		fee = newFee
 	}
-	if !converged {
-		return a, errors.New("evaluation transaction did not converge after 5 iterations")
+	return 0, fmt.Errorf("exact fee did not converge after %d iterations", maxIterations)
+}
+
+// GetTxCbor returns the CBOR-encoded transaction. A transaction loaded with
+// LoadTxCbor keeps its original body and auxiliary data bytes, so its hash
+// matches the one signers saw; only the witness set is re-encoded.
+func (a *Apollo) GetTxCbor() ([]byte, error) {
+	if a.tx == nil {
+		return nil, errors.New("no transaction built")
+	}
+	if a.loadedTx == nil {
+		return cbor.Encode(a.tx)
+	}
+	wsCbor, err := cbor.Encode(&a.tx.WitnessSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode witness set: %w", err)
 	}
+	items := slices.Clone(a.loadedTx)
+	items[1] = wsCbor
+	return cbor.Encode(items)
+}
 
-	// Build transaction body
-	body, err := a.buildBody(allInputUtxos, outputs, uint64(fee))
+// Submit submits the transaction to the chain. If submission fails, the
+// inputs reserved through SetUTxOLocker are released.
+func (a *Apollo) Submit() (common.Blake2b256, error) {
+	txCbor, err := a.GetTxCbor()
 	if err != nil {
-		return a, err
+		return common.Blake2b256{}, err
 	}
+	txId, err := a.Context.SubmitTx(txCbor)
+	if err != nil {
+		a.ReleaseUTxOLocks()
+		return common.Blake2b256{}, err
+	}
+	return txId, nil
+}
 
-	// Build witness set
-	witnessSet := a.buildWitnessSet(allInputUtxos)
+// confirmationPollInterval is how often SubmitAndWait asks the chain context
+// whether the transaction is on chain.
+var confirmationPollInterval = 5 * time.Second
 
-	// Assemble transaction
-	a.tx = &conway.ConwayTransaction{
-		Body:       body,
-		WitnessSet: witnessSet,
-		TxIsValid:  true,
+// SubmitAndWait submits the transaction and polls the chain context until it
+// is on chain with at least confirmations blocks, counting the including one,
+// or ctx is done. The chain context must implement
+// backend.TxConfirmationProvider; that is checked before submitting. A
+// wrapper such as the cache may only learn after submitting that its wrapped
+// context cannot report confirmations, which ends the wait at once.
+func (a *Apollo) SubmitAndWait(ctx context.Context, confirmations int) (*backend.TxConfirmation, error) {
+	provider, ok := a.Context.(backend.TxConfirmationProvider)
+	if !ok {
+		return nil, fmt.Errorf("chain context cannot report transaction confirmations: %w", backend.ErrUnsupported)
 	}
-
-	// Set metadata if present
-	if a.auxiliaryData != nil {
-		md, mdErr := a.buildMetadata()
-		if mdErr != nil {
-			return a, fmt.Errorf("failed to build metadata: %w", mdErr)
+	if a.tx == nil {
+		return nil, errors.New("no transaction built")
+	}
+	txId, err := a.builtTxId()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.Submit(); err != nil {
+		return nil, err
+	}
+	want := uint64(max(confirmations, 1)) //nolint:gosec // clamped to at least 1
+	ticker := time.NewTicker(confirmationPollInterval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		conf, err := provider.TxConfirmations(txId)
+		if errors.Is(err, backend.ErrUnsupported) {
+			return nil, fmt.Errorf("transaction %s submitted but cannot be confirmed: %w", txId, err)
 		}
-		if md != nil {
-			a.tx.TxMetadata = md
+		if err != nil {
+			lastErr = err
+		} else if conf != nil && conf.Confirmations >= want {
+			return conf, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s not confirmed: %w", txId, errors.Join(ctx.Err(), lastErr))
+		case <-ticker.C:
 		}
 	}
-
-	return a, nil
 }
 
-// Sign signs the transaction with the wallet.
-func (a *Apollo) Sign() (*Apollo, error) {
+// PrepareSubmit returns the ID and CBOR of the signed transaction without
+// contacting the network, so both can be logged or persisted before a
+// separate broadcast step. It fails if the transaction carries no vkey
+// witnesses.
+func (a *Apollo) PrepareSubmit() (common.Blake2b256, []byte, error) {
 	if a.tx == nil {
-		return a, errors.New("transaction not built - call Complete() first")
+		return common.Blake2b256{}, nil, errors.New("no transaction built")
 	}
-	if a.wallet == nil {
-		return a, errors.New("no wallet set")
+	if len(a.tx.WitnessSet.VkeyWitnesses.Items()) == 0 {
+		return common.Blake2b256{}, nil, errors.New("transaction is not signed - call Sign() first")
 	}
-
-	// Marshal body to CBOR and set it for downstream consumers
-	bodyCbor, err := cbor.Encode(&a.tx.Body)
+	txId, err := a.builtTxId()
 	if err != nil {
-		return a, fmt.Errorf("failed to encode tx body: %w", err)
+		return common.Blake2b256{}, nil, err
 	}
-	a.tx.Body.SetCbor(bodyCbor)
-
-	// Hash the freshly encoded body directly; Body.Id() caches its hash and
-	// SetCbor does not invalidate the cache, so it could return a stale digest
-	// if the body was mutated after a previous Id() call.
-	txHash := common.Blake2b256Hash(bodyCbor)
-
-	witness, err := a.wallet.SignTxBody(txHash)
+	txCbor, err := a.GetTxCbor()
 	if err != nil {
-		return a, fmt.Errorf("signing failed: %w", err)
+		return common.Blake2b256{}, nil, fmt.Errorf("failed to encode transaction: %w", err)
 	}
-
-	var witnesses []common.VkeyWitness
-	if existing := a.tx.WitnessSet.VkeyWitnesses.Items(); existing != nil {
-		witnesses = existing
-	}
-	witnesses = append(witnesses, witness)
-	a.tx.WitnessSet.VkeyWitnesses = cbor.NewSetType(witnesses, true)
-	return a, nil
-}
-
-// GetTx returns the built transaction.
-func (a *Apollo) GetTx() *conway.ConwayTransaction {
-	return a.tx
+	return txId, txCbor, nil
 }
 
-// GetTxCbor returns the CBOR-encoded transaction.
-func (a *Apollo) GetTxCbor() ([]byte, error) {
-	if a.tx == nil {
-		return nil, errors.New("no transaction built")
+// SubmitCbor submits an externally built and signed transaction through the
+// builder's chain context. The CBOR is passed through unchanged and need not
+// have been built by this builder.
+func (a *Apollo) SubmitCbor(txCbor []byte) (common.Blake2b256, error) {
+	if len(txCbor) == 0 {
+		return common.Blake2b256{}, errors.New("transaction CBOR is empty")
 	}
-	return cbor.Encode(a.tx)
-}
-
-// Submit submits the transaction to the chain.
-func (a *Apollo) Submit() (common.Blake2b256, error) {
-	txCbor, err := a.GetTxCbor()
-	if err != nil {
-		return common.Blake2b256{}, err
+	if a.Context == nil {
+		return common.Blake2b256{}, errors.New("chain context is required to submit a transaction")
 	}
 	return a.Context.SubmitTx(txCbor)
 }
@@ -1486,7 +3313,7 @@ func (a *Apollo) Submit() (common.Blake2b256, error) {
 
 func (a *Apollo) loadUtxos() error {
 	for _, addr := range a.inputAddresses {
-		utxos, err := a.Context.Utxos(addr)
+		utxos, err := a.loadAddressUtxos(addr)
 		if err != nil {
 			return fmt.Errorf("failed to load UTxOs for %s: %w", addr.String(), err)
 		}
@@ -1494,7 +3321,7 @@ func (a *Apollo) loadUtxos() error {
 	}
 	// If no UTxOs loaded and wallet is set, load from wallet address
 	if len(a.utxos) == 0 && len(a.preselectedUtxos) == 0 && a.wallet != nil {
-		utxos, err := a.Context.Utxos(a.wallet.Address())
+		utxos, err := a.loadAddressUtxos(a.wallet.Address())
 		if err != nil {
 			return fmt.Errorf("failed to load wallet UTxOs: %w", err)
 		}
@@ -1503,10 +3330,103 @@ func (a *Apollo) loadUtxos() error {
 	return nil
 }
 
+// utxoLoadCursor tracks the UTxOs of an address not yet offered to coin
+// selection under SetUTxOLoadLimit: those loaded but held back, and the next
+// page to load (0 once every page is loaded).
+type utxoLoadCursor struct {
+	addr     common.Address
+	held     []common.Utxo
+	nextPage int
+}
+
+func cloneUTxOLoadCursors(cursors []utxoLoadCursor) []utxoLoadCursor {
+	if cursors == nil {
+		return nil
+	}
+	clone := make([]utxoLoadCursor, len(cursors))
+	for i, cursor := range cursors {
+		clone[i] = cursor
+		clone[i].held = slices.Clone(cursor.held)
+	}
+	return clone
+}
+
+// loadAddressUtxos loads the UTxOs of addr. Under SetUTxOLoadLimit it loads
+// pages until the limit is reached and returns the largest UTxOs by lovelace,
+// up to the limit, recording a cursor for the rest. Ties are broken by input
+// order so the offered set is deterministic.
+func (a *Apollo) loadAddressUtxos(addr common.Address) ([]common.Utxo, error) {
+	if a.utxoLoadLimit <= 0 {
+		return a.Context.Utxos(addr)
+	}
+	cursor := utxoLoadCursor{addr: addr, nextPage: 1}
+	var loaded []common.Utxo
+	for len(loaded) < a.utxoLoadLimit && cursor.nextPage > 0 {
+		page, err := a.loadUtxoPage(&cursor)
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, page...)
+	}
+	if len(loaded) > a.utxoLoadLimit {
+		sorted := SortInputs(loaded)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			iAmt, jAmt := sorted[i].Output.Amount(), sorted[j].Output.Amount()
+			if iAmt == nil || jAmt == nil {
+				return iAmt != nil
+			}
+			return iAmt.Cmp(jAmt) > 0
+		})
+		loaded, cursor.held = sorted[:a.utxoLoadLimit], sorted[a.utxoLoadLimit:]
+	}
+	if len(cursor.held) > 0 || cursor.nextPage > 0 {
+		a.utxoLoadCursors = append(a.utxoLoadCursors, cursor)
+	}
+	return loaded, nil
+}
+
+// loadUtxoPage loads the next page of the cursor's address and advances it.
+func (a *Apollo) loadUtxoPage(cursor *utxoLoadCursor) ([]common.Utxo, error) {
+	page, err := backend.UtxosPage(a.Context, cursor.addr, cursor.nextPage)
+	if err != nil {
+		return nil, err
+	}
+	if len(page) == 0 {
+		cursor.nextPage = 0
+	} else {
+		cursor.nextPage++
+	}
+	return page, nil
+}
+
+// loadMoreUtxos offers coin selection more UTxOs of the addresses limited by
+// SetUTxOLoadLimit: those held back at load time first, then the next page of
+// each address. Addresses with nothing left are dropped.
+func (a *Apollo) loadMoreUtxos() error {
+	remaining := a.utxoLoadCursors[:0]
+	for _, cursor := range a.utxoLoadCursors {
+		if len(cursor.held) > 0 {
+			a.utxos = append(a.utxos, cursor.held...)
+			cursor.held = nil
+		} else {
+			page, err := a.loadUtxoPage(&cursor)
+			if err != nil {
+				return fmt.Errorf("failed to load UTxOs for %s: %w", cursor.addr.String(), err)
+			}
+			a.utxos = append(a.utxos, page...)
+		}
+		if cursor.nextPage > 0 {
+			remaining = append(remaining, cursor)
+		}
+	}
+	a.utxoLoadCursors = remaining
+	return nil
+}
+
 func (a *Apollo) buildOutputs() ([]babbage.BabbageTransactionOutput, error) {
 	outputs := make([]babbage.BabbageTransactionOutput, 0, len(a.payments))
 	for _, payment := range a.payments {
-		if err := payment.EnsureMinUTXO(a.Context); err != nil {
+		if err := a.ensureMinUTXO(payment); err != nil {
 			return nil, fmt.Errorf("failed to ensure min UTxO: %w", err)
 		}
 		txOut, err := payment.ToTxOut()
@@ -1518,6 +3438,15 @@ func (a *Apollo) buildOutputs() ([]babbage.BabbageTransactionOutput, error) {
 	return outputs, nil
 }
 
+// ensureMinUTXO applies the configured MinLovelace shortcut to the builder's
+// own payment type; other PaymentI implementations use their own rules.
+func (a *Apollo) ensureMinUTXO(payment PaymentI) error {
+	if p, ok := payment.(*Payment); ok {
+		return p.ensureMinUTXO(a.Context, a.config.MinLovelace)
+	}
+	return payment.EnsureMinUTXO(a.Context)
+}
+
 func (a *Apollo) totalOutputValue(outputs []babbage.BabbageTransactionOutput) (Value, error) {
 	total := Value{}
 	for _, out := range outputs {
@@ -1560,6 +3489,19 @@ func (a *Apollo) sumUtxoValues(utxos []common.Utxo) (Value, error) {
 }
 
 func (a *Apollo) selectCoins(required, currentInput Value) ([]common.Utxo, error) {
+	if a.exactInputs {
+		return nil, nil
+	}
+	if a.sweep {
+		var selected []common.Utxo
+		for _, utxo := range a.utxos {
+			if ref := utxoRef(utxo); !a.isUsed(ref) {
+				selected = append(selected, utxo)
+				a.markUsed(ref)
+			}
+		}
+		return selected, nil
+	}
 	if currentInput.GreaterOrEqual(required) {
 		return nil, nil
 	}
@@ -1622,12 +3564,109 @@ func (a *Apollo) estimateFee(inputs []common.Utxo, outputs []babbage.BabbageTran
 	if err != nil {
 		return 0, err
 	}
+	return a.feeForBody(body, inputs, a.estimatedWitnessCount(inputs), pp)
+}
+
+// estimatedWitnessCount is the number of vkey witnesses assumed for fee
+// estimation: one per distinct key among the wallet, the payment keys of
+// vkey-locked inputs and collateral, the required signers, the stake keys of
+// certificates, withdrawals and marked inputs, the committee cold keys of
+// committee certificates, key voters, and every key named by an attached
+// native script. Inputs sharing a payment key need one witness, so they count
+// once. The count is raised to SetExpectedSigners when that is larger.
+// Note: this count may underestimate if additional signers (e.g., multi-sig
+// participants) are added after Complete(). Callers can use
+// SetExpectedSigners() or ComputeExactFee() to account for extra witnesses.
+func (a *Apollo) estimatedWitnessCount(inputs []common.Utxo) int {
+	keys := make(map[common.Blake2b224]struct{})
+	if a.wallet != nil {
+		keys[a.wallet.PubKeyHash()] = struct{}{}
+	}
+	for _, utxo := range slices.Concat(inputs, a.collaterals) {
+		if hash, ok := vkeyPaymentHash(utxo); ok {
+			keys[hash] = struct{}{}
+		}
+	}
+	for _, hash := range a.requiredSigners {
+		keys[hash] = struct{}{}
+	}
+	maps.Copy(keys, a.requiredStakeWitnesses())
+	maps.Copy(keys, a.committeeColdWitnesses())
+	maps.Copy(keys, a.voterKeyWitnesses())
+	for i := range a.nativescripts {
+		nativeScriptKeyHashes(&a.nativescripts[i], keys)
+	}
+	return max(len(keys), a.expectedSigners, 1)
+}
+
+// nativeScriptKeyHashes adds every key hash named anywhere in ns to keys.
+// Which alternatives of an any-of or n-of-k script will sign is unknown, so
+// all of them are counted.
+func nativeScriptKeyHashes(ns *common.NativeScript, keys map[common.Blake2b224]struct{}) {
+	var scripts []common.NativeScript
+	switch s := ns.Item().(type) {
+	case *common.NativeScriptPubkey:
+		if len(s.Hash) == common.Blake2b224Size {
+			var hash common.Blake2b224
+			copy(hash[:], s.Hash)
+			keys[hash] = struct{}{}
+		}
+	case *common.NativeScriptAll:
+		scripts = s.Scripts
+	case *common.NativeScriptAny:
+		scripts = s.Scripts
+	case *common.NativeScriptNofK:
+		scripts = s.Scripts
+	}
+	for i := range scripts {
+		nativeScriptKeyHashes(&scripts[i], keys)
+	}
+}
+
+// vkeyPaymentHash returns the payment key hash that must witness spending a
+// UTxO, or false for script-locked and Byron outputs.
+func vkeyPaymentHash(utxo common.Utxo) (common.Blake2b224, bool) {
+	if utxo.Output == nil {
+		return common.Blake2b224{}, false
+	}
+	addr := utxo.Output.Address()
+	switch addr.Type() {
+	case common.AddressTypeKeyKey, common.AddressTypeKeyScript,
+		common.AddressTypeKeyPointer, common.AddressTypeKeyNone:
+		return addr.PaymentKeyHash(), true
+	default:
+		return common.Blake2b224{}, false
+	}
+}
+
+// feeForBody computes the minimum fee of a transaction with the given body
+// once it carries witnessCount vkey witnesses.
+func (a *Apollo) feeForBody(body conway.ConwayTransactionBody, inputs []common.Utxo, witnessCount int, pp backend.ProtocolParameters) (int64, error) {
+	txBytes, err := a.sizingTxCbor(body, inputs, witnessCount)
+	if err != nil {
+		return 0, err
+	}
+
+	var totalMem, totalSteps int64
+	for _, rv := range a.buildRedeemerMap(inputs) {
+		totalMem += rv.ExUnits.Memory
+		totalSteps += rv.ExUnits.Steps
+	}
+	// The ledger prices every script attached to the outputs of the spending
+	// and reference inputs, whether or not the transaction runs it; omitting
+	// them produces FeeTooSmallUTxO.
+	refScriptSize, err := a.totalReferenceScriptSize(inputs)
+	if err != nil {
+		return 0, err
+	}
+	return minFee(pp, len(txBytes), totalMem, totalSteps, refScriptSize)
+}
+
+// sizingTxCbor encodes the transaction the body would make once it carries
+// witnessCount vkey witnesses and the builder's metadata, for sizing.
+func (a *Apollo) sizingTxCbor(body conway.ConwayTransactionBody, inputs []common.Utxo, witnessCount int) ([]byte, error) {
 	ws := a.buildWitnessSet(inputs)
-	// Add fake vkey witnesses for size estimation (1 for wallet + 1 per required signer).
-	// Note: this count may underestimate if additional signers (e.g., multi-sig
-	// participants) are added after Complete(). Callers can use SetFeePadding()
-	// to account for extra witnesses.
-	witnessCount := 1 + len(a.requiredSigners)
+	// Add fake vkey witnesses for size estimation.
 	fakeWitnesses := make([]common.VkeyWitness, witnessCount)
 	for i := range fakeWitnesses {
 		fakeWitnesses[i] = common.VkeyWitness{
@@ -1645,7 +3684,7 @@ func (a *Apollo) estimateFee(inputs []common.Utxo, outputs []babbage.BabbageTran
 	if a.auxiliaryData != nil {
 		md, mdErr := a.buildMetadata()
 		if mdErr != nil {
-			return 0, mdErr
+			return nil, mdErr
 		}
 		if md != nil {
 			dummyTx.TxMetadata = md
@@ -1654,22 +3693,139 @@ func (a *Apollo) estimateFee(inputs []common.Utxo, outputs []babbage.BabbageTran
 
 	txBytes, err := cbor.Encode(&dummyTx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to encode dummy tx: %w", err)
+		return nil, fmt.Errorf("failed to encode dummy tx: %w", err)
+	}
+	return txBytes, nil
+}
+
+// settleTransaction balances the outputs against fee, iterating change,
+// collateral and execution units until the transaction shape is stable, and
+// builds the final body.
+func (a *Apollo) settleTransaction(
+	inputs []common.Utxo,
+	baseOutputs []babbage.BabbageTransactionOutput,
+	fee int64,
+	balance balanceContext,
+) ([]babbage.BabbageTransactionOutput, int64, conway.ConwayTransactionBody, error) {
+	var outputs []babbage.BabbageTransactionOutput
+	var err error
+	const maxEvaluationIterations = 5
+	var previousShape string
+	seenShapes := make(map[string]struct{}, maxEvaluationIterations)
+	converged := false
+	for iteration := range maxEvaluationIterations {
+		balanced, balanceErr := a.buildBalancedOutputs(baseOutputs, fee, balance)
+		if balanceErr != nil {
+			return nil, 0, conway.ConwayTransactionBody{}, balanceErr
+		}
+		outputs, fee = balanced.Outputs, balanced.Fee
+		if err := a.finalizeCollateral(fee); err != nil {
+			return nil, 0, conway.ConwayTransactionBody{}, err
+		}
+
+		if a.isEstimateRequired && a.estimateExUnits {
+			units, evalErr := a.estimateExecutionUnits(inputs, outputs, fee)
+			if evalErr != nil {
+				return nil, 0, conway.ConwayTransactionBody{}, fmt.Errorf("ExUnit estimation failed: %w", evalErr)
+			}
+			a.applyExecutionUnits(units, inputs)
+			a.debug("evaluated execution units", "iteration", iteration, "redeemers", len(units))
+		}
+
+		if fee < 0 {
+			return nil, 0, conway.ConwayTransactionBody{}, fmt.Errorf("negative fee: %d", fee)
+		}
+		body, bodyErr := a.buildBody(inputs, outputs, uint64(fee)) //nolint:gosec // validated non-negative above
+		if bodyErr != nil {
+			return nil, 0, conway.ConwayTransactionBody{}, bodyErr
+		}
+		bodyBytes, bodyErr := cbor.Encode(&body)
+		if bodyErr != nil {
+			return nil, 0, conway.ConwayTransactionBody{}, fmt.Errorf("failed to encode evaluation shape: %w", bodyErr)
+		}
+		shape := string(bodyBytes)
+		newFee := fee
+		if !a.forceFee && a.Fee == 0 {
+			newFee, err = a.estimateFee(inputs, outputs)
+			if err != nil {
+				return nil, 0, conway.ConwayTransactionBody{}, fmt.Errorf("fee re-estimation failed: %w", err)
+			}
+			newFee += a.FeePadding
+			if newFee < 0 {
+				newFee = 0
+			}
+		}
+		a.debug("fee convergence iteration",
+			"iteration", iteration,
+			"fee", fee,
+			"new_fee", newFee,
+			"shape_changed", previousShape != shape,
+		)
+		if newFee == fee && previousShape == shape {
+			converged = true
+			break
+		}
+		if _, seen := seenShapes[shape]; seen {
+			return nil, 0, conway.ConwayTransactionBody{}, errors.New("evaluation transaction did not converge after 5 iterations")
+		}
+		seenShapes[shape] = struct{}{}
+		previousShape = shape
+		fee = newFee
+	}
+	if !converged {
+		return nil, 0, conway.ConwayTransactionBody{}, errors.New("evaluation transaction did not converge after 5 iterations")
+	}
+	if err := a.validateExUnitsBudget(inputs); err != nil {
+		return nil, 0, conway.ConwayTransactionBody{}, err
+	}
+	if err := a.checkNoAssetBurn(inputs, outputs); err != nil {
+		return nil, 0, conway.ConwayTransactionBody{}, err
+	}
+
+	body, err := a.buildBody(inputs, outputs, uint64(fee))
+	if err != nil {
+		return nil, 0, conway.ConwayTransactionBody{}, err
+	}
+	if err := a.checkTxSize(body, inputs); err != nil {
+		return nil, 0, conway.ConwayTransactionBody{}, err
+	}
+	return outputs, fee, body, nil
+}
+
+// checkTxSize rejects a transaction that would exceed the protocol's max tx
+// size once signed by the estimated witnesses, reporting how much of it is
+// metadata.
+func (a *Apollo) checkTxSize(body conway.ConwayTransactionBody, inputs []common.Utxo) error {
+	pp, err := a.Context.ProtocolParams()
+	if err != nil {
+		return fmt.Errorf("failed to get protocol parameters: %w", err)
 	}
+	if pp.MaxTxSize <= 0 {
+		return nil
+	}
+	txBytes, err := a.sizingTxCbor(body, inputs, a.estimatedWitnessCount(inputs))
+	if err != nil {
+		return err
+	}
+	if len(txBytes) <= pp.MaxTxSize {
+		return nil
+	}
+	mdSize, err := a.metadataSize()
+	if err != nil {
+		return err
+	}
+	return &TxTooLargeError{Size: len(txBytes), MaxSize: pp.MaxTxSize, MetadataSize: mdSize}
+}
 
-	txSize := len(txBytes)
+// minFee is the ledger minimum fee: the linear size fee, the execution unit
+// cost and the Conway tiered reference-script fee.
+func minFee(pp backend.ProtocolParameters, txSize int, exMem, exSteps int64, refScriptSize int) (int64, error) {
 	fee := int64(txSize)*pp.MinFeeCoefficient + pp.MinFeeConstant
 
 	// Add execution unit costs for script transactions.
 	// fee += priceMem * totalExMem + priceStep * totalExSteps
-	redeemerMap := a.buildRedeemerMap(inputs)
-	if len(redeemerMap) > 0 {
-		var totalMem, totalSteps int64
-		for _, rv := range redeemerMap {
-			totalMem += rv.ExUnits.Memory
-			totalSteps += rv.ExUnits.Steps
-		}
-		exUnitFeeFloat := math.Ceil(float64(pp.PriceMem)*float64(totalMem) + float64(pp.PriceStep)*float64(totalSteps))
+	if exMem > 0 || exSteps > 0 {
+		exUnitFeeFloat := math.Ceil(float64(pp.PriceMem)*float64(exMem) + float64(pp.PriceStep)*float64(exSteps))
 		// Out-of-range float-to-int conversion is implementation-defined; reject
 		// rather than sign a transaction with a corrupted fee. NaN fails this check too.
 		if !(exUnitFeeFloat >= 0 && exUnitFeeFloat < float64(math.MaxInt64)) {
@@ -1679,7 +3835,7 @@ func (a *Apollo) estimateFee(inputs []common.Utxo, outputs []babbage.BabbageTran
 	}
 
 	// Add the Conway tiered reference-script fee.
-	refScriptFee, err := a.referenceScriptFeeWithParams(inputs, pp)
+	refScriptFee, err := referenceScriptFeeForSize(refScriptSize, pp)
 	if err != nil {
 		return 0, err
 	}
@@ -1706,21 +3862,6 @@ func (a *Apollo) referenceScriptFee(inputs []common.Utxo) (int64, error) {
 	return referenceScriptFeeForSize(refScriptSize, pp)
 }
 
-// referenceScriptFeeWithParams computes the Conway tiered reference-script fee.
-// Scripts attached (via script_ref) to the outputs of the transaction's spending
-// inputs AND reference inputs are priced per byte on a growing tier (native
-// scripts included, not only Plutus). The ledger counts the union of regular and
-// reference inputs regardless of whether the transaction executes scripts, so
-// omitting it produces FeeTooSmallUTxO. The fee is naturally zero when no
-// referenced UTxO carries a script.
-func (a *Apollo) referenceScriptFeeWithParams(inputs []common.Utxo, pp backend.ProtocolParameters) (int64, error) {
-	refScriptSize, err := a.totalReferenceScriptSize(inputs)
-	if err != nil {
-		return 0, err
-	}
-	return referenceScriptFeeForSize(refScriptSize, pp)
-}
-
 func referenceScriptFeeForSize(refScriptSize int, pp backend.ProtocolParameters) (int64, error) {
 	if refScriptSize == 0 {
 		return 0, nil
@@ -1825,41 +3966,10 @@ func (a *Apollo) estimateExecutionUnits(
 	if fee < 0 {
 		return nil, fmt.Errorf("negative fee: %d", fee)
 	}
-	body, err := a.buildBody(inputs, outputs, uint64(fee)) //nolint:gosec // validated non-negative above
-	if err != nil {
-		return nil, fmt.Errorf("failed to build preliminary tx body: %w", err)
-	}
-	ws := a.buildWitnessSet(inputs)
-
-	witnesses, err := a.evaluationWitnesses(&body)
+	evalResult, err := a.evaluatePreliminaryTx(inputs, outputs, uint64(fee)) //nolint:gosec // validated non-negative above
 	if err != nil {
 		return nil, err
 	}
-	ws.VkeyWitnesses = cbor.NewSetType(witnesses, true)
-
-	prelimTx := conway.ConwayTransaction{
-		Body:       body,
-		WitnessSet: ws,
-		TxIsValid:  true,
-	}
-	if a.auxiliaryData != nil {
-		md, mdErr := a.buildMetadata()
-		if mdErr != nil {
-			return nil, mdErr
-		}
-		if md != nil {
-			prelimTx.TxMetadata = md
-		}
-	}
-	txBytes, err := cbor.Encode(&prelimTx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode preliminary tx: %w", err)
-	}
-
-	evalResult, err := a.Context.EvaluateTx(txBytes, inputs)
-	if err != nil {
-		return nil, fmt.Errorf("EvaluateTx failed: %w", err)
-	}
 	if len(evalResult) == 0 {
 		return nil, errors.New("EvaluateTx returned no results")
 	}
@@ -1871,10 +3981,15 @@ func (a *Apollo) estimateExecutionUnits(
 	seenSpend := make(map[string]bool, len(a.redeemers))
 	seenMint := make(map[string]bool, len(a.mintRedeemers))
 	seenStake := make(map[string]bool, len(a.stakeRedeemers))
+	seenVote := make(map[string]bool, len(a.voteRedeemers))
 	for evalKey, evalUnits := range evalResult {
+		safety := a.exUnitSafetyFactor
+		if safety == 0 {
+			safety = 1
+		}
 		bufferedUnits := common.ExUnits{
-			Memory: bufferExUnits(evalUnits.Memory, 1+ExMemoryBuffer),
-			Steps:  bufferExUnits(evalUnits.Steps, 1+ExStepBuffer),
+			Memory: bufferExUnits(evalUnits.Memory, (1+a.exMemoryBuffer)*safety),
+			Steps:  bufferExUnits(evalUnits.Steps, (1+a.exStepBuffer)*safety),
 		}
 		switch evalKey.Tag {
 		case common.RedeemerTagSpend:
@@ -1907,40 +4022,180 @@ func (a *Apollo) estimateExecutionUnits(
 				return nil, fmt.Errorf("EvaluateTx returned withdrawal redeemer index %d out of range (%d withdrawals)", evalKey.Index, len(sortedWdAddrs))
 			}
 			addrKey := sortedWdAddrs[evalKey.Index]
-			wd := a.withdrawals[addrKey]
-			skhHex := hex.EncodeToString(wd.Address.StakeKeyHash().Bytes())
-			entry, ok := a.stakeRedeemers[skhHex]
-			if !ok {
-				return nil, fmt.Errorf("EvaluateTx returned a result for withdrawal %s, which has no registered redeemer", skhHex)
+			account, err := rewardAccountKey(a.withdrawals[addrKey].Address)
+			if err != nil {
+				return nil, fmt.Errorf("EvaluateTx returned a result for withdrawal %s: %w", addrKey, err)
 			}
-			_ = entry
-			seenStake[skhHex] = true
+			if _, ok := a.stakeRedeemers[account]; !ok {
+				return nil, fmt.Errorf("EvaluateTx returned a result for withdrawal %s, which has no registered redeemer", addrKey)
+			}
+			seenStake[account] = true
+		case common.RedeemerTagVoting:
+			voters := a.sortedVoters()
+			if uint64(evalKey.Index) >= uint64(len(voters)) {
+				return nil, fmt.Errorf("EvaluateTx returned voting redeemer index %d out of range (%d voters)", evalKey.Index, len(voters))
+			}
+			key := voterKey(voters[evalKey.Index])
+			if _, ok := a.voteRedeemers[key]; !ok {
+				return nil, fmt.Errorf("EvaluateTx returned a result for voter %s, which has no registered redeemer", key)
+			}
+			seenVote[key] = true
 		default:
 			return nil, fmt.Errorf("EvaluateTx returned unsupported redeemer tag %d", evalKey.Tag)
 		}
-		validated[evalKey] = bufferedUnits
+		validated[evalKey] = bufferedUnits
+	}
+
+	// Every registered redeemer must have received an execution budget; a
+	// redeemer left at zero ExUnits would fail phase-2 validation on-chain
+	// and forfeit the collateral.
+	for ref := range a.redeemers {
+		if !seenSpend[ref] {
+			return nil, fmt.Errorf("execution-unit evaluation returned no result for spend redeemer on input %s", ref)
+		}
+	}
+	for policyHex := range a.mintRedeemers {
+		if !seenMint[policyHex] {
+			return nil, fmt.Errorf("execution-unit evaluation returned no result for mint redeemer on policy %s", policyHex)
+		}
+	}
+	for account := range a.stakeRedeemers {
+		if !seenStake[account] {
+			return nil, fmt.Errorf("execution-unit evaluation returned no result for withdrawal redeemer on reward account %s", account)
+		}
+	}
+	for key := range a.voteRedeemers {
+		if !seenVote[key] {
+			return nil, fmt.Errorf("execution-unit evaluation returned no result for voting redeemer on voter %s", key)
+		}
+	}
+
+	return validated, nil
+}
+
+// maxEvalBudgetRetries bounds how often a preliminary evaluation that fails
+// for lack of script budget is retried with raised redeemer budgets.
+const maxEvalBudgetRetries = 3
+
+// evaluatePreliminaryTx evaluates the preliminary transaction built with the
+// registered redeemer budgets. Some evaluators reject it when those budgets,
+// still zero before the first estimate, are too low for the scripts to run.
+// On such an error every redeemer's budget is raised, doubling up to an even
+// share of the per-transaction maximum, and the evaluation is retried. The
+// budgets are raised in copies of the redeemer maps, so the caller's
+// registered budgets are left as they were.
+func (a *Apollo) evaluatePreliminaryTx(
+	inputs []common.Utxo,
+	outputs []babbage.BabbageTransactionOutput,
+	fee uint64,
+) (map[common.RedeemerKey]common.ExUnits, error) {
+	redeemers, mintRedeemers, stakeRedeemers := a.redeemers, a.mintRedeemers, a.stakeRedeemers
+	voteRedeemers := a.voteRedeemers
+	a.redeemers, a.mintRedeemers, a.stakeRedeemers = maps.Clone(redeemers), maps.Clone(mintRedeemers), maps.Clone(stakeRedeemers)
+	a.voteRedeemers = maps.Clone(voteRedeemers)
+	defer func() {
+		a.redeemers, a.mintRedeemers, a.stakeRedeemers = redeemers, mintRedeemers, stakeRedeemers
+		a.voteRedeemers = voteRedeemers
+	}()
+	for attempt := 0; ; attempt++ {
+		txBytes, err := a.preliminaryTxCbor(inputs, outputs, fee)
+		if err != nil {
+			return nil, err
+		}
+		if a.localEvaluation != nil {
+			// Local evaluation runs each script with the full transaction
+			// budget, so a budget failure is final.
+			return a.evaluateLocally(txBytes, inputs)
+		}
+		evalResult, err := a.Context.EvaluateTx(txBytes, inputs)
+		if err == nil {
+			return evalResult, nil
+		}
+		if !isBudgetExceededError(err) {
+			return nil, fmt.Errorf("EvaluateTx failed: %w", err)
+		}
+		if attempt == maxEvalBudgetRetries {
+			return nil, fmt.Errorf("EvaluateTx failed after %d retries with raised preliminary budgets: %w", maxEvalBudgetRetries, err)
+		}
+		if raiseErr := a.raisePreliminaryBudgets(maxEvalBudgetRetries - 1 - attempt); raiseErr != nil {
+			return nil, fmt.Errorf("EvaluateTx failed: %w (%v)", err, raiseErr)
+		}
+	}
+}
+
+// raisePreliminaryBudgets raises every registered redeemer budget to at least
+// its even share of the per-transaction maximum, halved shift times.
+func (a *Apollo) raisePreliminaryBudgets(shift int) error {
+	maxMem, maxSteps, err := a.maxTxExUnits()
+	if err != nil {
+		return err
+	}
+	if maxMem == 0 || maxSteps == 0 {
+		return errors.New("cannot raise preliminary script budgets: per-transaction limits are unknown")
+	}
+	count := int64(len(a.redeemers) + len(a.mintRedeemers) + len(a.stakeRedeemers) + len(a.voteRedeemers))
+	if count == 0 {
+		return errors.New("cannot raise preliminary script budgets: no redeemers registered")
+	}
+	floor := common.ExUnits{Memory: (maxMem / count) >> shift, Steps: (maxSteps / count) >> shift}
+	for _, entries := range []map[string]redeemerEntry{a.redeemers, a.mintRedeemers, a.stakeRedeemers, a.voteRedeemers} {
+		for key, entry := range entries {
+			entry.ExUnits.Memory = max(entry.ExUnits.Memory, floor.Memory)
+			entry.ExUnits.Steps = max(entry.ExUnits.Steps, floor.Steps)
+			entries[key] = entry
+		}
 	}
+	return nil
+}
 
-	// Every registered redeemer must have received an execution budget; a
-	// redeemer left at zero ExUnits would fail phase-2 validation on-chain
-	// and forfeit the collateral.
-	for ref := range a.redeemers {
-		if !seenSpend[ref] {
-			return nil, fmt.Errorf("execution-unit evaluation returned no result for spend redeemer on input %s", ref)
-		}
+// isBudgetExceededError reports whether an evaluation error says a script ran
+// out of execution budget. Backends only report this as text, such as
+// Ogmios' "overspent" budget failures.
+func isBudgetExceededError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	if !strings.Contains(msg, "budget") {
+		return false
 	}
-	for policyHex := range a.mintRedeemers {
-		if !seenMint[policyHex] {
-			return nil, fmt.Errorf("execution-unit evaluation returned no result for mint redeemer on policy %s", policyHex)
-		}
+	return strings.Contains(msg, "exceed") || strings.Contains(msg, "overspent") || strings.Contains(msg, "exhaust")
+}
+
+// preliminaryTxCbor encodes the transaction submitted for script evaluation.
+func (a *Apollo) preliminaryTxCbor(
+	inputs []common.Utxo,
+	outputs []babbage.BabbageTransactionOutput,
+	fee uint64,
+) ([]byte, error) {
+	body, err := a.buildBody(inputs, outputs, fee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build preliminary tx body: %w", err)
 	}
-	for skhHex := range a.stakeRedeemers {
-		if !seenStake[skhHex] {
-			return nil, fmt.Errorf("execution-unit evaluation returned no result for withdrawal redeemer on stake key %s", skhHex)
-		}
+	ws := a.buildWitnessSet(inputs)
+
+	witnesses, err := a.evaluationWitnesses(&body)
+	if err != nil {
+		return nil, err
 	}
+	ws.VkeyWitnesses = cbor.NewSetType(witnesses, true)
 
-	return validated, nil
+	prelimTx := conway.ConwayTransaction{
+		Body:       body,
+		WitnessSet: ws,
+		TxIsValid:  true,
+	}
+	if a.auxiliaryData != nil {
+		md, mdErr := a.buildMetadata()
+		if mdErr != nil {
+			return nil, mdErr
+		}
+		if md != nil {
+			prelimTx.TxMetadata = md
+		}
+	}
+	txBytes, err := cbor.Encode(&prelimTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preliminary tx: %w", err)
+	}
+	return txBytes, nil
 }
 
 // applyExecutionUnits is deliberately separate from estimateExecutionUnits:
@@ -1959,11 +4214,18 @@ func (a *Apollo) applyExecutionUnits(units map[common.RedeemerKey]common.ExUnits
 			entry.ExUnits = exUnits
 			a.mintRedeemers[policy] = entry
 		case common.RedeemerTagReward:
-			wd := a.withdrawals[a.sortedWithdrawalKeys()[key.Index]]
-			stakeKey := hex.EncodeToString(wd.Address.StakeKeyHash().Bytes())
-			entry := a.stakeRedeemers[stakeKey]
+			account, err := rewardAccountKey(a.withdrawals[a.sortedWithdrawalKeys()[key.Index]].Address)
+			if err != nil {
+				continue
+			}
+			entry := a.stakeRedeemers[account]
+			entry.ExUnits = exUnits
+			a.stakeRedeemers[account] = entry
+		case common.RedeemerTagVoting:
+			key := voterKey(a.sortedVoters()[key.Index])
+			entry := a.voteRedeemers[key]
 			entry.ExUnits = exUnits
-			a.stakeRedeemers[stakeKey] = entry
+			a.voteRedeemers[key] = entry
 		}
 	}
 }
@@ -2092,7 +4354,7 @@ func (a *Apollo) buildBody(
 	}
 
 	// Script data hash
-	if len(a.redeemers) > 0 || len(a.mintRedeemers) > 0 || len(a.stakeRedeemers) > 0 || len(a.datums) > 0 {
+	if a.hasRedeemers() || len(a.datums) > 0 {
 		pp, err := a.Context.ProtocolParams()
 		if err != nil {
 			return body, err
@@ -2119,17 +4381,25 @@ func (a *Apollo) buildBody(
 func (a *Apollo) buildWitnessSet(inputs []common.Utxo) conway.ConwayTransactionWitnessSet {
 	ws := conway.ConwayTransactionWitnessSet{}
 
-	if len(a.v1scripts) > 0 {
-		ws.WsPlutusV1Scripts = cbor.NewSetType(a.v1scripts, true)
+	v1scripts, v2scripts, v3scripts, nativescripts := a.v1scripts, a.v2scripts, a.v3scripts, a.nativescripts
+	if a.dedupReferenceScripts {
+		referenced := a.referenceScriptHashes(inputs)
+		v1scripts = withoutScripts(v1scripts, referenced)
+		v2scripts = withoutScripts(v2scripts, referenced)
+		v3scripts = withoutScripts(v3scripts, referenced)
+		nativescripts = withoutScripts(nativescripts, referenced)
 	}
-	if len(a.v2scripts) > 0 {
-		ws.WsPlutusV2Scripts = cbor.NewSetType(a.v2scripts, true)
+	if len(v1scripts) > 0 {
+		ws.WsPlutusV1Scripts = cbor.NewSetType(v1scripts, true)
 	}
-	if len(a.v3scripts) > 0 {
-		ws.WsPlutusV3Scripts = cbor.NewSetType(a.v3scripts, true)
+	if len(v2scripts) > 0 {
+		ws.WsPlutusV2Scripts = cbor.NewSetType(v2scripts, true)
+	}
+	if len(v3scripts) > 0 {
+		ws.WsPlutusV3Scripts = cbor.NewSetType(v3scripts, true)
 	}
-	if len(a.nativescripts) > 0 {
-		ws.WsNativeScripts = cbor.NewSetType(a.nativescripts, true)
+	if len(nativescripts) > 0 {
+		ws.WsNativeScripts = cbor.NewSetType(nativescripts, true)
 	}
 	if len(a.datums) > 0 {
 		ws.WsPlutusData = cbor.NewSetType(a.datums, true)
@@ -2145,6 +4415,64 @@ func (a *Apollo) buildWitnessSet(inputs []common.Utxo) conway.ConwayTransactionW
 	return ws
 }
 
+// ResolveScripts controls whether Complete() finds the validators of script
+// inputs added with CollectFrom that are neither attached nor available by
+// reference. A UTxO at the input's address carrying the script as a
+// reference script is added as a reference input; otherwise the script is
+// fetched with the chain context's ScriptCbor and attached.
+func (a *Apollo) ResolveScripts(enabled bool) *Apollo {
+	a.resolveScripts = enabled
+	return a
+}
+
+// resolveDedupReferenceUtxos resolves the reference inputs once for
+// DedupReferenceScripts, so the fee iterations that rebuild the witness set
+// do not query the chain context again.
+func (a *Apollo) resolveDedupReferenceUtxos() error {
+	a.dedupReferenceUtxos = nil
+	if !a.dedupReferenceScripts {
+		return nil
+	}
+	for _, refInput := range a.referenceInputs {
+		ref := hex.EncodeToString(refInput.TxId.Bytes()) + "#" + strconv.Itoa(int(refInput.OutputIndex))
+		utxo, err := a.Context.UtxoByRef(refInput.TxId, refInput.OutputIndex)
+		if err != nil {
+			return fmt.Errorf("failed to resolve reference input %s for script deduplication: %w", ref, err)
+		}
+		if utxo == nil {
+			return fmt.Errorf("reference input %s not found for script deduplication", ref)
+		}
+		a.dedupReferenceUtxos = append(a.dedupReferenceUtxos, *utxo)
+	}
+	return nil
+}
+
+// referenceScriptHashes returns the hashes of the reference scripts carried by
+// the spending inputs and the reference inputs resolved for deduplication.
+func (a *Apollo) referenceScriptHashes(inputs []common.Utxo) map[common.Blake2b224]struct{} {
+	hashes := make(map[common.Blake2b224]struct{})
+	for _, utxo := range slices.Concat(inputs, a.dedupReferenceUtxos) {
+		if script := utxo.Output.ScriptRef(); script != nil {
+			hashes[script.Hash()] = struct{}{}
+		}
+	}
+	return hashes
+}
+
+// withoutScripts returns scripts minus those whose hash is in drop.
+func withoutScripts[S common.Script](scripts []S, drop map[common.Blake2b224]struct{}) []S {
+	if len(drop) == 0 {
+		return scripts
+	}
+	kept := make([]S, 0, len(scripts))
+	for _, script := range scripts {
+		if _, ok := drop[script.Hash()]; !ok {
+			kept = append(kept, script)
+		}
+	}
+	return kept
+}
+
 func (a *Apollo) buildRedeemerMap(inputs []common.Utxo) map[common.RedeemerKey]common.RedeemerValue {
 	result := make(map[common.RedeemerKey]common.RedeemerValue)
 
@@ -2187,25 +4515,31 @@ func (a *Apollo) buildRedeemerMap(inputs []common.Utxo) map[common.RedeemerKey]c
 		}
 	}
 
-	// Stake redeemers - index based on sorted withdrawal address position
+	// Stake redeemers - index based on the reward account's position in
+	// ledger order
 	if len(a.stakeRedeemers) > 0 {
-		sortedWdAddrs := a.sortedWithdrawalKeys()
-		for skhHex, entry := range a.stakeRedeemers {
-			found := false
-			idx := uint32(0)
-			for i, addrKey := range sortedWdAddrs {
-				wd := a.withdrawals[addrKey]
-				addrSKH := hex.EncodeToString(wd.Address.StakeKeyHash().Bytes())
-				if addrSKH == skhHex {
-					idx = uint32(i)
-					found = true
-					break
-				}
+		for i, addrKey := range a.sortedWithdrawalKeys() {
+			account, err := rewardAccountKey(a.withdrawals[addrKey].Address)
+			if err != nil {
+				continue
 			}
-			if !found {
+			entry, ok := a.stakeRedeemers[account]
+			if !ok {
+				continue
+			}
+			key := common.RedeemerKey{Tag: common.RedeemerTagReward, Index: uint32(i)}
+			result[key] = common.RedeemerValue{Data: entry.Data, ExUnits: entry.ExUnits}
+		}
+	}
+
+	// Voting redeemers - index based on the voter's position in ledger order
+	if len(a.voteRedeemers) > 0 {
+		for i, voter := range a.sortedVoters() {
+			entry, ok := a.voteRedeemers[voterKey(voter)]
+			if !ok {
 				continue
 			}
-			key := common.RedeemerKey{Tag: common.RedeemerTagReward, Index: idx}
+			key := common.RedeemerKey{Tag: common.RedeemerTagVoting, Index: uint32(i)}
 			result[key] = common.RedeemerValue{Data: entry.Data, ExUnits: entry.ExUnits}
 		}
 	}
@@ -2252,7 +4586,7 @@ func (a *Apollo) usedScriptCostModels(
 		return nil, nil
 	}
 	if len(used) == 0 {
-		if len(a.redeemers) == 0 && len(a.mintRedeemers) == 0 && len(a.stakeRedeemers) == 0 {
+		if !a.hasRedeemers() {
 			return nil, nil
 		}
 		if len(available) == 1 {
@@ -2302,25 +4636,32 @@ func (a *Apollo) totalWithdrawalValue() Value {
 	return NewSimpleValue(total)
 }
 
-// sortedWithdrawalKeys returns withdrawal map keys sorted by raw address bytes.
-// This matches CBOR canonical ordering used by the Cardano ledger for redeemer indices.
+// sortedWithdrawalKeys returns withdrawal map keys in the ledger's order of
+// reward accounts, which fixes the reward redeemer indices: by network, then
+// script credentials before key credentials, then by credential hash. This
+// differs from raw address byte order, where key reward addresses (header
+// type 14) sort before script ones (type 15).
 func (a *Apollo) sortedWithdrawalKeys() []string {
 	type entry struct {
-		key       string
-		addrBytes []byte
+		key     string
+		account []byte
 	}
 	entries := make([]entry, 0, len(a.withdrawals))
 	for k, wd := range a.withdrawals {
-		b, err := wd.Address.Bytes()
-		if err != nil {
-			// Use empty bytes for addresses that fail to encode;
-			// this preserves deterministic ordering.
-			b = nil
+		var account []byte
+		if reward, err := RewardAddressFromAddress(wd.Address); err == nil {
+			account, _ = reward.Bytes()
+		}
+		if len(account) == 0 {
+			// Keep addresses without a stake credential in a deterministic
+			// position after the reward accounts.
+			account, _ = wd.Address.Bytes()
+			account = append([]byte{0xff}, account...)
 		}
-		entries = append(entries, entry{key: k, addrBytes: b})
+		entries = append(entries, entry{key: k, account: account})
 	}
 	sort.Slice(entries, func(i, j int) bool {
-		return bytes.Compare(entries[i].addrBytes, entries[j].addrBytes) < 0
+		return compareRewardAccounts(entries[i].account, entries[j].account) < 0
 	})
 	keys := make([]string, len(entries))
 	for i, e := range entries {
@@ -2329,6 +4670,40 @@ func (a *Apollo) sortedWithdrawalKeys() []string {
 	return keys
 }
 
+// compareRewardAccounts orders encoded reward addresses as the ledger orders
+// reward accounts.
+func compareRewardAccounts(x, y []byte) int {
+	if len(x) == 0 || len(y) == 0 {
+		return bytes.Compare(x, y)
+	}
+	if nx, ny := x[0]&0x0f, y[0]&0x0f; nx != ny {
+		return cmp.Compare(nx, ny)
+	}
+	// Header type 15 is a script credential, which the ledger orders first.
+	if sx, sy := x[0]>>4 == 0x0f, y[0]>>4 == 0x0f; sx != sy {
+		if sx {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(x[1:], y[1:])
+}
+
+// rewardAccountKey identifies the reward account a withdrawal draws from by
+// its canonical reward address bytes, so withdrawal redeemers resolve alike
+// for key and script stake credentials.
+func rewardAccountKey(addr common.Address) (string, error) {
+	reward, err := RewardAddressFromAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	raw, err := reward.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode reward address: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
 func (a *Apollo) governanceRequiredValue() (Value, error) {
 	total := uint64(0)
 	if a.treasuryDonation > 0 {
@@ -2344,6 +4719,75 @@ func (a *Apollo) governanceRequiredValue() (Value, error) {
 	return NewSimpleValue(total), nil
 }
 
+// DepositSummary reports the lovelace this transaction will lock in deposits
+// and the lovelace it will reclaim as refunds, so callers can show the impact
+// before building. Stake credential deposits use the key_deposit protocol
+// parameter and pool registrations the pool_deposit one; DRep and proposal
+// deposits use the amounts carried by their certificates and proposals. The
+// totals are the ones Complete balances.
+func (a *Apollo) DepositSummary() (deposits int64, refunds int64, err error) {
+	if a.err != nil {
+		return 0, 0, a.err
+	}
+	params, err := a.certificateDepositParams()
+	if err != nil {
+		return 0, 0, err
+	}
+	deposits, refunds = a.certificateDepositTotals(params)
+	for _, proposal := range a.proposalProcedures {
+		deposit := proposal.Deposit()
+		if deposit > uint64(math.MaxInt64-deposits) { //nolint:gosec // deposits is non-negative
+			return 0, 0, errors.New("governance proposal deposits overflow")
+		}
+		deposits += int64(deposit) //nolint:gosec // bound checked above
+	}
+	return deposits, refunds, nil
+}
+
+// normalizeMint merges mint units for the same asset, comparing policy IDs and
+// names case-insensitively and summing their quantities, and drops assets
+// whose quantities net to zero, since the ledger rejects zero mint amounts. A
+// policy with a redeemer must keep at least one asset, or its redeemer would
+// have nothing to bind to.
+func (a *Apollo) normalizeMint() error {
+	if len(a.mint) == 0 {
+		return nil
+	}
+	type mintKey struct{ policyId, name string }
+	totals := make(map[mintKey]*big.Int, len(a.mint))
+	var order []mintKey
+	for _, unit := range a.mint {
+		key := mintKey{policyId: strings.ToLower(unit.PolicyId), name: strings.ToLower(unit.Name)}
+		if total, ok := totals[key]; ok {
+			total.Add(total, big.NewInt(unit.Quantity))
+			continue
+		}
+		totals[key] = big.NewInt(unit.Quantity)
+		order = append(order, key)
+	}
+	merged := make([]Unit, 0, len(order))
+	for _, key := range order {
+		total := totals[key]
+		if !total.IsInt64() {
+			return fmt.Errorf("mint quantity of %s.%s overflows int64", key.policyId, key.name)
+		}
+		if total.Sign() == 0 {
+			continue
+		}
+		merged = append(merged, Unit{PolicyId: key.policyId, Name: key.name, Quantity: total.Int64()})
+	}
+	for _, key := range order {
+		if _, ok := a.mintRedeemers[key.policyId]; !ok {
+			continue
+		}
+		if !slices.ContainsFunc(merged, func(u Unit) bool { return u.PolicyId == key.policyId }) {
+			return fmt.Errorf("mint of policy %s nets to zero but has a redeemer", key.policyId)
+		}
+	}
+	a.mint = merged
+	return nil
+}
+
 func (a *Apollo) hasMint() bool {
 	return len(a.mint) > 0
 }
@@ -2369,6 +4813,154 @@ func (a *Apollo) GetMints() (Value, error) {
 	return a.mintValue()
 }
 
+// checkAssetFloors rejects a transaction whose outputs would leave the wallet
+// with less of a floored asset than SetAssetFloor requires. The retained
+// balance is everything held by the loaded and preselected UTxOs, plus the
+// net mint, minus what the outputs carry.
+func (a *Apollo) checkAssetFloors(outputValue Value) error {
+	if len(a.assetFloors) == 0 {
+		return nil
+	}
+	holdings, err := a.heldValue(false)
+	if err != nil {
+		return err
+	}
+	var minted Value
+	if a.hasMint() {
+		if minted, err = a.mintValue(); err != nil {
+			return err
+		}
+	}
+	for _, floor := range a.assetFloors {
+		// Floors are validated by SetAssetFloor.
+		policyBytes, _ := hex.DecodeString(floor.PolicyId)
+		name, _ := hex.DecodeString(floor.Name)
+		var policyId common.Blake2b224
+		copy(policyId[:], policyBytes)
+		retained := new(big.Int).Add(
+			valueAssetQuantity(holdings, policyId, name),
+			valueAssetQuantity(minted, policyId, name),
+		)
+		retained.Sub(retained, valueAssetQuantity(outputValue, policyId, name))
+		if retained.Cmp(big.NewInt(floor.Quantity)) < 0 {
+			return fmt.Errorf(
+				"asset floor for %s.%s not met: transaction would leave %s, floor is %d",
+				floor.PolicyId, floor.Name, retained, floor.Quantity,
+			)
+		}
+	}
+	return nil
+}
+
+// heldValue sums the preselected inputs and the UTxOs still available to coin
+// selection, counting each UTxO once. With includeAutoCollateral set, an
+// auto-selected collateral UTxO counts too, since Complete() may release it to
+// coin selection.
+func (a *Apollo) heldValue(includeAutoCollateral bool) (Value, error) {
+	released := make(map[string]bool)
+	if includeAutoCollateral && a.collateralAutoSelected {
+		for _, utxo := range a.collaterals {
+			released[utxoRef(utxo)] = true
+		}
+	}
+	seen := make(map[string]bool)
+	held := make([]common.Utxo, 0, len(a.utxos)+len(a.preselectedUtxos))
+	for _, utxo := range slices.Concat(a.preselectedUtxos, a.utxos) {
+		ref := utxoRef(utxo)
+		if seen[ref] || (a.usedUtxos[ref] && !released[ref]) {
+			continue
+		}
+		seen[ref] = true
+		held = append(held, utxo)
+	}
+	return a.sumUtxoValues(held)
+}
+
+// checkBurnHoldings verifies that the inputs available to the transaction hold
+// every asset queued for burning, so a burn mistake is reported directly
+// instead of as a coin selection failure.
+func (a *Apollo) checkBurnHoldings() error {
+	if !a.hasMint() {
+		return nil
+	}
+	burns, err := a.burnRequirementValue()
+	if err != nil {
+		return err
+	}
+	if burns.Assets == nil {
+		return nil
+	}
+	holdings, err := a.heldValue(true)
+	if err != nil {
+		return err
+	}
+	for _, policyId := range burns.Assets.Policies() {
+		for _, name := range burns.Assets.Assets(policyId) {
+			burned := burns.Assets.Asset(policyId, name)
+			held := valueAssetQuantity(holdings, policyId, name)
+			if held.Cmp(burned) < 0 {
+				return fmt.Errorf(
+					"cannot burn %s of asset %s.%s: only %s held",
+					burned, hex.EncodeToString(policyId.Bytes()), hex.EncodeToString(name), held,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// checkNoAssetBurn rejects a balanced transaction that spends a native asset
+// without carrying it to an output. The ledger only lets an asset leave by a
+// negative mint entry, so anything the inputs hold beyond what the outputs
+// carry and the mint burns would be silently lost or make the transaction
+// invalid.
+func (a *Apollo) checkNoAssetBurn(inputs []common.Utxo, outputs []babbage.BabbageTransactionOutput) error {
+	inputValue, err := a.sumUtxoValues(inputs)
+	if err != nil {
+		return err
+	}
+	if inputValue.Assets == nil {
+		return nil
+	}
+	outputValue, err := a.totalOutputValue(outputs)
+	if err != nil {
+		return err
+	}
+	var minted Value
+	if a.hasMint() {
+		if minted, err = a.mintValue(); err != nil {
+			return err
+		}
+	}
+	for _, policyId := range inputValue.Assets.Policies() {
+		for _, name := range inputValue.Assets.Assets(policyId) {
+			expected := new(big.Int).Add(
+				valueAssetQuantity(inputValue, policyId, name),
+				valueAssetQuantity(minted, policyId, name),
+			)
+			if carried := valueAssetQuantity(outputValue, policyId, name); carried.Cmp(expected) < 0 {
+				return fmt.Errorf(
+					"asset %s.%s would be burned without a mint entry: inputs hold %s, outputs carry %s",
+					hex.EncodeToString(policyId.Bytes()), hex.EncodeToString(name),
+					valueAssetQuantity(inputValue, policyId, name), carried,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// valueAssetQuantity returns the quantity of one asset in v, or zero.
+func valueAssetQuantity(v Value, policyId common.Blake2b224, name []byte) *big.Int {
+	if v.Assets == nil {
+		return new(big.Int)
+	}
+	if qty := v.Assets.Asset(policyId, name); qty != nil {
+		return qty
+	}
+	return new(big.Int)
+}
+
 // burnRequirementValue returns the absolute quantities of all assets being
 // burned (negative mint amounts). These must be covered by transaction inputs.
 func (a *Apollo) burnRequirementValue() (Value, error) {
@@ -2432,7 +5024,7 @@ func (a *Apollo) buildMintAsset() (*common.MultiAsset[common.MultiAssetTypeMint]
 }
 
 func (a *Apollo) isUsed(ref string) bool {
-	if a.usedUtxos[ref] {
+	if a.usedUtxos[ref] || a.lockedByOther(ref) {
 		return true
 	}
 	// Also check preselected
@@ -2444,9 +5036,9 @@ func (a *Apollo) isUsed(ref string) bool {
 	for _, utxo := range a.collaterals {
 		// A collateral UTxO flagged for overlap is intentionally left available
 		// to coin selection so it can ALSO be picked as a regular spending input
-		// (see collateralOverlapRef). Treat it as not-used here.
+		// (see collateralOverlapRefs). Treat it as not-used here.
 		cref := utxoRef(utxo)
-		if cref == a.collateralOverlapRef {
+		if slices.Contains(a.collateralOverlapRefs, cref) {
 			continue
 		}
 		if cref == ref {
@@ -2480,7 +5072,12 @@ func (a *Apollo) getChangeAddress() common.Address {
 // (attached scripts or redeemers from reference scripts).
 func (a *Apollo) hasScripts() bool {
 	return len(a.v1scripts) > 0 || len(a.v2scripts) > 0 || len(a.v3scripts) > 0 ||
-		len(a.redeemers) > 0 || len(a.mintRedeemers) > 0 || len(a.stakeRedeemers) > 0
+		a.hasRedeemers()
+}
+
+// hasRedeemers reports whether any redeemer is registered.
+func (a *Apollo) hasRedeemers() bool {
+	return len(a.redeemers) > 0 || len(a.mintRedeemers) > 0 || len(a.stakeRedeemers) > 0 || len(a.voteRedeemers) > 0
 }
 
 // setCollateral auto-selects collateral from UTxOs if needed.
@@ -2489,7 +5086,7 @@ func (a *Apollo) hasScripts() bool {
 // coin-selection pool (the common multi-UTxO case, where the tx shape is
 // unchanged). When no dedicated UTxO is free, it falls back to a UTxO that may
 // ALSO be used as a regular spending input: the candidate is recorded in
-// collateralOverlapRef and is NOT reserved, so coin selection can still pick
+// collateralOverlapRefs and is NOT reserved, so coin selection can still pick
 // it. This lets a wallet with a single UTxO build a script transaction. The
 // ledger permits the overlap because collateral is consumed only on phase-2
 // script failure and regular inputs only on success.
@@ -2503,10 +5100,11 @@ func (a *Apollo) setCollateral() error {
 	}
 	// Compute collateral from protocol params when possible.
 	// Total collateral = maxFee * collateralPercent / 100.
-	minCollateral := int64(5_000_000) // conservative fallback
+	minCollateral := a.config.CollateralFallback
+	pp, ppErr := a.Context.ProtocolParams()
 	if a.collateralAmount > 0 {
 		minCollateral = a.collateralAmount
-	} else if pp, err := a.Context.ProtocolParams(); err == nil {
+	} else if ppErr == nil {
 		if maxFee, err := a.Context.MaxTxFee(); err == nil && pp.CollateralPercent > 0 &&
 			maxFee <= math.MaxInt64/uint64(pp.CollateralPercent) {
 			computed := int64(maxFee) * int64(pp.CollateralPercent) / 100 //nolint:gosec // bounded above
@@ -2527,8 +5125,9 @@ func (a *Apollo) setCollateral() error {
 
 	// collateralEligible reports whether a UTxO can back collateral: it must be
 	// vkey-locked (never a script address), hold a representable lovelace amount
-	// of at least minCollateral, and -- if it carries native assets -- leave a
-	// positive ADA remainder so the assets can be returned via collateral_return.
+	// of at least minCollateral, and -- if it carries native assets -- leave an
+	// ADA remainder that covers the min-ADA of the collateral_return carrying
+	// the assets forward.
 	collateralEligible := func(utxo common.Utxo, requirePureLovelace bool) bool {
 		assets := utxo.Output.Assets()
 		if requirePureLovelace && assets != nil {
@@ -2548,10 +5147,21 @@ func (a *Apollo) setCollateral() error {
 		}
 		// An asset-bearing UTxO needs a positive remainder to carry the assets
 		// forward in the collateral return.
-		if assets != nil && lovelace-minCollateral == 0 {
+		if assets == nil {
+			return true
+		}
+		remainder := lovelace - minCollateral
+		if remainder == 0 {
 			return false
 		}
-		return true
+		// Without protocol parameters the return's min-UTxO cannot be
+		// checked, so keep to ADA-only collateral.
+		if ppErr != nil {
+			return false
+		}
+		ret := NewBabbageOutput(a.getChangeAddress(), Value{Coin: uint64(remainder), Assets: assets}, nil, nil) //nolint:gosec // remainder > 0
+		minReturn, err := MinLovelacePostAlonzo(&ret, pp.CoinsPerUtxoByteValue())
+		return err == nil && remainder >= minReturn
 	}
 
 	// selectCollateral records the chosen UTxO as collateral, reserves it out of
@@ -2563,7 +5173,13 @@ func (a *Apollo) setCollateral() error {
 		ref := utxoRef(utxo)
 		a.collaterals = append(a.collaterals, utxo)
 		a.collateralAutoSelected = true
-		a.markUsed(ref)
+		if a.collateralFromInputs {
+			// Leave the UTxO to coin selection; useInputAsCollateral swaps in
+			// a selected input afterwards if this one is not picked.
+			a.collateralOverlapRefs = []string{ref}
+		} else {
+			a.markUsed(ref)
+		}
 		a.totalCollateral = minCollateral
 		lovelace := utxo.Output.Amount().Int64()
 		remainder := lovelace - minCollateral
@@ -2596,43 +5212,255 @@ func (a *Apollo) setCollateral() error {
 		if a.isUsed(utxoRef(utxo)) {
 			continue
 		}
-		if collateralEligible(utxo, false) {
-			selectCollateral(utxo)
-			return nil
+		if collateralEligible(utxo, false) {
+			selectCollateral(utxo)
+			return nil
+		}
+	}
+	// Third pass: no single UTxO covers the collateral, so combine vkey UTxOs,
+	// largest first, within the protocol's collateral input cap, or the ledger
+	// default when it is unknown.
+	maxInputs := defaultMaxCollateralInputs
+	if ppErr == nil && pp.MaxCollateralInputs > 0 {
+		maxInputs = pp.MaxCollateralInputs
+	}
+	return a.selectCombinedCollateral(candidates, minCollateral, maxInputs, pp.CoinsPerUtxoByteValue(), ppErr)
+}
+
+// selectCombinedCollateral reserves the fewest vkey UTxOs, largest first,
+// whose lovelace covers minCollateral. ADA-only UTxOs are tried first;
+// token-bearing ones join only when those fall short, carrying their assets
+// forward in a collateral return that must meet its own min-UTxO. A positive
+// maxInputs caps how many may be combined. Without protocol parameters, ppErr,
+// that min-UTxO cannot be checked, so only ADA-only UTxOs are combined. With
+// SetCollateralFromInputs the chosen UTxOs stay available to coin selection,
+// as a single auto-selected collateral does.
+func (a *Apollo) selectCombinedCollateral(candidates []common.Utxo, minCollateral int64, maxInputs int, coinsPerUtxoByte int64, ppErr error) error {
+	var adaOnly, mixed []collateralCandidate
+	for _, utxo := range candidates {
+		if a.isUsed(utxoRef(utxo)) {
+			continue
+		}
+		addr := utxo.Output.Address()
+		if addr.Type() != common.AddressTypeKeyKey && addr.Type() != common.AddressTypeKeyNone {
+			continue
+		}
+		amt := utxo.Output.Amount()
+		if amt == nil || !amt.IsInt64() || amt.Sign() <= 0 {
+			continue
+		}
+		c := collateralCandidate{utxo: utxo, lovelace: amt.Int64()}
+		if utxo.Output.Assets() == nil {
+			adaOnly = append(adaOnly, c)
+		}
+		mixed = append(mixed, c)
+	}
+	byLovelace := func(pool []collateralCandidate) {
+		sort.SliceStable(pool, func(i, j int) bool { return pool[i].lovelace > pool[j].lovelace })
+	}
+	byLovelace(adaOnly)
+	byLovelace(mixed)
+
+	chosen, err := coverCollateral(adaOnly, minCollateral, maxInputs, a.getChangeAddress(), coinsPerUtxoByte)
+	if err != nil && len(mixed) > len(adaOnly) && ppErr != nil {
+		if errors.Is(err, errNoCollateral) {
+			return fmt.Errorf("%w: token-bearing UTxOs need protocol parameters: %w", errNoCollateral, ppErr)
+		}
+		return err
+	}
+	if err != nil && len(mixed) > len(adaOnly) {
+		withAssets, mixedErr := coverCollateral(mixed, minCollateral, maxInputs, a.getChangeAddress(), coinsPerUtxoByte)
+		switch {
+		case mixedErr == nil:
+			chosen, err = withAssets, nil
+		case errors.Is(err, errNoCollateral):
+			err = mixedErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	var assets *common.MultiAsset[common.MultiAssetTypeOutput]
+	for _, c := range chosen {
+		a.collaterals = append(a.collaterals, c.utxo)
+		if a.collateralFromInputs {
+			a.collateralOverlapRefs = append(a.collateralOverlapRefs, utxoRef(c.utxo))
+		} else {
+			a.markUsed(utxoRef(c.utxo))
+		}
+		total += c.lovelace
+		assets = addCollateralAssets(assets, c.utxo.Output.Assets())
+	}
+	a.collateralAutoSelected = true
+	a.totalCollateral = minCollateral
+	if remainder := total - minCollateral; remainder > 0 || assets != nil {
+		ret := NewBabbageOutput(a.getChangeAddress(), Value{Coin: uint64(remainder), Assets: assets}, nil, nil) //nolint:gosec // remainder >= 0
+		a.collateralReturn = &ret
+	}
+	return nil
+}
+
+var errNoCollateral = errors.New("script transaction requires collateral, but no eligible collateral UTxO was found")
+
+// defaultMaxCollateralInputs is the ledger's maxCollateralInputs, used to cap
+// combined collateral when protocol parameters do not report it.
+const defaultMaxCollateralInputs = 3
+
+// collateralCandidate is a UTxO considered for combined collateral.
+type collateralCandidate struct {
+	utxo     common.Utxo
+	lovelace int64
+}
+
+// coverCollateral takes candidates from the front of pool until their lovelace
+// covers minCollateral and, when they carry assets, leaves a remainder that
+// meets the min-UTxO of a collateral return to returnAddr holding them.
+func coverCollateral(
+	pool []collateralCandidate,
+	minCollateral int64,
+	maxInputs int,
+	returnAddr common.Address,
+	coinsPerUtxoByte int64,
+) ([]collateralCandidate, error) {
+	var total int64
+	var assets *common.MultiAsset[common.MultiAssetTypeOutput]
+	need := 0
+	for i, c := range pool {
+		if total > math.MaxInt64-c.lovelace {
+			break
+		}
+		total += c.lovelace
+		assets = addCollateralAssets(assets, c.utxo.Output.Assets())
+		if total < minCollateral {
+			continue
+		}
+		if assets != nil {
+			ret := NewBabbageOutput(returnAddr, Value{Coin: uint64(total - minCollateral), Assets: assets}, nil, nil) //nolint:gosec // total >= minCollateral
+			minReturn, err := MinLovelacePostAlonzo(&ret, coinsPerUtxoByte)
+			if err != nil || total-minCollateral < minReturn {
+				continue
+			}
 		}
+		need = i + 1
+		break
+	}
+	if need == 0 {
+		return nil, errNoCollateral
+	}
+	if maxInputs > 0 && need > maxInputs {
+		return nil, fmt.Errorf("cannot assemble collateral: need %d inputs but max is %d", need, maxInputs)
 	}
-	return errors.New("script transaction requires collateral, but no eligible collateral UTxO was found")
+	return pool[:need], nil
 }
 
-// releaseCollateralForOverlap un-reserves an auto-selected collateral UTxO so
-// coin selection can also pick it as a regular spending input. The ledger
+// addCollateralAssets accumulates assets into a copy owned by the collateral
+// return, leaving the UTxOs' own values untouched.
+func addCollateralAssets(
+	total, assets *common.MultiAsset[common.MultiAssetTypeOutput],
+) *common.MultiAsset[common.MultiAssetTypeOutput] {
+	if assets == nil {
+		return total
+	}
+	if total == nil {
+		return CloneMultiAsset(assets)
+	}
+	total.Add(assets)
+	return total
+}
+
+// releaseCollateralForOverlap un-reserves the auto-selected collateral UTxOs so
+// coin selection can also pick them as regular spending inputs. The ledger
 // permits a UTxO to be both a spending input and collateral because the two are
 // consumed on mutually exclusive paths (success vs phase-2 script failure).
 //
-// It only acts on a single auto-selected collateral input that has not already
-// been flagged for overlap, and reports whether anything was released so the
-// caller knows a retry is worthwhile. Caller-pinned collateral is never touched.
+// It only acts on auto-selected collateral that has not already been flagged
+// for overlap, and reports whether anything was released so the caller knows a
+// retry is worthwhile. Caller-pinned collateral is never touched.
 func (a *Apollo) releaseCollateralForOverlap() bool {
-	if !a.collateralAutoSelected || a.collateralOverlapRef != "" || len(a.collaterals) != 1 {
+	if !a.collateralAutoSelected || len(a.collateralOverlapRefs) > 0 || len(a.collaterals) == 0 {
 		return false
 	}
-	ref := utxoRef(a.collaterals[0])
-	delete(a.usedUtxos, ref)
-	a.collateralOverlapRef = ref
+	for _, utxo := range a.collaterals {
+		ref := utxoRef(utxo)
+		delete(a.usedUtxos, ref)
+		a.collateralOverlapRefs = append(a.collateralOverlapRefs, ref)
+	}
 	return true
 }
 
+// useInputAsCollateral moves auto-selected collateral onto one of the
+// spending inputs when SetCollateralFromInputs is enabled and the current
+// collateral, one UTxO or several combined, is not already spent. Only ADA-only vkey inputs holding at least
+// the preliminary total collateral qualify; finalizeCollateral() resizes the
+// total and return against the final fee. Without a qualifying input the
+// separate collateral is kept.
+func (a *Apollo) useInputAsCollateral(inputs []common.Utxo) {
+	if !a.collateralFromInputs || !a.collateralAutoSelected || len(a.collaterals) == 0 {
+		return
+	}
+	inputRefs := make(map[string]bool, len(inputs))
+	for _, utxo := range inputs {
+		inputRefs[utxoRef(utxo)] = true
+	}
+	spent := true
+	for _, utxo := range a.collaterals {
+		spent = spent && inputRefs[utxoRef(utxo)]
+	}
+	if spent {
+		return
+	}
+	for _, utxo := range inputs {
+		if utxo.Output == nil || utxo.Output.Assets() != nil {
+			continue
+		}
+		addr := utxo.Output.Address()
+		if addr.Type() != common.AddressTypeKeyKey && addr.Type() != common.AddressTypeKeyNone {
+			continue
+		}
+		amt := utxo.Output.Amount()
+		if amt == nil || !amt.IsInt64() || amt.Int64() < a.totalCollateral {
+			continue
+		}
+		for _, current := range a.collaterals {
+			if ref := utxoRef(current); !slices.Contains(a.collateralOverlapRefs, ref) {
+				delete(a.usedUtxos, ref)
+			}
+		}
+		a.collaterals = []common.Utxo{utxo}
+		a.collateralOverlapRefs = []string{utxoRef(utxo)}
+		a.collateralReturn = nil
+		if remainder := amt.Int64() - a.totalCollateral; remainder > 0 {
+			ret := NewBabbageOutput(a.getChangeAddress(), Value{Coin: uint64(remainder)}, nil, nil) //nolint:gosec // remainder > 0
+			a.collateralReturn = &ret
+		}
+		return
+	}
+}
+
 // restoreCollateralReservation reverses releaseCollateralForOverlap: it re-marks
-// the released collateral UTxO as used and clears the overlap flag. It is called
+// the released collateral UTxOs as used and clears the overlap refs. It is called
 // when the overlap retry still fails, so the builder is left in the same state
 // it had before the release and a subsequent Complete() is not skewed by a
 // half-applied overlap.
 func (a *Apollo) restoreCollateralReservation() {
-	if a.collateralOverlapRef == "" {
-		return
+	for _, ref := range a.collateralOverlapRefs {
+		a.markUsed(ref)
+	}
+	a.collateralOverlapRefs = nil
+}
+
+// requiredCollateral is the collateral the ledger demands for a fee:
+// ceil(fee * collateralPercent / 100).
+func requiredCollateral(fee int64, collateralPercent int) (int64, error) {
+	if collateralPercent <= 0 || fee <= 0 {
+		return 0, nil
 	}
-	a.markUsed(a.collateralOverlapRef)
-	a.collateralOverlapRef = ""
+	if fee > (math.MaxInt64-99)/int64(collateralPercent) {
+		return 0, fmt.Errorf("collateral sizing overflows: fee=%d collateralPercent=%d", fee, collateralPercent)
+	}
+	return (fee*int64(collateralPercent) + 99) / 100, nil
 }
 
 // finalizeCollateral sizes and validates the total collateral and the
@@ -2672,11 +5500,10 @@ func (a *Apollo) finalizeCollateral(fee int64) error {
 	if pp.CollateralPercent <= 0 || fee <= 0 {
 		return nil
 	}
-	if fee > (math.MaxInt64-99)/int64(pp.CollateralPercent) {
-		return fmt.Errorf("collateral sizing overflows: fee=%d collateralPercent=%d", fee, pp.CollateralPercent)
+	required, err := requiredCollateral(fee, pp.CollateralPercent)
+	if err != nil {
+		return err
 	}
-	// Ceil division: ceil(fee * percent / 100).
-	required := (fee*int64(pp.CollateralPercent) + 99) / 100
 	if required <= 0 {
 		return nil
 	}
@@ -2816,6 +5643,45 @@ func (a *Apollo) finalizeCollateral(fee int64) error {
 	return nil
 }
 
+// checkCollateralReturn verifies that an asset-carrying collateral return
+// balances the collateral inputs exactly: the inputs less the return leave
+// precisely total_collateral lovelace and no assets, and the return meets
+// min-UTxO. Anything else would forfeit tokens or invalidate the transaction
+// when a script fails.
+func (a *Apollo) checkCollateralReturn(coinsPerUtxoByte int64) error {
+	if a.collateralReturn == nil || a.collateralReturn.Assets() == nil {
+		return nil
+	}
+	inputs, err := a.sumUtxoValues(a.collaterals)
+	if err != nil {
+		return err
+	}
+	consumed, err := inputs.Sub(ValueFromMaryValue(a.collateralReturn.OutputAmount))
+	if err != nil {
+		return fmt.Errorf("collateral return exceeds the collateral inputs: %w", err)
+	}
+	if consumed.HasAssets() {
+		return errors.New("collateral return does not carry forward every asset on the collateral inputs")
+	}
+	if a.totalCollateral < 0 || consumed.Coin != uint64(a.totalCollateral) {
+		return fmt.Errorf(
+			"collateral return leaves %d lovelace consumed, but total collateral is %d",
+			consumed.Coin, a.totalCollateral,
+		)
+	}
+	minReturn, err := MinLovelacePostAlonzo(a.collateralReturn, coinsPerUtxoByte)
+	if err != nil {
+		return fmt.Errorf("failed to compute min UTxO for collateral return: %w", err)
+	}
+	if minReturn > 0 && a.collateralReturn.OutputAmount.Amount < uint64(minReturn) {
+		return fmt.Errorf(
+			"collateral return for native assets holds %d lovelace, below its %d min UTxO",
+			a.collateralReturn.OutputAmount.Amount, minReturn,
+		)
+	}
+	return nil
+}
+
 // validateCollateral checks the collateral input set against the ledger rules
 // that apollo can enforce locally: no duplicate collateral inputs and no more
 // than MaxCollateralInputs of them.
@@ -2859,8 +5725,8 @@ func (a *Apollo) validateCollateral() error {
 }
 
 // adjustForCertificateDeposits adjusts the total required value for certificate deposits.
-func (a *Apollo) adjustForCertificateDeposits(required Value, depositPerCert int64) (Value, error) {
-	adj := a.certificateDepositAdjustment(depositPerCert)
+func (a *Apollo) adjustForCertificateDeposits(required Value, params certificateDeposits) (Value, error) {
+	adj := a.certificateDepositAdjustment(params)
 	if adj > 0 {
 		deposit := NewSimpleValue(uint64(adj))
 		return required.Add(deposit)
@@ -2870,8 +5736,8 @@ func (a *Apollo) adjustForCertificateDeposits(required Value, depositPerCert int
 
 // certificateRefundValue returns the total deposit refund from deregistration certificates.
 // These refunds are implicit inputs in Cardano's balance equation.
-func (a *Apollo) certificateRefundValue(depositPerCert int64) Value {
-	adj := a.certificateDepositAdjustment(depositPerCert)
+func (a *Apollo) certificateRefundValue(params certificateDeposits) Value {
+	adj := a.certificateDepositAdjustment(params)
 	if adj < 0 {
 		return NewSimpleValue(uint64(-adj))
 	}
@@ -2880,8 +5746,15 @@ func (a *Apollo) certificateRefundValue(depositPerCert int64) Value {
 
 // certificateDepositAdjustment calculates the net deposit change from certificates.
 // Positive means deposits needed, negative means refunds.
-func (a *Apollo) certificateDepositAdjustment(depositPerCert int64) int64 {
-	var adjustment int64
+func (a *Apollo) certificateDepositAdjustment(params certificateDeposits) int64 {
+	deposits, refunds := a.certificateDepositTotals(params)
+	return deposits - refunds
+}
+
+// certificateDepositTotals sums the deposits locked and refunded by the
+// builder's certificates. A pool retirement's deposit is refunded to the
+// pool's reward account at the retirement epoch, not in this transaction.
+func (a *Apollo) certificateDepositTotals(params certificateDeposits) (deposits, refunds int64) {
 	for _, cert := range a.certificates {
 		switch cert.Type {
 		case uint(common.CertificateTypeStakeRegistration),
@@ -2889,21 +5762,362 @@ func (a *Apollo) certificateDepositAdjustment(depositPerCert int64) int64 {
 			uint(common.CertificateTypeStakeRegistrationDelegation),
 			uint(common.CertificateTypeVoteRegistrationDelegation),
 			uint(common.CertificateTypeStakeVoteRegistrationDelegation):
-			adjustment += depositPerCert
+			deposits += params.stake
 		case uint(common.CertificateTypeStakeDeregistration),
 			uint(common.CertificateTypeDeregistration):
-			adjustment -= depositPerCert
+			refunds += params.stake
+		case uint(common.CertificateTypePoolRegistration):
+			deposits += params.pool
 		case uint(common.CertificateTypeRegistrationDrep):
 			if c, ok := cert.Certificate.(*common.RegistrationDrepCertificate); ok {
-				adjustment += c.Amount
+				deposits += c.Amount
 			}
 		case uint(common.CertificateTypeDeregistrationDrep):
 			if c, ok := cert.Certificate.(*common.DeregistrationDrepCertificate); ok {
-				adjustment -= c.Amount
+				refunds += c.Amount
+			}
+		}
+	}
+	return deposits, refunds
+}
+
+// certificateDeposits holds the protocol deposits the builder's certificates
+// lock.
+type certificateDeposits struct {
+	stake int64 // per stake credential registration, from key_deposit
+	pool  int64 // per pool registration, from pool_deposit
+}
+
+// certificateDepositParams returns the stake key and pool deposits. Like
+// stakeKeyDeposit, the pool deposit is only looked up when a pool
+// registration is present, and errors fail closed.
+func (a *Apollo) certificateDepositParams() (certificateDeposits, error) {
+	stake, err := a.stakeKeyDeposit()
+	if err != nil {
+		return certificateDeposits{}, err
+	}
+	params := certificateDeposits{stake: stake}
+	if !slices.ContainsFunc(a.certificates, func(cert common.CertificateWrapper) bool {
+		return cert.Type == uint(common.CertificateTypePoolRegistration)
+	}) {
+		return params, nil
+	}
+	pp, err := a.Context.ProtocolParams()
+	if err != nil {
+		return certificateDeposits{}, fmt.Errorf("failed to get protocol params for pool deposit: %w", err)
+	}
+	params.pool, err = strconv.ParseInt(pp.PoolDeposits, 10, 64)
+	if err != nil || params.pool < 0 {
+		return certificateDeposits{}, fmt.Errorf("invalid pool_deposit protocol parameter %q", pp.PoolDeposits)
+	}
+	return params, nil
+}
+
+// stakeKeyDeposit returns the per-credential stake key deposit. The backend is
+// only consulted when certificates are present, and errors fail closed: a
+// silently wrong deposit produces a value-non-conserving transaction. The
+// configured StakeDeposit is used only when the parameters omit key_deposit.
+func (a *Apollo) stakeKeyDeposit() (int64, error) {
+	if len(a.certificates) == 0 {
+		return a.config.StakeDeposit, nil
+	}
+	pp, err := a.Context.ProtocolParams()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get protocol params for certificate deposit: %w", err)
+	}
+	if pp.KeyDeposits == "" {
+		return a.config.StakeDeposit, nil
+	}
+	d, err := strconv.ParseInt(pp.KeyDeposits, 10, 64)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid key_deposit protocol parameter %q", pp.KeyDeposits)
+	}
+	return d, nil
+}
+
+// validateExUnitsBudget rejects a transaction whose redeemers together exceed
+// the per-transaction execution unit limits, which the ledger would otherwise
+// reject on submission. Limits missing from the protocol parameters are not
+// enforced.
+func (a *Apollo) validateExUnitsBudget(inputs []common.Utxo) error {
+	redeemerMap := a.buildRedeemerMap(inputs)
+	if len(redeemerMap) == 0 {
+		return nil
+	}
+	if err := a.checkRedeemerExUnitCaps(redeemerMap); err != nil {
+		return err
+	}
+	totalMem, totalSteps, err := sumRedeemerExUnits(redeemerMap)
+	if err != nil {
+		return err
+	}
+	maxMem, maxSteps, err := a.maxTxExUnits()
+	if err != nil {
+		return err
+	}
+	if maxMem > 0 && totalMem > maxMem {
+		return fmt.Errorf("transaction execution memory %d exceeds the per-transaction maximum of %d", totalMem, maxMem)
+	}
+	if maxSteps > 0 && totalSteps > maxSteps {
+		return fmt.Errorf("transaction execution steps %d exceed the per-transaction maximum of %d", totalSteps, maxSteps)
+	}
+	return nil
+}
+
+// redeemerCapKeys returns the keys of the SetMaxExUnitsPerRedeemer caps in
+// redeemer order.
+func (a *Apollo) redeemerCapKeys() []common.RedeemerKey {
+	keys := slices.Collect(maps.Keys(a.maxRedeemerExUnits))
+	slices.SortFunc(keys, func(x, y common.RedeemerKey) int {
+		if x.Tag != y.Tag {
+			return cmp.Compare(x.Tag, y.Tag)
+		}
+		return cmp.Compare(x.Index, y.Index)
+	})
+	return keys
+}
+
+// checkRedeemerExUnitCaps rejects redeemers whose execution units exceed the
+// caps set with SetMaxExUnitsPerRedeemer.
+func (a *Apollo) checkRedeemerExUnitCaps(redeemers map[common.RedeemerKey]common.RedeemerValue) error {
+	for _, key := range a.redeemerCapKeys() {
+		rv, ok := redeemers[key]
+		if !ok {
+			continue
+		}
+		limit := a.maxRedeemerExUnits[key]
+		if rv.ExUnits.Memory > limit.Memory || rv.ExUnits.Steps > limit.Steps {
+			return fmt.Errorf(
+				"%s redeemer %d exceeds its execution unit cap: evaluated mem=%d steps=%d, cap mem=%d steps=%d",
+				redeemerTagName(key.Tag), key.Index, rv.ExUnits.Memory, rv.ExUnits.Steps, limit.Memory, limit.Steps,
+			)
+		}
+	}
+	return nil
+}
+
+// redeemerTagName names a redeemer tag for error messages.
+func redeemerTagName(tag common.RedeemerTag) string {
+	switch tag {
+	case common.RedeemerTagSpend:
+		return "spend"
+	case common.RedeemerTagMint:
+		return "mint"
+	case common.RedeemerTagCert:
+		return "cert"
+	case common.RedeemerTagReward:
+		return "reward"
+	case common.RedeemerTagVoting:
+		return "voting"
+	case common.RedeemerTagProposing:
+		return "proposing"
+	default:
+		return fmt.Sprintf("tag %d", tag)
+	}
+}
+
+// sumRedeemerExUnits totals the execution units of redeemers.
+func sumRedeemerExUnits(redeemers map[common.RedeemerKey]common.RedeemerValue) (totalMem, totalSteps int64, err error) {
+	for _, rv := range redeemers {
+		if rv.ExUnits.Memory < 0 || rv.ExUnits.Steps < 0 {
+			return 0, 0, fmt.Errorf("negative execution units: mem=%d steps=%d", rv.ExUnits.Memory, rv.ExUnits.Steps)
+		}
+		if rv.ExUnits.Memory > math.MaxInt64-totalMem || rv.ExUnits.Steps > math.MaxInt64-totalSteps {
+			return 0, 0, errors.New("total execution units overflow int64")
+		}
+		totalMem += rv.ExUnits.Memory
+		totalSteps += rv.ExUnits.Steps
+	}
+	return totalMem, totalSteps, nil
+}
+
+// maxTxExUnits returns the per-transaction execution unit limits from the
+// protocol parameters. A limit the parameters do not report is returned as 0.
+func (a *Apollo) maxTxExUnits() (maxMem, maxSteps int64, err error) {
+	pp, err := a.Context.ProtocolParams()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get protocol params for execution unit limits: %w", err)
+	}
+	if pp.MaxTxExMem != "" {
+		if maxMem, err = strconv.ParseInt(pp.MaxTxExMem, 10, 64); err != nil || maxMem < 0 {
+			return 0, 0, fmt.Errorf("invalid max_tx_ex_mem protocol parameter %q", pp.MaxTxExMem)
+		}
+	}
+	if pp.MaxTxExSteps != "" {
+		if maxSteps, err = strconv.ParseInt(pp.MaxTxExSteps, 10, 64); err != nil || maxSteps < 0 {
+			return 0, 0, fmt.Errorf("invalid max_tx_ex_steps protocol parameter %q", pp.MaxTxExSteps)
+		}
+	}
+	return maxMem, maxSteps, nil
+}
+
+// validateWalletlessBuild checks that a builder without a wallet still knows
+// where to send change and which UTxOs to spend. Such transactions are built
+// unsigned and must be signed externally.
+func (a *Apollo) validateWalletlessBuild() error {
+	if a.wallet != nil {
+		return nil
+	}
+	if a.changeAddress == nil {
+		return errors.New("wallet or change address is required to complete transaction")
+	}
+	if len(a.preselectedUtxos) == 0 && len(a.inputAddresses) == 0 && len(a.utxos) == 0 {
+		return errors.New("inputs or input addresses are required to complete transaction without a wallet")
+	}
+	return nil
+}
+
+// resolveInputDatums ensures that every script input spent with a redeemer
+// whose output carries only a datum hash has the matching datum in the
+// witness set, as the ledger requires. Datums not attached with AddDatum are
+// fetched from the chain context when it implements backend.DatumResolver.
+func (a *Apollo) resolveInputDatums() error {
+	var attached map[common.Blake2b256]bool
+	for _, utxo := range a.preselectedUtxos {
+		ref := utxoRef(utxo)
+		if _, ok := a.redeemers[ref]; !ok || utxo.Output == nil {
+			continue
+		}
+		if utxo.Output.Datum() != nil {
+			continue
+		}
+		want := utxo.Output.DatumHash()
+		if want == nil {
+			continue
+		}
+		if attached == nil {
+			attached = make(map[common.Blake2b256]bool, len(a.datums))
+			for i := range a.datums {
+				hash, err := hashDatum(&a.datums[i])
+				if err != nil {
+					return err
+				}
+				attached[hash] = true
+			}
+		}
+		if attached[*want] {
+			continue
+		}
+		resolver, ok := a.Context.(backend.DatumResolver)
+		if !ok {
+			return fmt.Errorf(
+				"missing datum for input %s: expected datum hash %s: chain context cannot resolve datums: %w",
+				ref, want.String(), backend.ErrUnsupported,
+			)
+		}
+		datum, err := resolver.DatumByHash(*want)
+		if err != nil {
+			return fmt.Errorf("missing datum for input %s: expected datum hash %s: %w", ref, want.String(), err)
+		}
+		if datum == nil {
+			return fmt.Errorf("missing datum for input %s: expected datum hash %s", ref, want.String())
+		}
+		hash, err := hashDatum(datum)
+		if err != nil {
+			return err
+		}
+		if hash != *want {
+			return fmt.Errorf("resolved datum for input %s hashes to %s, expected %s", ref, hash.String(), want.String())
+		}
+		a.datums = append(a.datums, *datum)
+		attached[hash] = true
+	}
+	return nil
+}
+
+func hashDatum(datum *common.Datum) (common.Blake2b256, error) {
+	datumCbor, err := cbor.Encode(datum)
+	if err != nil {
+		return common.Blake2b256{}, fmt.Errorf("failed to encode datum: %w", err)
+	}
+	return common.Blake2b256Hash(datumCbor), nil
+}
+
+// resolveInputScripts makes the validator of every script input spent with a
+// redeemer available to the transaction when ResolveScripts is enabled.
+func (a *Apollo) resolveInputScripts() error {
+	if !a.resolveScripts {
+		return nil
+	}
+	for _, utxo := range a.preselectedUtxos {
+		ref := utxoRef(utxo)
+		if _, ok := a.redeemers[ref]; !ok || utxo.Output == nil {
+			continue
+		}
+		addr := utxo.Output.Address()
+		switch addr.Type() {
+		case common.AddressTypeScriptKey, common.AddressTypeScriptScript,
+			common.AddressTypeScriptPointer, common.AddressTypeScriptNone:
+		default:
+			continue
+		}
+		scriptHash := addr.PaymentKeyHash()
+		if a.HasScript(scriptHash) {
+			continue
+		}
+		if err := a.resolveScript(scriptHash, addr); err != nil {
+			return fmt.Errorf("failed to resolve script %s for input %s: %w", scriptHash, ref, err)
+		}
+	}
+	return nil
+}
+
+// resolveScript makes the script with the given hash available, preferring a
+// UTxO at addr that carries it as a reference script over attaching it.
+func (a *Apollo) resolveScript(scriptHash common.Blake2b224, addr common.Address) error {
+	if backend.Supports(a.Context, backend.CapabilityUtxos) {
+		holders, err := backend.UtxosWithScriptRef(a.Context, addr)
+		if err != nil {
+			return err
+		}
+		for _, holder := range holders {
+			if script := holder.Output.ScriptRef(); script != nil && script.Hash() == scriptHash {
+				a.referenceInputs = append(a.referenceInputs, shelley.ShelleyTransactionInput{
+					TxId:        holder.Id.Id(),
+					OutputIndex: holder.Id.Index(),
+				})
+				return nil
 			}
 		}
 	}
-	return adjustment
+	if !backend.Supports(a.Context, backend.CapabilityScriptCbor) {
+		return errors.New("no reference script found and the chain context cannot fetch scripts")
+	}
+	scriptCbor, err := a.Context.ScriptCbor(scriptHash)
+	if err != nil {
+		return err
+	}
+	scriptRef, err := backend.ScriptRefFromHash(scriptHash, scriptCbor)
+	if err != nil {
+		return err
+	}
+	a.AttachScript(scriptRef.Script)
+	return a.err
+}
+
+// validateValidityInterval rejects negative slots and intervals that can
+// never be valid. A zero start or end means that bound is unset.
+func validateValidityInterval(start, end int64) error {
+	if start < 0 {
+		return fmt.Errorf("validity start must be non-negative, got %d", start)
+	}
+	if end < 0 {
+		return fmt.Errorf("ttl must be non-negative, got %d", end)
+	}
+	if start > 0 && end > 0 && start >= end {
+		return fmt.Errorf("invalid validity interval: start slot %d must be before ttl %d", start, end)
+	}
+	return nil
+}
+
+// addressNetworkId returns the network ID encoded in a Shelley address header.
+// Byron and malformed addresses report false.
+func addressNetworkId(addr common.Address) (uint8, bool) {
+	addrBytes, err := addr.Bytes()
+	if err != nil || len(addrBytes) == 0 || addr.Type() == common.AddressTypeByron {
+		return 0, false
+	}
+	return addrBytes[0] & 0x0f, true
 }
 
 func (a *Apollo) setErrOnce(err error) {
@@ -2955,6 +6169,64 @@ func cloneGovAnchor(anchor *common.GovAnchor) *common.GovAnchor {
 	return &cp
 }
 
+// voterKey identifies a voter in voteRedeemers.
+func voterKey(voter common.Voter) string {
+	return fmt.Sprintf("%d:%s", voter.Type, hex.EncodeToString(voter.Hash[:]))
+}
+
+// voterRank orders voter types as the ledger does: committee, DRep, then pool
+// voters, with script credentials before key credentials.
+func voterRank(voterType uint8) int {
+	switch voterType {
+	case common.VoterTypeConstitutionalCommitteeHotScriptHash:
+		return 0
+	case common.VoterTypeConstitutionalCommitteeHotKeyHash:
+		return 1
+	case common.VoterTypeDRepScriptHash:
+		return 2
+	case common.VoterTypeDRepKeyHash:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// sortedVoters returns the voters of the transaction in ledger order, the
+// order voting redeemer indexes refer to.
+func (a *Apollo) sortedVoters() []common.Voter {
+	voters := make([]common.Voter, 0, len(a.votingProcedures))
+	for voter := range a.votingProcedures {
+		if voter != nil {
+			voters = append(voters, *voter)
+		}
+	}
+	slices.SortFunc(voters, func(x, y common.Voter) int {
+		if c := cmp.Compare(voterRank(x.Type), voterRank(y.Type)); c != 0 {
+			return c
+		}
+		return bytes.Compare(x.Hash[:], y.Hash[:])
+	})
+	return voters
+}
+
+// voterKeyWitnesses returns the key hashes of key voters, which must sign
+// the transaction.
+func (a *Apollo) voterKeyWitnesses() map[common.Blake2b224]struct{} {
+	required := make(map[common.Blake2b224]struct{})
+	for voter := range a.votingProcedures {
+		if voter == nil {
+			continue
+		}
+		switch voter.Type {
+		case common.VoterTypeConstitutionalCommitteeHotKeyHash,
+			common.VoterTypeDRepKeyHash,
+			common.VoterTypeStakingPoolKeyHash:
+			required[common.Blake2b224(voter.Hash)] = struct{}{}
+		}
+	}
+	return required
+}
+
 func findVotingProcedureVoter(votes common.VotingProcedures, voter common.Voter) *common.Voter {
 	for existing := range votes {
 		if existing.Type == voter.Type && existing.Hash == voter.Hash {
@@ -3031,9 +6303,9 @@ func (a *Apollo) buildMetadata() (*common.MetaMap, error) {
 	for _, k := range keys {
 		v := a.auxiliaryData.metadata[k]
 		key := common.MetaInt{Value: new(big.Int).SetUint64(k)}
-		val, err := toMetadatum(v)
-		if err != nil {
-			return nil, fmt.Errorf("metadata key %d: %w", k, err)
+		val, errs := convertMetadatum(v, fmt.Sprintf("metadata key %d", k))
+		if len(errs) > 0 {
+			return nil, errs[0]
 		}
 		pairs = append(pairs, common.MetaPair{Key: key, Value: val})
 	}
@@ -3043,14 +6315,54 @@ func (a *Apollo) buildMetadata() (*common.MetaMap, error) {
 // toMetadatum converts a Go value to a TransactionMetadatum.
 // Supports scalars (string, int, int64, uint64, []byte), nested maps, and lists.
 func toMetadatum(v any) (common.TransactionMetadatum, error) {
+	md, errs := convertMetadatum(v, "metadata")
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return md, nil
+}
+
+// convertMetadatum converts v like toMetadatum but keeps going after a
+// problem, returning every issue found beneath path. ValidateMetadata reports
+// them all; the converted value is only usable when there are none.
+func convertMetadatum(v any, path string) (common.TransactionMetadatum, []error) {
+	var errs []error
 	switch tv := v.(type) {
+	case common.MetaText:
+		if err := validateMetadataText(tv.Value, path); err != nil {
+			errs = append(errs, err)
+		}
+		return tv, errs
+	case common.MetaBytes:
+		if err := validateMetadataBytes(tv.Value, path); err != nil {
+			errs = append(errs, err)
+		}
+		return tv, errs
+	case common.MetaInt:
+		if _, err := metadataInteger(tv.Value, path); err != nil {
+			errs = append(errs, err)
+		}
+		return tv, errs
+	case common.MetaList:
+		for i, item := range tv.Items {
+			_, itemErrs := convertMetadatum(item, fmt.Sprintf("%s[%d]", path, i))
+			errs = append(errs, itemErrs...)
+		}
+		return tv, errs
+	case common.MetaMap:
+		for i, pair := range tv.Pairs {
+			_, keyErrs := convertMetadatum(pair.Key, fmt.Sprintf("%s map entry %d key", path, i))
+			_, valErrs := convertMetadatum(pair.Value, fmt.Sprintf("%s map entry %d value", path, i))
+			errs = append(append(errs, keyErrs...), valErrs...)
+		}
+		return tv, errs
 	case common.TransactionMetadatum:
 		return tv, nil
 	case string:
-		if err := validateMetadataText(tv, "metadata"); err != nil {
-			return nil, err
+		if err := validateMetadataText(tv, path); err != nil {
+			errs = append(errs, err)
 		}
-		return common.MetaText{Value: tv}, nil
+		return common.MetaText{Value: tv}, errs
 	case int:
 		return common.MetaInt{Value: big.NewInt(int64(tv))}, nil
 	case int64:
@@ -3058,87 +6370,77 @@ func toMetadatum(v any) (common.TransactionMetadatum, error) {
 	case uint64:
 		return common.MetaInt{Value: new(big.Int).SetUint64(tv)}, nil
 	case *big.Int:
-		if tv == nil {
-			return nil, errors.New("nil metadata integer")
-		}
-		if tv.Cmp(minMetadataInteger) < 0 || tv.Cmp(maxMetadataInteger) > 0 {
-			return nil, fmt.Errorf("metadata integer %s is outside the supported range", tv.String())
+		value, err := metadataInteger(tv, path)
+		if err != nil {
+			return nil, []error{err}
 		}
-		return common.MetaInt{Value: new(big.Int).Set(tv)}, nil
+		return common.MetaInt{Value: value}, nil
 	case big.Int:
-		if tv.Cmp(minMetadataInteger) < 0 || tv.Cmp(maxMetadataInteger) > 0 {
-			return nil, fmt.Errorf("metadata integer %s is outside the supported range", tv.String())
+		value, err := metadataInteger(&tv, path)
+		if err != nil {
+			return nil, []error{err}
 		}
-		return common.MetaInt{Value: new(big.Int).Set(&tv)}, nil
+		return common.MetaInt{Value: value}, nil
 	case []byte:
-		if err := validateMetadataBytes(tv, "metadata"); err != nil {
-			return nil, err
+		if err := validateMetadataBytes(tv, path); err != nil {
+			errs = append(errs, err)
 		}
-		return common.MetaBytes{Value: tv}, nil
+		return common.MetaBytes{Value: tv}, errs
 	case MetadataMap:
 		pairs := make([]common.MetaPair, 0, len(tv))
-		for idx, entry := range tv {
-			key, err := toMetadatum(entry.Key)
-			if err != nil {
-				return nil, fmt.Errorf("map entry %d key: %w", idx, err)
-			}
-			val, err := toMetadatum(entry.Value)
-			if err != nil {
-				return nil, fmt.Errorf("map entry %d value: %w", idx, err)
-			}
+		for i, entry := range tv {
+			key, keyErrs := convertMetadatum(entry.Key, fmt.Sprintf("%s map entry %d key", path, i))
+			val, valErrs := convertMetadatum(entry.Value, fmt.Sprintf("%s map entry %d value", path, i))
+			errs = append(append(errs, keyErrs...), valErrs...)
 			pairs = append(pairs, common.MetaPair{Key: key, Value: val})
 		}
-		return common.MetaMap{Pairs: pairs}, nil
+		return common.MetaMap{Pairs: pairs}, errs
 	case map[string]any:
-		sortedKeys := make([]string, 0, len(tv))
-		for mk := range tv {
-			sortedKeys = append(sortedKeys, mk)
-		}
-		sort.Strings(sortedKeys)
 		pairs := make([]common.MetaPair, 0, len(tv))
-		for _, mk := range sortedKeys {
-			if err := validateMetadataText(mk, "metadata map key"); err != nil {
-				return nil, err
-			}
-			val, err := toMetadatum(tv[mk])
-			if err != nil {
-				return nil, fmt.Errorf("map key %q: %w", mk, err)
+		for _, mk := range slices.Sorted(maps.Keys(tv)) {
+			keyPath := fmt.Sprintf("%s.%q", path, mk)
+			if err := validateMetadataText(mk, keyPath+" key"); err != nil {
+				errs = append(errs, err)
 			}
+			val, valErrs := convertMetadatum(tv[mk], keyPath)
+			errs = append(errs, valErrs...)
 			pairs = append(pairs, common.MetaPair{
 				Key:   common.MetaText{Value: mk},
 				Value: val,
 			})
 		}
-		return common.MetaMap{Pairs: pairs}, nil
+		return common.MetaMap{Pairs: pairs}, errs
 	case map[uint64]any:
-		sortedKeys := make([]uint64, 0, len(tv))
-		for mk := range tv {
-			sortedKeys = append(sortedKeys, mk)
-		}
-		slices.Sort(sortedKeys)
 		pairs := make([]common.MetaPair, 0, len(tv))
-		for _, mk := range sortedKeys {
-			val, err := toMetadatum(tv[mk])
-			if err != nil {
-				return nil, fmt.Errorf("map key %d: %w", mk, err)
-			}
+		for _, mk := range slices.Sorted(maps.Keys(tv)) {
+			val, valErrs := convertMetadatum(tv[mk], fmt.Sprintf("%s.%d", path, mk))
+			errs = append(errs, valErrs...)
 			pairs = append(pairs, common.MetaPair{
 				Key:   common.MetaInt{Value: new(big.Int).SetUint64(mk)},
 				Value: val,
 			})
 		}
-		return common.MetaMap{Pairs: pairs}, nil
+		return common.MetaMap{Pairs: pairs}, errs
 	case []any:
 		items := make([]common.TransactionMetadatum, 0, len(tv))
 		for i, item := range tv {
-			m, err := toMetadatum(item)
-			if err != nil {
-				return nil, fmt.Errorf("list index %d: %w", i, err)
-			}
+			m, itemErrs := convertMetadatum(item, fmt.Sprintf("%s[%d]", path, i))
+			errs = append(errs, itemErrs...)
 			items = append(items, m)
 		}
-		return common.MetaList{Items: items}, nil
+		return common.MetaList{Items: items}, errs
 	default:
-		return nil, fmt.Errorf("unsupported metadata value type %T", v)
+		return nil, []error{fmt.Errorf("%s: unsupported metadata value type %T", path, v)}
+	}
+}
+
+// metadataInteger returns a copy of value if it fits a metadata integer.
+func metadataInteger(value *big.Int, path string) (*big.Int, error) {
+	if value == nil {
+		return nil, fmt.Errorf("%s: nil metadata integer", path)
+	}
+	if value.Cmp(minMetadataInteger) < 0 || value.Cmp(maxMetadataInteger) > 0 {
+		return nil, fmt.Errorf("%s: metadata integer %s is outside the supported range", path, value.String())
 	}
+	return new(big.Int).Set(value), nil
 }
@@ -2,21 +2,29 @@ package apollo
 
 import (
 	"bytes"
+	"context"
 	"crypto/ed25519"
 	"encoding/hex"
+	"errors"
+	"log/slog"
 	"math/big"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/blinklabs-io/bursa/bip32"
 	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
 	"github.com/blinklabs-io/gouroboros/ledger/mary"
 	"github.com/blinklabs-io/gouroboros/ledger/shelley"
 	plutigoData "github.com/blinklabs-io/plutigo/data"
 
 	"github.com/Salvionied/apollo/v2/backend"
+	"github.com/Salvionied/apollo/v2/backend/cache"
 	"github.com/Salvionied/apollo/v2/backend/fixed"
 )
 
@@ -118,6 +126,84 @@ func TestApolloChaining(t *testing.T) {
 	}
 }
 
+func TestSetValidityInterval(t *testing.T) {
+	a := New(setupFixedContext()).SetValidityInterval(500, 1000)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.ValidityStart != 500 || a.Ttl != 1000 {
+		t.Errorf("expected interval [500, 1000), got [%d, %d)", a.ValidityStart, a.Ttl)
+	}
+
+	a = New(setupFixedContext()).SetValidityInterval(1000, 1000)
+	if a.err == nil {
+		t.Error("expected error for empty validity interval")
+	}
+	if a.ValidityStart != 0 || a.Ttl != 0 {
+		t.Error("expected rejected interval not to be applied")
+	}
+}
+
+// tipContextSystemStart is the system start tipContext reports.
+const tipContextSystemStart = 1_600_000_000
+
+// tipContext reports a fixed chain tip on top of the fixed test context, on a
+// network without a well-known slot config and with the given slot length.
+type tipContext struct {
+	*fixed.FixedChainContext
+	tip        uint64
+	slotLength int
+}
+
+func (c *tipContext) Capabilities() backend.CapabilitySet {
+	return c.FixedChainContext.Capabilities() | backend.CapabilitySet(backend.CapabilityTip)
+}
+
+func (c *tipContext) Tip() (uint64, error) { return c.tip, nil }
+
+func (c *tipContext) GenesisParams() (backend.GenesisParameters, error) {
+	gp, err := c.FixedChainContext.GenesisParams()
+	gp.NetworkMagic = 42
+	gp.SystemStart = tipContextSystemStart
+	gp.SlotLength = c.slotLength
+	return gp, err
+}
+
+func TestSetDefaultValidityInterval(t *testing.T) {
+	cc := &tipContext{FixedChainContext: setupFixedContext(), tip: 1_000, slotLength: 2}
+	a, err := New(cc).SetDefaultValidityInterval(600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ValidityStart != 1_000 || a.Ttl != 1_300 {
+		t.Fatalf("expected interval [1000, 1300), got [%d, %d)", a.ValidityStart, a.Ttl)
+	}
+
+	cc.slotLength = 0
+	if _, err := New(cc).SetDefaultValidityInterval(600); err == nil {
+		t.Error("expected error without a genesis slot length")
+	}
+	if _, err := New(setupFixedContext()).SetDefaultValidityInterval(600); err == nil {
+		t.Error("expected error for a context without tip support")
+	}
+}
+
+func TestCompleteRejectsImpossibleValidityInterval(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	_, err := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		PayToAddress(addr, 2_000_000).
+		SetTtl(1000).
+		SetValidityStart(2000).
+		Complete()
+	if err == nil || !strings.Contains(err.Error(), "invalid validity interval") {
+		t.Fatalf("expected invalid validity interval error, got %v", err)
+	}
+}
+
 func TestCompleteRequiresWallet(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -127,6 +213,32 @@ func TestCompleteRequiresWallet(t *testing.T) {
 	}
 }
 
+func TestCompleteWithoutWallet(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	if _, err := New(cc).SetChangeAddress(addr).PayToAddress(addr, 2_000_000).Complete(); err == nil {
+		t.Fatal("expected error without inputs or input addresses")
+	}
+
+	a, err := New(cc).
+		AddInputAddress(addr).
+		SetChangeAddress(addr).
+		PayToAddress(addr, 2_000_000).
+		SetTtl(50000000).
+		Complete()
+	if err != nil {
+		t.Fatalf("expected walletless build to succeed, got %v", err)
+	}
+	if len(a.GetTx().Body.TxOutputs) != 2 {
+		t.Fatalf("expected payment and change outputs, got %d", len(a.GetTx().Body.TxOutputs))
+	}
+	if _, err := a.Sign(); err == nil {
+		t.Error("expected Sign to fail without a wallet")
+	}
+}
+
 func TestSignRequiresTransaction(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -147,6 +259,170 @@ func TestGetTxCborRequiresTransaction(t *testing.T) {
 	}
 }
 
+// submitRecorder records transactions passed to SubmitTx.
+type submitRecorder struct {
+	*fixed.FixedChainContext
+	submitted [][]byte
+}
+
+func (c *submitRecorder) SubmitTx(txCbor []byte) (common.Blake2b256, error) {
+	c.submitted = append(c.submitted, txCbor)
+	return common.Blake2b256Hash(txCbor), nil
+}
+
+func TestSubmitCbor(t *testing.T) {
+	cc := &submitRecorder{FixedChainContext: setupFixedContext()}
+	txCbor := []byte{0x84, 0xa0, 0xa0, 0xf5, 0xf6}
+
+	hash, err := New(cc).SubmitCbor(txCbor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.submitted) != 1 || !bytes.Equal(cc.submitted[0], txCbor) {
+		t.Fatal("expected CBOR to be submitted unchanged")
+	}
+	if hash != common.Blake2b256Hash(txCbor) {
+		t.Error("expected hash returned by the context")
+	}
+
+	if _, err := New(cc).SubmitCbor(nil); err == nil {
+		t.Error("expected error for empty CBOR")
+	}
+}
+
+func TestPrepareSubmit(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := &submitRecorder{FixedChainContext: setupFixedContext()}
+	addTestUtxo(cc.FixedChainContext, w.Address(), 10_000_000, 0x01, 0)
+
+	a := New(cc).SetWallet(w).PayToAddress(testAddress(t), 2_000_000).SetTtl(50000000)
+	if _, _, err := a.PrepareSubmit(); err == nil {
+		t.Error("expected error before the transaction is built")
+	}
+	a, err = a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.PrepareSubmit(); err == nil {
+		t.Error("expected error for an unsigned transaction")
+	}
+	a, err = a.Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txId, txCbor, err := a.PrepareSubmit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.submitted) != 0 {
+		t.Error("expected nothing to be submitted")
+	}
+	expected, err := a.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(txCbor, expected) {
+		t.Error("expected the signed transaction CBOR")
+	}
+	if txId != a.GetTx().Id() {
+		t.Errorf("expected tx id %s, got %s", a.GetTx().Id(), txId)
+	}
+}
+
+// confirmingContext reports a submitted transaction as confirmed by one more
+// block on each poll, after a first poll that finds it pending.
+type confirmingContext struct {
+	submitRecorder
+	polls int
+}
+
+func (c *confirmingContext) TxConfirmations(_ common.Blake2b256) (*backend.TxConfirmation, error) {
+	c.polls++
+	if c.polls == 1 {
+		return nil, nil
+	}
+	return &backend.TxConfirmation{BlockHeight: 100, Slot: 5000, Confirmations: uint64(c.polls - 1)}, nil
+}
+
+func TestSubmitAndWait(t *testing.T) {
+	interval := confirmationPollInterval
+	confirmationPollInterval = time.Millisecond
+	t.Cleanup(func() { confirmationPollInterval = interval })
+
+	cc := &confirmingContext{submitRecorder: submitRecorder{FixedChainContext: setupFixedContext()}}
+	addr := testAddress(t)
+	addTestUtxo(cc.FixedChainContext, addr, 10_000_000, 0x01, 0)
+	a, err := New(cc).SetWallet(NewExternalWallet(addr)).PayToAddress(addr, 2_000_000).SetTtl(50000000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf, err := a.SubmitAndWait(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.submitted) != 1 || cc.polls != 3 || conf.Confirmations != 2 || conf.BlockHeight != 100 || conf.Slot != 5000 {
+		t.Fatalf("unexpected confirmation %+v after %d polls", conf, cc.polls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.SubmitAndWait(ctx, 1000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to end the wait, got %v", err)
+	}
+
+	if _, err := New(setupFixedContext()).SubmitAndWait(context.Background(), 1); !errors.Is(err, backend.ErrUnsupported) {
+		t.Errorf("expected an unsupported error, got %v", err)
+	}
+
+	// The cache only learns on the first poll that its wrapped context
+	// cannot report confirmations; the wait must end there.
+	recorder := &submitRecorder{FixedChainContext: setupFixedContext()}
+	addTestUtxo(recorder.FixedChainContext, addr, 10_000_000, 0x01, 0)
+	a, err = New(cache.NewCachedChainContext(recorder, time.Minute)).SetWallet(NewExternalWallet(addr)).PayToAddress(addr, 2_000_000).SetTtl(50000000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SubmitAndWait(context.Background(), 1); !errors.Is(err, backend.ErrUnsupported) || len(recorder.submitted) != 1 {
+		t.Errorf("expected an unsupported error after one submission, got %v", err)
+	}
+}
+
+// bindingContext records the context the builder binds it to.
+type bindingContext struct {
+	*fixed.FixedChainContext
+	bound context.Context
+}
+
+func (c *bindingContext) WithContext(ctx context.Context) backend.ChainContext {
+	return &bindingContext{FixedChainContext: c.FixedChainContext, bound: ctx}
+}
+
+func TestWithContext(t *testing.T) {
+	cc := &bindingContext{FixedChainContext: setupFixedContext()}
+	addr := testAddress(t)
+	addTestUtxo(cc.FixedChainContext, addr, 10_000_000, 0x01, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a := New(cc).WithContext(ctx).SetWallet(NewExternalWallet(addr)).PayToAddress(addr, 2_000_000)
+	bound, ok := a.Context.(*bindingContext)
+	if !ok || bound.bound != ctx {
+		t.Fatal("expected the chain context to be bound to the builder's context")
+	}
+	cancel()
+	if _, err := a.Complete(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Complete to fail with the cancelled context, got %v", err)
+	}
+
+	plain := setupFixedContext()
+	if New(plain).WithContext(context.Background()).Context != backend.ChainContext(plain) {
+		t.Error("expected a context without binding support to be kept")
+	}
+}
+
 func TestAddPayment(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -160,6 +436,82 @@ func TestAddPayment(t *testing.T) {
 	}
 }
 
+func TestAddPaymentsFromRecords(t *testing.T) {
+	cc := setupFixedContext()
+	policy := testPolicyId(1).String()
+	records := []PaymentRecord{
+		{Address: validTestAddrBech32, Lovelace: 2_000_000},
+		{Address: validTestAddrBech32, Lovelace: 2_000_000, Units: []Unit{NewUnit(policy, "746f6b656e", 5)}},
+	}
+	a, err := New(cc).AddPaymentsFromRecords(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.payments) != 2 || len(a.payments[1].(*Payment).Units) != 1 {
+		t.Fatalf("expected 2 payments with the asset on the second, got %v", a.payments)
+	}
+
+	bad := []PaymentRecord{
+		records[0],
+		records[1],
+		{Address: "not-an-address", Lovelace: 2_000_000},
+		{Address: validTestAddrBech32, Lovelace: -1, Units: []Unit{NewUnit("zz", "00", 1)}},
+	}
+	b, err := New(cc).AddPaymentsFromRecords(bad)
+	if err == nil {
+		t.Fatal("expected errors for invalid records")
+	}
+	for _, want := range []string{"record 2: invalid receiver address", "record 3: negative lovelace", "record 3: invalid policy ID hex"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if len(b.payments) != 0 {
+		t.Fatalf("expected no payments to be added, got %d", len(b.payments))
+	}
+}
+
+func TestValidatePayments(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+
+	a := New(cc).PayToAddress(addr, 2_000_000)
+	if err := a.ValidatePayments(); err != nil {
+		t.Fatalf("expected valid payments, got %v", err)
+	}
+
+	var raw [29]byte
+	raw[0] = 0x61 // enterprise address on mainnet
+	mainnetAddr, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	datum := common.Datum{}
+	a.PayToAddress(addr, -1, NewUnit("zz", "00", 1)).
+		PayToAddress(mainnetAddr, 2_000_000).
+		AddPayment(&Payment{Receiver: addr, Lovelace: 2_000_000, Datum: &datum, DatumHash: make([]byte, 32)})
+
+	err = a.ValidatePayments()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %T", err)
+	}
+	if got := len(joined.Unwrap()); got != 4 {
+		t.Fatalf("expected 4 problems, got %d: %v", got, err)
+	}
+	for _, want := range []string{"payment 1: negative lovelace", "payment 1: invalid policy ID hex", "payment 2: receiver", "payment 3: both datum and datum hash"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if a.payments[0].(*Payment).Lovelace != 2_000_000 {
+		t.Error("validation must not modify payments")
+	}
+}
+
 func TestAddLoadedUTxOs(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -184,6 +536,43 @@ func TestAddInput(t *testing.T) {
 	}
 }
 
+func TestRequireInputWithAsset(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	var first, second common.Blake2b256
+	first[0] = 0x01
+	second[0] = 0x02
+	cc.AddUtxo(addr, makeAssetTestUtxo(t, first, 0, 5_000_000, testMultiAsset(0xaa, "tok", 1)))
+	cc.AddUtxo(addr, makeAssetTestUtxo(t, second, 1, 5_000_000, testMultiAsset(0xaa, "tok", 1)))
+	policyHex := hex.EncodeToString(testPolicyId(0xaa).Bytes())
+	nameHex := hex.EncodeToString([]byte("tok"))
+
+	a := New(cc)
+	if _, err := a.RequireInputWithAsset(hex.EncodeToString(second[:]), 1, policyHex, nameHex); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.RequireInputWithAsset(hex.EncodeToString(second[:]), 1, policyHex, nameHex); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.preselectedUtxos) != 1 || a.preselectedUtxos[0].Id.Id() != second {
+		t.Fatalf("expected only %x#1 to be preselected, got %d inputs", second, len(a.preselectedUtxos))
+	}
+
+	otherName := hex.EncodeToString([]byte("other"))
+	if _, err := a.RequireInputWithAsset(hex.EncodeToString(first[:]), 0, policyHex, otherName); err == nil {
+		t.Error("expected error for a UTxO without the asset")
+	}
+	if _, err := a.RequireInputWithAsset(hex.EncodeToString(first[:]), 5, policyHex, nameHex); err == nil {
+		t.Error("expected error for an unknown UTxO")
+	}
+	if _, err := a.RequireInputWithAsset(hex.EncodeToString(first[:]), 0, "zz", nameHex); err == nil {
+		t.Error("expected error for invalid policy hex")
+	}
+	if len(a.preselectedUtxos) != 1 {
+		t.Errorf("failed requirements must not preselect inputs, got %d", len(a.preselectedUtxos))
+	}
+}
+
 func TestAddRequiredSigner(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -283,6 +672,223 @@ func TestCompleteSimpleTransfer(t *testing.T) {
 	}
 }
 
+func TestSetUTxOLoadLimitKeepsLargest(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 3_000_000, 0x01, 0)
+	addTestUtxo(cc, addr, 9_000_000, 0x02, 0)
+	addTestUtxo(cc, addr, 1_000_000, 0x03, 0)
+	addTestUtxo(cc, addr, 7_000_000, 0x04, 0)
+
+	a := New(cc).SetWallet(NewExternalWallet(addr)).SetUTxOLoadLimit(2)
+	if err := a.loadUtxos(); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.utxos) != 2 {
+		t.Fatalf("expected 2 UTxOs, got %d", len(a.utxos))
+	}
+	if a.utxos[0].Id.Id()[0] != 0x02 || a.utxos[1].Id.Id()[0] != 0x04 {
+		t.Error("expected the two largest UTxOs to be kept")
+	}
+}
+
+func TestSetAssetFloor(t *testing.T) {
+	addr := testAddress(t)
+	var txHash common.Blake2b256
+	txHash[0] = 0x31
+	utxo := makeAssetTestUtxo(t, txHash, 0, 20_000_000, testMultiAsset(1, "gov", 10))
+	policy := testPolicyId(1).String()
+	name := hex.EncodeToString([]byte("gov"))
+
+	build := func(floor int64) error {
+		_, err := New(setupFixedContext()).
+			SetWallet(NewExternalWallet(addr)).
+			AddLoadedUTxOs(utxo).
+			SetAssetFloor(policy, name, floor).
+			PayToAddress(addr, 2_000_000, NewUnit(policy, name, 4)).
+			SetTtl(50000000).
+			Complete()
+		return err
+	}
+	if err := build(6); err != nil {
+		t.Fatalf("expected floor of 6 to be honoured, got %v", err)
+	}
+	if err := build(7); err == nil || !strings.Contains(err.Error(), "asset floor") {
+		t.Fatalf("expected asset floor error, got %v", err)
+	}
+
+	a := New(setupFixedContext()).SetAssetFloor(policy, "zz", 1)
+	if a.err == nil {
+		t.Error("expected error for non-hex asset name")
+	}
+	a = New(setupFixedContext()).SetAssetFloor(policy, name, 1).SetAssetFloor(policy, name, 3)
+	if len(a.assetFloors) != 1 || a.assetFloors[0].Quantity != 3 {
+		t.Errorf("expected floor to be replaced, got %v", a.assetFloors)
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	if cfg := New(setupFixedContext()).config; cfg != DefaultBuilderConfig() {
+		t.Errorf("expected default config, got %+v", cfg)
+	}
+	a := New(setupFixedContext()).SetConfig(BuilderConfig{StakeDeposit: 1, CollateralFallback: 0})
+	if a.err == nil {
+		t.Error("expected error for a zero collateral fallback")
+	}
+
+	// The default 1 ADA shortcut is above this payment, so it is raised to
+	// the protocol minimum; a lower configured shortcut accepts it as is.
+	build := func(cfg BuilderConfig) int64 {
+		t.Helper()
+		cc := setupFixedContext()
+		addr := testAddress(t)
+		addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+		p, err := NewPayment(validTestAddrBech32, 500_000, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		built, err := New(cc).SetConfig(cfg).
+			SetWallet(NewExternalWallet(addr)).
+			AddPayment(p).
+			SetTtl(50000000).
+			Clone().
+			Complete()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return int64(built.GetTx().Body.TxOutputs[0].OutputAmount.Amount) //nolint:gosec // test amounts are small
+	}
+	if got := build(DefaultBuilderConfig()); got <= 500_000 {
+		t.Errorf("expected payment raised to min UTxO, got %d", got)
+	}
+	cfg := DefaultBuilderConfig()
+	cfg.MinLovelace = 400_000
+	if got := build(cfg); got != 500_000 {
+		t.Errorf("expected payment kept at 500000, got %d", got)
+	}
+}
+
+func TestSetUTxOLoadLimitLoadsMoreWhenShort(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 5_000_000, 0x01, 0)
+	addTestUtxo(cc, addr, 5_000_000, 0x02, 0)
+
+	a, err := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		SetUTxOLoadLimit(1).
+		PayToAddress(addr, 8_000_000).
+		SetTtl(50000000).
+		Complete()
+	if err != nil {
+		t.Fatalf("expected the held-back UTxO to be loaded, got %v", err)
+	}
+	if got := len(a.GetTx().Body.TxInputs.Items()); got != 2 {
+		t.Errorf("expected 2 inputs, got %d", got)
+	}
+}
+
+func TestSetUTxOLoadLimitStopsPaging(t *testing.T) {
+	cc := setupFixedContext()
+	cc.SetUtxoPageSize(2)
+	addr := testAddress(t)
+	for i := range 6 {
+		addTestUtxo(cc, addr, 5_000_000, byte(i+1), 0)
+	}
+
+	build := func(lovelace int64) *Apollo {
+		t.Helper()
+		a, err := New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			SetUTxOLoadLimit(2).
+			PayToAddress(addr, lovelace).
+			SetTtl(50000000).
+			Complete()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	if got := len(build(3_000_000).utxos); got != 2 {
+		t.Errorf("expected only the first page to be loaded, got %d UTxOs", got)
+	}
+	// The first page cannot cover 12 ADA, so a second page is loaded.
+	if got := len(build(12_000_000).utxos); got != 4 {
+		t.Errorf("expected two pages to be loaded, got %d UTxOs", got)
+	}
+}
+
+func TestCompleteExact(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+	addTestUtxo(cc, addr, 50_000_000, 0x02, 0)
+	utxos, err := cc.Utxos(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := func(payment int64) (*Apollo, error) {
+		return New(cc).SetWallet(NewExternalWallet(addr)).
+			AddInput(utxos[0]).
+			PayToAddress(testAddress(t), payment).
+			SetTtl(50000000).
+			CompleteExact(200_000)
+	}
+
+	a, err := build(2_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := a.GetTx().Body
+	if body.TxFee != 200_000 {
+		t.Fatalf("fee = %d, want 200000", body.TxFee)
+	}
+	if refs := bodyInputRefs(t, a); len(refs) != 1 || refs[0] != utxoRef(utxos[0]) {
+		t.Fatalf("expected only the preselected input, got %v", refs)
+	}
+	var total uint64
+	for _, out := range body.TxOutputs {
+		total += out.OutputAmount.Amount
+	}
+	if total+body.TxFee != 10_000_000 {
+		t.Fatalf("outputs %d + fee %d do not balance the input", total, body.TxFee)
+	}
+	first, err := a.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := build(2_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := again.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("expected identical transactions from identical builds")
+	}
+
+	// The unselected 50 ADA UTxO must not be pulled in to cover the payment.
+	if _, err := build(9_900_000); err == nil {
+		t.Error("expected an error when the preselected inputs fall short")
+	}
+	if _, err := New(cc).SetWallet(NewExternalWallet(addr)).CompleteExact(200_000); err == nil {
+		t.Error("expected an error without preselected inputs")
+	}
+	if _, err := New(cc).AddInput(utxos[0]).CompleteExact(-1); err == nil {
+		t.Error("expected an error for a negative fee")
+	}
+	redeemer := testRedeemerDatum()
+	_, err = New(cc).SetWallet(NewExternalWallet(addr)).
+		AddInput(utxos[0]).
+		Mint(NewUnit(strings.Repeat("ab", 28), "746f6b656e", 1), &redeemer, nil).
+		CompleteExact(200_000)
+	if err == nil || !strings.Contains(err.Error(), "AddCollateral") {
+		t.Errorf("expected script tx without preset collateral to fail, got %v", err)
+	}
+}
+
 func TestCompleteCborEncoding(t *testing.T) {
 	cc := setupFixedContext()
 	addr := testAddress(t)
@@ -488,7 +1094,172 @@ func TestCollectFrom(t *testing.T) {
 	}
 }
 
-func TestPayToContract(t *testing.T) {
+func TestCompleteRequiresDatumForDatumHashInput(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 30_000_000, 0x01, 0)
+
+	datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(42))}
+	datumCbor, err := cbor.Encode(&datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datumHash := common.Blake2b256Hash(datumCbor)
+	locked := &Payment{
+		Receiver:  scriptAddressUtxo(t, 0x09, 1).Output.Address(),
+		Lovelace:  10_000_000,
+		DatumHash: datumHash.Bytes(),
+	}
+	out, err := locked.ToTxOut()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var txHash common.Blake2b256
+	txHash[0] = 0x09
+	scriptUtxo := common.Utxo{
+		Id:     shelley.ShelleyTransactionInput{TxId: txHash, OutputIndex: 0},
+		Output: out,
+	}
+
+	build := func(attach bool) (*Apollo, error) {
+		a := New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+			DisableExecutionUnitsEstimation().
+			CollectFrom(scriptUtxo, common.Datum{Data: plutigoData.NewInteger(big.NewInt(0))}, common.ExUnits{Memory: 1, Steps: 1})
+		if attach {
+			a.AddDatum(&datum)
+		}
+		return a.Complete()
+	}
+
+	_, err = build(false)
+	if err == nil {
+		t.Fatal("expected missing datum error")
+	}
+	if !strings.Contains(err.Error(), "missing datum for input "+utxoRef(scriptUtxo)) ||
+		!strings.Contains(err.Error(), datumHash.String()) {
+		t.Fatalf("error should name the input and expected hash, got: %v", err)
+	}
+
+	if _, err := build(true); err != nil {
+		t.Fatalf("Complete with the datum attached: %v", err)
+	}
+}
+
+func TestCompleteResolvesInputDatum(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 30_000_000, 0x01, 0)
+
+	datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(7))}
+	datumCbor, err := cbor.Encode(&datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cc.AddDatum(datum); err != nil {
+		t.Fatal(err)
+	}
+	locked := &Payment{
+		Receiver:  scriptAddressUtxo(t, 0x09, 1).Output.Address(),
+		Lovelace:  10_000_000,
+		DatumHash: common.Blake2b256Hash(datumCbor).Bytes(),
+	}
+	out, err := locked.ToTxOut()
+	if err != nil {
+		t.Fatal(err)
+	}
+	redeemer := common.Datum{Data: plutigoData.NewInteger(big.NewInt(0))}
+	exUnits := common.ExUnits{Memory: 1, Steps: 1}
+	build := func(ctx backend.ChainContext) (*Apollo, error) {
+		return New(ctx).
+			SetWallet(NewExternalWallet(addr)).
+			AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+			DisableExecutionUnitsEstimation().
+			CollectFrom(common.Utxo{Id: shelley.ShelleyTransactionInput{TxId: common.Blake2b256{0x09}}, Output: out}, redeemer, exUnits).
+			CollectFrom(common.Utxo{Id: shelley.ShelleyTransactionInput{TxId: common.Blake2b256{0x0a}}, Output: out}, redeemer, exUnits).
+			Complete()
+	}
+	if _, err := build(cache.NewCachedChainContext(cc, time.Minute)); err != nil {
+		t.Fatalf("Complete through the cache: %v", err)
+	}
+	// A context that cannot resolve datums reports that instead of a bare
+	// missing datum.
+	if _, err := build(struct{ backend.ChainContext }{cc}); !errors.Is(err, backend.ErrUnsupported) {
+		t.Fatalf("expected an unsupported error, got %v", err)
+	}
+	a, err := build(cc)
+	if err != nil {
+		t.Fatalf("Complete with a resolvable datum: %v", err)
+	}
+	if len(a.datums) != 1 {
+		t.Fatalf("expected the shared datum to be attached once, got %d datums", len(a.datums))
+	}
+	resolved, err := cbor.Encode(&a.datums[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(resolved, datumCbor) {
+		t.Errorf("expected datum %x, got %x", datumCbor, resolved)
+	}
+}
+
+func TestUpdateContractState(t *testing.T) {
+	script := common.PlutusV2Script{0x01, 0x02}
+	scriptHash := script.Hash()
+	scriptAddr, err := common.NewAddressFromBytes(append([]byte{0x70}, scriptHash.Bytes()...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxo := makeAssetTestUtxo(t, common.Blake2b256{0x05}, 1, 3_000_000, testMultiAsset(1, "state", 1))
+	output, ok := utxo.Output.(*babbage.BabbageTransactionOutput)
+	if !ok {
+		t.Fatalf("unexpected output type %T", utxo.Output)
+	}
+	output.OutputAddress = scriptAddr
+	redeemer := common.Datum{Data: plutigoData.NewInteger(big.NewInt(0))}
+	newDatum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(2))}
+	exUnits := common.ExUnits{Memory: 1000, Steps: 2000}
+
+	if _, err := New(setupFixedContext()).UpdateContractState(utxo, redeemer, newDatum, exUnits); err == nil {
+		t.Error("expected error when the spending script is unavailable")
+	}
+
+	a, err := New(setupFixedContext()).AttachScript(script).UpdateContractState(utxo, redeemer, newDatum, exUnits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.preselectedUtxos) != 1 || len(a.redeemers) != 1 {
+		t.Fatalf("expected the UTxO to be collected with its redeemer, got %d inputs and %d redeemers", len(a.preselectedUtxos), len(a.redeemers))
+	}
+	if len(a.payments) != 1 {
+		t.Fatalf("expected 1 payment, got %d", len(a.payments))
+	}
+	p, ok := a.payments[0].(*Payment)
+	if !ok {
+		t.Fatalf("unexpected payment type %T", a.payments[0])
+	}
+	if p.Receiver.String() != scriptAddr.String() || p.Lovelace != 3_000_000 || len(p.Units) != 1 || p.Units[0].Quantity != 1 {
+		t.Errorf("expected the full value paid back to the script, got %+v", p)
+	}
+	want, err := cbor.Encode(&newDatum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsInline || p.Datum == nil {
+		t.Fatal("expected the new datum inline")
+	}
+	if got, err := cbor.Encode(p.Datum); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("expected datum %x, got %x (err %v)", want, got, err)
+	}
+
+	keyUtxo := makeTestUtxo(t, common.Blake2b256{0x06}, 0, 3_000_000)
+	if _, err := New(setupFixedContext()).UpdateContractState(keyUtxo, redeemer, newDatum, exUnits); err == nil {
+		t.Error("expected error for a key-locked UTxO")
+	}
+}
+
+func TestPayToContract(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
 	addr := testAddress(t)
@@ -501,6 +1272,40 @@ func TestPayToContract(t *testing.T) {
 	}
 }
 
+func TestPayToContractTyped(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	want := testTypedDatum{Owner: []byte{0x01, 0x02}, Amount: 7}
+	a, err := New(cc).SetWallet(NewExternalWallet(addr)).PayToContractTyped(addr, &want, 2_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err = a.SetTtl(50000000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := UTxOsFromBuiltTx(a.GetTx())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got testTypedDatum
+	if err := DatumFromUTxO(outputs[0], &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Amount != want.Amount || !bytes.Equal(got.Owner, want.Owner) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if _, err := New(cc).PayToContractTyped(addr, 42, 2_000_000); err == nil {
+		t.Error("expected error for a datum that is not a struct")
+	}
+	if _, err := New(cc).PayToContractTyped(addr, nil, 2_000_000); err == nil {
+		t.Error("expected error for a nil datum")
+	}
+}
+
 func TestPayToContractWithDatumHash(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -519,6 +1324,118 @@ func TestPayToContractWithDatumHash(t *testing.T) {
 	}
 }
 
+func TestPayToContractWithDatumOption(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(7))}
+
+	a, err := New(cc).PayToContractWithDatumOption(addr, &datum, DatumModeInline, 2_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := a.payments[0].(*Payment)
+	if !p.IsInline || p.Datum != &datum || p.DatumHash != nil {
+		t.Errorf("inline mode: got IsInline=%v Datum=%v DatumHash=%x", p.IsInline, p.Datum, p.DatumHash)
+	}
+	if len(a.datums) != 0 {
+		t.Errorf("inline mode should not add witness datums, got %d", len(a.datums))
+	}
+
+	a, err = New(cc).PayToContractWithDatumOption(addr, &datum, DatumModeHash, 2_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = a.payments[0].(*Payment)
+	datumCbor, err := cbor.Encode(&datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHash := common.Blake2b256Hash(datumCbor)
+	if p.IsInline || p.Datum != nil || !bytes.Equal(p.DatumHash, wantHash.Bytes()) {
+		t.Errorf("hash mode: got IsInline=%v Datum=%v DatumHash=%x", p.IsInline, p.Datum, p.DatumHash)
+	}
+	if len(a.datums) != 1 {
+		t.Errorf("hash mode should add the datum to the witness set, got %d", len(a.datums))
+	}
+
+	if _, err := New(cc).PayToContractWithDatumOption(addr, &datum, DatumMode(9), 2_000_000); err == nil {
+		t.Error("expected error for an unknown datum mode")
+	}
+}
+
+func TestCompleteMergesDuplicateMints(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 30_000_000, 0x01, 0)
+
+	policyHex := "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4"
+	redeemer := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+	exUnits := common.ExUnits{Memory: 1, Steps: 1}
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		DisableExecutionUnitsEstimation().
+		Mint(NewUnit(policyHex, "746f6b656e", 3), &redeemer, &exUnits).
+		Mint(NewUnit(policyHex, "746F6B656E", 4), &redeemer, &exUnits).
+		Mint(NewUnit(policyHex, "6f74686572", 2), &redeemer, &exUnits).
+		Mint(NewUnit(policyHex, "6f74686572", -2), &redeemer, &exUnits)
+	a, err := a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.mint) != 1 {
+		t.Fatalf("expected 1 merged mint unit, got %d", len(a.mint))
+	}
+	mint := a.GetTx().Body.TxMint
+	if mint == nil {
+		t.Fatal("expected a mint field")
+	}
+	policyId := mint.Policies()
+	if len(policyId) != 1 {
+		t.Fatalf("expected 1 mint policy, got %d", len(policyId))
+	}
+	names := mint.Assets(policyId[0])
+	if len(names) != 1 || string(names[0]) != "token" {
+		t.Fatalf("expected only the token asset, got %d assets", len(names))
+	}
+	if qty := mint.Asset(policyId[0], names[0]); qty == nil || qty.Int64() != 7 {
+		t.Fatalf("expected a summed quantity of 7, got %v", qty)
+	}
+
+	// A redeemer-bearing policy whose mints all cancel out is rejected.
+	_, err = New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		DisableExecutionUnitsEstimation().
+		Mint(NewUnit(policyHex, "746f6b656e", 3), &redeemer, &exUnits).
+		Mint(NewUnit(policyHex, "746f6b656e", -3), &redeemer, &exUnits).
+		Complete()
+	if err == nil || !strings.Contains(err.Error(), "nets to zero") {
+		t.Fatalf("expected a net-zero mint error, got %v", err)
+	}
+
+	// A lone zero mint is dropped too.
+	b := New(cc).Mint(NewUnit(policyHex, "746f6b656e", 0), nil, nil)
+	if err := b.normalizeMint(); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.mint) != 0 {
+		t.Fatalf("expected the zero mint to be dropped, got %v", b.mint)
+	}
+
+	// Policy IDs restored without Mint's normalization still merge.
+	b.mint = []Unit{
+		{PolicyId: strings.ToUpper(policyHex), Name: "746f6b656e", Quantity: 1},
+		{PolicyId: policyHex, Name: "746f6b656e", Quantity: 2},
+	}
+	if err := b.normalizeMint(); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.mint) != 1 || b.mint[0].PolicyId != policyHex || b.mint[0].Quantity != 3 {
+		t.Fatalf("expected one merged unit of policy %s, got %v", policyHex, b.mint)
+	}
+}
+
 func TestMintWithRedeemer(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -538,6 +1455,90 @@ func TestMintWithRedeemer(t *testing.T) {
 	}
 }
 
+func TestBurnAssets(t *testing.T) {
+	if a := New(setupFixedContext()).BurnAssets(NewUnit(strings.Repeat("aa", 28), "746f6b", 0), nil); a.err == nil {
+		t.Error("expected error for a zero burn quantity")
+	}
+
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 50_000_000, 0x01, 0)
+	var policyId common.Blake2b224
+	for i := range policyId {
+		policyId[i] = 0xaa
+	}
+	assets := common.NewMultiAsset[common.MultiAssetTypeOutput](
+		map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput{
+			policyId: {cbor.NewByteString([]byte("tok")): big.NewInt(8)},
+		})
+	tokenUtxo := makeAssetTestUtxo(t, common.Blake2b256{0x02}, 0, 2_000_000, &assets)
+	cc.AddUtxo(addr, tokenUtxo)
+
+	// The ADA-rich UTxO covers the payment alone; the burn must still pull
+	// in the UTxO holding the tokens.
+	a, err := New(cc).SetWallet(NewExternalWallet(addr)).
+		PayToAddress(testAddress(t), 2_000_000).
+		BurnAssets(NewUnit(strings.Repeat("aa", 28), "746f6b", 5), nil).
+		SetTtl(50000000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(bodyInputRefs(t, a), utxoRef(tokenUtxo)) {
+		t.Fatal("expected the token UTxO to be selected")
+	}
+	if qty := a.GetTx().Body.TxMint.Asset(policyId, []byte("tok")); qty == nil || qty.Int64() != -5 {
+		t.Fatalf("expected a mint of -5, got %v", qty)
+	}
+	var change *big.Int
+	for _, out := range a.GetTx().Body.TxOutputs {
+		if out.OutputAmount.Assets != nil {
+			change = out.OutputAmount.Assets.Asset(policyId, []byte("tok"))
+		}
+	}
+	if change == nil || change.Int64() != 3 {
+		t.Fatalf("expected 3 tokens in change, got %v", change)
+	}
+}
+
+func TestTypedRedeemers(t *testing.T) {
+	redeemer := testTypedDatum{Owner: []byte{0x0a}, Amount: 3}
+	expected, err := cbor.Encode(&common.Datum{Data: mustMarshalPlutus(t, &redeemer)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hash common.Blake2b256
+	hash[0] = 1
+	utxo := makeTestUtxo(t, hash, 0, 5_000_000)
+	unit := NewUnit("a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4", "746f6b656e", 1)
+
+	a, err := New(setupFixedContext()).CollectFromTyped(utxo, &redeemer, common.ExUnits{Memory: 1000, Steps: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err = a.MintTyped(unit, redeemer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []redeemerEntry{a.redeemers[utxoRef(utxo)], a.mintRedeemers[unit.PolicyId]}
+	for i, entry := range entries {
+		got, err := cbor.Encode(&entry.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, expected) {
+			t.Errorf("redeemer %d: expected %x, got %x", i, expected, got)
+		}
+	}
+
+	if _, err := New(setupFixedContext()).CollectFromTyped(utxo, "not a struct", common.ExUnits{}); err == nil {
+		t.Error("expected error for a redeemer that is not a struct")
+	}
+	if _, err := New(setupFixedContext()).MintTyped(unit, nil, nil); err == nil {
+		t.Error("expected error for a nil redeemer")
+	}
+}
+
 // --- AttachScript Tests ---
 
 func TestAttachScriptV1Dedup(t *testing.T) {
@@ -630,6 +1631,39 @@ func TestAttachScriptNativeScript(t *testing.T) {
 	}
 }
 
+func TestSetExpectedSigners(t *testing.T) {
+	cc := setupFixedContext()
+	wallet := NewExternalWallet(testAddress(t))
+	a := New(cc).SetWallet(wallet)
+	if got := a.estimatedWitnessCount(nil); got != 1 {
+		t.Fatalf("expected 1 estimated witness, got %d", got)
+	}
+	if got := a.SetExpectedSigners(3).estimatedWitnessCount(nil); got != 3 {
+		t.Errorf("expected 3 estimated witnesses, got %d", got)
+	}
+
+	keys := make([]common.NativeScript, 2)
+	for i := range keys {
+		ns, err := NewNativeScriptPubkey(common.Blake2b224{byte(0xA0 + i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = ns
+	}
+	anyOf, err := NewNativeScriptAny(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New(cc).SetWallet(wallet).AttachScript(anyOf)
+	if got := b.estimatedWitnessCount(nil); got != 3 {
+		t.Errorf("expected the wallet and both script keys to be counted, got %d", got)
+	}
+
+	if New(cc).SetExpectedSigners(-1).err == nil {
+		t.Error("expected error for a negative signer count")
+	}
+}
+
 func TestAttachScriptMixedTypes(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -644,6 +1678,193 @@ func TestAttachScriptMixedTypes(t *testing.T) {
 	}
 }
 
+func TestAttachScriptsDedup(t *testing.T) {
+	a := New(setupFixedContext())
+	v1 := common.PlutusV1Script([]byte{0x01})
+	v2 := common.PlutusV2Script([]byte{0x01})
+	v3 := common.PlutusV3Script([]byte{0x02})
+
+	a.AttachScript(v3)
+	a.AttachScripts(v1, v2, v1, v3)
+
+	if len(a.v1scripts) != 1 || len(a.v2scripts) != 1 || len(a.v3scripts) != 1 {
+		t.Fatalf("script counts = %d/%d/%d, want 1/1/1", len(a.v1scripts), len(a.v2scripts), len(a.v3scripts))
+	}
+	if len(a.scriptHashes) != 3 {
+		t.Fatalf("script hash count = %d, want 3", len(a.scriptHashes))
+	}
+}
+
+func TestAttachScriptsPropagatesError(t *testing.T) {
+	a := New(setupFixedContext())
+	a.AttachScripts(common.PlutusV1Script([]byte{0x01}), common.PlutusV4Script([]byte{0x02}))
+
+	if a.err != ErrPlutusV4RequiresDijkstra {
+		t.Fatalf("AttachScripts error = %v, want %v", a.err, ErrPlutusV4RequiresDijkstra)
+	}
+}
+
+func TestHasScript(t *testing.T) {
+	cc := setupFixedContext()
+	attached := common.PlutusV2Script([]byte{0x01, 0x02})
+	referenced := common.PlutusV3Script([]byte{0x03, 0x04})
+	missing := common.PlutusV3Script([]byte{0x05})
+
+	var refTxHash common.Blake2b256
+	refTxHash[0] = 0x77
+	refOutput := babbage.BabbageTransactionOutput{
+		OutputAddress:  testAddress(t),
+		OutputAmount:   mary.MaryTransactionOutputValue{Amount: 5_000_000},
+		TxOutScriptRef: &common.ScriptRef{Type: common.ScriptRefTypePlutusV3, Script: referenced},
+	}
+	cc.AddUtxoByRef(common.Utxo{
+		Id:     shelley.ShelleyTransactionInput{TxId: refTxHash, OutputIndex: 0},
+		Output: &refOutput,
+	})
+
+	a := New(cc).AttachScript(attached)
+	if _, err := a.AddReferenceInput(hex.EncodeToString(refTxHash.Bytes()), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.HasScript(attached.Hash()) {
+		t.Error("expected attached script to be present")
+	}
+	if !a.HasScript(referenced.Hash()) {
+		t.Error("expected reference-input script to be present")
+	}
+	if a.HasScript(missing.Hash()) {
+		t.Error("expected unknown script to be absent")
+	}
+}
+
+func TestResolveScripts(t *testing.T) {
+	script := common.PlutusV2Script([]byte{0x01, 0x02})
+	scriptHash := script.Hash()
+	scriptAddr, err := common.NewAddressFromBytes(append([]byte{0x70}, scriptHash.Bytes()...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := common.Utxo{
+		Id: shelley.ShelleyTransactionInput{TxId: common.Blake2b256{0x09}, OutputIndex: 0},
+		Output: &babbage.BabbageTransactionOutput{
+			OutputAddress: scriptAddr,
+			OutputAmount:  mary.MaryTransactionOutputValue{Amount: 5_000_000},
+		},
+	}
+	holder := common.Utxo{
+		Id: shelley.ShelleyTransactionInput{TxId: common.Blake2b256{0x0a}, OutputIndex: 1},
+		Output: &babbage.BabbageTransactionOutput{
+			OutputAddress:  scriptAddr,
+			OutputAmount:   mary.MaryTransactionOutputValue{Amount: 5_000_000},
+			TxOutScriptRef: &common.ScriptRef{Type: common.ScriptRefTypePlutusV2, Script: script},
+		},
+	}
+
+	build := func(deployed bool) (*Apollo, error) {
+		cc := setupFixedContext()
+		addr := testAddress(t)
+		addTestUtxo(cc, addr, 20_000_000, 0x01, 0)
+		if deployed {
+			cc.AddUtxo(scriptAddr, holder)
+		}
+		return New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			ResolveScripts(true).
+			DisableExecutionUnitsEstimation().
+			CollectFrom(locked, common.Datum{Data: plutigoData.NewInteger(big.NewInt(0))}, common.ExUnits{Memory: 1000, Steps: 1000}).
+			Complete()
+	}
+
+	a, err := build(true)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if len(a.referenceInputs) != 1 || a.referenceInputs[0].TxId != holder.Id.Id() || a.referenceInputs[0].OutputIndex != 1 {
+		t.Fatalf("expected the reference script UTxO as a reference input, got %v", a.referenceInputs)
+	}
+	if len(a.v2scripts) != 0 {
+		t.Errorf("expected no attached scripts, got %d", len(a.v2scripts))
+	}
+
+	if _, err := build(false); err == nil || !strings.Contains(err.Error(), "failed to resolve script "+scriptHash.String()) {
+		t.Errorf("expected an unresolvable script error, got %v", err)
+	}
+}
+
+func TestDedupReferenceScripts(t *testing.T) {
+	script := common.PlutusV2Script([]byte{0x01, 0x02})
+	var refTxHash common.Blake2b256
+	refTxHash[0] = 0x78
+
+	build := func(dedup, viaReferenceInput bool) *Apollo {
+		cc := setupFixedContext()
+		addr := testAddress(t)
+		addTestUtxo(cc, addr, 20_000_000, 0x01, 0)
+		addTestUtxo(cc, addr, 10_000_000, 0x02, 0)
+		refOutput := babbage.BabbageTransactionOutput{
+			OutputAddress:  addr,
+			OutputAmount:   mary.MaryTransactionOutputValue{Amount: 5_000_000},
+			TxOutScriptRef: &common.ScriptRef{Type: common.ScriptRefTypePlutusV2, Script: script},
+		}
+		cc.AddUtxoByRef(common.Utxo{
+			Id:     shelley.ShelleyTransactionInput{TxId: refTxHash, OutputIndex: 0},
+			Output: &refOutput,
+		})
+
+		datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+		a := New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			AttachScript(script).
+			DedupReferenceScripts(dedup).
+			DisableExecutionUnitsEstimation().
+			Mint(NewUnit(script.Hash().String(), "746f6b656e", 1), &datum, &common.ExUnits{Memory: 1000, Steps: 1000})
+		var err error
+		if viaReferenceInput {
+			a, err = a.AddReferenceInput(hex.EncodeToString(refTxHash.Bytes()), 0)
+		} else {
+			a, err = a.PayToAddressWithReferenceScript(addr, 5_000_000, script)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := a.Complete(); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+		return a
+	}
+	witnessScripts := func(a *Apollo) int {
+		return len(a.GetTx().WitnessSet.WsPlutusV2Scripts.Items())
+	}
+
+	if got := witnessScripts(build(false, true)); got != 1 {
+		t.Fatalf("expected the script in the witness set without dedup, got %d", got)
+	}
+	if got := witnessScripts(build(true, true)); got != 0 {
+		t.Fatalf("expected the reference-input script to be dropped from the witness set, got %d", got)
+	}
+	// An output's reference script is not available to the transaction that
+	// creates it, so the witness copy must stay.
+	if got := witnessScripts(build(true, false)); got != 1 {
+		t.Fatalf("expected the witness script to stay alongside an output reference script, got %d", got)
+	}
+
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 20_000_000, 0x01, 0)
+	a, err := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		DedupReferenceScripts(true).
+		PayToAddress(addr, 2_000_000).
+		AddReferenceInput(hex.EncodeToString(refTxHash.Bytes()), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Complete(); err == nil || !strings.Contains(err.Error(), "script deduplication") {
+		t.Fatalf("expected an unresolvable reference input to fail Complete, got %v", err)
+	}
+}
+
 func TestAddDatum(t *testing.T) {
 	cc := setupFixedContext()
 	a := New(cc)
@@ -755,6 +1976,59 @@ func TestAddWithdrawalConflictingRedeemerSetsBuilderError(t *testing.T) {
 	}
 }
 
+func TestWithdrawalRedeemerIndexByRewardAccount(t *testing.T) {
+	cc := setupFixedContext()
+	reward := func(script bool, b byte) common.Address {
+		t.Helper()
+		cred := common.Credential{CredType: common.CredentialTypeAddrKeyHash}
+		if script {
+			cred.CredType = common.CredentialTypeScriptHash
+		}
+		cred.Credential[0] = b
+		addr, err := RewardAddressFromCredential(cred, cc.NetworkId())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return addr
+	}
+	keyAccount := reward(false, 0x01)
+	scriptAccount := reward(true, 0x02)
+	// A key and a script credential sharing a hash are distinct accounts.
+	sharedKey := reward(false, 0x03)
+	sharedScript := reward(true, 0x03)
+	redeemer := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+
+	a := New(cc).
+		AddWithdrawal(keyAccount, 1_000_000, nil, nil).
+		AddWithdrawal(scriptAccount, 1_000_000, &redeemer, &common.ExUnits{Memory: 1, Steps: 1}).
+		AddWithdrawal(sharedKey, 1_000_000, nil, nil).
+		AddWithdrawal(sharedScript, 1_000_000, &redeemer, &common.ExUnits{Memory: 2, Steps: 2})
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+
+	// The ledger orders script reward accounts before key ones, although
+	// their headers sort after them byte-wise.
+	order := a.sortedWithdrawalKeys()
+	want := []string{scriptAccount.String(), sharedScript.String(), keyAccount.String(), sharedKey.String()}
+	if !slices.Equal(order, want) {
+		t.Fatalf("withdrawal order = %v, want %v", order, want)
+	}
+	redeemers := a.buildRedeemerMap(nil)
+	if len(redeemers) != 2 {
+		t.Fatalf("expected 2 reward redeemers, got %d", len(redeemers))
+	}
+	for idx, memory := range []int64{1, 2} {
+		got, ok := redeemers[common.RedeemerKey{Tag: common.RedeemerTagReward, Index: uint32(idx)}]
+		if !ok {
+			t.Fatalf("missing reward redeemer at index %d", idx)
+		}
+		if int64(got.ExUnits.Memory) != memory {
+			t.Errorf("reward redeemer %d has memory %d, want %d", idx, got.ExUnits.Memory, memory)
+		}
+	}
+}
+
 // --- Metadata Tests ---
 
 func TestSetShelleyMetadata(t *testing.T) {
@@ -807,6 +2081,111 @@ func TestCompleteWithMetadata(t *testing.T) {
 	}
 }
 
+func TestCompleteMetadataSizeLimits(t *testing.T) {
+	chunks := func(n int) map[uint64]any {
+		list := make([]any, n)
+		for i := range list {
+			list[i] = strings.Repeat("x", 64)
+		}
+		return map[uint64]any{674: list}
+	}
+	build := func(t *testing.T, metadata map[uint64]any, limit int) error {
+		t.Helper()
+		cc := setupFixedContext()
+		addr := testAddress(t)
+		addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+		_, err := New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			PayToAddress(testAddress(t), 2_000_000).
+			SetTtl(50000000).
+			SetShelleyMetadata(metadata).
+			SetMetadataSizeLimit(limit).
+			Complete()
+		return err
+	}
+
+	if err := build(t, chunks(5), 100); err == nil || !strings.Contains(err.Error(), "exceeds limit of 100 bytes") {
+		t.Fatalf("expected metadata limit error, got %v", err)
+	}
+	if err := build(t, chunks(5), 1000); err != nil {
+		t.Fatalf("metadata under the limit: %v", err)
+	}
+	// Metadata alone larger than the max tx size of 16384 bytes.
+	if err := build(t, chunks(300), 0); err == nil || !strings.Contains(err.Error(), "exceeds max tx size 16384") {
+		t.Fatalf("expected max tx size error for metadata, got %v", err)
+	}
+	// Metadata that fits on its own but not with the rest of the transaction.
+	err := build(t, chunks(245), 0)
+	if err == nil || !strings.Contains(err.Error(), "exceeds max tx size 16384 (metadata is") {
+		t.Fatalf("expected max tx size error for the transaction, got %v", err)
+	}
+}
+
+func TestCompactChangeOnOversize(t *testing.T) {
+	build := func(compact bool) (*Apollo, error) {
+		cc := setupFixedContext()
+		addr := testAddress(t)
+		var txHash common.Blake2b256
+		txHash[0] = 0x01
+		cc.AddUtxo(addr, makeAssetTestUtxo(t, txHash, 0, 1_000_000_000, assetGrid(150, 1)))
+		return New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			PayToAddress(addr, 2_000_000).
+			SetTtl(50000000).
+			SetChangeAssetStrategy(ChangeAssetsPerPolicy).
+			SetCompactChangeOnOversize(compact).
+			Complete()
+	}
+
+	// One change output per policy pushes the transaction past 16384 bytes.
+	_, err := build(false)
+	var tooLarge *TxTooLargeError
+	if !errors.As(err, &tooLarge) || !errors.Is(err, ErrTxTooLarge) {
+		t.Fatalf("expected TxTooLargeError, got %v", err)
+	}
+	if tooLarge.MaxSize != 16384 || tooLarge.Size <= tooLarge.MaxSize {
+		t.Errorf("unexpected sizes: %d of max %d", tooLarge.Size, tooLarge.MaxSize)
+	}
+
+	a, err := build(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(a.GetTx().Body.TxOutputs); n >= 150 {
+		t.Errorf("expected the change to be compacted, got %d outputs", n)
+	}
+}
+
+func TestSetLoggerEmitsBuildDiagnostics(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	p, err := NewPayment(validTestAddrBech32, 2_000_000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	a := New(cc).
+		SetLogger(logger).
+		SetWallet(NewExternalWallet(addr)).
+		AddPayment(p)
+	if _, err := a.Complete(); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"selected inputs", "preliminary fee", "fee convergence iteration", "final balance"} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Errorf("expected %q in log output:\n%s", msg, buf.String())
+		}
+	}
+
+	// A nil logger restores the discarding default.
+	if New(cc).SetLogger(nil).logger != discardLogger {
+		t.Error("expected nil logger to restore the default")
+	}
+}
+
 // --- Change Address Tests ---
 
 func TestSetChangeAddress(t *testing.T) {
@@ -837,29 +2216,206 @@ func TestClone(t *testing.T) {
 	if clone.Ttl != a.Ttl {
 		t.Error("TTL not cloned")
 	}
-	if clone.FeePadding != a.FeePadding {
-		t.Error("FeePadding not cloned")
+	if clone.FeePadding != a.FeePadding {
+		t.Error("FeePadding not cloned")
+	}
+	if len(clone.payments) != len(a.payments) {
+		t.Error("payments not cloned")
+	}
+
+	// Verify independence
+	clone.Ttl = 9999
+	if a.Ttl == 9999 {
+		t.Error("clone is not independent from original")
+	}
+}
+
+// --- Loading/Utility Tests ---
+
+func TestLoadTxCborInvalidHex(t *testing.T) {
+	cc := setupFixedContext()
+	a := New(cc)
+
+	_, err := a.LoadTxCbor("not-hex!")
+	if err == nil {
+		t.Error("expected error for invalid hex")
+	}
+}
+
+func TestLoadTxCborPreservesBodyBytesThroughSigning(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	payment, err := NewPayment(validTestAddrBech32, 2_000_000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := New(cc).SetWallet(NewExternalWallet(addr)).AddPayment(payment).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := cbor.Encode(&built.GetTx().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canonical[0]&0xe0 != 0xa0 || canonical[0]&0x1f >= 24 {
+		t.Fatalf("unexpected body map header %#x", canonical[0])
+	}
+	// Re-encode the body as an indefinite-length map, which is valid CBOR but
+	// not what the encoder produces.
+	body := append([]byte{0xbf}, canonical[1:]...)
+	body = append(body, 0xff)
+	wsCbor, err := cbor.Encode(&built.GetTx().WitnessSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txCbor := append([]byte{0x84}, body...)
+	txCbor = append(txCbor, wsCbor...)
+	txCbor = append(txCbor, 0xf5, 0xf6)
+
+	a, err := New(cc).LoadTxCbor(hex.EncodeToString(txCbor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x42}, ed25519.SeedSize))
+	if _, err := a.SignWithSkey(privateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	bodyHash := common.Blake2b256Hash(body)
+	witnesses := a.GetTx().WitnessSet.VkeyWitnesses.Items()
+	if len(witnesses) != 1 || !ed25519.Verify(privateKey.Public().(ed25519.PublicKey), bodyHash.Bytes(), witnesses[0].Signature) {
+		t.Fatal("expected the witness to sign the original body hash")
+	}
+	txId, signedCbor, err := a.PrepareSubmit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txId != bodyHash {
+		t.Errorf("expected tx id %s, got %s", bodyHash, txId)
+	}
+	if !bytes.HasPrefix(signedCbor, append([]byte{0x84}, body...)) {
+		t.Error("expected the serialized tx to keep the original body bytes")
+	}
+}
+
+func TestVerifyAuxDataHashOnLoadedTx(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	buildItems := func(msg string) []cbor.RawMessage {
+		t.Helper()
+		payment, err := NewPayment(validTestAddrBech32, 2_000_000, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		built, err := New(cc).SetWallet(NewExternalWallet(addr)).
+			AddPayment(payment).
+			SetShelleyMetadata(map[uint64]any{674: msg}).
+			Complete()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := built.VerifyAuxDataHash(); err != nil {
+			t.Fatalf("built tx: %v", err)
+		}
+		txCbor, err := built.GetTxCbor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var items []cbor.RawMessage
+		if _, err := cbor.Decode(txCbor, &items); err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+	// The body of one transaction with the auxiliary data of another.
+	items := buildItems("original")
+	items[3] = buildItems("amended")[3]
+	amended, err := cbor.Encode(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(cc).LoadTxCbor(hex.EncodeToString(amended))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Loading keeps the body as it is, so the mismatch is reported.
+	if err := a.VerifyAuxDataHash(); err == nil || !strings.Contains(err.Error(), "auxiliary data hash mismatch") {
+		t.Fatalf("expected a mismatch after loading, got %v", err)
+	}
+	asLoaded, err := a.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loadedItems []cbor.RawMessage
+	if _, err := cbor.Decode(asLoaded, &loadedItems); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(loadedItems[0], items[0]) {
+		t.Fatal("expected loading to keep the body bytes")
+	}
+	if err := a.FixAuxDataHash(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.VerifyAuxDataHash(); err != nil {
+		t.Fatalf("expected FixAuxDataHash to correct the hash: %v", err)
+	}
+	loaded, err := a.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cbor.Decode(loaded, &items); err != nil {
+		t.Fatal(err)
+	}
+	var loadedBody conway.ConwayTransactionBody
+	if _, err := cbor.Decode(items[0], &loadedBody); err != nil {
+		t.Fatal(err)
+	}
+	if loadedBody.TxAuxDataHash == nil || *loadedBody.TxAuxDataHash != common.Blake2b256Hash(items[3]) {
+		t.Fatal("expected GetTxCbor to serialize the corrected hash")
+	}
+	privateKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x42}, ed25519.SeedSize))
+	if _, err := a.SignWithSkey(privateKey); err != nil {
+		t.Fatal(err)
+	}
+	txId, _, err := a.PrepareSubmit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	witnesses := a.GetTx().WitnessSet.VkeyWitnesses.Items()
+	if len(witnesses) != 1 || !ed25519.Verify(privateKey.Public().(ed25519.PublicKey), txId.Bytes(), witnesses[0].Signature) {
+		t.Fatal("expected the witness to sign the corrected body")
+	}
+
+	// A signed transaction cannot have its body corrected.
+	signed, err := a.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cbor.Decode(signed, &items); err != nil {
+		t.Fatal(err)
 	}
-	if len(clone.payments) != len(a.payments) {
-		t.Error("payments not cloned")
+	items[3] = buildItems("again")[3]
+	tampered, err := cbor.Encode(items)
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	// Verify independence
-	clone.Ttl = 9999
-	if a.Ttl == 9999 {
-		t.Error("clone is not independent from original")
+	b, err := New(cc).LoadTxCbor(hex.EncodeToString(tampered))
+	if err != nil {
+		t.Fatal(err)
 	}
-}
-
-// --- Loading/Utility Tests ---
-
-func TestLoadTxCborInvalidHex(t *testing.T) {
-	cc := setupFixedContext()
-	a := New(cc)
-
-	_, err := a.LoadTxCbor("not-hex!")
-	if err == nil {
-		t.Error("expected error for invalid hex")
+	if err := b.VerifyAuxDataHash(); err == nil || !strings.Contains(err.Error(), "auxiliary data hash mismatch") {
+		t.Fatalf("expected a mismatch, got %v", err)
+	}
+	if _, err := b.SignWithSkey(privateKey); err == nil {
+		t.Fatal("expected an error signing a body with a mismatched hash")
+	}
+	if err := b.FixAuxDataHash(); err == nil {
+		t.Fatal("expected an error fixing the hash of a signed transaction")
 	}
 }
 
@@ -981,6 +2537,50 @@ func TestAddRequiredSignerBothKeys(t *testing.T) {
 	}
 }
 
+func TestApplyNativeScriptConstraints(t *testing.T) {
+	must := func(ns common.NativeScript, err error) common.NativeScript {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ns
+	}
+	owner, alt1, alt2 := testPolicyId(0x01), testPolicyId(0x02), testPolicyId(0x03)
+	alternatives := must(NewNativeScriptAny([]common.NativeScript{
+		must(NewNativeScriptPubkey(alt1)),
+		must(NewNativeScriptPubkey(alt2)),
+	}))
+	ns := must(NewNativeScriptAll([]common.NativeScript{
+		must(NewNativeScriptPubkey(owner)),
+		must(NewNativeScriptInvalidBefore(100)),
+		must(NewNativeScriptInvalidHereafter(200)),
+		alternatives,
+	}))
+
+	a := New(setupFixedContext()).AddRequiredSigner(owner).ApplyNativeScriptConstraints(ns)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.ValidityStart != 100 || a.Ttl != 200 {
+		t.Errorf("expected validity [100, 200), got [%d, %d)", a.ValidityStart, a.Ttl)
+	}
+	if len(a.requiredSigners) != 1 || a.requiredSigners[0] != owner {
+		t.Errorf("expected only the mandatory signer, got %v", a.requiredSigners)
+	}
+
+	// An existing tighter TTL is kept.
+	a = New(setupFixedContext()).SetTtl(150).ApplyNativeScriptConstraints(ns)
+	if a.err != nil || a.Ttl != 150 {
+		t.Errorf("expected TTL 150 to be kept, got %d (err %v)", a.Ttl, a.err)
+	}
+
+	late := must(NewNativeScriptInvalidBefore(300))
+	a = New(setupFixedContext()).SetTtl(200).ApplyNativeScriptConstraints(late)
+	if a.err == nil {
+		t.Error("expected error for an empty validity interval")
+	}
+}
+
 // --- NewScriptRef Tests ---
 
 func TestNewScriptRefV1(t *testing.T) {
@@ -1189,6 +2789,244 @@ func TestCompleteWithCustomChangeAddress(t *testing.T) {
 	}
 }
 
+func TestOutputValueTo(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	var raw [29]byte
+	raw[0] = 0x60 // enterprise address, testnet
+	raw[1] = 0xCC
+	receiver, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := New(cc).SetWallet(NewExternalWallet(addr))
+	if _, err := a.OutputValueTo(receiver); err == nil {
+		t.Fatal("expected error before Complete")
+	}
+	a, err = a.PayToAddress(receiver, 2_000_000).
+		PayToAddress(receiver, 1_500_000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := a.OutputValueTo(receiver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Coin != 3_500_000 || got.HasAssets() {
+		t.Fatalf("receiver value = %d lovelace (assets %v), want 3500000 and no assets", got.Coin, got.HasAssets())
+	}
+
+	change, err := a.OutputValueTo(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Coin+got.Coin+a.GetTx().Body.TxFee != 10_000_000 {
+		t.Fatalf("change %d + payments %d + fee %d != input 10000000", change.Coin, got.Coin, a.GetTx().Body.TxFee)
+	}
+}
+
+func TestComputeExactFee(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 50_000_000, 0x01, 0)
+
+	a, err := New(cc).SetWallet(NewExternalWallet(addr)).
+		PayToAddress(addr, 2_000_000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimated := int64(a.GetTx().Body.TxFee)
+
+	// One witness is what Complete assumed, so the fee is unchanged.
+	fee, err := a.ComputeExactFee(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee != estimated {
+		t.Fatalf("exact fee for 1 witness = %d, estimated %d", fee, estimated)
+	}
+
+	fee, err = a.ComputeExactFee(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee <= estimated {
+		t.Fatalf("exact fee for 3 witnesses = %d, want more than %d", fee, estimated)
+	}
+	body := a.GetTx().Body
+	if int64(body.TxFee) != fee {
+		t.Fatalf("body fee %d, want %d", body.TxFee, fee)
+	}
+	var total uint64
+	for _, out := range body.TxOutputs {
+		total += out.OutputAmount.Amount
+	}
+	if total+body.TxFee != 50_000_000 {
+		t.Fatalf("outputs %d + fee %d do not balance the input", total, body.TxFee)
+	}
+
+	if _, err := a.ComputeExactFee(-1); err == nil {
+		t.Fatal("expected an error for a negative witness count")
+	}
+	if _, err := New(cc).ComputeExactFee(1); err == nil {
+		t.Fatal("expected an error before Complete")
+	}
+}
+
+func TestSignSameKeyInputsOnce(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	for i := range 3 {
+		addTestUtxo(cc, w.Address(), 3_000_000, byte(0x01+i), 0)
+	}
+
+	a, err := New(cc).SetWallet(w).
+		PayToAddress(testAddress(t), 7_000_000).
+		SetTtl(50000000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(a.GetTx().Body.TxInputs.Items()); n != 3 {
+		t.Fatalf("expected 3 inputs, got %d", n)
+	}
+	if n := a.estimatedWitnessCount(a.utxos); n != 1 {
+		t.Fatalf("expected 1 estimated witness, got %d", n)
+	}
+
+	// Signing again replaces the witness rather than adding a second one.
+	for range 2 {
+		if a, err = a.Sign(); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(a.GetTx().WitnessSet.VkeyWitnesses.Items()); n != 1 {
+			t.Fatalf("expected 1 vkey witness, got %d", n)
+		}
+	}
+}
+
+func TestProjectedOutputCount(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 50_000_000, 0x01, 0)
+
+	var raw [29]byte
+	raw[0] = 0x60 // enterprise address, testnet
+	raw[1] = 0xCC
+	receiver, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[1] = 0xDD
+	secondChange, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := New(cc).SetWallet(NewExternalWallet(addr)).
+		PayToAddress(receiver, 2_000_000).
+		PayToAddress(receiver, 3_000_000).
+		SetChangeAddresses([]common.Address{addr, secondChange}, []int{1, 1})
+	projected, err := a.ProjectedOutputCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two payments plus change split across two addresses.
+	if projected != 4 {
+		t.Fatalf("projected %d outputs, want 4", projected)
+	}
+	if a.GetTx() != nil || len(a.GetUsedUTxOs()) != 0 {
+		t.Fatal("projection must not build or select on the original builder")
+	}
+
+	a, err = a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(a.GetTx().Body.TxOutputs); got != projected {
+		t.Fatalf("built %d outputs, projected %d", got, projected)
+	}
+	if after, err := a.ProjectedOutputCount(); err != nil || after != projected {
+		t.Fatalf("ProjectedOutputCount after Complete = %d, %v; want %d", after, err, projected)
+	}
+}
+
+func TestAssertOutputs(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	var raw [29]byte
+	raw[0] = 0x60 // enterprise address, testnet
+	raw[1] = 0xCC
+	receiver, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := New(cc).SetWallet(NewExternalWallet(addr))
+	if err := a.AssertOutputs(nil); err == nil {
+		t.Fatal("expected error before Complete")
+	}
+	a, err = a.PayToAddress(receiver, 2_000_000).
+		PayToAddress(receiver, 1_500_000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := []ExpectedOutput{
+		{Address: receiver, MinValue: NewSimpleValue(2_000_000)},
+		{Address: receiver, MinValue: NewSimpleValue(1_000_000)},
+	}
+	if err := a.AssertOutputs(spec); err != nil {
+		t.Fatalf("expected outputs to match, got %v", err)
+	}
+
+	// The smaller expectation must not take the only output the larger fits.
+	if err := a.AssertOutputs([]ExpectedOutput{spec[1], spec[0]}); err != nil {
+		t.Fatalf("expected outputs to match in any order, got %v", err)
+	}
+
+	err = a.AssertOutputs([]ExpectedOutput{
+		{Address: receiver, MinValue: NewSimpleValue(2_000_000)},
+		{Address: receiver, MinValue: NewSimpleValue(3_000_000)},
+	})
+	if err == nil || !strings.Contains(err.Error(), "expected output 1") {
+		t.Fatalf("expected a missing-output diff, got %v", err)
+	}
+
+	err = a.AssertOutputs(spec[:1])
+	if err == nil || !strings.Contains(err.Error(), "unexpected output") {
+		t.Fatalf("expected an unexpected-output diff, got %v", err)
+	}
+
+	// Only the change Complete adds is exempt, not a payment to the change
+	// address.
+	self, err := New(cc).SetWallet(NewExternalWallet(addr)).
+		PayToAddress(addr, 2_000_000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = self.AssertOutputs(nil)
+	if err == nil || !strings.Contains(err.Error(), "unexpected output 0") {
+		t.Fatalf("expected the payment to the change address to be unexpected, got %v", err)
+	}
+	if err := self.AssertOutputs([]ExpectedOutput{{Address: addr, MinValue: NewSimpleValue(2_000_000)}}); err != nil {
+		t.Fatalf("expected the change output to be skipped, got %v", err)
+	}
+}
+
 // --- Reference Inputs in Complete ---
 
 func TestCompleteWithReferenceInputs(t *testing.T) {
@@ -1336,41 +3174,192 @@ func TestCompleteReservesReferenceScriptFeeForCoinSelection(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	a := New(cc).
-		SetWallet(NewExternalWallet(addr)).
-		AddPayment(p)
-	a, err = a.AddReferenceInput(hashHex, 0)
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		AddPayment(p)
+	a, err = a.AddReferenceInput(hashHex, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Complete(); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	inputRefs := bodyInputRefs(t, a)
+	if len(inputRefs) != 2 {
+		t.Fatalf("expected both wallet UTxOs to be selected, got %v", inputRefs)
+	}
+	maxFee, err := cc.MaxTxFee()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.tx.Body.TxFee <= maxFee {
+		t.Fatalf("expected final fee %d to exceed size-only max fee %d", a.tx.Body.TxFee, maxFee)
+	}
+}
+
+func TestReferenceScriptSizeFeeDelta(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addRef := func(hashByte byte, script common.Script) string {
+		var txHash common.Blake2b256
+		txHash[0] = hashByte
+		output := &babbage.BabbageTransactionOutput{
+			OutputAddress: addr,
+			OutputAmount:  mary.MaryTransactionOutputValue{Amount: 2_000_000},
+		}
+		if script != nil {
+			output.TxOutScriptRef = &common.ScriptRef{Type: common.ScriptRefTypePlutusV2, Script: script}
+		}
+		cc.AddUtxoByRef(common.Utxo{Id: shelley.ShelleyTransactionInput{TxId: txHash}, Output: output})
+		return hex.EncodeToString(txHash.Bytes())
+	}
+	withScript := addRef(0xe1, common.PlutusV2Script(bytes.Repeat([]byte{0x42}, 1_000)))
+	withoutScript := addRef(0xe2, nil)
+
+	inputs := []common.Utxo{makeTestUtxo(t, common.Blake2b256{0x01}, 0, 10_000_000)}
+	outputs := []babbage.BabbageTransactionOutput{NewBabbageOutputSimple(addr, 2_000_000)}
+	fee := func(refHash string) (int64, int) {
+		t.Helper()
+		a, err := New(cc).AddReferenceInput(refHash, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, err := a.totalReferenceScriptSize(inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := a.estimateFee(inputs, outputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f, size
+	}
+	feeWith, sizeWith := fee(withScript)
+	feeWithout, sizeWithout := fee(withoutScript)
+	if sizeWith != 1_000 || sizeWithout != 0 {
+		t.Fatalf("expected reference script sizes 1000 and 0, got %d and %d", sizeWith, sizeWithout)
+	}
+	// Identical bodies, so the delta is the first-tier price: 1000 bytes at 15
+	// lovelace per byte.
+	if delta := feeWith - feeWithout; delta != 15_000 {
+		t.Errorf("expected reference script fee delta 15000, got %d", delta)
+	}
+}
+
+// --- AddVerificationKeyWitness ---
+
+func TestAddVerificationKeyWitnessNoTx(t *testing.T) {
+	cc := setupFixedContext()
+	a := New(cc)
+	witness := common.VkeyWitness{
+		Vkey:      make([]byte, 32),
+		Signature: make([]byte, 64),
+	}
+	_, err := a.AddVerificationKeyWitness(witness)
+	if err == nil {
+		t.Error("expected error when no transaction built")
+	}
+}
+
+func TestAssembleTx(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+	a, err := New(cc).SetWallet(NewExternalWallet(addr)).PayToAddress(addr, 2_000_000).SetTtl(50000000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txCbor, err := a.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodyHash := a.GetTx().Id()
+	witnessSet := func(seed byte, hash common.Blake2b256) []byte {
+		witness, err := NewVkeyWitnessFromSkey(hash, bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ws := conway.ConwayTransactionWitnessSet{VkeyWitnesses: cbor.NewSetType([]common.VkeyWitness{witness}, true)}
+		wsCbor, err := cbor.Encode(&ws)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wsCbor
+	}
+	first, second := witnessSet(0x01, bodyHash), witnessSet(0x02, bodyHash)
+
+	assembled, err := AssembleTx(txCbor, first, second, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx conway.ConwayTransaction
+	if _, err := cbor.Decode(assembled, &tx); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(tx.WitnessSet.VkeyWitnesses.Items()); got != 2 {
+		t.Errorf("expected 2 distinct vkey witnesses, got %d", got)
+	}
+	if tx.Id() != bodyHash {
+		t.Errorf("expected tx id %s, got %s", bodyHash, tx.Id())
+	}
+
+	if _, err := AssembleTx(txCbor, witnessSet(0x03, common.Blake2b256{0x01})); err == nil || !strings.Contains(err.Error(), "does not sign transaction") {
+		t.Errorf("expected a foreign witness to be rejected, got %v", err)
+	}
+	if _, err := AssembleTx(txCbor, []byte{0xff}); err == nil {
+		t.Error("expected error for invalid witness set CBOR")
+	}
+}
+
+func TestWitnessSetCborRoundTrip(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	build := func() *Apollo {
+		a, err := New(cc).SetWallet(NewExternalWallet(addr)).PayToAddress(addr, 2_000_000).SetTtl(50000000).Complete()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	signed := build()
+	if _, err := signed.GetWitnessSetCbor(); err != nil {
+		t.Fatal(err)
+	}
+	witness := common.VkeyWitness{Vkey: make([]byte, 32), Signature: make([]byte, 64)}
+	if _, err := signed.AddVerificationKeyWitness(witness); err != nil {
+		t.Fatal(err)
+	}
+	wsCbor, err := signed.GetWitnessSetCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned := build()
+	bodyBefore, err := cbor.Encode(&unsigned.GetTx().Body)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	if _, err := a.Complete(); err != nil {
-		t.Fatalf("Complete failed: %v", err)
+	if err := unsigned.SetWitnessSetCbor(wsCbor); err != nil {
+		t.Fatal(err)
 	}
-	inputRefs := bodyInputRefs(t, a)
-	if len(inputRefs) != 2 {
-		t.Fatalf("expected both wallet UTxOs to be selected, got %v", inputRefs)
+	if got := len(unsigned.GetTx().WitnessSet.VkeyWitnesses.Items()); got != 1 {
+		t.Fatalf("expected 1 vkey witness after replacing the witness set, got %d", got)
 	}
-	maxFee, err := cc.MaxTxFee()
+	bodyAfter, err := cbor.Encode(&unsigned.GetTx().Body)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if a.tx.Body.TxFee <= maxFee {
-		t.Fatalf("expected final fee %d to exceed size-only max fee %d", a.tx.Body.TxFee, maxFee)
+	if !bytes.Equal(bodyBefore, bodyAfter) {
+		t.Error("replacing the witness set changed the body")
 	}
-}
 
-// --- AddVerificationKeyWitness ---
-
-func TestAddVerificationKeyWitnessNoTx(t *testing.T) {
-	cc := setupFixedContext()
-	a := New(cc)
-	witness := common.VkeyWitness{
-		Vkey:      make([]byte, 32),
-		Signature: make([]byte, 64),
+	if err := unsigned.SetWitnessSetCbor([]byte{0xff}); err == nil {
+		t.Error("expected error for invalid witness set CBOR")
 	}
-	_, err := a.AddVerificationKeyWitness(witness)
-	if err == nil {
+	if err := New(cc).SetWitnessSetCbor(wsCbor); err == nil {
 		t.Error("expected error when no transaction built")
 	}
 }
@@ -1537,7 +3526,7 @@ func TestSetCollateralSkipsExactMultiAssetCandidateWithoutMutatingState(t *testi
 	selectedHash[0] = 0x22
 
 	skipped := makeAssetTestUtxo(t, skippedHash, 0, 5_000_000, testMultiAsset(1, "skip", 1))
-	selected := makeAssetTestUtxo(t, selectedHash, 0, 6_000_000, testMultiAsset(2, "pick", 2))
+	selected := makeAssetTestUtxo(t, selectedHash, 0, 7_000_000, testMultiAsset(2, "pick", 2))
 
 	a := New(cc).
 		SetWallet(NewExternalWallet(addr)).
@@ -1566,11 +3555,92 @@ func TestSetCollateralSkipsExactMultiAssetCandidateWithoutMutatingState(t *testi
 	if a.collateralReturn == nil {
 		t.Fatal("expected collateral return for selected multi-asset collateral")
 	}
-	if amount := a.collateralReturn.Amount(); amount == nil || amount.Cmp(big.NewInt(1_000_000)) != 0 {
+	if amount := a.collateralReturn.Amount(); amount == nil || amount.Cmp(big.NewInt(2_000_000)) != 0 {
 		t.Fatalf("unexpected collateral return amount: %v", amount)
 	}
 }
 
+func TestCompleteWithAssetBearingCollateral(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+
+	// The wallet holds no pure-ADA UTxO. The first candidate's 500_000
+	// lovelace remainder cannot cover min-ADA for a return carrying its token.
+	var smallHash, largeHash common.Blake2b256
+	smallHash[0] = 0x44
+	largeHash[0] = 0x55
+	small := makeAssetTestUtxo(t, smallHash, 0, 5_500_000, testMultiAsset(1, "small", 1))
+	large := makeAssetTestUtxo(t, largeHash, 0, 20_000_000, testMultiAsset(2, "large", 7))
+
+	datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+	unit := NewUnit("a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4", "746f6b656e", 1)
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		SetCollateralAmount(5_000_000).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		DisableExecutionUnitsEstimation().
+		AddLoadedUTxOs(small, large).
+		Mint(unit, &datum, &common.ExUnits{Memory: 1, Steps: 1}).
+		PayToAddress(addr, 2_000_000)
+	if _, err := a.Complete(); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if len(a.collaterals) != 1 || utxoRef(a.collaterals[0]) != utxoRef(large) {
+		t.Fatalf("expected the large asset-bearing UTxO as collateral, got %v", a.collaterals)
+	}
+	ret := a.tx.Body.TxCollateralReturn
+	if ret == nil {
+		t.Fatal("expected a collateral return carrying the collateral assets")
+	}
+	if qty := ret.Assets().Asset(testPolicyId(2), []byte("large")); qty == nil || qty.Int64() != 7 {
+		t.Fatalf("collateral return assets = %v, want 7 large", qty)
+	}
+	pp, _ := cc.ProtocolParams()
+	minReturn, err := MinLovelacePostAlonzo(ret, pp.CoinsPerUtxoByteValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	returned := ret.Amount().Int64()
+	if returned < minReturn {
+		t.Fatalf("collateral return %d is below min-ADA %d", returned, minReturn)
+	}
+	// total_collateral is the lovelace consumed; the assets are not counted.
+	if total := a.tx.Body.TxTotalCollateral; total != 5_000_000 || int64(total)+returned != 20_000_000 { //nolint:gosec // small test values
+		t.Fatalf("total_collateral = %d with return %d, want 5000000 of 20000000", total, returned)
+	}
+}
+
+func TestCompleteRejectsExUnitsOverTxMaximum(t *testing.T) {
+	build := func(exUnits common.ExUnits) error {
+		cc := setupFixedContext()
+		addr := testAddress(t)
+		addTestUtxo(cc, addr, 20_000_000, 0x01, 0)
+		addTestUtxo(cc, addr, 10_000_000, 0x02, 0)
+		datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+		unit := NewUnit("a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4", "746f6b656e", 1)
+		_, err := New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+			DisableExecutionUnitsEstimation().
+			Mint(unit, &datum, &exUnits).
+			PayToAddress(addr, 2_000_000).
+			Complete()
+		return err
+	}
+	if err := build(common.ExUnits{Memory: 1_000_000, Steps: 1_000_000}); err != nil {
+		t.Fatalf("expected in-budget transaction to build, got %v", err)
+	}
+	err := build(common.ExUnits{Memory: 15_000_000, Steps: 1_000_000})
+	if err == nil || !strings.Contains(err.Error(), "exceeds the per-transaction maximum of 14000000") {
+		t.Fatalf("expected execution memory limit error, got %v", err)
+	}
+	err = build(common.ExUnits{Memory: 1_000_000, Steps: 10_000_000_001})
+	if err == nil || !strings.Contains(err.Error(), "execution steps 10000000001") {
+		t.Fatalf("expected execution steps limit error, got %v", err)
+	}
+}
+
 func TestSetCollateralRejectedExactMultiAssetCandidateLeavesBuilderCleanOnError(t *testing.T) {
 	cc := setupFixedContext()
 	addr := testAddress(t)
@@ -1673,6 +3743,58 @@ func TestCompleteUsesOnlyReferencedCostModelInScriptDataHash(t *testing.T) {
 	}
 }
 
+func TestEstimatedFeeCoversScriptDataHash(t *testing.T) {
+	cc := setupFixedContext()
+	pp, err := cc.ProtocolParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := testAddress(t)
+
+	var spendHash, collateralHash common.Blake2b256
+	spendHash[0] = 0x01
+	collateralHash[0] = 0x02
+	datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+	unit := NewUnit("a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4", "746f6b656e", 1)
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		AddInput(makeTestUtxo(t, spendHash, 0, 10_000_000)).
+		AddCollateral(makeTestUtxo(t, collateralHash, 0, 5_000_000)).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		DisableExecutionUnitsEstimation().
+		Mint(unit, &datum, &common.ExUnits{Memory: 1, Steps: 1}).
+		PayToAddress(addr, 2_000_000)
+	if _, err := a.Complete(); err != nil {
+		t.Fatal(err)
+	}
+	if a.tx.Body.TxScriptDataHash == nil {
+		t.Fatal("expected script data hash in script transaction body")
+	}
+	txCbor, err := a.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The unsigned tx already carries the 32-byte script data hash; the fee
+	// must also cover at least one vkey witness (32-byte key + 64-byte
+	// signature) and the 1 mem/1 step execution units. Had estimation sized a
+	// body without the hash, the fee would fall ~35 bytes short of this bound.
+	minFee := int64(len(txCbor)+32+64)*pp.MinFeeCoefficient + pp.MinFeeConstant + 1
+	if int64(a.tx.Body.TxFee) < minFee {
+		t.Fatalf("fee %d does not cover final tx size (need at least %d)", a.tx.Body.TxFee, minFee)
+	}
+
+	plain := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		AddInput(makeTestUtxo(t, spendHash, 0, 10_000_000)).
+		PayToAddress(addr, 2_000_000)
+	if _, err := plain.Complete(); err != nil {
+		t.Fatal(err)
+	}
+	if plain.tx.Body.TxScriptDataHash != nil {
+		t.Fatal("expected no script data hash in plain transfer")
+	}
+}
+
 // --- ConsumeUTxO ---
 
 func TestConsumeUTxO(t *testing.T) {
@@ -1911,8 +4033,8 @@ func TestTwoUtxoWalletPicksSeparateCollateral(t *testing.T) {
 		t.Fatalf("Complete failed: %v", err)
 	}
 
-	if a.collateralOverlapRef != "" {
-		t.Fatalf("expected no overlap for a multi-UTxO wallet, got overlap ref %s", a.collateralOverlapRef)
+	if len(a.collateralOverlapRefs) != 0 {
+		t.Fatalf("expected no overlap for a multi-UTxO wallet, got overlap refs %v", a.collateralOverlapRefs)
 	}
 	if len(a.collaterals) != 1 {
 		t.Fatalf("expected 1 collateral, got %d", len(a.collaterals))
@@ -1925,6 +4047,80 @@ func TestTwoUtxoWalletPicksSeparateCollateral(t *testing.T) {
 	}
 }
 
+// TestCollateralFromInputsSingleUtxoScriptSpend verifies the single-UTxO
+// script-spend case: the wallet's only UTxO funds the transaction and also
+// backs the collateral, with the collateral return carrying its remainder.
+func TestCollateralFromInputsSingleUtxoScriptSpend(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 20_000_000, 0x01, 0)
+
+	redeemer := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		SetCollateralFromInputs(true).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		DisableExecutionUnitsEstimation().
+		CollectFrom(scriptAddressUtxo(t, 0x02, 2_000_000), redeemer, common.ExUnits{Memory: 1, Steps: 1})
+	payment, err := NewPayment(validTestAddrBech32, 5_000_000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.AddPayment(payment)
+	if _, err := a.Complete(); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	collRefs := bodyCollateralRefs(t, a)
+	if len(collRefs) != 1 {
+		t.Fatalf("expected 1 collateral input, got %v", collRefs)
+	}
+	if !slices.Contains(bodyInputRefs(t, a), collRefs[0]) {
+		t.Fatalf("collateral %s is not a spending input %v", collRefs[0], bodyInputRefs(t, a))
+	}
+	totalColl := a.tx.Body.TxTotalCollateral
+	if a.tx.Body.TxCollateralReturn == nil {
+		t.Fatal("expected a collateral return for the ADA remainder")
+	}
+	gotReturn := a.tx.Body.TxCollateralReturn.Amount()
+	wantReturn := new(big.Int).SetUint64(20_000_000 - totalColl)
+	if gotReturn == nil || gotReturn.Cmp(wantReturn) != 0 {
+		t.Fatalf("collateral_return = %v, want %v", gotReturn, wantReturn)
+	}
+}
+
+// TestCollateralFromInputsAvoidsSeparateUtxo verifies that a multi-UTxO wallet
+// uses a spent input as collateral instead of referencing another UTxO.
+func TestCollateralFromInputsAvoidsSeparateUtxo(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 30_000_000, 0x01, 0)
+	addTestUtxo(cc, addr, 5_000_000, 0x02, 0)
+
+	datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+	unit := NewUnit("a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4", "746f6b656e", 1)
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		SetCollateralFromInputs(true).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		DisableExecutionUnitsEstimation().
+		Mint(unit, &datum, &common.ExUnits{Memory: 1, Steps: 1})
+	payment, err := NewPayment(validTestAddrBech32, 2_000_000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.AddPayment(payment)
+	if _, err := a.Complete(); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	inputRefs := bodyInputRefs(t, a)
+	collRefs := bodyCollateralRefs(t, a)
+	if len(collRefs) != 1 || !slices.Contains(inputRefs, collRefs[0]) {
+		t.Fatalf("expected collateral to be one of the inputs %v, got %v", inputRefs, collRefs)
+	}
+}
+
 // TestScriptAddressCollateralRejected verifies an auto-selected collateral is
 // never taken from a script address: only vkey-locked UTxOs are eligible.
 func TestScriptAddressCollateralRejected(t *testing.T) {
@@ -2032,6 +4228,325 @@ func TestMaxCollateralInputsEnforced(t *testing.T) {
 	}
 }
 
+// TestSweepTo verifies that a sweep spends every wallet UTxO into a single
+// output, and that a script sweep takes its collateral from the swept inputs.
+func TestSweepTo(t *testing.T) {
+	var raw [29]byte
+	raw[0] = 0x60 // enterprise address, testnet
+	raw[1] = 0xCC
+	destination, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sweep := func(script bool) *Apollo {
+		t.Helper()
+		cc := setupFixedContext()
+		addr := testAddress(t)
+		addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+		addTestUtxo(cc, addr, 3_000_000, 0x02, 0)
+		var txHash common.Blake2b256
+		txHash[0] = 0x03
+		cc.AddUtxo(addr, makeAssetTestUtxo(t, txHash, 0, 5_000_000, testMultiAsset(0x01, "token", 7)))
+
+		a, err := New(cc).SetWallet(NewExternalWallet(addr)).SweepTo(destination)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if script {
+			datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+			unit := NewUnit("a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4", "746f6b656e", 1)
+			a.AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+				DisableExecutionUnitsEstimation().
+				Mint(unit, &datum, &common.ExUnits{Memory: 1, Steps: 1})
+		}
+		a, err = a.Complete()
+		if err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+		if n := len(a.tx.Body.TxInputs.Items()); n != 3 {
+			t.Fatalf("expected all 3 UTxOs as inputs, got %d", n)
+		}
+		outputs := a.tx.Body.TxOutputs
+		if len(outputs) != 1 || outputs[0].OutputAddress.String() != destination.String() {
+			t.Fatalf("expected a single output to the destination, got %d outputs", len(outputs))
+		}
+		if got := outputs[0].OutputAmount.Amount + a.tx.Body.TxFee; got != 18_000_000 {
+			t.Errorf("output plus fee = %d, want 18000000", got)
+		}
+		if qty := outputs[0].OutputAmount.Assets.Asset(testPolicyId(0x01), []byte("token")); qty == nil || qty.Int64() != 7 {
+			t.Errorf("expected the swept token in the output, got %v", qty)
+		}
+		return a
+	}
+
+	sweep(false)
+	a := sweep(true)
+	collRefs := bodyCollateralRefs(t, a)
+	if len(collRefs) != 1 || !slices.Contains(bodyInputRefs(t, a), collRefs[0]) {
+		t.Fatalf("expected the collateral to be one of the swept inputs, got %v", collRefs)
+	}
+}
+
+// TestAutoCollateralCombinesWithinMaxInputs verifies that when no single UTxO
+// covers the collateral, several small ones are combined, but never more than
+// MaxCollateralInputs of them.
+func TestAutoCollateralCombinesWithinMaxInputs(t *testing.T) {
+	newBuilder := func(maxInputs int) *Apollo {
+		pp := backend.ProtocolParameters{
+			MinFeeConstant:      155381,
+			MinFeeCoefficient:   44,
+			MaxTxSize:           16384,
+			CoinsPerUtxoByte:    "4310",
+			CollateralPercent:   150,
+			MaxCollateralInputs: maxInputs,
+			MaxValSize:          "5000",
+			KeyDeposits:         "2000000",
+			PoolDeposits:        "500000000",
+		}
+		cc := fixed.NewFixedChainContext(pp, backend.GenesisParameters{NetworkMagic: 1}, 0)
+		a := New(cc).
+			SetWallet(NewExternalWallet(testAddress(t))).
+			AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+			SetCollateralAmount(5_000_000)
+		// Many tiny ada UTxOs, none large enough to back collateral alone.
+		for i := byte(1); i <= 8; i++ {
+			var h common.Blake2b256
+			h[0] = i
+			a.AddLoadedUTxOs(makeTestUtxo(t, h, 0, 1_200_000))
+		}
+		return a
+	}
+
+	a := newBuilder(3)
+	err := a.setCollateral()
+	if err == nil {
+		t.Fatal("expected collateral input cap error")
+	}
+	if !strings.Contains(err.Error(), "cannot assemble collateral: need 5 inputs but max is 3") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(a.collaterals) != 0 {
+		t.Errorf("no collateral should be reserved on failure, got %d", len(a.collaterals))
+	}
+
+	a = newBuilder(5)
+	if err := a.setCollateral(); err != nil {
+		t.Fatalf("setCollateral: %v", err)
+	}
+	if len(a.collaterals) != 5 {
+		t.Fatalf("expected 5 collateral inputs, got %d", len(a.collaterals))
+	}
+	if a.totalCollateral != 5_000_000 {
+		t.Errorf("total collateral = %d, want 5000000", a.totalCollateral)
+	}
+	if a.collateralReturn == nil || a.collateralReturn.Amount().Uint64() != 1_000_000 {
+		t.Errorf("expected a 1000000 lovelace collateral return, got %v", a.collateralReturn)
+	}
+}
+
+// TestAutoCollateralCombinesTokenBearingUTxOs verifies that token-bearing
+// UTxOs are combined when the ADA-only ones fall short, with their assets
+// carried forward in a collateral return that meets min-UTxO.
+func TestAutoCollateralCombinesTokenBearingUTxOs(t *testing.T) {
+	a := New(setupFixedContext()).
+		SetWallet(NewExternalWallet(testAddress(t))).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		SetCollateralAmount(5_000_000)
+	for i := byte(1); i <= 2; i++ {
+		var h common.Blake2b256
+		h[0] = i
+		a.AddLoadedUTxOs(makeTestUtxo(t, h, 0, 1_200_000))
+	}
+	for i := byte(3); i <= 5; i++ {
+		var h common.Blake2b256
+		h[0] = i
+		a.AddLoadedUTxOs(makeAssetTestUtxo(t, h, 0, 3_000_000, testMultiAsset(i, "token", 1)))
+	}
+
+	if err := a.setCollateral(); err != nil {
+		t.Fatalf("setCollateral: %v", err)
+	}
+	// Two token-bearing UTxOs cover the 5 ADA but leave only 1 ADA for a
+	// return holding two policies, so a third is taken.
+	if len(a.collaterals) != 3 {
+		t.Fatalf("expected 3 collateral inputs, got %d", len(a.collaterals))
+	}
+	for _, utxo := range a.collaterals {
+		if utxo.Output.Assets() == nil {
+			t.Errorf("expected only the larger token-bearing UTxOs, got %s", utxoRef(utxo))
+		}
+	}
+	if a.collateralReturn == nil || a.collateralReturn.Amount().Uint64() != 4_000_000 {
+		t.Fatalf("expected a 4000000 lovelace collateral return, got %v", a.collateralReturn)
+	}
+	for i := byte(3); i <= 5; i++ {
+		if qty := a.collateralReturn.Assets().Asset(testPolicyId(i), []byte("token")); qty == nil || qty.Int64() != 1 {
+			t.Errorf("expected the collateral return to carry policy %d's token, got %v", i, qty)
+		}
+	}
+	if err := a.finalizeCollateral(200_000); err != nil {
+		t.Fatalf("finalizeCollateral: %v", err)
+	}
+}
+
+func TestCheckCollateralReturn(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 20_000_000, 0x01, 0)
+	var txHash common.Blake2b256
+	txHash[0] = 0x02
+	cc.AddUtxo(addr, makeAssetTestUtxo(t, txHash, 0, 8_000_000, testMultiAsset(0x01, "token", 7)))
+
+	datum := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+	unit := NewUnit("a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4", "746f6b656e", 1)
+	a, err := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		DisableExecutionUnitsEstimation().
+		Mint(unit, &datum, &common.ExUnits{Memory: 1, Steps: 1}).
+		PayToAddress(addr, 2_000_000).
+		SetTtl(50000000).
+		AddCollateral(makeAssetTestUtxo(t, txHash, 0, 8_000_000, testMultiAsset(0x01, "token", 7))).
+		SetCollateralAmount(3_000_000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret := a.GetTx().Body.TxCollateralReturn
+	if ret == nil || ret.OutputAmount.Amount != 5_000_000 {
+		t.Fatalf("expected a 5000000 lovelace collateral return, got %v", ret)
+	}
+	if qty := ret.OutputAmount.Assets.Asset(testPolicyId(0x01), []byte("token")); qty == nil || qty.Int64() != 7 {
+		t.Fatalf("expected the collateral return to carry the token, got %v", qty)
+	}
+
+	pp, err := cc.ProtocolParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := NewBabbageOutput(addr, Value{Coin: 5_000_000}, nil, nil)
+	tampered.OutputAmount.Assets = testMultiAsset(0x01, "token", 6)
+	a.collateralReturn = &tampered
+	if err := a.checkCollateralReturn(pp.CoinsPerUtxoByteValue()); err == nil ||
+		!strings.Contains(err.Error(), "carry forward every asset") {
+		t.Errorf("expected a dropped token to be rejected, got %v", err)
+	}
+	tampered.OutputAmount.Assets = testMultiAsset(0x01, "token", 7)
+	tampered.OutputAmount.Amount = 4_000_000
+	if err := a.checkCollateralReturn(pp.CoinsPerUtxoByteValue()); err == nil ||
+		!strings.Contains(err.Error(), "but total collateral is 3000000") {
+		t.Errorf("expected an unbalanced return to be rejected, got %v", err)
+	}
+	tampered.OutputAmount.Amount = 1_000
+	a.totalCollateral = 8_000_000 - 1_000
+	if err := a.checkCollateralReturn(pp.CoinsPerUtxoByteValue()); err == nil ||
+		!strings.Contains(err.Error(), "below its") {
+		t.Errorf("expected a return below min UTxO to be rejected, got %v", err)
+	}
+}
+
+// unavailableParamsContext fails every protocol parameter query.
+type unavailableParamsContext struct {
+	*fixed.FixedChainContext
+}
+
+var errParamsUnavailable = errors.New("protocol parameters unavailable")
+
+func (unavailableParamsContext) ProtocolParams() (backend.ProtocolParameters, error) {
+	return backend.ProtocolParameters{}, errParamsUnavailable
+}
+
+// TestAutoCollateralSkipsTokenBearingUTxOsWithoutParams verifies that, when
+// protocol parameters cannot be loaded, token-bearing UTxOs are not combined
+// into collateral, as their collateral return's min-UTxO cannot be checked.
+func TestAutoCollateralSkipsTokenBearingUTxOsWithoutParams(t *testing.T) {
+	a := New(unavailableParamsContext{setupFixedContext()}).
+		SetWallet(NewExternalWallet(testAddress(t))).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		SetCollateralAmount(5_000_000)
+	for i := byte(1); i <= 3; i++ {
+		var h common.Blake2b256
+		h[0] = i
+		a.AddLoadedUTxOs(makeAssetTestUtxo(t, h, 0, 3_000_000, testMultiAsset(i, "token", 1)))
+	}
+
+	err := a.setCollateral()
+	if !errors.Is(err, errNoCollateral) || !errors.Is(err, errParamsUnavailable) {
+		t.Fatalf("expected a no-collateral error naming the params failure, got %v", err)
+	}
+	if len(a.collaterals) != 0 {
+		t.Fatalf("expected no collateral, got %d inputs", len(a.collaterals))
+	}
+}
+
+// TestAutoCollateralCapsInputsWithoutParams verifies that combined collateral
+// falls back to the ledger's three-input cap when protocol parameters cannot
+// be loaded.
+func TestAutoCollateralCapsInputsWithoutParams(t *testing.T) {
+	a := New(unavailableParamsContext{setupFixedContext()}).
+		SetWallet(NewExternalWallet(testAddress(t))).
+		AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+		SetCollateralAmount(5_000_000)
+	for i := byte(1); i <= 8; i++ {
+		var h common.Blake2b256
+		h[0] = i
+		a.AddLoadedUTxOs(makeTestUtxo(t, h, 0, 1_200_000))
+	}
+
+	err := a.setCollateral()
+	if err == nil || !strings.Contains(err.Error(), "need 5 inputs but max is 3") {
+		t.Fatalf("expected the default collateral input cap, got %v", err)
+	}
+}
+
+// TestCombinedCollateralFromInputsOverlaps verifies that combined collateral
+// honours SetCollateralFromInputs by leaving its UTxOs to coin selection, and
+// that a reserved combined collateral is released and restored as a whole.
+func TestCombinedCollateralFromInputsOverlaps(t *testing.T) {
+	newBuilder := func(fromInputs bool) *Apollo {
+		a := New(setupFixedContext()).
+			SetWallet(NewExternalWallet(testAddress(t))).
+			AttachScript(common.PlutusV2Script([]byte{0x01, 0x02})).
+			SetCollateralAmount(5_000_000).
+			SetCollateralFromInputs(fromInputs)
+		for i := byte(1); i <= 3; i++ {
+			var h common.Blake2b256
+			h[0] = i
+			a.AddLoadedUTxOs(makeTestUtxo(t, h, 0, 2_000_000))
+		}
+		if err := a.setCollateral(); err != nil {
+			t.Fatalf("setCollateral: %v", err)
+		}
+		if len(a.collaterals) != 3 {
+			t.Fatalf("expected 3 combined collateral inputs, got %d", len(a.collaterals))
+		}
+		return a
+	}
+
+	a := newBuilder(true)
+	for _, utxo := range a.collaterals {
+		if a.isUsed(utxoRef(utxo)) {
+			t.Errorf("collateral %s should stay available to coin selection", utxoRef(utxo))
+		}
+	}
+
+	a = newBuilder(false)
+	if !a.releaseCollateralForOverlap() {
+		t.Fatal("expected combined collateral to be released for overlap")
+	}
+	for _, utxo := range a.collaterals {
+		if a.isUsed(utxoRef(utxo)) {
+			t.Errorf("released collateral %s should be available to coin selection", utxoRef(utxo))
+		}
+	}
+	a.restoreCollateralReservation()
+	for _, utxo := range a.collaterals {
+		if !a.isUsed(utxoRef(utxo)) {
+			t.Errorf("restored collateral %s should be reserved again", utxoRef(utxo))
+		}
+	}
+}
+
 // TestManualScriptAddressCollateralRejected verifies that caller-pinned
 // (AddCollateral) collateral at a script address is rejected by
 // validateCollateral, matching the ledger requirement that collateral be
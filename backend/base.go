@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -9,6 +10,8 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
@@ -80,6 +83,317 @@ func Supports(ctx ChainContext, capability Capability) bool {
 	return CapabilitiesOf(ctx).Has(capability)
 }
 
+// RewardBalanceProvider is an optional extension to ChainContext for backends
+// that can report the withdrawable reward balance of a stake account.
+type RewardBalanceProvider interface {
+	// RewardBalance returns the withdrawable rewards, in lovelace, held by
+	// the given reward (stake) address.
+	RewardBalance(rewardAddress common.Address) (uint64, error)
+}
+
+// TxSummary identifies an on-chain transaction that touched an address.
+type TxSummary struct {
+	TxHash      common.Blake2b256
+	BlockHeight uint64
+	BlockTime   uint64
+	Slot        uint64
+}
+
+// AddressHistoryProvider is an optional extension to ChainContext for
+// backends that can list the transactions touching an address.
+type AddressHistoryProvider interface {
+	// AddressTransactions returns the transactions touching addr in slots at
+	// or after fromSlot, oldest first.
+	AddressTransactions(addr common.Address, fromSlot uint64) ([]TxSummary, error)
+}
+
+// ContextBinder is an optional extension to ChainContext for backends whose
+// requests can follow the cancellation and deadline of a context.Context.
+type ContextBinder interface {
+	// WithContext returns a chain context that shares this one's
+	// configuration and caches but makes its requests with ctx.
+	WithContext(ctx context.Context) ChainContext
+}
+
+// WithContext returns cc bound to ctx when it implements ContextBinder, and cc
+// unchanged otherwise.
+func WithContext(ctx context.Context, cc ChainContext) ChainContext {
+	if binder, ok := cc.(ContextBinder); ok {
+		return binder.WithContext(ctx)
+	}
+	return cc
+}
+
+// TxConfirmation locates a transaction included on chain. BlockHeight is zero
+// when the backend does not index block heights.
+type TxConfirmation struct {
+	BlockHeight uint64
+	Slot        uint64
+	// Confirmations counts the including block and every block after it.
+	Confirmations uint64
+}
+
+// TxConfirmationProvider is an optional extension to ChainContext for
+// backends that can tell whether a transaction is on chain.
+type TxConfirmationProvider interface {
+	// TxConfirmations returns where txHash was included, or nil when it is
+	// not on chain yet.
+	TxConfirmations(txHash common.Blake2b256) (*TxConfirmation, error)
+}
+
+// EraSummary is one era of the chain's history. StartTime is measured from
+// the system start.
+type EraSummary struct {
+	StartSlot  uint64
+	StartTime  time.Duration
+	SlotLength time.Duration
+}
+
+// EraHistory is the chain's era history, oldest era first, so slots can be
+// converted to times across eras with different slot lengths.
+type EraHistory struct {
+	SystemStart time.Time
+	Eras        []EraSummary
+}
+
+// SlotToTime returns the start time of slot. The last era is taken to
+// continue indefinitely.
+func (h *EraHistory) SlotToTime(slot uint64) (time.Time, error) {
+	if len(h.Eras) == 0 {
+		return time.Time{}, errors.New("era history has no eras")
+	}
+	era := h.Eras[0]
+	if slot < era.StartSlot {
+		return time.Time{}, fmt.Errorf("slot %d is before the era history", slot)
+	}
+	for _, next := range h.Eras[1:] {
+		if slot < next.StartSlot {
+			break
+		}
+		era = next
+	}
+	if era.SlotLength <= 0 {
+		return time.Time{}, fmt.Errorf("era starting at slot %d has a non-positive slot length", era.StartSlot)
+	}
+	offset := slot - era.StartSlot
+	if offset > uint64(math.MaxInt64/era.SlotLength) {
+		return time.Time{}, fmt.Errorf("slot %d is too far in the future", slot)
+	}
+	return h.SystemStart.Add(era.StartTime + time.Duration(offset)*era.SlotLength), nil //nolint:gosec // bounded above
+}
+
+// TimeToSlot returns the slot containing t. The last era is taken to continue
+// indefinitely.
+func (h *EraHistory) TimeToSlot(t time.Time) (uint64, error) {
+	if len(h.Eras) == 0 {
+		return 0, errors.New("era history has no eras")
+	}
+	elapsed := t.Sub(h.SystemStart)
+	era := h.Eras[0]
+	if elapsed < era.StartTime {
+		return 0, fmt.Errorf("time %s is before the era history", t)
+	}
+	for _, next := range h.Eras[1:] {
+		if elapsed < next.StartTime {
+			break
+		}
+		era = next
+	}
+	if era.SlotLength <= 0 {
+		return 0, fmt.Errorf("era starting at slot %d has a non-positive slot length", era.StartSlot)
+	}
+	return era.StartSlot + uint64((elapsed-era.StartTime)/era.SlotLength), nil //nolint:gosec // non-negative above
+}
+
+// EraHistoryProvider is an optional extension to ChainContext for backends
+// that report the chain's era history.
+type EraHistoryProvider interface {
+	EraHistory() (*EraHistory, error)
+}
+
+// UtxoExistenceChecker is an optional extension to ChainContext for backends
+// that can tell whether an address holds a UTxO without fetching them all.
+type UtxoExistenceChecker interface {
+	// HasUTxOs reports whether addr holds at least one unspent output.
+	HasUTxOs(addr common.Address) (bool, error)
+}
+
+// HasUTxOs reports whether addr holds at least one UTxO. It uses the cheaper
+// UtxoExistenceChecker query when ctx implements it and falls back to Utxos.
+func HasUTxOs(ctx ChainContext, addr common.Address) (bool, error) {
+	if checker, ok := ctx.(UtxoExistenceChecker); ok {
+		return checker.HasUTxOs(addr)
+	}
+	utxos, err := ctx.Utxos(addr)
+	if err != nil {
+		return false, err
+	}
+	return len(utxos) > 0, nil
+}
+
+// UtxoPager is an optional extension to ChainContext for backends that can
+// return the UTxOs of an address a page at a time, so a caller that needs only
+// some of them does not fetch the whole set.
+type UtxoPager interface {
+	// UtxosPage returns the UTxOs at addr on the given 1-based page, in pages
+	// of a size the backend chooses. Pages after the last are empty.
+	UtxosPage(addr common.Address, page int) ([]common.Utxo, error)
+}
+
+// UtxosPage returns one page of the UTxOs at addr. It uses the UtxoPager query
+// when ctx implements it; otherwise the first page holds every UTxO and later
+// pages are empty.
+func UtxosPage(ctx ChainContext, addr common.Address, page int) ([]common.Utxo, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("invalid UTxO page %d", page)
+	}
+	if pager, ok := ctx.(UtxoPager); ok {
+		return pager.UtxosPage(addr, page)
+	}
+	if page > 1 {
+		return nil, nil
+	}
+	return ctx.Utxos(addr)
+}
+
+// FilteredUtxoProvider is an optional extension to ChainContext for backends
+// that can skip outputs without a datum or reference script before resolving
+// them.
+type FilteredUtxoProvider interface {
+	// UtxosWithDatum returns the UTxOs at addr carrying a datum hash or an
+	// inline datum.
+	UtxosWithDatum(addr common.Address) ([]common.Utxo, error)
+	// UtxosWithScriptRef returns the UTxOs at addr carrying a reference
+	// script.
+	UtxosWithScriptRef(addr common.Address) ([]common.Utxo, error)
+}
+
+// UtxosWithDatum returns the UTxOs at addr carrying a datum hash or an inline
+// datum. It uses the FilteredUtxoProvider query when ctx implements it and
+// otherwise filters Utxos.
+func UtxosWithDatum(ctx ChainContext, addr common.Address) ([]common.Utxo, error) {
+	if provider, ok := ctx.(FilteredUtxoProvider); ok {
+		return provider.UtxosWithDatum(addr)
+	}
+	utxos, err := ctx.Utxos(addr)
+	if err != nil {
+		return nil, err
+	}
+	return filterUtxos(utxos, func(out common.TransactionOutput) bool {
+		return out.DatumHash() != nil || out.Datum() != nil
+	}), nil
+}
+
+// UtxosWithScriptRef returns the UTxOs at addr carrying a reference script. It
+// uses the FilteredUtxoProvider query when ctx implements it and otherwise
+// filters Utxos.
+func UtxosWithScriptRef(ctx ChainContext, addr common.Address) ([]common.Utxo, error) {
+	if provider, ok := ctx.(FilteredUtxoProvider); ok {
+		return provider.UtxosWithScriptRef(addr)
+	}
+	utxos, err := ctx.Utxos(addr)
+	if err != nil {
+		return nil, err
+	}
+	return filterUtxos(utxos, func(out common.TransactionOutput) bool {
+		return out.ScriptRef() != nil
+	}), nil
+}
+
+func filterUtxos(utxos []common.Utxo, keep func(common.TransactionOutput) bool) []common.Utxo {
+	var kept []common.Utxo
+	for _, utxo := range utxos {
+		if utxo.Output != nil && keep(utxo.Output) {
+			kept = append(kept, utxo)
+		}
+	}
+	return kept
+}
+
+// DatumResolver is an optional extension to ChainContext for backends that
+// can look up a datum by its hash, such as the datum of an output that
+// carries only the hash.
+type DatumResolver interface {
+	// DatumByHash returns the datum whose CBOR hashes to datumHash.
+	DatumByHash(datumHash common.Blake2b256) (*common.Datum, error)
+}
+
+// DatumFromCbor decodes the datum CBOR a backend returned for datumHash,
+// rejecting bytes that do not hash to it.
+func DatumFromCbor(datumHash common.Blake2b256, datumCbor []byte) (*common.Datum, error) {
+	if len(datumCbor) == 0 {
+		return nil, fmt.Errorf("no datum found for hash %s", datumHash.String())
+	}
+	if computed := common.Blake2b256Hash(datumCbor); computed != datumHash {
+		return nil, fmt.Errorf("datum hash mismatch for %s: fetched datum hashes to %s", datumHash.String(), computed.String())
+	}
+	var datum common.Datum
+	if _, err := cbor.Decode(datumCbor, &datum); err != nil {
+		return nil, fmt.Errorf("failed to decode datum %s: %w", datumHash.String(), err)
+	}
+	return &datum, nil
+}
+
+// DefaultEvaluateBatchConcurrency bounds the concurrent EvaluateTx calls
+// EvaluateBatch makes when the backend has no native batch endpoint.
+const DefaultEvaluateBatchConcurrency = 4
+
+// BatchEvaluator is an optional extension to ChainContext for backends that
+// can evaluate several transactions in one request.
+type BatchEvaluator interface {
+	// EvaluateBatch returns the execution units of each transaction in
+	// txCbors, in the same order.
+	EvaluateBatch(txCbors [][]byte) ([]map[common.RedeemerKey]common.ExUnits, error)
+}
+
+// EvaluateBatch evaluates candidate transactions, e.g. variants with different
+// input sets, and returns their execution units in order. It uses the
+// BatchEvaluator endpoint when ctx implements it; otherwise it calls
+// EvaluateTx with at most concurrency requests in flight, or
+// DefaultEvaluateBatchConcurrency when concurrency is not positive. The error
+// of the lowest failing index is returned.
+func EvaluateBatch(ctx ChainContext, txCbors [][]byte, concurrency int) ([]map[common.RedeemerKey]common.ExUnits, error) {
+	if batcher, ok := ctx.(BatchEvaluator); ok {
+		results, err := batcher.EvaluateBatch(txCbors)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) != len(txCbors) {
+			return nil, fmt.Errorf("batch evaluation returned %d results for %d transactions", len(results), len(txCbors))
+		}
+		return results, nil
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultEvaluateBatchConcurrency
+	}
+	results := make([]map[common.RedeemerKey]common.ExUnits, len(txCbors))
+	errs := make([]error, len(txCbors))
+
+	workers := min(len(txCbors), concurrency)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index], errs[index] = ctx.EvaluateTx(txCbors[index], nil)
+			}
+		}()
+	}
+	for index := range txCbors {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("tx %d: %w", i, err)
+		}
+	}
+	return results, nil
+}
+
 func (c Capability) String() string {
 	switch c {
 	case CapabilityProtocolParams:
@@ -559,6 +873,50 @@ func ScriptRefFromBytes(scriptType uint, scriptBytes []byte, expectedHashHex str
 	return &common.ScriptRef{Type: scriptType, Script: script}, nil
 }
 
+// ScriptRefFromHash builds a reference script from the CBOR a backend returned
+// for scriptHash, finding its language by which one the hash matches.
+func ScriptRefFromHash(
+	scriptHash common.Blake2b224,
+	scriptCbor []byte,
+) (*common.ScriptRef, error) {
+	var native common.NativeScript
+	if _, err := cbor.Decode(scriptCbor, &native); err == nil && native.Hash() == scriptHash {
+		return &common.ScriptRef{
+			Type:   common.ScriptRefTypeNativeScript,
+			Script: native,
+		}, nil
+	}
+	v1 := common.PlutusV1Script(scriptCbor)
+	if v1.Hash() == scriptHash {
+		return &common.ScriptRef{
+			Type:   common.ScriptRefTypePlutusV1,
+			Script: v1,
+		}, nil
+	}
+	v2 := common.PlutusV2Script(scriptCbor)
+	if v2.Hash() == scriptHash {
+		return &common.ScriptRef{
+			Type:   common.ScriptRefTypePlutusV2,
+			Script: v2,
+		}, nil
+	}
+	v3 := common.PlutusV3Script(scriptCbor)
+	if v3.Hash() == scriptHash {
+		return &common.ScriptRef{
+			Type:   common.ScriptRefTypePlutusV3,
+			Script: v3,
+		}, nil
+	}
+	v4 := common.PlutusV4Script(scriptCbor)
+	if v4.Hash() == scriptHash {
+		return &common.ScriptRef{
+			Type:   common.ScriptRefTypePlutusV4,
+			Script: v4,
+		}, nil
+	}
+	return nil, errors.New("unable to determine reference script language from script hash")
+}
+
 // ComputeMaxTxFee computes the maximum transaction fee from protocol parameters,
 // validating that all values are non-negative before the calculation.
 func ComputeMaxTxFee(pp ProtocolParameters) (uint64, error) {
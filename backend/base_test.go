@@ -2,10 +2,14 @@ package backend
 
 import (
 	"encoding/hex"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
 )
 
 func TestCoinsPerUtxoByteValueDefault(t *testing.T) {
@@ -317,3 +321,149 @@ func TestComputeMaxTxFeeOverflow(t *testing.T) {
 		t.Errorf("ComputeMaxTxFee = %d, %v; want %d, nil", fee, err, 16384*44+155381)
 	}
 }
+
+type existenceChainContext struct {
+	legacyChainContext
+}
+
+func (existenceChainContext) HasUTxOs(common.Address) (bool, error) { return true, nil }
+
+func TestHasUTxOs(t *testing.T) {
+	var addr common.Address
+	has, err := HasUTxOs(legacyChainContext{}, addr)
+	if err != nil || has {
+		t.Fatalf("expected Utxos fallback to report no UTxOs, got %v, %v", has, err)
+	}
+	has, err = HasUTxOs(existenceChainContext{}, addr)
+	if err != nil || !has {
+		t.Fatalf("expected UtxoExistenceChecker to be used, got %v, %v", has, err)
+	}
+}
+
+type filterChainContext struct {
+	legacyChainContext
+	utxos []common.Utxo
+}
+
+func (c filterChainContext) Utxos(common.Address) ([]common.Utxo, error) { return c.utxos, nil }
+
+func TestUtxosWithDatumAndScriptRef(t *testing.T) {
+	optCbor, err := cbor.Encode([]any{0, common.Blake2b256{0x01}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opt babbage.BabbageTransactionOutputDatumOption
+	if err := opt.UnmarshalCBOR(optCbor); err != nil {
+		t.Fatal(err)
+	}
+	plain := babbage.BabbageTransactionOutput{}
+	withDatum := babbage.BabbageTransactionOutput{DatumOption: &opt}
+	withScript := babbage.BabbageTransactionOutput{
+		TxOutScriptRef: &common.ScriptRef{
+			Type:   common.ScriptRefTypePlutusV2,
+			Script: common.PlutusV2Script([]byte{0x01}),
+		},
+	}
+	ctx := filterChainContext{utxos: []common.Utxo{
+		{Id: shelley.ShelleyTransactionInput{OutputIndex: 0}, Output: &plain},
+		{Id: shelley.ShelleyTransactionInput{OutputIndex: 1}, Output: &withDatum},
+		{Id: shelley.ShelleyTransactionInput{OutputIndex: 2}, Output: &withScript},
+	}}
+
+	var addr common.Address
+	utxos, err := UtxosWithDatum(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 1 || utxos[0].Id.Index() != 1 {
+		t.Fatalf("expected only the datum UTxO, got %d UTxOs", len(utxos))
+	}
+	utxos, err = UtxosWithScriptRef(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 1 || utxos[0].Id.Index() != 2 {
+		t.Fatalf("expected only the script UTxO, got %d UTxOs", len(utxos))
+	}
+}
+
+type evalChainContext struct {
+	legacyChainContext
+}
+
+func (evalChainContext) EvaluateTx(txCbor []byte, _ []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+	if len(txCbor) == 0 {
+		return nil, errors.New("empty tx")
+	}
+	return map[common.RedeemerKey]common.ExUnits{
+		{Tag: common.RedeemerTagSpend, Index: 0}: {Memory: uint64(txCbor[0]), Steps: 1},
+	}, nil
+}
+
+type batchChainContext struct {
+	legacyChainContext
+}
+
+func (batchChainContext) EvaluateBatch(txCbors [][]byte) ([]map[common.RedeemerKey]common.ExUnits, error) {
+	return make([]map[common.RedeemerKey]common.ExUnits, len(txCbors)), nil
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	txs := [][]byte{{1}, {2}, {3}, {4}, {5}}
+	results, err := EvaluateBatch(evalChainContext{}, txs, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := common.RedeemerKey{Tag: common.RedeemerTagSpend, Index: 0}
+	for i, result := range results {
+		if got := result[key].Memory; got != uint64(txs[i][0]) {
+			t.Errorf("result %d: expected memory %d, got %d", i, txs[i][0], got)
+		}
+	}
+
+	_, err = EvaluateBatch(evalChainContext{}, [][]byte{{1}, nil, nil}, 0)
+	if err == nil || !strings.Contains(err.Error(), "tx 1") {
+		t.Fatalf("expected error for tx 1, got %v", err)
+	}
+
+	results, err = EvaluateBatch(batchChainContext{}, txs, 0)
+	if err != nil || len(results) != len(txs) {
+		t.Fatalf("expected BatchEvaluator to be used, got %d results, %v", len(results), err)
+	}
+}
+
+func TestScriptRefFromHashDetectsPlutusV4(t *testing.T) {
+	script := common.PlutusV4Script([]byte{0x49, 0x48, 0x01, 0x00})
+	ref, err := ScriptRefFromHash(script.Hash(), script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Type != common.ScriptRefTypePlutusV4 {
+		t.Fatalf("script ref type = %d, want %d", ref.Type, common.ScriptRefTypePlutusV4)
+	}
+	if _, ok := ref.Script.(common.PlutusV4Script); !ok {
+		t.Fatalf("expected PlutusV4 script, got %T", ref.Script)
+	}
+}
+
+func TestDatumFromCbor(t *testing.T) {
+	datumCbor := []byte{0x18, 0x2a}
+	datumHash := common.Blake2b256Hash(datumCbor)
+	datum, err := DatumFromCbor(datumHash, datumCbor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := cbor.Encode(datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(encoded) != "182a" {
+		t.Errorf("expected the datum to round-trip, got %x", encoded)
+	}
+	if _, err := DatumFromCbor(common.Blake2b256{0x01}, datumCbor); err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Errorf("expected a hash mismatch error, got %v", err)
+	}
+	if _, err := DatumFromCbor(datumHash, nil); err == nil {
+		t.Error("expected an error for an empty datum")
+	}
+}
@@ -2,6 +2,7 @@ package blockfrost
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -10,6 +11,7 @@ import (
 	"math"
 	"math/big"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -31,7 +33,14 @@ type BlockFrostChainContext struct {
 	projectId string
 	networkId uint8
 	client    *http.Client
+	// ctx bounds every request; nil means context.Background().
+	ctx   context.Context
+	cache *paramsCache
+}
 
+// paramsCache holds the protocol and genesis parameters shared by a context
+// and the copies WithContext makes of it.
+type paramsCache struct {
 	mu             sync.Mutex
 	cachedParams   *backend.ProtocolParameters
 	cachedGenesis  *backend.GenesisParameters
@@ -66,13 +75,45 @@ func NewBlockFrostChainContext(baseUrl string, networkId uint8, projectId string
 		baseUrl:   baseUrl,
 		projectId: projectId,
 		networkId: networkId,
-		client:    &http.Client{Timeout: 30 * time.Second},
+		client:    newDefaultHTTPClient(),
+		cache:     &paramsCache{},
 	}
 }
 
+// WithContext returns a copy of the context whose requests are made with ctx,
+// so they stop when it is cancelled or its deadline passes. The copy shares
+// the parameter caches. It implements backend.ContextBinder.
+func (b *BlockFrostChainContext) WithContext(ctx context.Context) backend.ChainContext {
+	bound := *b
+	bound.ctx = ctx
+	return &bound
+}
+
+func (b *BlockFrostChainContext) requestContext() context.Context {
+	if b.ctx == nil {
+		return context.Background()
+	}
+	return b.ctx
+}
+
+func newDefaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// SetHTTPClient replaces the HTTP client used for BlockFrost requests, for
+// example to configure timeouts, proxies, TLS, or tracing round-trippers. A
+// nil client restores the default client with a 30 second timeout. It must be
+// called before the context is used concurrently.
+func (b *BlockFrostChainContext) SetHTTPClient(client *http.Client) {
+	if client == nil {
+		client = newDefaultHTTPClient()
+	}
+	b.client = client
+}
+
 func (b *BlockFrostChainContext) request(method, path string, body io.Reader, contentType string) ([]byte, error) {
 	url := b.baseUrl + path
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(b.requestContext(), method, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -102,15 +143,32 @@ func (b *BlockFrostChainContext) request(method, path string, body io.Reader, co
 		if len(snippet) > maxBlockfrostErrorSnippetSize {
 			snippet = snippet[:maxBlockfrostErrorSnippetSize]
 		}
-		return nil, fmt.Errorf("blockfrost API error %d: %s", resp.StatusCode, string(snippet))
+		return nil, &apiError{statusCode: resp.StatusCode, body: string(snippet)}
 	}
 	return data, nil
 }
 
+// apiError is a non-2xx BlockFrost response.
+type apiError struct {
+	statusCode int
+	body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("blockfrost API error %d: %s", e.statusCode, e.body)
+}
+
+// isNotFound reports whether err is a BlockFrost 404, which the API returns
+// for addresses that have never appeared on chain.
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.statusCode == http.StatusNotFound
+}
+
 func (b *BlockFrostChainContext) ProtocolParams() (backend.ProtocolParameters, error) {
-	b.mu.Lock()
-	if b.cachedParams != nil && time.Since(b.paramsCacheAt) < cacheExpiry {
-		pp := *b.cachedParams
+	b.cache.mu.Lock()
+	if b.cache.cachedParams != nil && time.Since(b.cache.paramsCacheAt) < cacheExpiry {
+		pp := *b.cache.cachedParams
 		// Deep copy CostModels to prevent callers from mutating the cache.
 		if pp.CostModels != nil {
 			cm := make(map[string][]int64, len(pp.CostModels))
@@ -121,10 +179,10 @@ func (b *BlockFrostChainContext) ProtocolParams() (backend.ProtocolParameters, e
 			}
 			pp.CostModels = cm
 		}
-		b.mu.Unlock()
+		b.cache.mu.Unlock()
 		return pp, nil
 	}
-	b.mu.Unlock()
+	b.cache.mu.Unlock()
 
 	data, err := b.request("GET", "/epochs/latest/parameters", nil, "")
 	if err != nil {
@@ -153,22 +211,22 @@ func (b *BlockFrostChainContext) ProtocolParams() (backend.ProtocolParameters, e
 		cached.CostModels = cm
 	}
 
-	b.mu.Lock()
-	b.cachedParams = &cached
-	b.paramsCacheAt = time.Now()
-	b.mu.Unlock()
+	b.cache.mu.Lock()
+	b.cache.cachedParams = &cached
+	b.cache.paramsCacheAt = time.Now()
+	b.cache.mu.Unlock()
 
 	return pp, nil
 }
 
 func (b *BlockFrostChainContext) GenesisParams() (backend.GenesisParameters, error) {
-	b.mu.Lock()
-	if b.cachedGenesis != nil && time.Since(b.genesisCacheAt) < cacheExpiry {
-		gp := *b.cachedGenesis
-		b.mu.Unlock()
+	b.cache.mu.Lock()
+	if b.cache.cachedGenesis != nil && time.Since(b.cache.genesisCacheAt) < cacheExpiry {
+		gp := *b.cache.cachedGenesis
+		b.cache.mu.Unlock()
 		return gp, nil
 	}
-	b.mu.Unlock()
+	b.cache.mu.Unlock()
 
 	data, err := b.request("GET", "/genesis", nil, "")
 	if err != nil {
@@ -186,16 +244,17 @@ func (b *BlockFrostChainContext) GenesisParams() (backend.GenesisParameters, err
 		NetworkMagic:           raw.NetworkMagic,
 		EpochLength:            raw.EpochLength,
 		MaxLovelaceSupply:      strconv.FormatInt(raw.MaxLovelaceSupply, 10),
+		SystemStart:            raw.SystemStart,
 		SlotLength:             raw.SlotLength,
 		SlotsPerKesPeriod:      raw.SlotsPerKesPeriod,
 		MaxKesEvolutions:       raw.MaxKesEvolutions,
 		SecurityParam:          raw.SecurityParam,
 	}
 
-	b.mu.Lock()
-	b.cachedGenesis = &gp
-	b.genesisCacheAt = time.Now()
-	b.mu.Unlock()
+	b.cache.mu.Lock()
+	b.cache.cachedGenesis = &gp
+	b.cache.genesisCacheAt = time.Now()
+	b.cache.mu.Unlock()
 
 	return gp, nil
 }
@@ -246,20 +305,194 @@ func (b *BlockFrostChainContext) Tip() (uint64, error) {
 	return uint64(result.Slot), nil
 }
 
-func (b *BlockFrostChainContext) Utxos(address common.Address) ([]common.Utxo, error) {
+// TxConfirmations looks up the block that included txHash. It implements
+// backend.TxConfirmationProvider.
+func (b *BlockFrostChainContext) TxConfirmations(txHash common.Blake2b256) (*backend.TxConfirmation, error) {
+	data, err := b.request("GET", "/txs/"+hex.EncodeToString(txHash.Bytes()), nil, "")
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var tx struct {
+		BlockHeight int64 `json:"block_height"`
+		Slot        int64 `json:"slot"`
+	}
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return nil, err
+	}
+	data, err = b.request("GET", "/blocks/latest", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var latest struct {
+		Height int64 `json:"height"`
+	}
+	if err := json.Unmarshal(data, &latest); err != nil {
+		return nil, err
+	}
+	if tx.BlockHeight < 0 || tx.Slot < 0 || latest.Height < tx.BlockHeight {
+		return nil, fmt.Errorf("invalid block height %d or slot %d for transaction at tip height %d", tx.BlockHeight, tx.Slot, latest.Height)
+	}
+	return &backend.TxConfirmation{
+		BlockHeight:   uint64(tx.BlockHeight),
+		Slot:          uint64(tx.Slot),
+		Confirmations: uint64(latest.Height-tx.BlockHeight) + 1,
+	}, nil
+}
+
+// EraHistory returns the network's eras from /network/eras, counted from the
+// system start reported by /genesis.
+func (b *BlockFrostChainContext) EraHistory() (*backend.EraHistory, error) {
+	gp, err := b.GenesisParams()
+	if err != nil {
+		return nil, err
+	}
+	if gp.SystemStart <= 0 {
+		return nil, fmt.Errorf("invalid system start: %d", gp.SystemStart)
+	}
+	data, err := b.request("GET", "/network/eras", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var eras []struct {
+		Start struct {
+			Time float64 `json:"time"`
+			Slot uint64  `json:"slot"`
+		} `json:"start"`
+		Parameters struct {
+			SlotLength float64 `json:"slot_length"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(data, &eras); err != nil {
+		return nil, err
+	}
+	history := &backend.EraHistory{SystemStart: time.Unix(gp.SystemStart, 0).UTC()}
+	for _, era := range eras {
+		if era.Start.Time < 0 || era.Parameters.SlotLength <= 0 {
+			return nil, fmt.Errorf("invalid era starting at slot %d", era.Start.Slot)
+		}
+		history.Eras = append(history.Eras, backend.EraSummary{
+			StartSlot:  era.Start.Slot,
+			StartTime:  time.Duration(era.Start.Time * float64(time.Second)),
+			SlotLength: time.Duration(era.Parameters.SlotLength * float64(time.Second)),
+		})
+	}
+	return history, nil
+}
+
+// RewardBalance returns the withdrawable rewards of a registered stake account.
+func (b *BlockFrostChainContext) RewardBalance(rewardAddress common.Address) (uint64, error) {
+	data, err := b.request("GET", "/accounts/"+rewardAddress.String(), nil, "")
+	if err != nil {
+		return 0, err
+	}
+	var result struct {
+		WithdrawableAmount string `json:"withdrawable_amount"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return 0, err
+	}
+	amount, err := strconv.ParseUint(result.WithdrawableAmount, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid withdrawable amount %q: %w", result.WithdrawableAmount, err)
+	}
+	return amount, nil
+}
+
+// AddressTransactions returns the transactions touching address in slots at
+// or after fromSlot, oldest first. History is paged newest first so paging
+// stops at fromSlot. Slots are derived from the block times the endpoint
+// returns, using the era history, so no block is looked up.
+func (b *BlockFrostChainContext) AddressTransactions(address common.Address, fromSlot uint64) ([]backend.TxSummary, error) {
 	const maxPages = 1000
-	var allUtxos []common.Utxo
-	resolver := newScriptRefResolver(b)
+	var summaries []backend.TxSummary
+	history, err := b.EraHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get era history: %w", err)
+	}
 
 	for page := 1; page <= maxPages+1; page++ {
-		path := fmt.Sprintf("/addresses/%s/utxos?page=%d", address.String(), page)
+		path := fmt.Sprintf("/addresses/%s/transactions?order=desc&page=%d", address.String(), page)
 		data, err := b.request("GET", path, nil, "")
 		if err != nil {
 			return nil, err
 		}
+		var rawTxs []bfAddressTransaction
+		if err := json.Unmarshal(data, &rawTxs); err != nil {
+			return nil, err
+		}
+		if len(rawTxs) == 0 {
+			break
+		}
+		if page > maxPages {
+			return nil, fmt.Errorf("address transaction pagination exceeded %d pages; results may be incomplete", maxPages)
+		}
+		for _, raw := range rawTxs {
+			if raw.BlockTime > math.MaxInt64 {
+				return nil, fmt.Errorf("block time %d out of range", raw.BlockTime)
+			}
+			slot, err := history.TimeToSlot(time.Unix(int64(raw.BlockTime), 0))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get slot of block %d: %w", raw.BlockHeight, err)
+			}
+			if slot < fromSlot {
+				slices.Reverse(summaries)
+				return summaries, nil
+			}
+			hashBytes, err := hex.DecodeString(raw.TxHash)
+			if err != nil {
+				return nil, err
+			}
+			if len(hashBytes) != common.Blake2b256Size {
+				return nil, fmt.Errorf("invalid tx hash length: expected %d bytes, got %d", common.Blake2b256Size, len(hashBytes))
+			}
+			var txHash common.Blake2b256
+			copy(txHash[:], hashBytes)
+			summaries = append(summaries, backend.TxSummary{
+				TxHash:      txHash,
+				BlockHeight: raw.BlockHeight,
+				BlockTime:   raw.BlockTime,
+				Slot:        slot,
+			})
+		}
+	}
+	slices.Reverse(summaries)
+	return summaries, nil
+}
 
-		var rawUtxos []bfAddressUTxO
-		if err := json.Unmarshal(data, &rawUtxos); err != nil {
+func (b *BlockFrostChainContext) Utxos(address common.Address) ([]common.Utxo, error) {
+	return b.matchingUtxos(address, nil)
+}
+
+// UtxosWithDatum returns the UTxOs at address carrying a datum. BlockFrost
+// cannot filter on datums, but outputs without one are dropped before they
+// are hydrated.
+func (b *BlockFrostChainContext) UtxosWithDatum(address common.Address) ([]common.Utxo, error) {
+	return b.matchingUtxos(address, func(raw bfAddressUTxO) bool {
+		return raw.DataHash != "" || (len(raw.InlineDatum) > 0 && string(raw.InlineDatum) != "null")
+	})
+}
+
+// UtxosWithScriptRef returns the UTxOs at address carrying a reference
+// script, dropping outputs without one before their scripts are fetched.
+func (b *BlockFrostChainContext) UtxosWithScriptRef(address common.Address) ([]common.Utxo, error) {
+	return b.matchingUtxos(address, func(raw bfAddressUTxO) bool {
+		return raw.ReferenceScriptHash != ""
+	})
+}
+
+// matchingUtxos fetches every page of UTxOs at address and hydrates those
+// keep accepts, or all of them when keep is nil.
+func (b *BlockFrostChainContext) matchingUtxos(address common.Address, keep func(bfAddressUTxO) bool) ([]common.Utxo, error) {
+	const maxPages = 1000
+	var allUtxos []common.Utxo
+	resolver := newScriptRefResolver(b)
+
+	for page := 1; page <= maxPages+1; page++ {
+		rawUtxos, err := b.rawUtxoPage(address, page)
+		if err != nil {
 			return nil, err
 		}
 		if len(rawUtxos) == 0 {
@@ -269,6 +502,9 @@ func (b *BlockFrostChainContext) Utxos(address common.Address) ([]common.Utxo, e
 			return nil, fmt.Errorf("UTxO pagination exceeded %d pages; results may be incomplete", maxPages)
 		}
 
+		if keep != nil {
+			rawUtxos = slices.DeleteFunc(rawUtxos, func(raw bfAddressUTxO) bool { return !keep(raw) })
+		}
 		utxos, err := b.hydrateUtxoPage(rawUtxos, address, resolver.resolve)
 		if err != nil {
 			return nil, err
@@ -278,6 +514,52 @@ func (b *BlockFrostChainContext) Utxos(address common.Address) ([]common.Utxo, e
 	return allUtxos, nil
 }
 
+// UtxosPage returns one page of up to 100 UTxOs at address, so callers that
+// need only some of a large UTxO set can stop paging early.
+func (b *BlockFrostChainContext) UtxosPage(address common.Address, page int) ([]common.Utxo, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("invalid UTxO page %d", page)
+	}
+	rawUtxos, err := b.rawUtxoPage(address, page)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawUtxos) == 0 {
+		return nil, nil
+	}
+	return b.hydrateUtxoPage(rawUtxos, address, newScriptRefResolver(b).resolve)
+}
+
+func (b *BlockFrostChainContext) rawUtxoPage(address common.Address, page int) ([]bfAddressUTxO, error) {
+	path := fmt.Sprintf("/addresses/%s/utxos?page=%d", address.String(), page)
+	data, err := b.request("GET", path, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var rawUtxos []bfAddressUTxO
+	if err := json.Unmarshal(data, &rawUtxos); err != nil {
+		return nil, err
+	}
+	return rawUtxos, nil
+}
+
+// HasUTxOs reports whether address holds at least one UTxO by fetching a
+// single-entry page instead of the full UTxO set.
+func (b *BlockFrostChainContext) HasUTxOs(address common.Address) (bool, error) {
+	data, err := b.request("GET", fmt.Sprintf("/addresses/%s/utxos?count=1&page=1", address.String()), nil, "")
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	var rawUtxos []json.RawMessage
+	if err := json.Unmarshal(data, &rawUtxos); err != nil {
+		return false, err
+	}
+	return len(rawUtxos) > 0, nil
+}
+
 func (b *BlockFrostChainContext) SubmitTx(txCbor []byte) (common.Blake2b256, error) {
 	body := bytes.NewReader(txCbor)
 	data, err := b.request("POST", "/tx/submit", body, "application/cbor")
@@ -784,6 +1066,27 @@ func (b *BlockFrostChainContext) ScriptCbor(scriptHash common.Blake2b224) ([]byt
 	return scriptCbor, nil
 }
 
+// DatumByHash fetches the datum with the given hash. It implements
+// backend.DatumResolver.
+func (b *BlockFrostChainContext) DatumByHash(datumHash common.Blake2b256) (*common.Datum, error) {
+	path := fmt.Sprintf("/scripts/datum/%s/cbor", hex.EncodeToString(datumHash.Bytes()))
+	data, err := b.request("GET", path, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Cbor string `json:"cbor"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	datumCbor, err := hex.DecodeString(result.Cbor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid datum CBOR hex: %w", err)
+	}
+	return backend.DatumFromCbor(datumHash, datumCbor)
+}
+
 // --- BlockFrost evaluate-with-utxos request types ---
 //
 // /utils/txs/evaluate/utxos accepts resolved additional UTxOs as [txIn, txOut]
@@ -970,12 +1273,19 @@ type bfGenesisParams struct {
 	NetworkMagic           int     `json:"network_magic"`
 	EpochLength            int     `json:"epoch_length"`
 	MaxLovelaceSupply      int64   `json:"max_lovelace_supply,string"`
+	SystemStart            int64   `json:"system_start"`
 	SlotLength             int     `json:"slot_length"`
 	SlotsPerKesPeriod      int     `json:"slots_per_kes_period"`
 	MaxKesEvolutions       int     `json:"max_kes_evolutions"`
 	SecurityParam          int     `json:"security_param"`
 }
 
+type bfAddressTransaction struct {
+	TxHash      string `json:"tx_hash"`
+	BlockHeight uint64 `json:"block_height"`
+	BlockTime   uint64 `json:"block_time"`
+}
+
 type bfAddressUTxO struct {
 	TxHash              string            `json:"tx_hash"`
 	OutputIndex         int               `json:"output_index"`
@@ -1251,47 +1561,5 @@ func (b *BlockFrostChainContext) scriptRefByHash(hashHex string) (*common.Script
 	if err != nil {
 		return nil, err
 	}
-	return scriptRefFromHash(scriptHash, scriptCbor)
-}
-
-func scriptRefFromHash(
-	scriptHash common.Blake2b224,
-	scriptCbor []byte,
-) (*common.ScriptRef, error) {
-	var native common.NativeScript
-	if _, err := cbor.Decode(scriptCbor, &native); err == nil && native.Hash() == scriptHash {
-		return &common.ScriptRef{
-			Type:   common.ScriptRefTypeNativeScript,
-			Script: native,
-		}, nil
-	}
-	v1 := common.PlutusV1Script(scriptCbor)
-	if v1.Hash() == scriptHash {
-		return &common.ScriptRef{
-			Type:   common.ScriptRefTypePlutusV1,
-			Script: v1,
-		}, nil
-	}
-	v2 := common.PlutusV2Script(scriptCbor)
-	if v2.Hash() == scriptHash {
-		return &common.ScriptRef{
-			Type:   common.ScriptRefTypePlutusV2,
-			Script: v2,
-		}, nil
-	}
-	v3 := common.PlutusV3Script(scriptCbor)
-	if v3.Hash() == scriptHash {
-		return &common.ScriptRef{
-			Type:   common.ScriptRefTypePlutusV3,
-			Script: v3,
-		}, nil
-	}
-	v4 := common.PlutusV4Script(scriptCbor)
-	if v4.Hash() == scriptHash {
-		return &common.ScriptRef{
-			Type:   common.ScriptRefTypePlutusV4,
-			Script: v4,
-		}, nil
-	}
-	return nil, errors.New("unable to determine reference script language from script hash")
+	return backend.ScriptRefFromHash(scriptHash, scriptCbor)
 }
@@ -2,6 +2,7 @@ package blockfrost
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -35,6 +36,228 @@ func TestBlockfrostCapabilities(t *testing.T) {
 	}
 }
 
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSetHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"slot":42}`))
+	}))
+	defer server.Close()
+
+	ctx := NewBlockFrostChainContext(server.URL, 0, "")
+	transport := &countingTransport{}
+	ctx.SetHTTPClient(&http.Client{Transport: transport})
+	tip, err := ctx.Tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip != 42 || transport.requests.Load() != 1 {
+		t.Fatalf("tip = %d with %d requests through the custom client, want 42 with 1", tip, transport.requests.Load())
+	}
+
+	ctx.SetHTTPClient(nil)
+	if ctx.client == nil || ctx.client.Timeout != 30*time.Second {
+		t.Fatalf("expected nil client to restore the default, got %+v", ctx.client)
+	}
+}
+
+func TestAddressTransactionsStopsAtFromSlot(t *testing.T) {
+	addr := testAddress(t)
+	newer := strings.Repeat("bb", 32)
+	older := strings.Repeat("aa", 32)
+	oldest := strings.Repeat("99", 32)
+	var blockLookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/addresses/" + addr.String() + "/transactions":
+			if r.URL.Query().Get("order") != "desc" {
+				http.Error(w, "expected descending order", http.StatusBadRequest)
+				return
+			}
+			switch r.URL.Query().Get("page") {
+			case "1":
+				_, _ = w.Write([]byte(`[{"tx_hash":"` + newer + `","block_height":12,"block_time":1700000200},` +
+					`{"tx_hash":"` + older + `","block_height":11,"block_time":1700000100}]`))
+			case "2":
+				_, _ = w.Write([]byte(`[{"tx_hash":"` + oldest + `","block_height":10,"block_time":1700000000}]`))
+			default:
+				_, _ = w.Write([]byte(`[]`))
+			}
+		case "/api/v0/genesis":
+			_, _ = w.Write([]byte(`{"network_magic":2,"system_start":1699995000,"slot_length":1,"max_lovelace_supply":"45000000000000000"}`))
+		case "/api/v0/network/eras":
+			_, _ = w.Write([]byte(`[{"start":{"time":0,"slot":0,"epoch":0},"parameters":{"epoch_length":86400,"slot_length":1}}]`))
+		default:
+			blockLookups.Add(1)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := NewBlockFrostChainContext(server.URL, 0, "")
+	txs, err := ctx.AddressTransactions(addr, 5100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].Slot != 5100 || txs[1].Slot != 5200 {
+		t.Fatalf("expected the two newest transactions oldest first, got %+v", txs)
+	}
+	if hex.EncodeToString(txs[0].TxHash.Bytes()) != older || txs[0].BlockHeight != 11 || txs[0].BlockTime != 1700000100 {
+		t.Fatalf("unexpected summary %+v", txs[0])
+	}
+
+	all, err := ctx.AddressTransactions(addr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || hex.EncodeToString(all[0].TxHash.Bytes()) != oldest {
+		t.Fatalf("expected full history across pages, got %+v", all)
+	}
+	if got := blockLookups.Load(); got != 0 {
+		t.Fatalf("expected slots from block times without block lookups, got %d other requests", got)
+	}
+}
+
+func TestHasUTxOs(t *testing.T) {
+	funded := testAddress(t)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/api/v0/addresses/"+funded.String()+"/utxos" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("count") != "1" {
+			http.Error(w, "expected a single-entry page", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"tx_hash":"` + strings.Repeat("aa", 32) + `","output_index":0}]`))
+	}))
+	defer server.Close()
+
+	ctx := NewBlockFrostChainContext(server.URL, 0, "")
+	has, err := ctx.HasUTxOs(funded)
+	if err != nil || !has {
+		t.Fatalf("expected funded address to have UTxOs, got %v, %v", has, err)
+	}
+	var raw [29]byte
+	raw[0] = 0x60
+	raw[1] = 0xCC
+	unknown, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	has, err = ctx.HasUTxOs(unknown)
+	if err != nil || has {
+		t.Fatalf("expected unknown address to have no UTxOs, got %v, %v", has, err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("expected one request per call, got %d", got)
+	}
+}
+
+func TestTxConfirmations(t *testing.T) {
+	included := strings.Repeat("aa", 32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/txs/" + included:
+			_, _ = w.Write([]byte(`{"hash":"` + included + `","block_height":100,"slot":5000}`))
+		case "/api/v0/blocks/latest":
+			_, _ = w.Write([]byte(`{"height":102,"slot":5060}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var txHash common.Blake2b256
+	for i := range txHash {
+		txHash[i] = 0xaa
+	}
+	ctx := NewBlockFrostChainContext(server.URL, 0, "")
+	conf, err := ctx.TxConfirmations(txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf == nil || conf.BlockHeight != 100 || conf.Slot != 5000 || conf.Confirmations != 3 {
+		t.Fatalf("unexpected confirmation %+v", conf)
+	}
+	conf, err = ctx.TxConfirmations(common.Blake2b256{0x01})
+	if err != nil || conf != nil {
+		t.Fatalf("expected a pending transaction to report nil, got %+v, %v", conf, err)
+	}
+}
+
+func TestEraHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/genesis":
+			_, _ = w.Write([]byte(`{"network_magic":764824073,"system_start":1506203091,"slot_length":1,"max_lovelace_supply":"45000000000000000"}`))
+		case "/api/v0/network/eras":
+			_, _ = w.Write([]byte(`[
+				{"start":{"time":0,"slot":0,"epoch":0},"end":{"time":89856000,"slot":4492800,"epoch":208},"parameters":{"epoch_length":21600,"slot_length":20,"safe_zone":4320}},
+				{"start":{"time":89856000,"slot":4492800,"epoch":208},"end":{"time":101952000,"slot":16588800,"epoch":236},"parameters":{"epoch_length":432000,"slot_length":1,"safe_zone":129600}}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	history, err := NewBlockFrostChainContext(server.URL, 1, "").EraHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !history.SystemStart.Equal(time.Unix(1506203091, 0)) {
+		t.Errorf("unexpected system start %s", history.SystemStart)
+	}
+	want := []backend.EraSummary{
+		{StartSlot: 0, StartTime: 0, SlotLength: 20 * time.Second},
+		{StartSlot: 4492800, StartTime: 89856000 * time.Second, SlotLength: time.Second},
+	}
+	if len(history.Eras) != len(want) {
+		t.Fatalf("expected %d eras, got %+v", len(want), history.Eras)
+	}
+	for i := range want {
+		if history.Eras[i] != want[i] {
+			t.Errorf("era %d = %+v, want %+v", i, history.Eras[i], want[i])
+		}
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"slot":42}`))
+	}))
+	defer server.Close()
+
+	ctx := NewBlockFrostChainContext(server.URL, 0, "")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	bound := ctx.WithContext(cancelled)
+	if _, err := bound.Tip(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the bound context to stop the request, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Error("expected no request to reach the server")
+	}
+	if slot, err := ctx.Tip(); err != nil || slot != 42 {
+		t.Fatalf("expected the original context to be unaffected, got %d, %v", slot, err)
+	}
+	if bound.(*BlockFrostChainContext).cache != ctx.cache {
+		t.Error("expected the bound context to share the parameter caches")
+	}
+}
+
 func testAddress(t *testing.T) common.Address {
 	t.Helper()
 	var raw [57]byte
@@ -151,6 +374,93 @@ func TestUtxosHydratesDuplicateReferenceScriptsOnce(t *testing.T) {
 	}
 }
 
+func TestUtxosWithDatumSkipsScriptHydration(t *testing.T) {
+	addr := testAddress(t)
+	var scriptRequests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/addresses/" + addr.String() + "/utxos":
+			if r.URL.Query().Get("page") == "1" {
+				_ = json.NewEncoder(w).Encode([]bfAddressUTxO{
+					{
+						TxHash:              strings.Repeat("a", 64),
+						OutputIndex:         0,
+						Address:             addr.String(),
+						Amount:              []bfAddressAmount{{Unit: "lovelace", Quantity: "1000000"}},
+						ReferenceScriptHash: strings.Repeat("c", 56),
+					},
+					{
+						TxHash:      strings.Repeat("b", 64),
+						OutputIndex: 1,
+						Address:     addr.String(),
+						Amount:      []bfAddressAmount{{Unit: "lovelace", Quantity: "2000000"}},
+						DataHash:    strings.Repeat("d", 64),
+					},
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]bfAddressUTxO{})
+		default:
+			scriptRequests.Add(1)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := NewBlockFrostChainContext(server.URL, 0, "")
+	utxos, err := ctx.UtxosWithDatum(addr)
+	if err != nil {
+		t.Fatalf("UtxosWithDatum: %v", err)
+	}
+	if len(utxos) != 1 || utxos[0].Id.Index() != 1 || utxos[0].Output.DatumHash() == nil {
+		t.Fatalf("expected only the datum UTxO, got %d UTxOs", len(utxos))
+	}
+	if got := scriptRequests.Load(); got != 0 {
+		t.Fatalf("script requests = %d, want 0", got)
+	}
+}
+
+func TestUtxosPageFetchesOnlyTheRequestedPage(t *testing.T) {
+	addr := testAddress(t)
+	var pages []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/addresses/"+addr.String()+"/utxos" {
+			http.NotFound(w, r)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		if page != "2" {
+			_ = json.NewEncoder(w).Encode([]bfAddressUTxO{})
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]bfAddressUTxO{{
+			TxHash:      strings.Repeat("a", 64),
+			OutputIndex: 3,
+			Address:     addr.String(),
+			Amount:      []bfAddressAmount{{Unit: "lovelace", Quantity: "1000000"}},
+		}})
+	}))
+	defer server.Close()
+
+	ctx := NewBlockFrostChainContext(server.URL, 0, "")
+	utxos, err := ctx.UtxosPage(addr, 2)
+	if err != nil {
+		t.Fatalf("UtxosPage: %v", err)
+	}
+	if len(utxos) != 1 || utxos[0].Id.Index() != 3 {
+		t.Fatalf("expected the page's UTxO, got %d UTxOs", len(utxos))
+	}
+	if utxos, err = ctx.UtxosPage(addr, 3); err != nil || len(utxos) != 0 {
+		t.Fatalf("expected an empty page after the last, got %d UTxOs, err %v", len(utxos), err)
+	}
+	if len(pages) != 2 || pages[0] != "2" || pages[1] != "3" {
+		t.Fatalf("requested pages %v, want [2 3]", pages)
+	}
+}
+
 func TestUtxosHydratesReferenceScriptsConcurrentlyInResponseOrder(t *testing.T) {
 	addr := testAddress(t)
 	const txHash = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
@@ -986,20 +1296,6 @@ func TestBfScriptRefFromScriptLanguageDetection(t *testing.T) {
 	}
 }
 
-func TestScriptRefFromHashDetectsPlutusV4(t *testing.T) {
-	script := common.PlutusV4Script([]byte{0x49, 0x48, 0x01, 0x00})
-	ref, err := scriptRefFromHash(script.Hash(), script)
-	if err != nil {
-		t.Fatal(err)
-	}
-	if ref.Type != common.ScriptRefTypePlutusV4 {
-		t.Fatalf("script ref type = %d, want %d", ref.Type, common.ScriptRefTypePlutusV4)
-	}
-	if _, ok := ref.Script.(common.PlutusV4Script); !ok {
-		t.Fatalf("expected PlutusV4 script, got %T", ref.Script)
-	}
-}
-
 func TestBuildEvalUtxosRequestRejectsOverflowQuantity(t *testing.T) {
 	var txId common.Blake2b256
 	var policyId common.Blake2b224
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -14,6 +16,12 @@ type CachedChainContext struct {
 	inner backend.ChainContext
 	ttl   time.Duration
 
+	// The entries are shared with the contexts WithContext derives, so
+	// binding a request context does not start an empty cache.
+	*entries
+}
+
+type entries struct {
 	mu             sync.Mutex
 	cachedParams   *backend.ProtocolParameters
 	cachedGenesis  *backend.GenesisParameters
@@ -24,8 +32,19 @@ type CachedChainContext struct {
 // NewCachedChainContext creates a new cached wrapper around the given ChainContext.
 func NewCachedChainContext(inner backend.ChainContext, ttl time.Duration) *CachedChainContext {
 	return &CachedChainContext{
-		inner: inner,
-		ttl:   ttl,
+		inner:   inner,
+		ttl:     ttl,
+		entries: &entries{},
+	}
+}
+
+// WithContext binds the wrapped context to ctx when it supports binding. It
+// implements backend.ContextBinder; the returned context shares this cache.
+func (c *CachedChainContext) WithContext(ctx context.Context) backend.ChainContext {
+	return &CachedChainContext{
+		inner:   backend.WithContext(ctx, c.inner),
+		ttl:     c.ttl,
+		entries: c.entries,
 	}
 }
 
@@ -135,3 +154,51 @@ func (c *CachedChainContext) UtxoByRef(txHash common.Blake2b256, index uint32) (
 func (c *CachedChainContext) ScriptCbor(scriptHash common.Blake2b224) ([]byte, error) {
 	return c.inner.ScriptCbor(scriptHash)
 }
+
+// UtxosPage forwards to the wrapped context, which returns every UTxO on the
+// first page when it cannot page.
+func (c *CachedChainContext) UtxosPage(address common.Address, page int) ([]common.Utxo, error) {
+	return backend.UtxosPage(c.inner, address, page)
+}
+
+// DatumByHash forwards to the wrapped context when it implements
+// backend.DatumResolver.
+func (c *CachedChainContext) DatumByHash(datumHash common.Blake2b256) (*common.Datum, error) {
+	resolver, ok := c.inner.(backend.DatumResolver)
+	if !ok {
+		return nil, fmt.Errorf("wrapped chain context cannot resolve datums: %w", backend.ErrUnsupported)
+	}
+	return resolver.DatumByHash(datumHash)
+}
+
+// RewardBalance forwards to the wrapped context when it implements
+// backend.RewardBalanceProvider. Balances change every epoch, so they are
+// never cached.
+func (c *CachedChainContext) RewardBalance(rewardAddress common.Address) (uint64, error) {
+	provider, ok := c.inner.(backend.RewardBalanceProvider)
+	if !ok {
+		return 0, fmt.Errorf("wrapped chain context cannot report reward balances: %w", backend.ErrUnsupported)
+	}
+	return provider.RewardBalance(rewardAddress)
+}
+
+// TxConfirmations forwards to the wrapped context when it implements
+// backend.TxConfirmationProvider. Confirmations change with every block, so
+// they are never cached.
+func (c *CachedChainContext) TxConfirmations(txHash common.Blake2b256) (*backend.TxConfirmation, error) {
+	provider, ok := c.inner.(backend.TxConfirmationProvider)
+	if !ok {
+		return nil, fmt.Errorf("wrapped chain context cannot report transaction confirmations: %w", backend.ErrUnsupported)
+	}
+	return provider.TxConfirmations(txHash)
+}
+
+// EraHistory forwards to the wrapped context when it implements
+// backend.EraHistoryProvider.
+func (c *CachedChainContext) EraHistory() (*backend.EraHistory, error) {
+	provider, ok := c.inner.(backend.EraHistoryProvider)
+	if !ok {
+		return nil, fmt.Errorf("wrapped chain context cannot report its era history: %w", backend.ErrUnsupported)
+	}
+	return provider.EraHistory()
+}
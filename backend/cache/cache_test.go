@@ -1,12 +1,48 @@
 package cache
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
 
 	"github.com/Salvionied/apollo/v2/backend"
 	"github.com/Salvionied/apollo/v2/backend/fixed"
 )
 
+// confirmingContext reports every transaction as confirmed in one block, on
+// a chain with one era.
+type confirmingContext struct {
+	*fixed.FixedChainContext
+}
+
+func (confirmingContext) EraHistory() (*backend.EraHistory, error) {
+	return &backend.EraHistory{Eras: []backend.EraSummary{{SlotLength: time.Second}}}, nil
+}
+
+func (confirmingContext) TxConfirmations(common.Blake2b256) (*backend.TxConfirmation, error) {
+	return &backend.TxConfirmation{BlockHeight: 10, Slot: 100, Confirmations: 1}, nil
+}
+
+// bindingContext counts protocol parameter fetches and records the context
+// it is bound to.
+type bindingContext struct {
+	*fixed.FixedChainContext
+	fetches *int
+	bound   context.Context
+}
+
+func (c *bindingContext) WithContext(ctx context.Context) backend.ChainContext {
+	return &bindingContext{FixedChainContext: c.FixedChainContext, fetches: c.fetches, bound: ctx}
+}
+
+func (c *bindingContext) ProtocolParams() (backend.ProtocolParameters, error) {
+	*c.fetches++
+	return c.FixedChainContext.ProtocolParams()
+}
+
 func TestCapabilitiesMatchWrappedContext(t *testing.T) {
 	ctx := NewCachedChainContext(fixed.NewEmptyFixedChainContext(), 0)
 	if !backend.Supports(ctx, backend.CapabilityProtocolParams|backend.CapabilityUtxoByRef) {
@@ -16,3 +52,84 @@ func TestCapabilitiesMatchWrappedContext(t *testing.T) {
 		t.Fatal("cache reported unsupported wrapped capability")
 	}
 }
+
+func TestTxConfirmationsForwardsToWrappedContext(t *testing.T) {
+	ctx := NewCachedChainContext(confirmingContext{fixed.NewEmptyFixedChainContext()}, 0)
+	conf, err := ctx.TxConfirmations(common.Blake2b256{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf == nil || conf.Slot != 100 {
+		t.Fatalf("confirmation = %+v, want the wrapped context's", conf)
+	}
+
+	ctx = NewCachedChainContext(fixed.NewEmptyFixedChainContext(), 0)
+	if _, err := ctx.TxConfirmations(common.Blake2b256{}); !errors.Is(err, backend.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestEraHistoryForwardsToWrappedContext(t *testing.T) {
+	ctx := NewCachedChainContext(confirmingContext{fixed.NewEmptyFixedChainContext()}, 0)
+	history, err := ctx.EraHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history == nil || len(history.Eras) != 1 {
+		t.Fatalf("era history = %+v, want the wrapped context's", history)
+	}
+
+	ctx = NewCachedChainContext(fixed.NewEmptyFixedChainContext(), 0)
+	if _, err := ctx.EraHistory(); !errors.Is(err, backend.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestWithContextBindsWrappedContextAndSharesCache(t *testing.T) {
+	var fetches int
+	ctx := NewCachedChainContext(&bindingContext{FixedChainContext: fixed.NewEmptyFixedChainContext(), fetches: &fetches}, time.Minute)
+	if _, err := ctx.ProtocolParams(); err != nil {
+		t.Fatal(err)
+	}
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bound, ok := backend.WithContext(reqCtx, ctx).(*CachedChainContext)
+	if !ok {
+		t.Fatal("expected the cache to bind itself")
+	}
+	if inner, ok := bound.inner.(*bindingContext); !ok || inner.bound != reqCtx {
+		t.Fatal("expected the wrapped context to be bound to the request context")
+	}
+	if _, err := bound.ProtocolParams(); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 {
+		t.Fatalf("protocol parameters fetched %d times, want 1 from the shared cache", fetches)
+	}
+}
+
+func TestRewardBalanceForwardsToWrappedContext(t *testing.T) {
+	inner := fixed.NewEmptyFixedChainContext()
+	var raw [29]byte
+	raw[0] = 0xe0
+	raw[1] = 0x01
+	rewardAddr, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner.SetRewardBalance(rewardAddr, 5_000_000)
+	balance, err := NewCachedChainContext(inner, 0).RewardBalance(rewardAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 5_000_000 {
+		t.Fatalf("reward balance = %d, want 5000000", balance)
+	}
+
+	// Embedding the interface hides the fixed context's RewardBalance.
+	plain := struct{ backend.ChainContext }{inner}
+	if _, err := NewCachedChainContext(plain, 0).RewardBalance(rewardAddr); !errors.Is(err, backend.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
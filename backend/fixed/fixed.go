@@ -3,10 +3,13 @@ package fixed
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"maps"
 	"math/big"
 	"strconv"
 	"sync"
 
+	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 
 	"github.com/Salvionied/apollo/v2/backend"
@@ -21,16 +24,31 @@ type FixedChainContext struct {
 	mu             sync.RWMutex
 	utxos          map[string][]common.Utxo // keyed by address string
 	utxosByRef     map[string]common.Utxo   // keyed by "txid#index"
+	rewards        map[string]uint64        // keyed by reward address string
+	datums         map[common.Blake2b256]common.Datum
+	evalFunc       EvalFunc
+	utxoPageSize   int
 }
 
+// EvalFunc stubs script evaluation for EvaluateTx.
+type EvalFunc func(txCbor []byte, additionalUtxos []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error)
+
 // Capabilities reports the deterministic in-memory operations provided by the
-// fixed test context. It intentionally does not pretend to be a chain node.
+// fixed test context. It intentionally does not pretend to be a chain node;
+// evaluation is reported only once a stub is set with SetEvalResult or
+// SetEvalFunc.
 func (f *FixedChainContext) Capabilities() backend.CapabilitySet {
-	return backend.CapabilitySet(backend.CapabilityProtocolParams |
+	caps := backend.CapabilitySet(backend.CapabilityProtocolParams |
 		backend.CapabilityGenesisParams |
 		backend.CapabilityMaxTxFee |
 		backend.CapabilityUtxos |
 		backend.CapabilityUtxoByRef)
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.evalFunc != nil {
+		caps |= backend.CapabilitySet(backend.CapabilityEvaluateTx | backend.CapabilityEvaluateTxAdditionalUtxos)
+	}
+	return caps
 }
 
 // NewFixedChainContext creates a new FixedChainContext with the given protocol parameters.
@@ -41,6 +59,8 @@ func NewFixedChainContext(pp backend.ProtocolParameters, gp backend.GenesisParam
 		networkId:      networkId,
 		utxos:          make(map[string][]common.Utxo),
 		utxosByRef:     make(map[string]common.Utxo),
+		rewards:        make(map[string]uint64),
+		datums:         make(map[common.Blake2b256]common.Datum),
 	}
 }
 
@@ -89,6 +109,26 @@ func (f *FixedChainContext) AddUtxoByRef(utxo common.Utxo) {
 	f.utxosByRef[utxoRefKey(utxo.Id.Id(), utxo.Id.Index())] = utxo
 }
 
+// SetRewardBalance sets the withdrawable reward balance reported for a reward
+// address by RewardBalance.
+func (f *FixedChainContext) SetRewardBalance(rewardAddr common.Address, lovelace uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rewards[rewardAddr.String()] = lovelace
+}
+
+// AddDatum registers a datum for resolution by its hash (DatumByHash).
+func (f *FixedChainContext) AddDatum(datum common.Datum) error {
+	datumCbor, err := cbor.Encode(&datum)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.datums[common.Blake2b256Hash(datumCbor)] = datum
+	return nil
+}
+
 func utxoRefKey(txHash common.Blake2b256, index uint32) string {
 	return hex.EncodeToString(txHash.Bytes()) + "#" + strconv.Itoa(int(index))
 }
@@ -152,12 +192,64 @@ func (f *FixedChainContext) Utxos(address common.Address) ([]common.Utxo, error)
 	return result, nil
 }
 
+// SetUtxoPageSize sets how many UTxOs UtxosPage returns per page. A size of
+// zero or less, the default, puts every UTxO of an address on the first page.
+func (f *FixedChainContext) SetUtxoPageSize(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.utxoPageSize = n
+}
+
+// UtxosPage returns one page of the UTxOs at address, in the order they were
+// added, with pages sized by SetUtxoPageSize.
+func (f *FixedChainContext) UtxosPage(address common.Address, page int) ([]common.Utxo, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("invalid UTxO page %d", page)
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	src := f.utxos[address.String()]
+	size := f.utxoPageSize
+	if size <= 0 {
+		size = max(len(src), 1)
+	}
+	start := (page - 1) * size
+	if start >= len(src) {
+		return nil, nil
+	}
+	end := min(start+size, len(src))
+	return append([]common.Utxo(nil), src[start:end]...), nil
+}
+
 func (f *FixedChainContext) SubmitTx(_ []byte) (common.Blake2b256, error) {
 	return common.Blake2b256{}, backend.NewUnsupportedError("fixed chain context", backend.CapabilitySubmitTx)
 }
 
-func (f *FixedChainContext) EvaluateTx(_ []byte, _ []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
-	return nil, backend.NewUnsupportedError("fixed chain context", backend.CapabilityEvaluateTx)
+// SetEvalResult makes EvaluateTx return a copy of result for every
+// transaction, so tests can exercise ExUnit estimation deterministically.
+func (f *FixedChainContext) SetEvalResult(result map[common.RedeemerKey]common.ExUnits) {
+	stub := maps.Clone(result)
+	f.SetEvalFunc(func([]byte, []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+		return maps.Clone(stub), nil
+	})
+}
+
+// SetEvalFunc makes EvaluateTx delegate to fn. A nil fn restores the default,
+// under which evaluation is unsupported.
+func (f *FixedChainContext) SetEvalFunc(fn EvalFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.evalFunc = fn
+}
+
+func (f *FixedChainContext) EvaluateTx(txCbor []byte, additionalUtxos []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+	f.mu.RLock()
+	fn := f.evalFunc
+	f.mu.RUnlock()
+	if fn == nil {
+		return nil, backend.NewUnsupportedError("fixed chain context", backend.CapabilityEvaluateTx)
+	}
+	return fn(txCbor, additionalUtxos)
 }
 
 func (f *FixedChainContext) UtxoByRef(txHash common.Blake2b256, index uint32) (*common.Utxo, error) {
@@ -173,3 +265,21 @@ func (f *FixedChainContext) UtxoByRef(txHash common.Blake2b256, index uint32) (*
 func (f *FixedChainContext) ScriptCbor(_ common.Blake2b224) ([]byte, error) {
 	return nil, backend.NewUnsupportedError("fixed chain context", backend.CapabilityScriptCbor)
 }
+
+// DatumByHash returns a datum registered with AddDatum.
+func (f *FixedChainContext) DatumByHash(datumHash common.Blake2b256) (*common.Datum, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if datum, ok := f.datums[datumHash]; ok {
+		return &datum, nil
+	}
+	return nil, errors.New("datum not found in fixed chain context")
+}
+
+// RewardBalance returns the balance set with SetRewardBalance, or zero for
+// reward addresses that have none.
+func (f *FixedChainContext) RewardBalance(rewardAddr common.Address) (uint64, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.rewards[rewardAddr.String()], nil
+}
@@ -58,3 +58,36 @@ func TestFixedCapabilitiesAndUnsupportedOperations(t *testing.T) {
 		})
 	}
 }
+
+func TestSetEvalResultAndFunc(t *testing.T) {
+	ctx := NewEmptyFixedChainContext()
+	key := common.RedeemerKey{Tag: common.RedeemerTagMint, Index: 0}
+	ctx.SetEvalResult(map[common.RedeemerKey]common.ExUnits{key: {Memory: 10, Steps: 20}})
+	if !backend.Supports(ctx, backend.CapabilityEvaluateTx) {
+		t.Fatal("expected evaluation to be reported once stubbed")
+	}
+	result, err := ctx.EvaluateTx(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result[key].Memory != 10 || result[key].Steps != 20 {
+		t.Fatalf("unexpected eval result %+v", result)
+	}
+	result[key] = common.ExUnits{}
+	if again, _ := ctx.EvaluateTx(nil, nil); again[key].Memory != 10 {
+		t.Fatal("mutating a returned result must not change the stub")
+	}
+
+	stubErr := errors.New("script failed")
+	ctx.SetEvalFunc(func([]byte, []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+		return nil, stubErr
+	})
+	if _, err := ctx.EvaluateTx(nil, nil); !errors.Is(err, stubErr) {
+		t.Fatalf("expected stub error, got %v", err)
+	}
+
+	ctx.SetEvalFunc(nil)
+	if _, err := ctx.EvaluateTx(nil, nil); !errors.Is(err, backend.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported after clearing the stub, got %v", err)
+	}
+}
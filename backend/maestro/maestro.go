@@ -470,6 +470,103 @@ func (m *MaestroChainContext) ScriptCbor(scriptHash common.Blake2b224) ([]byte,
 	return hex.DecodeString(resp.Data.Bytes)
 }
 
+// DatumByHash fetches the datum with the given hash. It implements
+// backend.DatumResolver.
+func (m *MaestroChainContext) DatumByHash(datumHash common.Blake2b256) (*common.Datum, error) {
+	var resp struct {
+		Data struct {
+			Bytes string `json:"bytes"`
+		} `json:"data"`
+	}
+	found, err := m.getJSON("/datums/"+hex.EncodeToString(datumHash.Bytes()), &resp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("datum %s not found", datumHash.String())
+	}
+	datumCbor, err := hex.DecodeString(resp.Data.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid datum CBOR hex: %w", err)
+	}
+	return backend.DatumFromCbor(datumHash, datumCbor)
+}
+
+// TxConfirmations looks up the block that included txHash. It implements
+// backend.TxConfirmationProvider.
+func (m *MaestroChainContext) TxConfirmations(txHash common.Blake2b256) (*backend.TxConfirmation, error) {
+	var tx struct {
+		Data struct {
+			BlockHeight uint64 `json:"block_height"`
+			Slot        uint64 `json:"block_absolute_slot"`
+		} `json:"data"`
+	}
+	found, err := m.getJSON("/transactions/"+hex.EncodeToString(txHash.Bytes()), &tx)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	var tip struct {
+		Data struct {
+			Height uint64 `json:"height"`
+		} `json:"data"`
+	}
+	found, err = m.getJSON("/chain-tip", &tip)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("maestro chain tip not found")
+	}
+	if tip.Data.Height < tx.Data.BlockHeight {
+		return nil, fmt.Errorf("invalid block height %d for transaction at tip height %d", tx.Data.BlockHeight, tip.Data.Height)
+	}
+	return &backend.TxConfirmation{
+		BlockHeight:   tx.Data.BlockHeight,
+		Slot:          tx.Data.Slot,
+		Confirmations: tip.Data.Height - tx.Data.BlockHeight + 1,
+	}, nil
+}
+
+// getJSON issues a raw GET to path, reusing the SDK client's base URL, API
+// key and HTTP client, and decodes the JSON response into out. It reports
+// false for a 404, so callers can tell a missing resource from a failure.
+func (m *MaestroChainContext) getJSON(path string, out any) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, m.client.BaseUrl+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("api-key", m.apiKey)
+
+	httpClient := m.client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, err := io.ReadAll(io.LimitReader(resp.Body, maxMaestroEvaluateErrorResponseBytes))
+		if err != nil {
+			return false, fmt.Errorf("failed to read maestro error response with status %d: %w", resp.StatusCode, err)
+		}
+		return false, fmt.Errorf("maestro request %s failed with status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode maestro response: %w", err)
+	}
+	return true, nil
+}
+
 func maestroUtxoToCommon(raw models.Utxo, address common.Address) (common.Utxo, error) {
 	hashBytes, err := hex.DecodeString(raw.TxHash)
 	if err != nil {
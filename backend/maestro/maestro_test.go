@@ -292,6 +292,43 @@ func TestSubmitTxPostsCborToTxManager(t *testing.T) {
 	}
 }
 
+func TestDatumByHashFetchesDatumBytes(t *testing.T) {
+	datumCbor := []byte{0x18, 0x2a}
+	datumHash := common.Blake2b256Hash(datumCbor)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/datums/"+hex.EncodeToString(datumHash.Bytes()) {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("api-key") != "project-id" {
+			t.Errorf("api-key = %q, want project-id", r.Header.Get("api-key"))
+		}
+		_, _ = w.Write([]byte(`{"data":{"bytes":"` + hex.EncodeToString(datumCbor) + `","json":{"int":42}}}`))
+	}))
+	defer server.Close()
+
+	ctx, err := NewMaestroChainContextWithNetwork(0, "project-id", "preprod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.client.BaseUrl = server.URL
+
+	datum, err := ctx.DatumByHash(datumHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := cbor.Encode(datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, datumCbor) {
+		t.Fatalf("datum = %x, want %x", got, datumCbor)
+	}
+	if _, err := ctx.DatumByHash(common.Blake2b256{0x01}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
 func TestBuildEvaluateRequest(t *testing.T) {
 	var txId common.Blake2b256
 	idBytes, err := hex.DecodeString(testTxHashHex)
@@ -668,3 +705,39 @@ func TestEvaluationsToExUnitsConvertsResults(t *testing.T) {
 		t.Fatalf("unexpected mint budget %+v", eu)
 	}
 }
+
+func TestTxConfirmationsCountsBlocksFromTip(t *testing.T) {
+	txHash := common.Blake2b256{0xab}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/transactions/" + hex.EncodeToString(txHash.Bytes()):
+			_, _ = w.Write([]byte(`{"data":{"block_height":100,"block_absolute_slot":5000}}`))
+		case "/chain-tip":
+			_, _ = w.Write([]byte(`{"data":{"height":102,"slot":5060}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx, err := NewMaestroChainContextWithNetwork(0, "project-id", "preprod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.client.BaseUrl = server.URL
+
+	conf, err := ctx.TxConfirmations(txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf == nil || conf.BlockHeight != 100 || conf.Slot != 5000 || conf.Confirmations != 3 {
+		t.Fatalf("confirmation = %+v, want height 100, slot 5000, 3 confirmations", conf)
+	}
+	conf, err = ctx.TxConfirmations(common.Blake2b256{0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf != nil {
+		t.Fatalf("expected no confirmation for an unknown transaction, got %+v", conf)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"math"
 	"math/big"
 	"strconv"
+	"time"
 
 	"github.com/SundaeSwap-finance/kugo"
 	ogmigo "github.com/SundaeSwap-finance/ogmigo/v6"
@@ -29,6 +30,8 @@ type OgmiosChainContext struct {
 	ogmios    *ogmigo.Client
 	kupo      *kugo.Client
 	networkId uint8
+	// ctx bounds every request; nil means context.Background().
+	ctx context.Context
 }
 
 // Capabilities reports the operations supported by the configured Ogmios
@@ -51,8 +54,24 @@ func NewOgmiosChainContext(ogmiosClient *ogmigo.Client, kupoClient *kugo.Client,
 	}
 }
 
+// WithContext returns a copy of the context whose Ogmios and Kupo requests
+// are made with ctx, so they stop when it is cancelled or its deadline
+// passes. It implements backend.ContextBinder.
+func (o *OgmiosChainContext) WithContext(ctx context.Context) backend.ChainContext {
+	bound := *o
+	bound.ctx = ctx
+	return &bound
+}
+
+func (o *OgmiosChainContext) requestContext() context.Context {
+	if o.ctx == nil {
+		return context.Background()
+	}
+	return o.ctx
+}
+
 func (o *OgmiosChainContext) ProtocolParams() (backend.ProtocolParameters, error) {
-	ctx := context.Background()
+	ctx := o.requestContext()
 	raw, err := o.ogmios.CurrentProtocolParameters(ctx)
 	if err != nil {
 		return backend.ProtocolParameters{}, err
@@ -67,7 +86,7 @@ func (o *OgmiosChainContext) ProtocolParams() (backend.ProtocolParameters, error
 }
 
 func (o *OgmiosChainContext) GenesisParams() (backend.GenesisParameters, error) {
-	ctx := context.Background()
+	ctx := o.requestContext()
 	raw, err := o.ogmios.GenesisConfig(ctx, "shelley")
 	if err != nil {
 		return backend.GenesisParameters{}, err
@@ -81,12 +100,35 @@ func (o *OgmiosChainContext) GenesisParams() (backend.GenesisParameters, error)
 	return genesis.toGenesisParams(), nil
 }
 
+// EraHistory returns the chain's era summaries, counted from the Shelley
+// genesis start time.
+func (o *OgmiosChainContext) EraHistory() (*backend.EraHistory, error) {
+	gp, err := o.GenesisParams()
+	if err != nil {
+		return nil, err
+	}
+	if gp.SystemStart <= 0 {
+		return nil, errors.New("genesis configuration has no start time")
+	}
+	summaries, err := o.ogmios.EraSummaries(o.requestContext())
+	if err != nil {
+		return nil, err
+	}
+	// Re-encode the summaries to Ogmios's own schema rather than depend on
+	// the client's numeric field types.
+	raw, err := json.Marshal(summaries.Summaries)
+	if err != nil {
+		return nil, err
+	}
+	return eraHistoryFromSummaries(raw, time.Unix(gp.SystemStart, 0).UTC())
+}
+
 func (o *OgmiosChainContext) NetworkId() uint8 {
 	return o.networkId
 }
 
 func (o *OgmiosChainContext) CurrentEpoch() (uint64, error) {
-	ctx := context.Background()
+	ctx := o.requestContext()
 	return o.ogmios.CurrentEpoch(ctx)
 }
 
@@ -99,7 +141,7 @@ func (o *OgmiosChainContext) MaxTxFee() (uint64, error) {
 }
 
 func (o *OgmiosChainContext) Tip() (uint64, error) {
-	ctx := context.Background()
+	ctx := o.requestContext()
 	point, err := o.ogmios.ChainTip(ctx)
 	if err != nil {
 		return 0, err
@@ -112,10 +154,33 @@ func (o *OgmiosChainContext) Tip() (uint64, error) {
 }
 
 func (o *OgmiosChainContext) Utxos(address common.Address) ([]common.Utxo, error) {
+	return o.matchingUtxos(address, nil)
+}
+
+// UtxosWithDatum returns the UTxOs at address carrying a datum. Kupo cannot
+// filter on datums, but matches without one are dropped before their UTxOs
+// are resolved.
+func (o *OgmiosChainContext) UtxosWithDatum(address common.Address) ([]common.Utxo, error) {
+	return o.matchingUtxos(address, func(match kugo.Match) bool {
+		return match.DatumHash != ""
+	})
+}
+
+// UtxosWithScriptRef returns the UTxOs at address carrying a reference
+// script, dropping matches without one before their UTxOs are resolved.
+func (o *OgmiosChainContext) UtxosWithScriptRef(address common.Address) ([]common.Utxo, error) {
+	return o.matchingUtxos(address, func(match kugo.Match) bool {
+		return match.Script.Script != ""
+	})
+}
+
+// matchingUtxos resolves the unspent Kupo matches at address that keep
+// accepts, or all of them when keep is nil.
+func (o *OgmiosChainContext) matchingUtxos(address common.Address, keep func(kugo.Match) bool) ([]common.Utxo, error) {
 	if o.kupo == nil {
 		return nil, backend.NewUnsupportedError("Ogmios without Kupo", backend.CapabilityUtxos)
 	}
-	ctx := context.Background()
+	ctx := o.requestContext()
 	matches, err := o.kupo.Matches(ctx, kugo.OnlyUnspent(), kugo.Address(address.String()))
 	if err != nil {
 		return nil, err
@@ -123,17 +188,41 @@ func (o *OgmiosChainContext) Utxos(address common.Address) ([]common.Utxo, error
 
 	var utxos []common.Utxo
 	for _, match := range matches {
+		if keep != nil && !keep(match) {
+			continue
+		}
 		utxo, err := matchToUtxo(ctx, match, address, o.kupo)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse UTxO match: %w", err)
 		}
+		// A match may name its reference script without carrying it; the
+		// builder needs the script to price the reference-script fee.
+		if match.ScriptHash != "" && utxo.Output.ScriptRef() == nil {
+			if err := o.resolveScriptRef(utxo, match.ScriptHash); err != nil {
+				return nil, err
+			}
+		}
 		utxos = append(utxos, utxo)
 	}
 	return utxos, nil
 }
 
+// HasUTxOs reports whether address holds at least one UTxO. Kupo has no
+// result limit, but the matches are not resolved into UTxOs, which skips the
+// per-output datum lookups Utxos performs.
+func (o *OgmiosChainContext) HasUTxOs(address common.Address) (bool, error) {
+	if o.kupo == nil {
+		return false, backend.NewUnsupportedError("Ogmios without Kupo", backend.CapabilityUtxos)
+	}
+	matches, err := o.kupo.Matches(o.requestContext(), kugo.OnlyUnspent(), kugo.Address(address.String()))
+	if err != nil {
+		return false, err
+	}
+	return len(matches) > 0, nil
+}
+
 func (o *OgmiosChainContext) SubmitTx(txCbor []byte) (common.Blake2b256, error) {
-	ctx := context.Background()
+	ctx := o.requestContext()
 	txHex := hex.EncodeToString(txCbor)
 	resp, err := o.ogmios.SubmitTx(ctx, txHex)
 	if err != nil {
@@ -155,7 +244,7 @@ func (o *OgmiosChainContext) SubmitTx(txCbor []byte) (common.Blake2b256, error)
 }
 
 func (o *OgmiosChainContext) EvaluateTx(txCbor []byte, additionalUtxos []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
-	ctx := context.Background()
+	ctx := o.requestContext()
 	txHex := hex.EncodeToString(txCbor)
 	var resp *ogmigo.EvaluateTxResponse
 	var err error
@@ -176,6 +265,40 @@ func (o *OgmiosChainContext) EvaluateTx(txCbor []byte, additionalUtxos []common.
 	return evaluateResponseToExUnits(resp)
 }
 
+// EncodeAdditionalUtxos encodes resolved UTxOs as the additionalUtxo array of
+// an Ogmios v6 evaluateTransaction request: one object per UTxO with its
+// transaction, index, address and value, an inline datum or datum hash, and
+// any reference script. EvaluateTx sends the same encoding.
+func EncodeAdditionalUtxos(utxos []common.Utxo) ([]byte, error) {
+	items, err := commonUtxosToShared(utxos)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(items)
+}
+
+// DecodeAdditionalUtxos parses an Ogmios v6 additionalUtxo array into UTxOs,
+// the inverse of EncodeAdditionalUtxos.
+func DecodeAdditionalUtxos(data []byte) ([]common.Utxo, error) {
+	var items []shared.Utxo
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse additional UTxOs: %w", err)
+	}
+	utxos := make([]common.Utxo, 0, len(items))
+	for i, item := range items {
+		addr, err := common.NewAddress(item.Address)
+		if err != nil {
+			return nil, fmt.Errorf("additional UTxO %d: invalid address %q: %w", i, item.Address, err)
+		}
+		utxo, err := ogmiosUtxoToCommon(item, addr)
+		if err != nil {
+			return nil, fmt.Errorf("additional UTxO %d: %w", i, err)
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, nil
+}
+
 // commonUtxosToShared converts resolved gouroboros UTxOs into the ogmigo
 // shared.Utxo wire form expected by EvaluateTxWithAdditionalUtxos.
 func commonUtxosToShared(utxos []common.Utxo) ([]shared.Utxo, error) {
@@ -332,7 +455,7 @@ func evaluateResponseToExUnits(resp *ogmigo.EvaluateTxResponse) (map[common.Rede
 }
 
 func (o *OgmiosChainContext) UtxoByRef(txHash common.Blake2b256, index uint32) (*common.Utxo, error) {
-	ctx := context.Background()
+	ctx := o.requestContext()
 	hashHex := hex.EncodeToString(txHash.Bytes())
 	query := chainsync.TxInQuery{
 		Transaction: shared.UtxoTxID{ID: hashHex},
@@ -358,11 +481,39 @@ func (o *OgmiosChainContext) UtxoByRef(txHash common.Blake2b256, index uint32) (
 	return &result, nil
 }
 
+// resolveScriptRef sets the reference script of utxo, fetched from Kupo by
+// its hash.
+func (o *OgmiosChainContext) resolveScriptRef(utxo common.Utxo, hashHex string) error {
+	output, ok := utxo.Output.(*babbage.BabbageTransactionOutput)
+	if !ok {
+		return fmt.Errorf("unexpected UTxO output type: %T", utxo.Output)
+	}
+	hashBytes, err := hex.DecodeString(hashHex)
+	if err != nil {
+		return fmt.Errorf("invalid script hash hex %q: %w", hashHex, err)
+	}
+	if len(hashBytes) != common.Blake2b224Size {
+		return fmt.Errorf("invalid script hash length: expected %d bytes, got %d", common.Blake2b224Size, len(hashBytes))
+	}
+	var scriptHash common.Blake2b224
+	copy(scriptHash[:], hashBytes)
+	scriptCbor, err := o.ScriptCbor(scriptHash)
+	if err != nil {
+		return fmt.Errorf("failed to fetch reference script %s: %w", hashHex, err)
+	}
+	ref, err := backend.ScriptRefFromHash(scriptHash, scriptCbor)
+	if err != nil {
+		return fmt.Errorf("reference script %s: %w", hashHex, err)
+	}
+	output.TxOutScriptRef = ref
+	return nil
+}
+
 func (o *OgmiosChainContext) ScriptCbor(scriptHash common.Blake2b224) ([]byte, error) {
 	if o.kupo == nil {
 		return nil, backend.NewUnsupportedError("Ogmios without Kupo", backend.CapabilityScriptCbor)
 	}
-	ctx := context.Background()
+	ctx := o.requestContext()
 	hashHex := hex.EncodeToString(scriptHash.Bytes())
 	script, err := o.kupo.Script(ctx, hashHex)
 	if err != nil {
@@ -371,6 +522,61 @@ func (o *OgmiosChainContext) ScriptCbor(scriptHash common.Blake2b224) ([]byte, e
 	return hex.DecodeString(script.Script)
 }
 
+// TxConfirmations looks up the outputs of txHash in Kupo. It implements
+// backend.TxConfirmationProvider. Kupo does not index block heights, so
+// BlockHeight is left zero and Confirmations is estimated from the slots
+// elapsed since inclusion and the genesis active slot coefficient; it is
+// never less than one once the transaction is on chain. A transaction whose
+// outputs Kupo does not track, or has pruned, is reported as not on chain.
+func (o *OgmiosChainContext) TxConfirmations(txHash common.Blake2b256) (*backend.TxConfirmation, error) {
+	if o.kupo == nil {
+		return nil, fmt.Errorf("kupo client required to look up transactions: %w", backend.ErrUnsupported)
+	}
+	matches, err := o.kupo.Matches(o.requestContext(), kugo.Transaction(hex.EncodeToString(txHash.Bytes())))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	slot := uint64(matches[0].CreatedAt.SlotNo)
+	tip, err := o.Tip()
+	if err != nil {
+		return nil, err
+	}
+	if tip < slot {
+		return nil, fmt.Errorf("transaction slot %d is past the chain tip %d", slot, tip)
+	}
+	gp, err := o.GenesisParams()
+	if err != nil {
+		return nil, err
+	}
+	if gp.ActiveSlotsCoefficient <= 0 || gp.ActiveSlotsCoefficient > 1 {
+		return nil, fmt.Errorf("invalid active slot coefficient: %v", gp.ActiveSlotsCoefficient)
+	}
+	return &backend.TxConfirmation{
+		Slot:          slot,
+		Confirmations: uint64(float64(tip-slot)*gp.ActiveSlotsCoefficient) + 1,
+	}, nil
+}
+
+// DatumByHash fetches the datum with the given hash from Kupo. It implements
+// backend.DatumResolver.
+func (o *OgmiosChainContext) DatumByHash(datumHash common.Blake2b256) (*common.Datum, error) {
+	if o.kupo == nil {
+		return nil, fmt.Errorf("kupo client required to resolve datums: %w", backend.ErrUnsupported)
+	}
+	datumCborHex, err := o.kupo.Datum(o.requestContext(), hex.EncodeToString(datumHash.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	datumCbor, err := hex.DecodeString(datumCborHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid datum CBOR hex: %w", err)
+	}
+	return backend.DatumFromCbor(datumHash, datumCbor)
+}
+
 // --- Ogmios response types and conversion ---
 
 type ogmiosProtocolParams struct {
@@ -502,6 +708,7 @@ func ogmiosCostModelKey(key string) string {
 
 type ogmiosGenesisConfig struct {
 	NetworkMagic      int     `json:"networkMagic"`
+	StartTime         string  `json:"startTime"`
 	EpochLength       int     `json:"epochLength"`
 	SlotLength        int     `json:"slotLength"`
 	SlotsPerKesPeriod int     `json:"slotsPerKesPeriod"`
@@ -513,12 +720,17 @@ type ogmiosGenesisConfig struct {
 }
 
 func (g *ogmiosGenesisConfig) toGenesisParams() backend.GenesisParameters {
+	var systemStart int64
+	if start, err := time.Parse(time.RFC3339, g.StartTime); err == nil {
+		systemStart = start.Unix()
+	}
 	return backend.GenesisParameters{
 		ActiveSlotsCoefficient: g.ActiveSlots,
 		UpdateQuorum:           g.UpdateQuorum,
 		NetworkMagic:           g.NetworkMagic,
 		EpochLength:            g.EpochLength,
 		MaxLovelaceSupply:      strconv.FormatInt(g.MaxLovelaceSupply, 10),
+		SystemStart:            systemStart,
 		SlotLength:             g.SlotLength,
 		SlotsPerKesPeriod:      g.SlotsPerKesPeriod,
 		MaxKesEvolutions:       g.MaxKesEvolutions,
@@ -526,6 +738,39 @@ func (g *ogmiosGenesisConfig) toGenesisParams() backend.GenesisParameters {
 	}
 }
 
+// eraHistoryFromSummaries decodes the result of Ogmios's
+// queryLedgerState/eraSummaries.
+func eraHistoryFromSummaries(raw []byte, systemStart time.Time) (*backend.EraHistory, error) {
+	var summaries []struct {
+		Start struct {
+			Time struct {
+				Seconds uint64 `json:"seconds"`
+			} `json:"time"`
+			Slot uint64 `json:"slot"`
+		} `json:"start"`
+		Parameters struct {
+			SlotLength struct {
+				Milliseconds uint64 `json:"milliseconds"`
+			} `json:"slotLength"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(raw, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to decode era summaries: %w", err)
+	}
+	history := &backend.EraHistory{SystemStart: systemStart}
+	for _, summary := range summaries {
+		if summary.Parameters.SlotLength.Milliseconds == 0 || summary.Start.Time.Seconds > math.MaxInt64/uint64(time.Second) {
+			return nil, fmt.Errorf("invalid era starting at slot %d", summary.Start.Slot)
+		}
+		history.Eras = append(history.Eras, backend.EraSummary{
+			StartSlot:  summary.Start.Slot,
+			StartTime:  time.Duration(summary.Start.Time.Seconds) * time.Second,                      //nolint:gosec // bounded above
+			SlotLength: time.Duration(summary.Parameters.SlotLength.Milliseconds) * time.Millisecond, //nolint:gosec // slot lengths are small
+		})
+	}
+	return history, nil
+}
+
 // datumFetcher resolves a datum's CBOR (hex-encoded) by its hash. It is
 // implemented by *kugo.Client via the Kupo /v1/datums/{hash} endpoint.
 type datumFetcher interface {
@@ -8,8 +8,10 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/SundaeSwap-finance/kugo"
 	ogmigo "github.com/SundaeSwap-finance/ogmigo/v6"
@@ -539,6 +541,113 @@ func TestCommonUtxoToSharedHashOnlyDatum(t *testing.T) {
 	}
 }
 
+// capturedEvaluateRequest is an Ogmios v6 evaluateTransaction request with an
+// additional UTxO set covering assets with a reference script, an inline datum
+// and a datum hash.
+const capturedEvaluateRequest = `{
+  "jsonrpc": "2.0",
+  "method": "evaluateTransaction",
+  "params": {
+    "transaction": {
+      "cbor": "84a0a0f5f6"
+    },
+    "additionalUtxo": [
+      {
+        "transaction": {
+          "id": "1111111111111111111111111111111111111111111111111111111111111111"
+        },
+        "index": 3,
+        "address": "addr_test1qz4qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq9mqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq5agsrl",
+        "value": {
+          "ada": {
+            "lovelace": 1500000
+          },
+          "abababababababababababababababababababababababababababab": {
+            "544f4b454e": 42
+          }
+        },
+        "script": {
+          "language": "plutus:v2",
+          "cbor": "49480100"
+        }
+      },
+      {
+        "transaction": {
+          "id": "4444444444444444444444444444444444444444444444444444444444444444"
+        },
+        "index": 0,
+        "address": "addr_test1qz4qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq9mqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq5agsrl",
+        "value": {
+          "ada": {
+            "lovelace": 2000000
+          }
+        },
+        "datum": "d87980"
+      },
+      {
+        "transaction": {
+          "id": "5555555555555555555555555555555555555555555555555555555555555555"
+        },
+        "index": 1,
+        "address": "addr_test1qz4qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq9mqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq5agsrl",
+        "value": {
+          "ada": {
+            "lovelace": 3000000
+          }
+        },
+        "datumHash": "efefefefefefefefefefefefefefefefefefefefefefefefefefefefefefefef"
+      }
+    ]
+  },
+  "id": null
+}`
+
+func TestAdditionalUtxosRoundTrip(t *testing.T) {
+	var request struct {
+		Params struct {
+			AdditionalUtxo json.RawMessage `json:"additionalUtxo"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(capturedEvaluateRequest), &request); err != nil {
+		t.Fatal(err)
+	}
+
+	utxos, err := DecodeAdditionalUtxos(request.Params.AdditionalUtxo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 3 {
+		t.Fatalf("decoded %d UTxOs, want 3", len(utxos))
+	}
+	if utxos[0].Id.Index() != 3 || utxos[0].Output.Amount().Uint64() != 1_500_000 {
+		t.Fatalf("first UTxO = %s#%d with %s lovelace", utxos[0].Id.Id(), utxos[0].Id.Index(), utxos[0].Output.Amount())
+	}
+	if utxos[0].Output.ScriptRef() == nil || utxos[0].Output.Assets() == nil {
+		t.Fatal("first UTxO must carry its assets and reference script")
+	}
+	if utxos[1].Output.Datum() == nil {
+		t.Fatal("second UTxO must carry its inline datum")
+	}
+	if hash := utxos[2].Output.DatumHash(); hash == nil || hex.EncodeToString(hash.Bytes()) != strings.Repeat("ef", 32) {
+		t.Fatalf("third UTxO datum hash = %v", hash)
+	}
+
+	encoded, err := EncodeAdditionalUtxos(utxos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want any
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(request.Params.AdditionalUtxo, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("re-encoded additional UTxOs differ from the captured payload:\ngot  %s\nwant %s", encoded, request.Params.AdditionalUtxo)
+	}
+}
+
 func TestOgmiosScriptRefJSONLanguageDetection(t *testing.T) {
 	raw := []byte{0x49, 0x48, 0x01, 0x00}
 	for _, tc := range []struct {
@@ -601,3 +710,50 @@ func TestProtocolParamsPreserveReferenceScriptFeeParameters(t *testing.T) {
 		t.Fatalf("reference-script fee = %d, want %d", got, want)
 	}
 }
+
+func TestEraHistoryFromSummaries(t *testing.T) {
+	raw := []byte(`[
+		{"start":{"time":{"seconds":0},"slot":0,"epoch":0},"end":{"time":{"seconds":89856000},"slot":4492800,"epoch":208},"parameters":{"epochLength":21600,"slotLength":{"milliseconds":20000},"safeZone":4320}},
+		{"start":{"time":{"seconds":89856000},"slot":4492800,"epoch":208},"end":{"time":{"seconds":101952000},"slot":16588800,"epoch":236},"parameters":{"epochLength":432000,"slotLength":{"milliseconds":1000},"safeZone":129600}}
+	]`)
+	start := time.Unix(1506203091, 0)
+	history, err := eraHistoryFromSummaries(raw, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []backend.EraSummary{
+		{StartSlot: 0, StartTime: 0, SlotLength: 20 * time.Second},
+		{StartSlot: 4492800, StartTime: 89856000 * time.Second, SlotLength: time.Second},
+	}
+	if !history.SystemStart.Equal(start) || !reflect.DeepEqual(history.Eras, want) {
+		t.Fatalf("unexpected era history %+v", history)
+	}
+	if _, err := eraHistoryFromSummaries([]byte(`[{"start":{"slot":0},"parameters":{"slotLength":{"milliseconds":0}}}]`), start); err == nil {
+		t.Error("expected an error for a zero slot length")
+	}
+}
+
+func TestTxConfirmationsWithoutMatches(t *testing.T) {
+	if _, err := NewOgmiosChainContext(nil, nil, 0).TxConfirmations(common.Blake2b256{}); !errors.Is(err, backend.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported without Kupo, got %v", err)
+	}
+
+	var gotPattern string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPattern = r.URL.Path
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	ctx := NewOgmiosChainContext(nil, kugo.New(kugo.WithEndpoint(server.URL)), 0)
+
+	conf, err := ctx.TxConfirmations(common.Blake2b256{0xab})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf != nil {
+		t.Fatalf("expected no confirmation for an unknown transaction, got %+v", conf)
+	}
+	if !strings.Contains(gotPattern, "ab00") {
+		t.Fatalf("matches path %q does not name the transaction", gotPattern)
+	}
+}
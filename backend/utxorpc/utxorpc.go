@@ -544,6 +544,74 @@ func (u *UtxoRpcChainContext) ScriptCbor(_ common.Blake2b224) ([]byte, error) {
 	return nil, backend.NewUnsupportedError("UTxO RPC", backend.CapabilityScriptCbor)
 }
 
+// DatumByHash fetches the datum with the given hash. It implements
+// backend.DatumResolver.
+func (u *UtxoRpcChainContext) DatumByHash(datumHash common.Blake2b256) (*common.Datum, error) {
+	req := connect.NewRequest(&query.ReadDataRequest{
+		Keys: [][]byte{datumHash.Bytes()},
+	})
+	u.client.AddHeadersToRequest(req)
+	resp, err := u.client.ReadData(req)
+	if err != nil {
+		return nil, err
+	}
+	return datumFromRpc(datumHash, resp.Msg.GetValues())
+}
+
+// datumFromRpc decodes the datum ReadData returned for datumHash.
+func datumFromRpc(datumHash common.Blake2b256, values []*query.AnyChainDatum) (*common.Datum, error) {
+	for _, value := range values {
+		if datumCbor := value.GetNativeBytes(); len(datumCbor) > 0 {
+			return backend.DatumFromCbor(datumHash, datumCbor)
+		}
+	}
+	return nil, fmt.Errorf("datum %s not found", datumHash.String())
+}
+
+// TxConfirmations looks up the block that included txHash. It implements
+// backend.TxConfirmationProvider.
+func (u *UtxoRpcChainContext) TxConfirmations(txHash common.Blake2b256) (*backend.TxConfirmation, error) {
+	req := connect.NewRequest(&query.ReadTxRequest{
+		Hash: txHash.Bytes(),
+	})
+	u.client.AddHeadersToRequest(req)
+	resp, err := u.client.ReadTx(req)
+	if err != nil {
+		if connect.CodeOf(err) == connect.CodeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	tipReq := connect.NewRequest(&syncpb.ReadTipRequest{})
+	u.client.AddHeadersToRequest(tipReq)
+	tipResp, err := u.client.ReadTip(tipReq)
+	if err != nil {
+		return nil, err
+	}
+	tip := tipResp.Msg.GetTip()
+	if tip == nil {
+		return nil, errors.New("no tip in response")
+	}
+	return confirmationFromRpc(resp.Msg.GetTx(), tip.GetHeight())
+}
+
+// confirmationFromRpc locates the transaction ReadTx returned against the
+// chain tip height. A transaction without a block is not on chain yet.
+func confirmationFromRpc(tx *query.AnyChainTx, tipHeight uint64) (*backend.TxConfirmation, error) {
+	block := tx.GetBlockRef()
+	if block == nil {
+		return nil, nil
+	}
+	if tipHeight < block.GetHeight() {
+		return nil, fmt.Errorf("invalid block height %d for transaction at tip height %d", block.GetHeight(), tipHeight)
+	}
+	return &backend.TxConfirmation{
+		BlockHeight:   block.GetHeight(),
+		Slot:          block.GetSlot(),
+		Confirmations: tipHeight - block.GetHeight() + 1,
+	}, nil
+}
+
 func utxoFromRpc(item *query.AnyUtxoData) (common.Utxo, error) {
 	nativeBytes := item.GetNativeBytes()
 	if len(nativeBytes) == 0 {
@@ -12,6 +12,7 @@ import (
 	"github.com/blinklabs-io/gouroboros/ledger/conway"
 	"github.com/blinklabs-io/plutigo/data"
 	cardano "github.com/utxorpc/go-codegen/utxorpc/v1alpha/cardano"
+	query "github.com/utxorpc/go-codegen/utxorpc/v1alpha/query"
 	submit "github.com/utxorpc/go-codegen/utxorpc/v1alpha/submit"
 
 	"github.com/Salvionied/apollo/v2/backend"
@@ -576,6 +577,45 @@ func TestExpectedRedeemerKeysRejectsMalformedCbor(t *testing.T) {
 	}
 }
 
+func TestDatumFromRpc(t *testing.T) {
+	datumCbor := []byte{0x18, 0x2a}
+	datumHash := common.Blake2b256Hash(datumCbor)
+	datum, err := datumFromRpc(datumHash, []*query.AnyChainDatum{{Key: datumHash.Bytes(), NativeBytes: datumCbor}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := cbor.Encode(datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, datumCbor) {
+		t.Fatalf("datum = %x, want %x", got, datumCbor)
+	}
+	if _, err := datumFromRpc(datumHash, nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if _, err := datumFromRpc(common.Blake2b256{0x01}, []*query.AnyChainDatum{{NativeBytes: datumCbor}}); err == nil {
+		t.Fatal("expected an error for a datum that does not match its hash")
+	}
+}
+
+func TestConfirmationFromRpc(t *testing.T) {
+	tx := &query.AnyChainTx{BlockRef: &query.ChainPoint{Slot: 5000, Height: 100}}
+	conf, err := confirmationFromRpc(tx, 102)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf == nil || conf.BlockHeight != 100 || conf.Slot != 5000 || conf.Confirmations != 3 {
+		t.Fatalf("confirmation = %+v, want height 100, slot 5000, 3 confirmations", conf)
+	}
+	if conf, err := confirmationFromRpc(&query.AnyChainTx{}, 102); err != nil || conf != nil {
+		t.Fatalf("expected no confirmation for a transaction without a block, got %+v, %v", conf, err)
+	}
+	if _, err := confirmationFromRpc(tx, 99); err == nil {
+		t.Fatal("expected an error for a block past the tip")
+	}
+}
+
 func TestEvaluateTxRejectsMalformedTransactionBeforeRequest(t *testing.T) {
 	ctx := &UtxoRpcChainContext{}
 	if _, err := ctx.EvaluateTx([]byte{0xff}, nil); err == nil {
@@ -0,0 +1,53 @@
+package apollo
+
+import (
+	"fmt"
+
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+
+	"github.com/Salvionied/apollo/v2/backend"
+)
+
+// Calculator does the builder's min-ADA, fee and collateral arithmetic from
+// fixed protocol parameters, without a chain context. It shares the formulas
+// Complete uses, so planners and simulators get the same numbers.
+type Calculator struct {
+	pp backend.ProtocolParameters
+}
+
+// NewCalculator creates a calculator for the given protocol parameters. Edit
+// a copy of the parameters to model a different network or a future update.
+func NewCalculator(pp backend.ProtocolParameters) *Calculator {
+	return &Calculator{pp: pp}
+}
+
+// ProtocolParams returns the parameters the calculator uses.
+func (c *Calculator) ProtocolParams() backend.ProtocolParameters {
+	return c.pp
+}
+
+// MinAda returns the minimum lovelace output must hold, sized with the output
+// holding the larger of its own lovelace and that minimum, whose encoding may
+// be wider than the current amount. It agrees with MinLovelaceForOutput.
+func (c *Calculator) MinAda(output babbage.BabbageTransactionOutput) (int64, error) {
+	return minLovelaceHolding(output, c.pp.CoinsPerUtxoByteValue())
+}
+
+// Fee returns the minimum fee of a transaction of txSize bytes whose
+// redeemers use exMem memory and exSteps steps in total and whose spending
+// and reference inputs carry refScriptSize bytes of reference scripts.
+func (c *Calculator) Fee(txSize int, exMem, exSteps int64, refScriptSize int) (int64, error) {
+	if txSize < 0 || exMem < 0 || exSteps < 0 || refScriptSize < 0 {
+		return 0, fmt.Errorf("fee inputs must be non-negative: size=%d mem=%d steps=%d ref script size=%d",
+			txSize, exMem, exSteps, refScriptSize)
+	}
+	return minFee(c.pp, txSize, exMem, exSteps, refScriptSize)
+}
+
+// Collateral returns the total collateral a transaction paying fee must post.
+func (c *Calculator) Collateral(fee int64) (int64, error) {
+	if fee < 0 {
+		return 0, fmt.Errorf("fee must be non-negative, got %d", fee)
+	}
+	return requiredCollateral(fee, c.pp.CollateralPercent)
+}
@@ -0,0 +1,84 @@
+package apollo
+
+import (
+	"testing"
+
+	"github.com/Salvionied/apollo/v2/backend"
+)
+
+func mainnetCalculator() *Calculator {
+	return NewCalculator(backend.ProtocolParameters{
+		MinFeeConstant:             155381,
+		MinFeeCoefficient:          44,
+		CoinsPerUtxoByte:           "4310",
+		PriceMem:                   0.0577,
+		PriceStep:                  0.0000721,
+		CollateralPercent:          150,
+		MinFeeRefScriptCostPerByte: 15,
+	})
+}
+
+func TestCalculatorMinAda(t *testing.T) {
+	c := mainnetCalculator()
+	// An ADA-only output to a 57-byte base address encodes to 67 bytes once it
+	// holds a 5-byte coin: (67 + 160) * 4310 = 978370, whether it holds less
+	// now or more that still fits five bytes.
+	for _, lovelace := range []uint64{0, 1, 50_000_000} {
+		output := NewBabbageOutputSimple(testAddress(t), lovelace)
+		got, err := c.MinAda(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 978_370 {
+			t.Errorf("MinAda with %d lovelace = %d, want 978370", lovelace, got)
+		}
+		// The offline helper agrees.
+		if offline, err := MinLovelaceForOutput(output, 4310); err != nil || offline != got {
+			t.Errorf("MinLovelaceForOutput with %d lovelace = %d, %v, want %d", lovelace, offline, err, got)
+		}
+	}
+}
+
+func TestCalculatorFee(t *testing.T) {
+	c := mainnetCalculator()
+	tests := []struct {
+		name          string
+		size          int
+		mem, steps    int64
+		refScriptSize int
+		want          int64
+	}{
+		// 300 * 44 + 155381.
+		{name: "size only", size: 300, want: 168_581},
+		// ceil(1234567 * 0.0577 + 987654321 * 0.0000721) = 142445.
+		{name: "execution units", size: 300, mem: 1_234_567, steps: 987_654_321, want: 311_026},
+		// 25600 * 15 + 4400 * 15 * 1.2 = 463200.
+		{name: "tiered reference scripts", size: 300, refScriptSize: 30_000, want: 631_781},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := c.Fee(test.size, test.mem, test.steps, test.refScriptSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("fee = %d, want %d", got, test.want)
+			}
+		})
+	}
+	if _, err := c.Fee(-1, 0, 0, 0); err == nil {
+		t.Error("expected an error for a negative size")
+	}
+}
+
+func TestCalculatorCollateral(t *testing.T) {
+	c := mainnetCalculator()
+	// ceil(168581 * 150 / 100) = 252872.
+	got, err := c.Collateral(168_581)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 252_872 {
+		t.Errorf("collateral = %d, want 252872", got)
+	}
+}
@@ -0,0 +1,229 @@
+package apollo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+)
+
+// UTxOsFromBuiltTx returns the outputs of a built transaction as UTxOs keyed
+// by its transaction ID, so they can be spent by a follow-up transaction
+// before the first one is on chain.
+func UTxOsFromBuiltTx(tx *conway.ConwayTransaction) ([]common.Utxo, error) {
+	if tx == nil {
+		return nil, errors.New("transaction not built")
+	}
+	return (&Apollo{tx: tx}).builtUTxOs()
+}
+
+// builtUTxOs returns the outputs of the builder's transaction as UTxOs keyed
+// by builtTxId.
+func (a *Apollo) builtUTxOs() ([]common.Utxo, error) {
+	txId, err := a.builtTxId()
+	if err != nil {
+		return nil, err
+	}
+	utxos := make([]common.Utxo, 0, len(a.tx.Body.TxOutputs))
+	for i := range a.tx.Body.TxOutputs {
+		output := a.tx.Body.TxOutputs[i]
+		utxos = append(utxos, common.Utxo{
+			Id: shelley.ShelleyTransactionInput{
+				TxId:        txId,
+				OutputIndex: uint32(i), //nolint:gosec // output count is bounded by the max tx size
+			},
+			Output: &output,
+		})
+	}
+	return utxos, nil
+}
+
+// builtTxId hashes the body bytes signers hash (txBodyCbor), so a loaded body
+// keeps its original encoding and a body mutated after a previous Id() call
+// does not yield a stale digest.
+func (a *Apollo) builtTxId() (common.Blake2b256, error) {
+	bodyCbor, err := a.txBodyCbor()
+	if err != nil {
+		return common.Blake2b256{}, err
+	}
+	return common.Blake2b256Hash(bodyCbor), nil
+}
+
+// TxChain builds a sequence of dependent transactions. Each transaction may
+// spend the outputs of the transactions before it, and none may spend an input
+// already spent earlier in the chain. The transactions must be submitted in
+// order.
+type TxChain struct {
+	builders []*Apollo
+	txIds    []common.Blake2b256
+	consumed [][]common.Utxo
+}
+
+// NewTxChain creates a chain from builders in submission order. The builders
+// are completed and signed by Build and must not be reused afterwards.
+func NewTxChain(builders ...*Apollo) *TxChain {
+	return &TxChain{builders: builders}
+}
+
+// BuildChain completes and signs builders in order, feeding the outputs of
+// each transaction to the ones after it, and returns the signed transaction
+// CBORs in submission order.
+func BuildChain(builders []*Apollo) ([][]byte, error) {
+	return NewTxChain(builders...).Build()
+}
+
+// Build completes and signs every transaction in the chain. Unspent outputs
+// of earlier transactions that pay to a builder's wallet are added to its
+// coin selection pool, and inputs spent earlier are excluded from it.
+func (c *TxChain) Build() ([][]byte, error) {
+	if len(c.builders) == 0 {
+		return nil, errors.New("transaction chain is empty")
+	}
+	c.txIds = make([]common.Blake2b256, 0, len(c.builders))
+	c.consumed = make([][]common.Utxo, 0, len(c.builders))
+	pending := make(map[string]common.Utxo)
+	var pendingOrder []string
+	spent := make(map[string]bool)
+	produced := make(map[common.Blake2b256]int)
+
+	txCbors := make([][]byte, 0, len(c.builders))
+	for i, b := range c.builders {
+		if b == nil {
+			return nil, fmt.Errorf("chain tx %d: builder is nil", i)
+		}
+		if err := b.prepareChainInputs(spent, pending, pendingOrder); err != nil {
+			return nil, fmt.Errorf("chain tx %d: %w", i, err)
+		}
+		built, err := b.Complete()
+		if err != nil {
+			return nil, fmt.Errorf("chain tx %d: %w", i, err)
+		}
+		built, err = built.Sign()
+		if err != nil {
+			return nil, fmt.Errorf("chain tx %d: %w", i, err)
+		}
+		tx := built.GetTx()
+
+		var consumed []common.Utxo
+		for _, input := range tx.Body.TxInputs.Items() {
+			ref := utxoRef(common.Utxo{Id: input})
+			if spent[ref] {
+				return nil, fmt.Errorf("chain tx %d: input %s is already spent earlier in the chain", i, ref)
+			}
+			spent[ref] = true
+			if utxo, ok := pending[ref]; ok {
+				consumed = append(consumed, utxo)
+				delete(pending, ref)
+				continue
+			}
+			if producer, ok := produced[input.Id()]; ok {
+				return nil, fmt.Errorf("chain tx %d: input %s is not an output of chain tx %d", i, ref, producer)
+			}
+		}
+
+		outputs, err := built.builtUTxOs()
+		if err != nil {
+			return nil, fmt.Errorf("chain tx %d: %w", i, err)
+		}
+		txId, err := built.builtTxId()
+		if err != nil {
+			return nil, fmt.Errorf("chain tx %d: %w", i, err)
+		}
+		if _, ok := produced[txId]; ok {
+			return nil, fmt.Errorf("chain tx %d: duplicates an earlier transaction", i)
+		}
+		produced[txId] = i
+		for _, utxo := range outputs {
+			ref := utxoRef(utxo)
+			pending[ref] = utxo
+			pendingOrder = append(pendingOrder, ref)
+		}
+
+		txCbor, err := built.GetTxCbor()
+		if err != nil {
+			return nil, fmt.Errorf("chain tx %d: %w", i, err)
+		}
+		c.txIds = append(c.txIds, txId)
+		c.consumed = append(c.consumed, consumed)
+		txCbors = append(txCbors, txCbor)
+	}
+	return txCbors, nil
+}
+
+// TxIds returns the IDs of the built transactions in submission order.
+func (c *TxChain) TxIds() []common.Blake2b256 {
+	return append([]common.Blake2b256(nil), c.txIds...)
+}
+
+// Consumed returns the outputs of earlier chain transactions spent by the
+// transaction at index i.
+func (c *TxChain) Consumed(i int) []common.Utxo {
+	if i < 0 || i >= len(c.consumed) {
+		return nil
+	}
+	return append([]common.Utxo(nil), c.consumed[i]...)
+}
+
+// UseOutputsFrom lets the builder spend the outputs of prev, a completed
+// transaction that is not on chain yet, so both can land in the same block.
+// The inputs prev spends are excluded from coin selection and its outputs at
+// the wallet address are offered instead; its other outputs can be spent with
+// CollectFrom after UTxOsFromBuiltTx. Call it after SetWallet, and submit prev
+// first. Use TxChain to build longer sequences.
+func (a *Apollo) UseOutputsFrom(prev *Apollo) *Apollo {
+	if prev == nil || prev.tx == nil {
+		a.setErrOnce(errors.New("UseOutputsFrom: previous transaction not built"))
+		return a
+	}
+	outputs, err := prev.builtUTxOs()
+	if err != nil {
+		a.setErrOnce(fmt.Errorf("UseOutputsFrom: %w", err))
+		return a
+	}
+	spent := make(map[string]bool)
+	for _, input := range prev.tx.Body.TxInputs.Items() {
+		spent[utxoRef(common.Utxo{Id: input})] = true
+	}
+	pending := make(map[string]common.Utxo, len(outputs))
+	pendingOrder := make([]string, 0, len(outputs))
+	for _, utxo := range outputs {
+		ref := utxoRef(utxo)
+		pending[ref] = utxo
+		pendingOrder = append(pendingOrder, ref)
+	}
+	if err := a.prepareChainInputs(spent, pending, pendingOrder); err != nil {
+		a.setErrOnce(fmt.Errorf("UseOutputsFrom: %w", err))
+	}
+	return a
+}
+
+// prepareChainInputs excludes inputs spent earlier in the chain from coin
+// selection and offers the chain's unspent outputs at the wallet address
+// instead; outputs the wallet cannot sign for are never offered. The wallet
+// UTxOs that Complete would otherwise load are loaded first, since adding
+// UTxOs disables that fallback.
+func (a *Apollo) prepareChainInputs(spent map[string]bool, pending map[string]common.Utxo, pendingOrder []string) error {
+	for ref := range spent {
+		a.markUsed(ref)
+	}
+	if a.wallet == nil || len(pending) == 0 {
+		return nil
+	}
+	if len(a.utxos) == 0 && len(a.preselectedUtxos) == 0 && len(a.inputAddresses) == 0 {
+		utxos, err := a.loadAddressUtxos(a.wallet.Address())
+		if err != nil {
+			return fmt.Errorf("failed to load wallet UTxOs: %w", err)
+		}
+		a.utxos = utxos
+	}
+	walletAddr := a.wallet.Address().String()
+	for _, ref := range pendingOrder {
+		utxo, ok := pending[ref]
+		if ok && utxo.Output.Address().String() == walletAddr {
+			a.utxos = append(a.utxos, utxo)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package apollo
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestBuildChainSpendsEarlierOutputs(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, w.Address(), 10_000_000, 0x01, 0)
+
+	first := New(cc).SetWallet(w).PayToAddress(testAddress(t), 3_000_000).SetTtl(50000000)
+	second := New(cc).SetWallet(w).PayToAddress(testAddress(t), 5_000_000).SetTtl(50000000)
+	chain := NewTxChain(first, second)
+	txCbors, err := chain.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txCbors) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(txCbors))
+	}
+
+	txIds := chain.TxIds()
+	consumed := chain.Consumed(1)
+	if len(consumed) != 1 || consumed[0].Id.Id() != txIds[0] {
+		t.Fatalf("expected second tx to spend an output of the first, got %v", consumed)
+	}
+	if len(chain.Consumed(0)) != 0 {
+		t.Error("expected first tx to spend no chain outputs")
+	}
+	for _, input := range second.GetTx().Body.TxInputs.Items() {
+		if input.Id() != txIds[0] {
+			t.Errorf("second tx spends %s, which is not a chain output", input.Id())
+		}
+	}
+
+	outputs, err := UTxOsFromBuiltTx(first.GetTx())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != len(first.GetTx().Body.TxOutputs) || outputs[0].Id.Id() != txIds[0] {
+		t.Fatalf("unexpected outputs from built tx: %v", outputs)
+	}
+}
+
+func TestUseOutputsFrom(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, w.Address(), 10_000_000, 0x01, 0)
+
+	first, err := New(cc).SetWallet(w).PayToAddress(testAddress(t), 3_000_000).SetTtl(50000000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstId := first.GetTx().Id()
+	second, err := New(cc).SetWallet(w).UseOutputsFrom(first).PayToAddress(testAddress(t), 5_000_000).SetTtl(50000000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := second.GetTx().Body.TxInputs.Items()
+	if len(inputs) != 1 || inputs[0].Id() != firstId {
+		t.Fatalf("expected the second tx to spend the first tx's change, got %v", inputs)
+	}
+
+	if New(cc).SetWallet(w).UseOutputsFrom(New(cc)).err == nil {
+		t.Error("expected error for an unbuilt previous transaction")
+	}
+}
+
+func TestBuildChainRejectsInvalidChains(t *testing.T) {
+	if _, err := BuildChain(nil); err == nil {
+		t.Error("expected error for an empty chain")
+	}
+	if _, err := BuildChain([]*Apollo{nil}); err == nil {
+		t.Error("expected error for a nil builder")
+	}
+
+	// The wallet cannot cover the second payment once the first spent its
+	// only UTxO and the change is too small.
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, w.Address(), 10_000_000, 0x01, 0)
+	_, err = BuildChain([]*Apollo{
+		New(cc).SetWallet(w).PayToAddress(testAddress(t), 6_000_000).SetTtl(50000000),
+		New(cc).SetWallet(w).PayToAddress(testAddress(t), 6_000_000).SetTtl(50000000),
+	})
+	if err == nil {
+		t.Fatal("expected the second transaction to fail coin selection")
+	}
+}
+
+func TestUseOutputsFromLoadedNonCanonicalBody(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, w.Address(), 10_000_000, 0x01, 0)
+
+	built, err := New(cc).SetWallet(w).PayToAddress(testAddress(t), 3_000_000).SetTtl(50000000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := cbor.Encode(&built.GetTx().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Re-encode the body as an indefinite-length map, which is valid CBOR but
+	// hashes differently from the encoder's output.
+	body := append([]byte{0xbf}, canonical[1:]...)
+	body = append(body, 0xff)
+	wsCbor, err := cbor.Encode(&built.GetTx().WitnessSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txCbor := append([]byte{0x84}, body...)
+	txCbor = append(txCbor, wsCbor...)
+	txCbor = append(txCbor, 0xf5, 0xf6)
+	loaded, err := New(cc).LoadTxCbor(hex.EncodeToString(txCbor))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bodyHash := common.Blake2b256Hash(body)
+	second, err := New(cc).SetWallet(w).UseOutputsFrom(loaded).PayToAddress(testAddress(t), 5_000_000).SetTtl(50000000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := second.GetTx().Body.TxInputs.Items()
+	if len(inputs) != 1 || inputs[0].Id() != bodyHash {
+		t.Fatalf("expected the second tx to spend outputs keyed by the original body hash %s, got %v", bodyHash, inputs)
+	}
+}
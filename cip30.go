@@ -0,0 +1,120 @@
+package apollo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+)
+
+// CIP30Connector is the part of a CIP-30 wallet API the builder needs, such as
+// a browser wallet reached through a dApp frontend. Values are the CBOR the
+// CIP-30 methods return, as raw bytes rather than hex.
+type CIP30Connector interface {
+	// GetUtxos returns the wallet's UTxOs, each a TransactionUnspentOutput.
+	GetUtxos() ([][]byte, error)
+	// GetChangeAddress returns the raw bytes of the wallet's change address.
+	GetChangeAddress() ([]byte, error)
+	// SignTx signs a transaction and returns the resulting witness set.
+	SignTx(txCbor []byte, partialSign bool) ([]byte, error)
+}
+
+// CIP30Wallet is a Wallet backed by a CIP-30 connector. Its address is the
+// connector's change address. CIP-30 wallets sign whole transactions, so Sign
+// uses SignTx rather than SignTxBody.
+type CIP30Wallet struct {
+	conn    CIP30Connector
+	address common.Address
+}
+
+// NewCIP30Wallet creates a wallet from a CIP-30 connector, reading its change
+// address once.
+func NewCIP30Wallet(conn CIP30Connector) (*CIP30Wallet, error) {
+	if conn == nil {
+		return nil, errors.New("CIP-30 connector must not be nil")
+	}
+	addrBytes, err := conn.GetChangeAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get change address: %w", err)
+	}
+	addr, err := common.NewAddressFromBytes(addrBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse change address: %w", err)
+	}
+	return &CIP30Wallet{conn: conn, address: addr}, nil
+}
+
+func (w *CIP30Wallet) Address() common.Address {
+	return w.address
+}
+
+// SignTxBody always fails: CIP-30 wallets only sign whole transactions.
+func (w *CIP30Wallet) SignTxBody(_ common.Blake2b256) (common.VkeyWitness, error) {
+	return common.VkeyWitness{}, errors.New("CIP-30 wallet signs whole transactions; use SignTx")
+}
+
+// SignTx asks the connector to sign the transaction, allowing a partial
+// signature since other keys may sign too, and returns its vkey witnesses.
+func (w *CIP30Wallet) SignTx(txCbor []byte) ([]common.VkeyWitness, error) {
+	wsCbor, err := w.conn.SignTx(txCbor, true)
+	if err != nil {
+		return nil, fmt.Errorf("CIP-30 signing failed: %w", err)
+	}
+	var ws conway.ConwayTransactionWitnessSet
+	if _, err := cbor.Decode(wsCbor, &ws); err != nil {
+		return nil, fmt.Errorf("failed to decode CIP-30 witness set: %w", err)
+	}
+	return ws.VkeyWitnesses.Items(), nil
+}
+
+func (w *CIP30Wallet) PubKeyHash() common.Blake2b224 {
+	return w.address.PaymentKeyHash()
+}
+
+func (w *CIP30Wallet) StakePubKeyHash() common.Blake2b224 {
+	return w.address.StakeKeyHash()
+}
+
+// Utxos decodes the connector's UTxOs, ready for AddLoadedUTxOs.
+func (w *CIP30Wallet) Utxos() ([]common.Utxo, error) {
+	raw, err := w.conn.GetUtxos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CIP-30 UTxOs: %w", err)
+	}
+	utxos := make([]common.Utxo, 0, len(raw))
+	for i, item := range raw {
+		utxo, err := decodeUnspentOutput(item)
+		if err != nil {
+			return nil, fmt.Errorf("CIP-30 UTxO %d: %w", i, err)
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, nil
+}
+
+// String returns the wallet's address.
+func (w CIP30Wallet) String() string {
+	return fmt.Sprintf("CIP30Wallet{address: %s}", w.address.String())
+}
+
+// decodeUnspentOutput decodes a CIP-30 TransactionUnspentOutput, an
+// [input, output] pair.
+func decodeUnspentOutput(data []byte) (common.Utxo, error) {
+	var pair struct {
+		cbor.StructAsArray
+		Input  shelley.ShelleyTransactionInput
+		Output cbor.RawMessage
+	}
+	if _, err := cbor.Decode(data, &pair); err != nil {
+		return common.Utxo{}, fmt.Errorf("failed to decode unspent output: %w", err)
+	}
+	output, err := babbage.NewBabbageTransactionOutputFromCbor(pair.Output)
+	if err != nil {
+		return common.Utxo{}, fmt.Errorf("failed to decode output: %w", err)
+	}
+	return common.Utxo{Id: pair.Input, Output: output}, nil
+}
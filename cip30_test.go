@@ -0,0 +1,117 @@
+package apollo
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+)
+
+// fakeCIP30 serves a BursaWallet's UTxOs and signatures the way a browser
+// wallet would over CIP-30.
+type fakeCIP30 struct {
+	wallet *BursaWallet
+	utxos  []common.Utxo
+	signed int
+}
+
+func (f *fakeCIP30) GetUtxos() ([][]byte, error) {
+	var raw [][]byte
+	for _, utxo := range f.utxos {
+		pair := struct {
+			cbor.StructAsArray
+			Input  shelley.ShelleyTransactionInput
+			Output *babbage.BabbageTransactionOutput
+		}{
+			Input:  utxo.Id.(shelley.ShelleyTransactionInput),
+			Output: utxo.Output.(*babbage.BabbageTransactionOutput),
+		}
+		data, err := cbor.Encode(&pair)
+		if err != nil {
+			return nil, err
+		}
+		raw = append(raw, data)
+	}
+	return raw, nil
+}
+
+func (f *fakeCIP30) GetChangeAddress() ([]byte, error) {
+	return f.wallet.Address().Bytes()
+}
+
+func (f *fakeCIP30) SignTx(txCbor []byte, _ bool) ([]byte, error) {
+	f.signed++
+	var items []cbor.RawMessage
+	if _, err := cbor.Decode(txCbor, &items); err != nil {
+		return nil, err
+	}
+	witness, err := f.wallet.SignTxBody(common.Blake2b256Hash(items[0]))
+	if err != nil {
+		return nil, err
+	}
+	ws := conway.ConwayTransactionWitnessSet{
+		VkeyWitnesses: cbor.NewSetType([]common.VkeyWitness{witness}, true),
+	}
+	return cbor.Encode(&ws)
+}
+
+func TestCIP30Wallet(t *testing.T) {
+	bw, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, bw.Address(), 10_000_000, 0x01, 0)
+	held, err := cc.Utxos(bw.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := &fakeCIP30{wallet: bw, utxos: held}
+
+	w, err := NewCIP30Wallet(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Address().String() != bw.Address().String() || w.PubKeyHash() != bw.PubKeyHash() {
+		t.Fatal("expected the connector's change address and payment key")
+	}
+	utxos, err := w.Utxos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 1 || utxoRef(utxos[0]) != utxoRef(conn.utxos[0]) ||
+		utxos[0].Output.Amount().Uint64() != 10_000_000 {
+		t.Fatalf("unexpected decoded UTxOs: %+v", utxos)
+	}
+	if _, err := w.SignTxBody(common.Blake2b256{}); err == nil {
+		t.Error("expected SignTxBody to fail")
+	}
+
+	a, err := New(setupFixedContext()).SetWallet(w).
+		AddLoadedUTxOs(utxos...).
+		PayToAddress(testAddress(t), 2_000_000).
+		SetTtl(50000000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err = a.Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.signed != 1 {
+		t.Fatalf("expected one SignTx call, got %d", conn.signed)
+	}
+	witnesses := a.GetTx().WitnessSet.VkeyWitnesses.Items()
+	if len(witnesses) != 1 {
+		t.Fatalf("expected 1 vkey witness, got %d", len(witnesses))
+	}
+	txId := a.GetTx().Id()
+	if !ed25519.Verify(witnesses[0].Vkey, txId.Bytes(), witnesses[0].Signature) {
+		t.Error("witness does not sign the transaction id")
+	}
+}
@@ -3,6 +3,7 @@ package apollo
 import (
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 )
@@ -27,7 +28,9 @@ type CoinSelector interface {
 var defaultCoinSelector CoinSelector = NewMACSSelector()
 
 // LargestFirstSelector selects UTxOs greedily by descending lovelace amount,
-// consuming ADA-only UTxOs before asset-carrying ones.
+// consuming ADA-only UTxOs before asset-carrying ones. ADA-only UTxOs made
+// redundant by the lovelace bundled with asset-carrying UTxOs picked later
+// are dropped from the result.
 type LargestFirstSelector struct{}
 
 // Name returns the algorithm's identifier.
@@ -85,8 +88,46 @@ func (s *LargestFirstSelector) Select(available []common.Utxo, target Value) ([]
 		}
 
 		if remaining.Coin == 0 && !remaining.HasAssets() {
-			return selected, nil
+			return dropRedundantAdaOnly(selected, target.Coin), nil
 		}
 	}
 	return nil, errors.New("insufficient UTxOs to cover required value")
 }
+
+// dropRedundantAdaOnly removes ADA-only UTxOs whose lovelace is not needed to
+// cover coinTarget, smallest first. A token UTxO selected for its assets still
+// carries its min-ADA, which can cover the coin target on its own. Amounts
+// must already be validated as uint64.
+func dropRedundantAdaOnly(selected []common.Utxo, coinTarget uint64) []common.Utxo {
+	excess := new(big.Int)
+	for _, utxo := range selected {
+		excess.Add(excess, utxo.Output.Amount())
+	}
+	excess.Sub(excess, new(big.Int).SetUint64(coinTarget))
+	if excess.Sign() <= 0 {
+		return selected
+	}
+	drop := make([]bool, len(selected))
+	dropped := false
+	for i := len(selected) - 1; i >= 0; i-- {
+		if selected[i].Output.Assets() != nil {
+			continue
+		}
+		amt := selected[i].Output.Amount()
+		if amt.Cmp(excess) <= 0 {
+			excess.Sub(excess, amt)
+			drop[i] = true
+			dropped = true
+		}
+	}
+	if !dropped {
+		return selected
+	}
+	kept := make([]common.Utxo, 0, len(selected))
+	for i, utxo := range selected {
+		if !drop[i] {
+			kept = append(kept, utxo)
+		}
+	}
+	return kept
+}
@@ -81,6 +81,22 @@ func runSelectorConformance(t *testing.T, newSelector func() CoinSelector) {
 		}
 	})
 
+	t.Run("TokenLovelaceCountsTowardCoin", func(t *testing.T) {
+		tokenUtxo := makeSelectorUtxo(t, 0x01, 0, 5_000_000, makeTestAssets(0xAA, "tokenA", 100))
+		pool := []common.Utxo{
+			tokenUtxo,
+			makeSelectorUtxo(t, 0x02, 0, 10_000_000, nil),
+		}
+		target := NewValue(3_000_000, makeTestAssets(0xAA, "tokenA", 50))
+		selected, err := newSelector().Select(pool, target)
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		if len(selected) != 1 || utxoRef(selected[0]) != utxoRef(tokenUtxo) {
+			t.Errorf("expected only the token UTxO to be selected, got %d UTxOs", len(selected))
+		}
+	})
+
 	t.Run("NoDuplicateSelections", func(t *testing.T) {
 		pool := []common.Utxo{
 			makeSelectorUtxo(t, 0x01, 0, 2_000_000, makeTestAssets(0xAA, "tokenA", 100)),
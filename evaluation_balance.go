@@ -1,12 +1,20 @@
 package apollo
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"math/bits"
+	"slices"
+	"strconv"
 
+	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
+
+	"github.com/Salvionied/apollo/v2/backend"
 )
 
 // balanceContext contains every value in the Cardano balance equation that is
@@ -18,6 +26,17 @@ type balanceContext struct {
 	governanceRequired Value
 	stakeDeposit       int64
 	changeAddress      common.Address
+	// changeSplits, when it has more than one entry, replaces the single
+	// change output with weighted outputs (see SetChangeAddresses).
+	changeSplits []changeSplit
+	// assetStrategy lays out change assets when change is not weighted.
+	assetStrategy ChangeAssetStrategy
+}
+
+// changeSplit is one weighted destination for change.
+type changeSplit struct {
+	Address common.Address
+	Weight  int
 }
 
 type balancedOutputs struct {
@@ -72,6 +91,42 @@ func (a *Apollo) buildBalancedOutputs(
 		return balancedOutputs{}, fmt.Errorf("invalid min UTxO for change output: %d", minChange)
 	}
 
+	// Change gathering the tokens of many selected inputs can exceed the
+	// protocol's max value size, and the asset strategy may ask for several
+	// outputs anyway; spread the assets over them.
+	if change.HasAssets() && len(ctx.changeSplits) <= 1 {
+		maxValSize, err := maxValueSize(pp)
+		if err != nil {
+			return balancedOutputs{}, err
+		}
+		bundles, bundleErr := changeAssetBundles(change, ctx.assetStrategy, maxValSize)
+		if bundleErr != nil {
+			return balancedOutputs{}, bundleErr
+		}
+		if len(bundles) > 1 {
+			split, splitErr := bundleChangeOutputs(ctx.changeAddress, change.Coin, bundles, pp.CoinsPerUtxoByteValue())
+			if splitErr != nil {
+				return balancedOutputs{}, splitErr
+			}
+			outputs = append(outputs, split...)
+			return balancedOutputs{Outputs: outputs, Fee: requestedFee}, nil
+		}
+	}
+
+	// Weighted change applies once there is more than dust to distribute;
+	// ADA-only dust below a single output's min-UTxO still goes to the fee.
+	if len(ctx.changeSplits) > 1 && (change.HasAssets() || change.Coin >= uint64(minChange)) {
+		maxValSize, err := maxValueSize(pp)
+		if err != nil {
+			return balancedOutputs{}, err
+		}
+		outputs, err = appendSplitChange(outputs, change, ctx.changeSplits, maxValSize, pp.CoinsPerUtxoByteValue())
+		if err != nil {
+			return balancedOutputs{}, err
+		}
+		return balancedOutputs{Outputs: outputs, Fee: requestedFee}, nil
+	}
+
 	if change.Coin < uint64(minChange) {
 		if !change.HasAssets() {
 			if uint64(requestedFee) > math.MaxInt64-change.Coin { //nolint:gosec // checked non-negative above
@@ -80,27 +135,465 @@ func (a *Apollo) buildBalancedOutputs(
 			return balancedOutputs{Outputs: outputs, Fee: requestedFee + int64(change.Coin)}, nil //nolint:gosec // bound checked above
 		}
 		// A token-bearing output must meet its min-UTxO requirement. Raising
-		// its ADA consumes extra change, so prove the selected inputs cover it.
-		change.Coin = uint64(minChange)
-		changeOutput = NewBabbageOutput(ctx.changeAddress, change, nil, nil)
-		actualMin, minErr := MinLovelacePostAlonzo(&changeOutput, pp.CoinsPerUtxoByteValue())
+		// its ADA would spend more than the inputs hold; ensureChangeMinAda
+		// selects the ADA for it before balancing.
+		minAda, minErr := changeMinAda(ctx.changeAddress, change, pp.CoinsPerUtxoByteValue())
 		if minErr != nil {
-			return balancedOutputs{}, fmt.Errorf("failed to compute actual min UTxO for change output: %w", minErr)
-		}
-		if actualMin < 0 {
-			return balancedOutputs{}, fmt.Errorf("invalid min UTxO for change output: %d", actualMin)
-		}
-		change.Coin = uint64(actualMin)
-		changeOutput = NewBabbageOutput(ctx.changeAddress, change, nil, nil)
-		neededWithChange, addErr := needed.Add(NewSimpleValue(change.Coin))
-		if addErr != nil || !ctx.totalInput.GreaterOrEqual(neededWithChange) {
-			return balancedOutputs{}, errors.New("insufficient funds for asset change min UTxO")
+			return balancedOutputs{}, minErr
 		}
+		return balancedOutputs{}, fmt.Errorf(
+			"insufficient funds for asset change min UTxO: change holds %d lovelace, needs %d",
+			change.Coin, minAda,
+		)
 	}
 	outputs = append(outputs, changeOutput)
 	return balancedOutputs{Outputs: outputs, Fee: requestedFee}, nil
 }
 
+// maxValueSize parses the protocol's max value size. A backend that does not
+// report it yields 0, which disables the size limit.
+func maxValueSize(pp backend.ProtocolParameters) (int, error) {
+	if pp.MaxValSize == "" {
+		return 0, nil
+	}
+	size, err := strconv.Atoi(pp.MaxValSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max value size %q in protocol params: %w", pp.MaxValSize, err)
+	}
+	return size, nil
+}
+
+// ensureChangeMinAda selects more ADA when the inputs, worth available, would
+// leave token-bearing change below its min-UTxO after paying required, counting
+// every output its assets are split over. ADA-only change needs nothing, since
+// dust below min-UTxO goes to the fee. It returns the extra UTxOs, already
+// marked used, and fails when the pool runs out.
+func (a *Apollo) ensureChangeMinAda(available, required Value) ([]common.Utxo, error) {
+	pp, err := a.Context.ProtocolParams()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get protocol params for change output: %w", err)
+	}
+	changeAddr := a.getChangeAddress()
+	maxValSize, err := maxValueSize(pp)
+	if err != nil {
+		return nil, err
+	}
+	var extra []common.Utxo
+	for {
+		if !available.GreaterOrEqual(required) {
+			// Selection fell short of the target itself; balancing reports it.
+			return extra, nil
+		}
+		change, err := available.Sub(required)
+		if err != nil {
+			return nil, fmt.Errorf("change value underflow: %w", err)
+		}
+		change.Assets, err = normalizeChangeAssets(change.Assets)
+		if err != nil {
+			return nil, err
+		}
+		if !change.HasAssets() {
+			return extra, nil
+		}
+		var minAda uint64
+		if len(a.changeSplits) > 1 {
+			minAda, err = changeMinAda(changeAddr, change, pp.CoinsPerUtxoByteValue())
+		} else {
+			minAda, err = changeLayoutMinAda(changeAddr, change, a.changeAssetStrategy, maxValSize, pp.CoinsPerUtxoByteValue())
+		}
+		if err != nil {
+			return nil, err
+		}
+		if change.Coin >= minAda {
+			return extra, nil
+		}
+		more, err := a.selectCoins(NewSimpleValue(minAda-change.Coin), Value{})
+		if err == nil && len(more) == 0 {
+			err = errors.New("no UTxOs left to select")
+		}
+		if err != nil {
+			return nil, fmt.Errorf(
+				"insufficient funds for asset change min UTxO: change holds %d lovelace, needs %d: %w",
+				change.Coin, minAda, err,
+			)
+		}
+		extra = append(extra, more...)
+		moreValue, err := a.sumUtxoValues(more)
+		if err != nil {
+			return nil, err
+		}
+		available, err = available.Add(moreValue)
+		if err != nil {
+			return nil, fmt.Errorf("input value overflow: %w", err)
+		}
+	}
+}
+
+// changeMinAda returns the min-UTxO of a change output to addr holding the
+// assets of change, sized with the lovelace it would hold at that minimum.
+func changeMinAda(addr common.Address, change Value, coinsPerUtxoByte int64) (uint64, error) {
+	out := NewBabbageOutput(addr, change, nil, nil)
+	minCoin, err := MinLovelacePostAlonzo(&out, coinsPerUtxoByte)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute min UTxO for change output: %w", err)
+	}
+	if minCoin < 0 {
+		return 0, fmt.Errorf("invalid min UTxO for change output: %d", minCoin)
+	}
+	if change.Coin >= uint64(minCoin) {
+		return uint64(minCoin), nil
+	}
+	// A larger coin can encode longer, so size the output again at the minimum.
+	change.Coin = uint64(minCoin)
+	out = NewBabbageOutput(addr, change, nil, nil)
+	actualMin, err := MinLovelacePostAlonzo(&out, coinsPerUtxoByte)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute actual min UTxO for change output: %w", err)
+	}
+	if actualMin < 0 {
+		return 0, fmt.Errorf("invalid min UTxO for change output: %d", actualMin)
+	}
+	return uint64(actualMin), nil
+}
+
+// appendSplitChange distributes change across weighted destinations. Lovelace
+// is split by weight, with the rounding remainder going to the first
+// destination, which also receives every native asset so they are not
+// fragmented. When those assets exceed maxValSize, the first destination's
+// share is spread over several outputs to it. Each output must meet its own
+// min-UTxO.
+func appendSplitChange(
+	outputs []babbage.BabbageTransactionOutput,
+	change Value,
+	splits []changeSplit,
+	maxValSize int,
+	coinsPerUtxoByte int64,
+) ([]babbage.BabbageTransactionOutput, error) {
+	var totalWeight uint64
+	for _, split := range splits {
+		totalWeight += uint64(split.Weight) //nolint:gosec // weights validated positive by SetChangeAddresses
+	}
+	shares := make([]uint64, len(splits))
+	var allocated uint64
+	for i, split := range splits {
+		// change.Coin * weight / totalWeight without overflowing uint64; the
+		// high word is below totalWeight because weight <= totalWeight.
+		hi, lo := bits.Mul64(change.Coin, uint64(split.Weight)) //nolint:gosec // validated positive
+		shares[i], _ = bits.Div64(hi, lo, totalWeight)
+		allocated += shares[i]
+	}
+	shares[0] += change.Coin - allocated
+
+	for i, split := range splits {
+		value := NewSimpleValue(shares[i])
+		if i == 0 && change.HasAssets() {
+			value.Assets = change.Assets
+			bundles, err := sizeLimitedBundles(value, maxValSize)
+			if err != nil {
+				return nil, err
+			}
+			if len(bundles) > 1 {
+				bundled, err := bundleChangeOutputs(split.Address, shares[i], bundles, coinsPerUtxoByte)
+				if err != nil {
+					return nil, err
+				}
+				outputs = append(outputs, bundled...)
+				continue
+			}
+		}
+		out := NewBabbageOutput(split.Address, value, nil, nil)
+		minCoin, err := MinLovelacePostAlonzo(&out, coinsPerUtxoByte)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute min UTxO for change output %d: %w", i, err)
+		}
+		if minCoin < 0 || shares[i] < uint64(minCoin) {
+			return nil, fmt.Errorf(
+				"change of %d lovelace cannot be split by weight: output %d to %s would hold %d, below its min UTxO of %d",
+				change.Coin, i, split.Address.String(), shares[i], minCoin,
+			)
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+// changeAssetBundles groups the assets of change into one bundle per change
+// output as strategy asks. A single bundle (or none) means one change output.
+// A non-positive maxValSize disables the size limit.
+func changeAssetBundles(
+	change Value,
+	strategy ChangeAssetStrategy,
+	maxValSize int,
+) ([]*common.MultiAsset[common.MultiAssetTypeOutput], error) {
+	switch strategy {
+	case ChangeAssetsPerPolicy:
+		policies := change.Assets.Policies()
+		slices.SortFunc(policies, func(x, y common.Blake2b224) int { return bytes.Compare(x[:], y[:]) })
+		var bundles []*common.MultiAsset[common.MultiAssetTypeOutput]
+		for _, policyId := range policies {
+			assets := make(map[cbor.ByteString]common.MultiAssetTypeOutput)
+			for _, name := range change.Assets.Assets(policyId) {
+				assets[cbor.NewByteString(name)] = new(big.Int).Set(change.Assets.Asset(policyId, name))
+			}
+			policyAssets := common.NewMultiAsset[common.MultiAssetTypeOutput](
+				map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput{policyId: assets},
+			)
+			// A policy too large for one output is split further.
+			split, err := sizeLimitedBundles(Value{Coin: change.Coin, Assets: &policyAssets}, maxValSize)
+			if err != nil {
+				return nil, err
+			}
+			if split == nil {
+				split = []*common.MultiAsset[common.MultiAssetTypeOutput]{&policyAssets}
+			}
+			bundles = append(bundles, split...)
+		}
+		return bundles, nil
+	case ChangeAssetsSpread:
+		bundles, err := sizeLimitedBundles(change, maxValSize)
+		if err != nil || len(bundles) <= 1 {
+			return bundles, err
+		}
+		balanced, err := balanceAssetBundles(change, len(bundles), maxValSize)
+		if err != nil {
+			return nil, err
+		}
+		if balanced != nil {
+			return balanced, nil
+		}
+		return bundles, nil
+	default:
+		return sizeLimitedBundles(change, maxValSize)
+	}
+}
+
+// sizeLimitedBundles partitions the assets of change when it exceeds
+// maxValSize, and returns nil when it fits in one output.
+func sizeLimitedBundles(
+	change Value,
+	maxValSize int,
+) ([]*common.MultiAsset[common.MultiAssetTypeOutput], error) {
+	if maxValSize <= 0 {
+		return nil, nil
+	}
+	size, err := valueCborSize(change)
+	if err != nil {
+		return nil, err
+	}
+	if size <= maxValSize {
+		return nil, nil
+	}
+	return partitionChangeAssets(change, maxValSize)
+}
+
+// balanceAssetBundles deals the assets of change, in policy and name order,
+// into n bundles of near-equal asset count. It returns nil if a bundle would
+// exceed maxValSize, leaving the caller to its greedy partition.
+func balanceAssetBundles(
+	change Value,
+	n int,
+	maxValSize int,
+) ([]*common.MultiAsset[common.MultiAssetTypeOutput], error) {
+	type asset struct {
+		policyId common.Blake2b224
+		name     []byte
+	}
+	var assets []asset
+	policies := change.Assets.Policies()
+	slices.SortFunc(policies, func(x, y common.Blake2b224) int { return bytes.Compare(x[:], y[:]) })
+	for _, policyId := range policies {
+		names := change.Assets.Assets(policyId)
+		slices.SortFunc(names, bytes.Compare)
+		for _, name := range names {
+			assets = append(assets, asset{policyId: policyId, name: name})
+		}
+	}
+	bundles := make([]*common.MultiAsset[common.MultiAssetTypeOutput], 0, n)
+	start := 0
+	for i := range n {
+		end := start + (len(assets)-start)/(n-i)
+		current := make(map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput)
+		for _, a := range assets[start:end] {
+			if current[a.policyId] == nil {
+				current[a.policyId] = make(map[cbor.ByteString]common.MultiAssetTypeOutput)
+			}
+			current[a.policyId][cbor.NewByteString(a.name)] = new(big.Int).Set(change.Assets.Asset(a.policyId, a.name))
+		}
+		fits, err := assetBundleFits(change.Coin, current, maxValSize)
+		if err != nil {
+			return nil, err
+		}
+		if !fits {
+			return nil, nil
+		}
+		bundle := common.NewMultiAsset[common.MultiAssetTypeOutput](current)
+		bundles = append(bundles, &bundle)
+		start = end
+	}
+	return bundles, nil
+}
+
+// bundleChangeOutputs builds one change output to addr per asset bundle. Every
+// output gets its min-UTxO and the first also takes the remaining lovelace.
+func bundleChangeOutputs(
+	addr common.Address,
+	coin uint64,
+	bundles []*common.MultiAsset[common.MultiAssetTypeOutput],
+	coinsPerUtxoByte int64,
+) ([]babbage.BabbageTransactionOutput, error) {
+	mins, total, err := bundleMinAda(addr, coin, bundles, coinsPerUtxoByte)
+	if err != nil {
+		return nil, err
+	}
+	if total > coin {
+		return nil, fmt.Errorf(
+			"insufficient funds for asset change min UTxO: %d change outputs need %d lovelace, change holds %d",
+			len(bundles), total, coin,
+		)
+	}
+	mins[0] += coin - total
+	outputs := make([]babbage.BabbageTransactionOutput, 0, len(bundles))
+	for i, bundle := range bundles {
+		outputs = append(outputs, NewBabbageOutput(addr, Value{Coin: mins[i], Assets: bundle}, nil, nil))
+	}
+	return outputs, nil
+}
+
+// bundleMinAda returns the min-UTxO of a change output to addr per asset
+// bundle, and their total. Sizing with the full change coin bounds the
+// min-UTxO of whatever share of it each output ends up holding.
+func bundleMinAda(
+	addr common.Address,
+	coin uint64,
+	bundles []*common.MultiAsset[common.MultiAssetTypeOutput],
+	coinsPerUtxoByte int64,
+) ([]uint64, uint64, error) {
+	mins := make([]uint64, len(bundles))
+	var total uint64
+	for i, bundle := range bundles {
+		out := NewBabbageOutput(addr, Value{Coin: coin, Assets: bundle}, nil, nil)
+		minCoin, err := MinLovelacePostAlonzo(&out, coinsPerUtxoByte)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to compute min UTxO for change output %d: %w", i, err)
+		}
+		if minCoin < 0 {
+			return nil, 0, fmt.Errorf("invalid min UTxO for change output %d: %d", i, minCoin)
+		}
+		mins[i] = uint64(minCoin)
+		total += mins[i]
+	}
+	return mins, total, nil
+}
+
+// changeLayoutMinAda returns the lovelace change needs to cover the min-UTxO
+// of every output buildBalancedOutputs would spread its assets over, or of the
+// single change output when it fits in one.
+func changeLayoutMinAda(
+	addr common.Address,
+	change Value,
+	strategy ChangeAssetStrategy,
+	maxValSize int,
+	coinsPerUtxoByte int64,
+) (uint64, error) {
+	bundles, err := changeAssetBundles(change, strategy, maxValSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(bundles) <= 1 {
+		return changeMinAda(addr, change, coinsPerUtxoByte)
+	}
+	_, total, err := bundleMinAda(addr, change.Coin, bundles, coinsPerUtxoByte)
+	if err != nil || total <= change.Coin {
+		return total, err
+	}
+	// As in changeMinAda, size the outputs again at the coin they will hold.
+	_, total, err = bundleMinAda(addr, total, bundles, coinsPerUtxoByte)
+	return total, err
+}
+
+// partitionChangeAssets groups the assets of change, in policy and name order,
+// into bundles whose value with change.Coin stays within maxValSize.
+func partitionChangeAssets(
+	change Value,
+	maxValSize int,
+) ([]*common.MultiAsset[common.MultiAssetTypeOutput], error) {
+	var bundles []*common.MultiAsset[common.MultiAssetTypeOutput]
+	current := make(map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput)
+	count := 0
+	policies := change.Assets.Policies()
+	slices.SortFunc(policies, func(x, y common.Blake2b224) int { return bytes.Compare(x[:], y[:]) })
+	for _, policyId := range policies {
+		names := change.Assets.Assets(policyId)
+		slices.SortFunc(names, bytes.Compare)
+		for _, name := range names {
+			qty := new(big.Int).Set(change.Assets.Asset(policyId, name))
+			if current[policyId] == nil {
+				current[policyId] = make(map[cbor.ByteString]common.MultiAssetTypeOutput)
+			}
+			current[policyId][cbor.NewByteString(name)] = qty
+			fits, err := assetBundleFits(change.Coin, current, maxValSize)
+			if err != nil {
+				return nil, err
+			}
+			if fits {
+				count++
+				continue
+			}
+			if count == 0 {
+				return nil, fmt.Errorf("change asset %x.%x alone exceeds the max value size of %d bytes", policyId.Bytes(), name, maxValSize)
+			}
+			delete(current[policyId], cbor.NewByteString(name))
+			if len(current[policyId]) == 0 {
+				delete(current, policyId)
+			}
+			bundle := common.NewMultiAsset[common.MultiAssetTypeOutput](current)
+			bundles = append(bundles, &bundle)
+			current = map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput{
+				policyId: {cbor.NewByteString(name): qty},
+			}
+			fits, err = assetBundleFits(change.Coin, current, maxValSize)
+			if err != nil {
+				return nil, err
+			}
+			if !fits {
+				return nil, fmt.Errorf("change asset %x.%x alone exceeds the max value size of %d bytes", policyId.Bytes(), name, maxValSize)
+			}
+			count = 1
+		}
+	}
+	if count > 0 {
+		bundle := common.NewMultiAsset[common.MultiAssetTypeOutput](current)
+		bundles = append(bundles, &bundle)
+	}
+	return bundles, nil
+}
+
+// assetBundleFits reports whether assets with coin lovelace encode within
+// maxValSize bytes.
+func assetBundleFits(
+	coin uint64,
+	assets map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput,
+	maxValSize int,
+) (bool, error) {
+	ma := common.NewMultiAsset[common.MultiAssetTypeOutput](assets)
+	size, err := valueCborSize(Value{Coin: coin, Assets: &ma})
+	if err != nil {
+		return false, err
+	}
+	return size <= maxValSize, nil
+}
+
+// valueCborSize returns the serialized size of v as the ledger measures it
+// against the max value size.
+func valueCborSize(v Value) (int, error) {
+	mv := v.ToMaryValue()
+	encoded, err := cbor.Encode(&mv)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode value: %w", err)
+	}
+	return len(encoded), nil
+}
+
 func errorsNewFeeOverflow(fee int64, dust uint64) error {
 	return fmt.Errorf("fee overflow absorbing %d lovelace dust into %d", dust, fee)
 }
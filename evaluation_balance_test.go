@@ -1,12 +1,18 @@
 package apollo
 
 import (
+	"fmt"
 	"math"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
+
+	"github.com/Salvionied/apollo/v2/backend"
+	"github.com/Salvionied/apollo/v2/backend/fixed"
 )
 
 func evaluationAsset(t *testing.T, quantity int64) *common.MultiAsset[common.MultiAssetTypeOutput] {
@@ -21,6 +27,23 @@ func evaluationAsset(t *testing.T, quantity int64) *common.MultiAsset[common.Mul
 	return &assets
 }
 
+// assetGrid returns perPolicy assets with 32-byte names under each of
+// policies policies.
+func assetGrid(policies, perPolicy int) *common.MultiAsset[common.MultiAssetTypeOutput] {
+	data := make(map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput, policies)
+	for i := range policies {
+		var policy common.Blake2b224
+		policy[0] = byte(i + 1)
+		names := make(map[cbor.ByteString]common.MultiAssetTypeOutput, perPolicy)
+		for j := range perPolicy {
+			names[cbor.NewByteString([]byte(fmt.Sprintf("%032d", j)))] = big.NewInt(1)
+		}
+		data[policy] = names
+	}
+	assets := common.NewMultiAsset[common.MultiAssetTypeOutput](data)
+	return &assets
+}
+
 func TestBalancedOutputsAbsorbsAdaDustIntoFee(t *testing.T) {
 	a := New(setupFixedContext())
 	got, err := a.buildBalancedOutputs(nil, 2_000_000, balanceContext{
@@ -54,6 +77,23 @@ func TestBalancedOutputsNeverDropsAssetChange(t *testing.T) {
 	}
 }
 
+func TestBalancedOutputsRejectsInvalidMaxValSize(t *testing.T) {
+	pp, err := setupFixedContext().ProtocolParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pp.MaxValSize = "unbounded"
+	a := New(fixed.NewFixedChainContext(pp, backend.GenesisParameters{NetworkMagic: 1}, 0))
+	_, err = a.buildBalancedOutputs(nil, 2_000_000, balanceContext{
+		totalInput:    NewValue(5_000_000, evaluationAsset(t, 1)),
+		totalRequired: Value{},
+		changeAddress: testAddress(t),
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid max value size") {
+		t.Fatalf("expected an invalid max value size error, got %v", err)
+	}
+}
+
 func TestBalancedOutputsRejectsNegativeFee(t *testing.T) {
 	a := New(setupFixedContext())
 	_, err := a.buildBalancedOutputs(nil, -1, balanceContext{changeAddress: testAddress(t)})
@@ -162,3 +202,336 @@ func TestBalancedOutputsIncludesGovernanceValues(t *testing.T) {
 		t.Fatal("governance double-count check failed: separate fields must change the result")
 	}
 }
+
+func TestBalancedOutputsSplitsChangeByWeight(t *testing.T) {
+	a := New(setupFixedContext())
+	first := testAddress(t)
+	var raw [29]byte
+	raw[0] = 0x60
+	second, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.buildBalancedOutputs(nil, 1_000_000, balanceContext{
+		totalInput:    NewValue(11_000_001, evaluationAsset(t, 1)),
+		changeAddress: first,
+		changeSplits:  []changeSplit{{Address: first, Weight: 3}, {Address: second, Weight: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Outputs) != 2 {
+		t.Fatalf("expected 2 change outputs, got %d", len(got.Outputs))
+	}
+	firstValue := ValueFromMaryValue(got.Outputs[0].OutputAmount)
+	secondValue := ValueFromMaryValue(got.Outputs[1].OutputAmount)
+	// 10000001 lovelace split 3:2; the rounding remainder goes to the first.
+	if firstValue.Coin != 6_000_001 || secondValue.Coin != 4_000_000 {
+		t.Fatalf("unexpected split %d/%d", firstValue.Coin, secondValue.Coin)
+	}
+	if !firstValue.HasAssets() || secondValue.HasAssets() {
+		t.Fatal("expected all assets on the first change output")
+	}
+}
+
+func TestBalancedOutputsSplitRejectsShareBelowMinUtxo(t *testing.T) {
+	a := New(setupFixedContext())
+	addr := testAddress(t)
+	_, err := a.buildBalancedOutputs(nil, 1_000_000, balanceContext{
+		totalInput:    NewSimpleValue(3_000_000),
+		changeAddress: addr,
+		changeSplits:  []changeSplit{{Address: addr, Weight: 9}, {Address: addr, Weight: 1}},
+	})
+	if err == nil {
+		t.Fatal("expected error for change share below min UTxO")
+	}
+}
+
+func TestSetChangeAddressesValidation(t *testing.T) {
+	addr := testAddress(t)
+	cases := []struct {
+		name    string
+		addrs   []common.Address
+		weights []int
+	}{
+		{"empty", nil, nil},
+		{"length mismatch", []common.Address{addr, addr}, []int{1}},
+		{"zero weight", []common.Address{addr, addr}, []int{1, 0}},
+	}
+	for _, tc := range cases {
+		if a := New(setupFixedContext()).SetChangeAddresses(tc.addrs, tc.weights); a.err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+	a := New(setupFixedContext()).SetChangeAddresses([]common.Address{addr}, []int{1})
+	if a.err != nil || a.changeSplits != nil || a.getChangeAddress().String() != addr.String() {
+		t.Fatal("expected a single address to behave like SetChangeAddress")
+	}
+}
+
+func TestCompleteSplitsOversizedTokenChange(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	const perUtxo = 40
+	for i := range 4 {
+		var policy common.Blake2b224
+		policy[0] = byte(i + 1)
+		names := make(map[cbor.ByteString]common.MultiAssetTypeOutput, perUtxo)
+		for j := range perUtxo {
+			names[cbor.NewByteString([]byte(fmt.Sprintf("%032d", j)))] = big.NewInt(1)
+		}
+		assets := common.NewMultiAsset[common.MultiAssetTypeOutput](
+			map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput{policy: names},
+		)
+		var txHash common.Blake2b256
+		txHash[0] = byte(i + 1)
+		cc.AddUtxo(addr, makeAssetTestUtxo(t, txHash, 0, 40_000_000, &assets))
+	}
+
+	// Paying more than three inputs hold forces selection of all four, and
+	// their combined tokens exceed the 5000-byte max value size.
+	p, err := NewPayment(validTestAddrBech32, 125_000_000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(cc).SetWallet(NewExternalWallet(addr)).AddPayment(p).Complete()
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	tx := a.GetTx()
+	if n := len(tx.Body.TxInputs.Items()); n != 4 {
+		t.Fatalf("expected 4 inputs, got %d", n)
+	}
+	changeOutputs := 0
+	assetCount := 0
+	for i, out := range tx.Body.TxOutputs {
+		size, err := valueCborSize(ValueFromMaryValue(out.OutputAmount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size > 5000 {
+			t.Errorf("output %d value is %d bytes, above the max value size", i, size)
+		}
+		if out.OutputAddress.String() != addr.String() {
+			continue
+		}
+		changeOutputs++
+		if assets := out.OutputAmount.Assets; assets != nil {
+			for _, policy := range assets.Policies() {
+				assetCount += len(assets.Assets(policy))
+			}
+		}
+	}
+	if changeOutputs < 2 {
+		t.Errorf("expected the change to be split, got %d change outputs", changeOutputs)
+	}
+	if assetCount != 4*perUtxo {
+		t.Errorf("expected %d assets in change, got %d", 4*perUtxo, assetCount)
+	}
+}
+
+func TestChangeAssetStrategy(t *testing.T) {
+	addr := testAddress(t)
+	assetValue := func(policies, perPolicy int) Value {
+		return Value{Coin: 20_000_000, Assets: assetGrid(policies, perPolicy)}
+	}
+	changeOutputs := func(strategy ChangeAssetStrategy) []babbage.BabbageTransactionOutput {
+		t.Helper()
+		a := New(setupFixedContext()).SetChangeAssetStrategy(strategy)
+		if a.err != nil {
+			t.Fatal(a.err)
+		}
+		balanced, err := a.buildBalancedOutputs(nil, 200_000, balanceContext{
+			totalInput:    assetValue(3, 2),
+			changeAddress: addr,
+			assetStrategy: a.changeAssetStrategy,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return balanced.Outputs
+	}
+
+	if n := len(changeOutputs(ChangeAssetsSingleOutput)); n != 1 {
+		t.Errorf("single output: got %d change outputs, want 1", n)
+	}
+	if n := len(changeOutputs(ChangeAssetsSpread)); n != 1 {
+		t.Errorf("spread within the size limit: got %d change outputs, want 1", n)
+	}
+	perPolicy := changeOutputs(ChangeAssetsPerPolicy)
+	if len(perPolicy) != 3 {
+		t.Fatalf("per policy: got %d change outputs, want 3", len(perPolicy))
+	}
+	var coin uint64
+	for i, out := range perPolicy {
+		if policies := out.OutputAmount.Assets.Policies(); len(policies) != 1 {
+			t.Errorf("per policy output %d holds %d policies", i, len(policies))
+		}
+		coin += out.OutputAmount.Amount
+	}
+	if coin != 20_000_000-200_000 {
+		t.Errorf("per policy outputs hold %d lovelace, want %d", coin, 20_000_000-200_000)
+	}
+
+	// Oversized change: spreading uses as many outputs as the greedy split
+	// but keeps their asset counts within one of each other.
+	large := assetValue(4, 40)
+	greedy, err := changeAssetBundles(large, ChangeAssetsSingleOutput, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spread, err := changeAssetBundles(large, ChangeAssetsSpread, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(greedy) < 2 || len(spread) != len(greedy) {
+		t.Fatalf("got %d spread bundles for %d greedy bundles", len(spread), len(greedy))
+	}
+	minCount, maxCount := math.MaxInt, 0
+	for _, bundle := range spread {
+		count := 0
+		for _, policy := range bundle.Policies() {
+			count += len(bundle.Assets(policy))
+		}
+		minCount, maxCount = min(minCount, count), max(maxCount, count)
+	}
+	if maxCount-minCount > 1 {
+		t.Errorf("spread bundles hold between %d and %d assets", minCount, maxCount)
+	}
+
+	if a := New(setupFixedContext()).SetChangeAssetStrategy(ChangeAssetStrategy(9)); a.err == nil {
+		t.Error("expected error for an unknown strategy")
+	}
+}
+
+func TestEnsureChangeMinAda(t *testing.T) {
+	addr := testAddress(t)
+	assets := evaluationAsset(t, 5)
+	pp, err := setupFixedContext().ProtocolParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	minAda, err := changeMinAda(addr, Value{Assets: assets}, pp.CoinsPerUtxoByteValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	required := NewSimpleValue(2_000_000)
+	inputsLeaving := func(changeCoin uint64) Value {
+		return Value{Coin: required.Coin + changeCoin, Assets: assets}
+	}
+
+	t.Run("exactly min ada", func(t *testing.T) {
+		a := New(setupFixedContext()).SetChangeAddress(addr)
+		extra, err := a.ensureChangeMinAda(inputsLeaving(minAda), required)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(extra) != 0 {
+			t.Fatalf("expected no extra inputs, got %d", len(extra))
+		}
+	})
+
+	t.Run("below min selects another input", func(t *testing.T) {
+		var txHash common.Blake2b256
+		txHash[0] = 0x05
+		pool := makeTestUtxo(t, txHash, 0, 5_000_000)
+		a := New(setupFixedContext()).SetChangeAddress(addr).AddLoadedUTxOs(pool)
+		extra, err := a.ensureChangeMinAda(inputsLeaving(minAda-100_000), required)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(extra) != 1 || utxoRef(extra[0]) != utxoRef(pool) {
+			t.Fatalf("expected the pool UTxO to be selected, got %d inputs", len(extra))
+		}
+		if !a.isUsed(utxoRef(pool)) {
+			t.Error("expected the extra input to be marked used")
+		}
+	})
+
+	t.Run("below min with no inputs left", func(t *testing.T) {
+		a := New(setupFixedContext()).SetChangeAddress(addr)
+		_, err := a.ensureChangeMinAda(inputsLeaving(minAda-100_000), required)
+		if err == nil || !strings.Contains(err.Error(), "insufficient funds for asset change min UTxO") {
+			t.Fatalf("expected a change min UTxO error, got %v", err)
+		}
+	})
+
+	t.Run("ada-only dust needs nothing", func(t *testing.T) {
+		a := New(setupFixedContext()).SetChangeAddress(addr)
+		extra, err := a.ensureChangeMinAda(NewSimpleValue(required.Coin+1), required)
+		if err != nil || len(extra) != 0 {
+			t.Fatalf("expected no extra inputs, got %d (err %v)", len(extra), err)
+		}
+	})
+}
+
+func TestSplitChangeMinAda(t *testing.T) {
+	addr := testAddress(t)
+	pp, err := setupFixedContext().ProtocolParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	large := assetGrid(4, 40)
+	single, err := changeMinAda(addr, Value{Assets: large}, pp.CoinsPerUtxoByteValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := changeLayoutMinAda(addr, Value{Assets: large}, ChangeAssetsSingleOutput, 5000, pp.CoinsPerUtxoByteValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layout <= single {
+		t.Fatalf("expected split change to need more than %d lovelace, got %d", single, layout)
+	}
+
+	// Change covering one output's min-UTxO falls short once split.
+	var txHash common.Blake2b256
+	txHash[0] = 0x05
+	pool := makeTestUtxo(t, txHash, 0, 10_000_000)
+	a := New(setupFixedContext()).SetChangeAddress(addr).AddLoadedUTxOs(pool)
+	required := NewSimpleValue(2_000_000)
+	extra, err := a.ensureChangeMinAda(Value{Coin: required.Coin + single, Assets: large}, required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(extra) != 1 {
+		t.Fatalf("expected another input for the split change, got %d", len(extra))
+	}
+
+	// Weighted change spreads the first destination's assets when they
+	// exceed the max value size.
+	var raw [29]byte
+	raw[0] = 0x60
+	second, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := New(setupFixedContext()).buildBalancedOutputs(nil, 200_000, balanceContext{
+		totalInput:    Value{Coin: 60_000_000, Assets: large},
+		changeAddress: addr,
+		changeSplits:  []changeSplit{{Address: addr, Weight: 2}, {Address: second, Weight: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Outputs) < 3 {
+		t.Fatalf("expected the first destination's change to be split, got %d outputs", len(got.Outputs))
+	}
+	var coin uint64
+	for i, out := range got.Outputs {
+		size, err := valueCborSize(ValueFromMaryValue(out.OutputAmount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size > 5000 {
+			t.Errorf("output %d value is %d bytes, above the max value size", i, size)
+		}
+		coin += out.OutputAmount.Amount
+	}
+	if last := got.Outputs[len(got.Outputs)-1]; last.OutputAddress.String() != second.String() || last.OutputAmount.Amount != 19_933_333 {
+		t.Errorf("expected the second destination to keep its share, got %d to %s", last.OutputAmount.Amount, last.OutputAddress.String())
+	}
+	if coin != 60_000_000-200_000 {
+		t.Errorf("change outputs hold %d lovelace, want %d", coin, 60_000_000-200_000)
+	}
+}
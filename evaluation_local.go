@@ -0,0 +1,204 @@
+package apollo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/common/script"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
+)
+
+// SlotConfig maps slots to POSIX time for a network, which script contexts
+// need to express a transaction's validity interval. It implements
+// common.SlotState.
+type SlotConfig struct {
+	// ZeroTime is the start of ZeroSlot.
+	ZeroTime time.Time
+	// ZeroSlot is the first slot with SlotLength, the start of the Shelley
+	// era on networks that began in Byron.
+	ZeroSlot   uint64
+	SlotLength time.Duration
+}
+
+var (
+	// MainnetSlotConfig is the slot configuration of mainnet.
+	MainnetSlotConfig = SlotConfig{ZeroTime: time.UnixMilli(1596059091000), ZeroSlot: 4492800, SlotLength: time.Second}
+	// PreprodSlotConfig is the slot configuration of the preprod testnet.
+	PreprodSlotConfig = SlotConfig{ZeroTime: time.UnixMilli(1655769600000), ZeroSlot: 86400, SlotLength: time.Second}
+	// PreviewSlotConfig is the slot configuration of the preview testnet.
+	PreviewSlotConfig = SlotConfig{ZeroTime: time.UnixMilli(1666656000000), ZeroSlot: 0, SlotLength: time.Second}
+)
+
+// SlotToTime returns the start time of slot.
+func (c SlotConfig) SlotToTime(slot uint64) (time.Time, error) {
+	if c.SlotLength <= 0 {
+		return time.Time{}, errors.New("slot length must be positive")
+	}
+	if slot < c.ZeroSlot {
+		return time.Time{}, fmt.Errorf("slot %d is before the zero slot %d", slot, c.ZeroSlot)
+	}
+	return c.ZeroTime.Add(time.Duration(slot-c.ZeroSlot) * c.SlotLength), nil //nolint:gosec // slot distances fit in int64 nanoseconds for any real chain
+}
+
+// TimeToSlot returns the slot containing t.
+func (c SlotConfig) TimeToSlot(t time.Time) (uint64, error) {
+	if c.SlotLength <= 0 {
+		return 0, errors.New("slot length must be positive")
+	}
+	if t.Before(c.ZeroTime) {
+		return 0, fmt.Errorf("time %s is before the zero time %s", t, c.ZeroTime)
+	}
+	return c.ZeroSlot + uint64(t.Sub(c.ZeroTime)/c.SlotLength), nil //nolint:gosec // non-negative above
+}
+
+// SetLocalEvaluation makes Complete() compute execution units with the
+// plutigo CEK machine instead of the chain context's EvaluateTx, so script
+// transactions can be built offline. Spending inputs must be resolved,
+// reference inputs must resolve through the chain context, and every script
+// run must be Plutus V3, attached or carried as a reference script. slots
+// converts the validity interval to the POSIX times scripts see. Scripts are
+// run with the per-transaction execution limits as budget and plutigo's
+// built-in cost model.
+func (a *Apollo) SetLocalEvaluation(slots SlotConfig) *Apollo {
+	if slots.SlotLength <= 0 {
+		a.setErrOnce(fmt.Errorf("SetLocalEvaluation: slot length must be positive, got %s", slots.SlotLength))
+		return a
+	}
+	a.localEvaluation = &slots
+	return a
+}
+
+// evaluateLocally runs every redeemer of the encoded preliminary transaction
+// and returns the execution units each used.
+func (a *Apollo) evaluateLocally(txBytes []byte, inputs []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+	tx, err := conway.NewConwayTransactionFromCbor(txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction for local evaluation: %w", err)
+	}
+	resolved, err := a.resolvedEvaluationInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+	scripts := make(map[common.Blake2b224]common.Script)
+	for _, s := range a.v1scripts {
+		scripts[s.Hash()] = s
+	}
+	for _, s := range a.v2scripts {
+		scripts[s.Hash()] = s
+	}
+	for _, s := range a.v3scripts {
+		scripts[s.Hash()] = s
+	}
+	for _, utxo := range resolved {
+		if s := utxo.Output.ScriptRef(); s != nil {
+			scripts[s.Hash()] = s
+		}
+	}
+	// Find every script before building the context, so a missing or
+	// unsupported script is reported as such.
+	v3scripts := make(map[common.RedeemerKey]common.PlutusV3Script)
+	for key := range a.buildRedeemerMap(inputs) {
+		hash, err := a.redeemerScriptHash(key, inputs)
+		if err != nil {
+			return nil, err
+		}
+		switch s := scripts[hash].(type) {
+		case common.PlutusV3Script:
+			v3scripts[key] = s
+		case *common.PlutusV3Script:
+			v3scripts[key] = *s
+		case nil:
+			return nil, fmt.Errorf("script %s for redeemer %d:%d is neither attached nor available by reference", hash, key.Tag, key.Index)
+		default:
+			return nil, fmt.Errorf("local evaluation supports Plutus V3 scripts only; script %s is %T", hash, s)
+		}
+	}
+	maxMem, maxSteps, err := a.maxTxExUnits()
+	if err != nil {
+		return nil, err
+	}
+	if maxMem == 0 || maxSteps == 0 {
+		return nil, errors.New("local evaluation needs the per-transaction execution limits")
+	}
+
+	txInfo, err := script.NewTxInfoV3FromTransaction(*a.localEvaluation, tx, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build script context: %w", err)
+	}
+	result := make(map[common.RedeemerKey]common.ExUnits, len(v3scripts))
+	for _, pair := range txInfo.Redeemers {
+		key := common.RedeemerKey{Tag: pair.Value.Tag, Index: pair.Value.Index}
+		v3, ok := v3scripts[key]
+		if !ok {
+			return nil, fmt.Errorf("redeemer %d:%d is not registered with the builder", key.Tag, key.Index)
+		}
+		ctx := script.NewScriptContextV3(txInfo, pair.Value, pair.Key)
+		used, err := v3.Evaluate(ctx.ToPlutusData(), common.ExUnits{Memory: maxMem, Steps: maxSteps}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("script %s failed for redeemer %d:%d: %w", v3.Hash(), key.Tag, key.Index, err)
+		}
+		result[key] = used
+	}
+	return result, nil
+}
+
+// resolvedEvaluationInputs returns the spending inputs followed by the
+// resolved reference inputs.
+func (a *Apollo) resolvedEvaluationInputs(inputs []common.Utxo) ([]common.Utxo, error) {
+	resolved := make([]common.Utxo, 0, len(inputs)+len(a.referenceInputs))
+	resolved = append(resolved, inputs...)
+	for _, refInput := range a.referenceInputs {
+		utxo, err := a.Context.UtxoByRef(refInput.TxId, refInput.OutputIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve reference input %s#%d for local evaluation: %w",
+				hex.EncodeToString(refInput.TxId.Bytes()), refInput.OutputIndex, err)
+		}
+		if utxo == nil || utxo.Output == nil {
+			return nil, fmt.Errorf("reference input %s#%d not found for local evaluation",
+				hex.EncodeToString(refInput.TxId.Bytes()), refInput.OutputIndex)
+		}
+		resolved = append(resolved, *utxo)
+	}
+	return resolved, nil
+}
+
+// redeemerScriptHash returns the hash of the script a redeemer runs, using
+// the same index order as buildRedeemerMap.
+func (a *Apollo) redeemerScriptHash(key common.RedeemerKey, inputs []common.Utxo) (common.Blake2b224, error) {
+	switch key.Tag {
+	case common.RedeemerTagSpend:
+		if uint64(key.Index) >= uint64(len(inputs)) {
+			return common.Blake2b224{}, fmt.Errorf("spend redeemer index %d out of range (%d inputs)", key.Index, len(inputs))
+		}
+		return inputs[key.Index].Output.Address().PaymentKeyHash(), nil
+	case common.RedeemerTagMint:
+		policies := a.sortedMintPolicyIds()
+		if uint64(key.Index) >= uint64(len(policies)) {
+			return common.Blake2b224{}, fmt.Errorf("mint redeemer index %d out of range (%d policies)", key.Index, len(policies))
+		}
+		policyBytes, err := hex.DecodeString(policies[key.Index])
+		if err != nil || len(policyBytes) != common.Blake2b224Size {
+			return common.Blake2b224{}, fmt.Errorf("invalid mint policy %s", policies[key.Index])
+		}
+		var policyId common.Blake2b224
+		copy(policyId[:], policyBytes)
+		return policyId, nil
+	case common.RedeemerTagReward:
+		keys := a.sortedWithdrawalKeys()
+		if uint64(key.Index) >= uint64(len(keys)) {
+			return common.Blake2b224{}, fmt.Errorf("reward redeemer index %d out of range (%d withdrawals)", key.Index, len(keys))
+		}
+		return a.withdrawals[keys[key.Index]].Address.StakeKeyHash(), nil
+	case common.RedeemerTagVoting:
+		voters := a.sortedVoters()
+		if uint64(key.Index) >= uint64(len(voters)) {
+			return common.Blake2b224{}, fmt.Errorf("voting redeemer index %d out of range (%d voters)", key.Index, len(voters))
+		}
+		return common.Blake2b224(voters[key.Index].Hash), nil
+	default:
+		return common.Blake2b224{}, fmt.Errorf("local evaluation does not support redeemer tag %d", key.Tag)
+	}
+}
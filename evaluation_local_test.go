@@ -0,0 +1,87 @@
+package apollo
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/mary"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+	plutigoData "github.com/blinklabs-io/plutigo/data"
+)
+
+func TestSlotConfig(t *testing.T) {
+	start, err := MainnetSlotConfig.SlotToTime(4492800)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start.Unix() != 1596059091 {
+		t.Errorf("expected the Shelley start time, got %s", start)
+	}
+	at, err := PreprodSlotConfig.SlotToTime(100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slot, err := PreprodSlotConfig.TimeToSlot(at.Add(500 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot != 100000 {
+		t.Errorf("expected slot 100000, got %d", slot)
+	}
+	if _, err := MainnetSlotConfig.SlotToTime(100); err == nil {
+		t.Error("expected error for a slot before the zero slot")
+	}
+	if _, err := (SlotConfig{}).TimeToSlot(time.Now()); err == nil {
+		t.Error("expected error for a zero slot length")
+	}
+}
+
+func TestSetLocalEvaluation(t *testing.T) {
+	a := New(setupFixedContext()).SetLocalEvaluation(SlotConfig{})
+	if a.err == nil || a.localEvaluation != nil {
+		t.Fatal("expected a zero slot length to be rejected")
+	}
+
+	redeemer := common.Datum{Data: plutigoData.NewInteger(big.NewInt(0))}
+	exUnits := common.ExUnits{Memory: 1000, Steps: 2000}
+	build := func(utxo common.Utxo, scripts ...common.Script) error {
+		cc := setupFixedContext()
+		addr := testAddress(t)
+		addTestUtxo(cc, addr, 20_000_000, 0x01, 0)
+		_, err := New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			SetLocalEvaluation(PreviewSlotConfig).
+			AttachScripts(scripts...).
+			CollectFrom(utxo, redeemer, exUnits).
+			SetTtl(50000000).
+			Complete()
+		return err
+	}
+
+	err := build(scriptAddressUtxo(t, 0x09, 5_000_000))
+	if err == nil || !strings.Contains(err.Error(), "neither attached nor available by reference") {
+		t.Errorf("expected a missing script error, got %v", err)
+	}
+
+	script := common.PlutusV2Script{0x01, 0x02}
+	scriptHash := script.Hash()
+	scriptAddr, err := common.NewAddressFromBytes(append([]byte{0x70}, scriptHash.Bytes()...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxo := common.Utxo{
+		Id: shelley.ShelleyTransactionInput{TxId: common.Blake2b256{0x0a}, OutputIndex: 0},
+		Output: &babbage.BabbageTransactionOutput{
+			OutputAddress: scriptAddr,
+			OutputAmount:  mary.MaryTransactionOutputValue{Amount: 5_000_000},
+		},
+	}
+	err = build(utxo, script)
+	if err == nil || !strings.Contains(err.Error(), "Plutus V3 scripts only") {
+		t.Errorf("expected a Plutus V2 script to be rejected, got %v", err)
+	}
+}
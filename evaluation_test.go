@@ -3,6 +3,8 @@ package apollo
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"math/big"
 	"strings"
 	"testing"
@@ -523,3 +525,205 @@ func TestEvaluationKeepsDeterministicOutputOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestSetExUnitBuffersAppliesCustomMargins(t *testing.T) {
+	cc := &balancedEvalContext{
+		FixedChainContext: setupFixedContext(),
+		t:                 t,
+		resultFor: func(_ int, _ *conway.ConwayTransaction, _ []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+			return mintRedeemerUnits(1_000, 1_000), nil
+		},
+	}
+	a := setupMintEvalBuilder(t, cc, 2_000_000, 5).SetExUnitBuffers(0.5, 0)
+	a, err := a.Complete()
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	got, ok := a.GetTx().WitnessSet.WsRedeemers.Redeemers[common.RedeemerKey{Tag: common.RedeemerTagMint, Index: 0}]
+	if !ok {
+		t.Fatal("expected mint redeemer in witness set")
+	}
+	if got.ExUnits.Memory != 1_500 || got.ExUnits.Steps != 1_000 {
+		t.Fatalf("ExUnits = %+v, want memory 1500 and steps 1000", got.ExUnits)
+	}
+}
+
+func TestFixedContextEvalResultDrivesEstimation(t *testing.T) {
+	cc := setupFixedContext()
+	cc.SetEvalResult(mintRedeemerUnits(1_000, 1_000))
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 50_000_000, 0x01, 0)
+	addTestUtxo(cc, addr, 20_000_000, 0x02, 0)
+
+	policyHex := strings.Repeat("ab", 28)
+	redeemer := testRedeemerDatum()
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		SetTtl(50_000_000).
+		Mint(NewUnit(policyHex, "746f6b656e", 5), &redeemer, nil).
+		SetExUnitBuffers(0.5, 0)
+	a, err := a.Complete()
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	got := a.GetTx().WitnessSet.WsRedeemers.Redeemers[common.RedeemerKey{Tag: common.RedeemerTagMint, Index: 0}]
+	if got.ExUnits.Memory != 1_500 || got.ExUnits.Steps != 1_000 {
+		t.Fatalf("ExUnits = %+v, want memory 1500 and steps 1000", got.ExUnits)
+	}
+}
+
+func TestSetMaxExUnitsPerRedeemer(t *testing.T) {
+	mintKey := common.RedeemerKey{Tag: common.RedeemerTagMint, Index: 0}
+	build := func(limit common.ExUnits) (*Apollo, error) {
+		cc := setupFixedContext()
+		cc.SetEvalResult(mintRedeemerUnits(1_000, 1_000))
+		addr := testAddress(t)
+		addTestUtxo(cc, addr, 50_000_000, 0x01, 0)
+		redeemer := testRedeemerDatum()
+		return New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			SetTtl(50_000_000).
+			Mint(NewUnit(strings.Repeat("ab", 28), "746f6b656e", 5), &redeemer, nil).
+			SetExUnitBuffers(0.5, 0).
+			SetMaxExUnitsPerRedeemer(mintKey, limit).
+			Complete()
+	}
+
+	// The buffered memory of 1500 fits a cap of exactly 1500.
+	if _, err := build(common.ExUnits{Memory: 1_500, Steps: 1_000}); err != nil {
+		t.Fatalf("Complete within the cap: %v", err)
+	}
+	_, err := build(common.ExUnits{Memory: 1_499, Steps: 1_000})
+	if err == nil {
+		t.Fatal("expected an execution unit cap error")
+	}
+	want := "mint redeemer 0 exceeds its execution unit cap: evaluated mem=1500 steps=1000, cap mem=1499 steps=1000"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("error = %v, want it to contain %q", err, want)
+	}
+
+	if a := New(setupFixedContext()).SetMaxExUnitsPerRedeemer(mintKey, common.ExUnits{Memory: -1}); a.err == nil {
+		t.Error("expected error for a negative cap")
+	}
+}
+
+func TestSetExUnitSafetyFactorScalesBufferedUnits(t *testing.T) {
+	cc := &balancedEvalContext{
+		FixedChainContext: setupFixedContext(),
+		t:                 t,
+		resultFor: func(_ int, _ *conway.ConwayTransaction, _ []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+			return mintRedeemerUnits(5_000_000, 1_000), nil
+		},
+	}
+	a := setupMintEvalBuilder(t, cc, 2_000_000, 5).SetExUnitBuffers(0.5, 0).SetExUnitSafetyFactor(1.8)
+	a, err := a.Complete()
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	got := a.GetTx().WitnessSet.WsRedeemers.Redeemers[common.RedeemerKey{Tag: common.RedeemerTagMint, Index: 0}]
+	if got.ExUnits.Memory != 13_500_000 || got.ExUnits.Steps != 1_800 {
+		t.Fatalf("ExUnits = %+v, want memory 13500000 and steps 1800", got.ExUnits)
+	}
+
+	// 13.5M of the fixed context's 14M memory maximum is within 10%.
+	warnings, err := a.ExUnitsBudgetWarnings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "execution memory 13500000") {
+		t.Fatalf("expected a memory budget warning, got %v", warnings)
+	}
+
+	for _, factor := range []float64{0.5, math.NaN(), math.Inf(1)} {
+		if b := New(setupFixedContext()).SetExUnitSafetyFactor(factor); b.err == nil {
+			t.Errorf("expected builder error for factor %v", factor)
+		}
+	}
+}
+
+func TestEvaluateRetriesWithRaisedBudgetsOnBudgetError(t *testing.T) {
+	cc := &balancedEvalContext{
+		FixedChainContext: setupFixedContext(),
+		t:                 t,
+		resultFor: func(_ int, tx *conway.ConwayTransaction, _ []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+			prelim := tx.WitnessSet.WsRedeemers.Redeemers[common.RedeemerKey{Tag: common.RedeemerTagMint, Index: 0}]
+			if prelim.ExUnits.Memory < 1_000_000 {
+				return nil, errors.New("script exceeded its execution budget")
+			}
+			return mintRedeemerUnits(1_000, 1_000), nil
+		},
+	}
+	a, err := setupMintEvalBuilder(t, cc, 2_000_000, 5).Complete()
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if len(cc.calls) < 2 {
+		t.Fatalf("expected a retry after the budget error, got %d calls", len(cc.calls))
+	}
+	got := a.GetTx().WitnessSet.WsRedeemers.Redeemers[common.RedeemerKey{Tag: common.RedeemerTagMint, Index: 0}]
+	if got.ExUnits.Memory != 1_200 || got.ExUnits.Steps != 1_200 {
+		t.Fatalf("ExUnits = %+v, want the evaluated units, not the raised preliminary budget", got.ExUnits)
+	}
+
+	cc = &balancedEvalContext{
+		FixedChainContext: setupFixedContext(),
+		t:                 t,
+		resultFor: func(_ int, _ *conway.ConwayTransaction, _ []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+			return nil, errors.New("script exceeded its execution budget")
+		},
+	}
+	_, err = setupMintEvalBuilder(t, cc, 2_000_000, 5).Complete()
+	if err == nil || !strings.Contains(err.Error(), "retries") {
+		t.Fatalf("expected a non-convergence error, got %v", err)
+	}
+	if len(cc.calls) != maxEvalBudgetRetries+1 {
+		t.Fatalf("expected %d evaluation attempts, got %d", maxEvalBudgetRetries+1, len(cc.calls))
+	}
+}
+
+func TestEvaluatePreliminaryTxKeepsRegisteredBudgets(t *testing.T) {
+	cc := &balancedEvalContext{
+		FixedChainContext: setupFixedContext(),
+		t:                 t,
+		resultFor: func(call int, _ *conway.ConwayTransaction, _ []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+			if call == 0 {
+				return nil, errors.New("script exceeded its execution budget")
+			}
+			return mintRedeemerUnits(1_000, 1_000), nil
+		},
+	}
+	a := setupMintEvalBuilder(t, cc, 2_000_000, 5)
+	registered := a.mintRedeemers
+	before := maps.Clone(registered)
+	if _, err := a.evaluatePreliminaryTx(nil, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.calls) != 2 {
+		t.Fatalf("expected a retry with raised budgets, got %d calls", len(cc.calls))
+	}
+	if !maps.Equal(registered, before) {
+		t.Fatalf("registered budgets changed from %v to %v", before, registered)
+	}
+}
+
+func TestSetExUnitBuffersRejectsInvalidValues(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		mem, step float64
+	}{
+		{"negative memory", -0.1, 0.2},
+		{"negative steps", 0.2, -0.1},
+		{"NaN memory", math.NaN(), 0.2},
+		{"infinite steps", 0.2, math.Inf(1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := New(setupFixedContext()).SetExUnitBuffers(tc.mem, tc.step)
+			if a.err == nil {
+				t.Fatal("expected builder error")
+			}
+			if a.exMemoryBuffer != ExMemoryBuffer || a.exStepBuffer != ExStepBuffer {
+				t.Fatal("invalid buffers must not replace the defaults")
+			}
+		})
+	}
+}
@@ -49,6 +49,15 @@ func missingSignerHashes(required map[common.Blake2b224]struct{}, found map[comm
 	return sortedSignerHashes(missing)
 }
 
+// walletSignsTxBody reports whether w can sign a body hash locally.
+func walletSignsTxBody(w Wallet) bool {
+	if _, isExternal := w.(*ExternalWallet); isExternal {
+		return false
+	}
+	_, signsWholeTx := w.(TxSigner)
+	return !signsWholeTx
+}
+
 // evaluationWitnesses resolves and validates witnesses for the exact
 // preliminary body sent to an evaluator. These witnesses are intentionally
 // local to evaluation and are never retained in a completed transaction.
@@ -94,9 +103,10 @@ func (a *Apollo) evaluationWitnesses(body *conway.ConwayTransactionBody) ([]comm
 	}
 
 	if a.wallet != nil {
-		// ExternalWallet is watch-only and supplies no evaluation witnesses;
-		// callers must register EvaluationWitnessProvider for its required hashes.
-		if _, isExternal := a.wallet.(*ExternalWallet); !isExternal {
+		// ExternalWallet is watch-only and TxSigner wallets such as CIP30Wallet
+		// only sign whole transactions, so neither can sign the evaluation body;
+		// callers must register EvaluationWitnessProvider for their required hashes.
+		if walletSignsTxBody(a.wallet) {
 			paymentHash := a.wallet.PubKeyHash()
 			if _, needed := required[paymentHash]; needed {
 				witness, err := a.wallet.SignTxBody(bodyHash)
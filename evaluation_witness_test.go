@@ -368,3 +368,50 @@ func TestCloneCopiesEvaluationProvidersWithoutAliasing(t *testing.T) {
 		t.Fatalf("provider slices aliased: original %d clone %d", len(original.evaluationWitnessProviders), len(clone.evaluationWitnessProviders))
 	}
 }
+
+func TestCompleteCIP30WalletRequiredSignerUsesProvider(t *testing.T) {
+	bw, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := &fakeCIP30{wallet: bw}
+	w, err := NewCIP30Wallet(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := &balancedEvalContext{
+		FixedChainContext: setupFixedContext(),
+		t:                 t,
+		resultFor: func(_ int, _ *conway.ConwayTransaction, _ []common.Utxo) (map[common.RedeemerKey]common.ExUnits, error) {
+			return mintRedeemerUnits(1_000, 1_000), nil
+		},
+	}
+	addTestUtxo(cc.FixedChainContext, w.Address(), 50_000_000, 0x01, 0)
+	addTestUtxo(cc.FixedChainContext, w.Address(), 20_000_000, 0x02, 0)
+	policyHex := strings.Repeat("ab", 28)
+	redeemer := testRedeemerDatum()
+
+	_, err = New(cc).
+		SetWallet(w).
+		AddRequiredSigner(w.PubKeyHash()).
+		AddEvaluationWitnessProvider(bw).
+		PayToAddress(testAddress(t), 2_000_000).
+		SetTtl(50_000_000).
+		Mint(NewUnit(policyHex, "746f6b656e", 1), &redeemer, nil).
+		Complete()
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if conn.signed != 0 {
+		t.Fatalf("CIP-30 connector must not sign evaluation txs, got %d SignTx calls", conn.signed)
+	}
+	if len(cc.calls) == 0 {
+		t.Fatal("expected the mint to be evaluated")
+	}
+	for _, call := range cc.calls {
+		witnesses := call.Tx.WitnessSet.VkeyWitnesses.Items()
+		if len(witnesses) != 1 || common.Blake2b224Hash(witnesses[0].Vkey) != w.PubKeyHash() {
+			t.Fatalf("expected the provider's payment witness in the evaluation tx, got %#v", witnesses)
+		}
+	}
+}
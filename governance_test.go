@@ -3,10 +3,14 @@ package apollo
 import (
 	"encoding/json"
 	"math"
+	"math/big"
 	"testing"
 
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 	"github.com/blinklabs-io/gouroboros/ledger/conway"
+	plutigoData "github.com/blinklabs-io/plutigo/data"
+
+	"github.com/Salvionied/apollo/v2/backend/fixed"
 )
 
 func newGovernanceTestApollo(t *testing.T) *Apollo {
@@ -132,6 +136,52 @@ func TestAddVoteReplacesExistingVoterAction(t *testing.T) {
 	}
 }
 
+func TestAddScriptVote(t *testing.T) {
+	redeemer := common.Datum{Data: plutigoData.NewInteger(big.NewInt(1))}
+	if a := newGovernanceTestApollo(t).AddScriptVote(testVoter(0x01), testGovActionId(0), common.VotingProcedure{}, redeemer, nil); a.err == nil {
+		t.Fatal("expected error for a key voter")
+	}
+
+	script := common.PlutusV3Script{0x01, 0x02}
+	scriptVoter := common.Voter{Type: common.VoterTypeDRepScriptHash, Hash: script.Hash()}
+	committee := common.Voter{Type: common.VoterTypeConstitutionalCommitteeHotKeyHash, Hash: [28]byte{0x07}}
+	// Committee voters sort before DRep voters, so the script voter is at
+	// index 1 whatever the hashes.
+	a := newGovernanceTestApollo(t)
+	cc, ok := a.Context.(*fixed.FixedChainContext)
+	if !ok {
+		t.Fatalf("unexpected chain context %T", a.Context)
+	}
+	cc.SetEvalResult(map[common.RedeemerKey]common.ExUnits{
+		{Tag: common.RedeemerTagVoting, Index: 1}: {Memory: 1_000, Steps: 2_000},
+	})
+	a = a.AttachScript(script).
+		AddVote(testVoter(0x01), testGovActionId(0), common.VotingProcedure{Vote: common.GovVoteYes}).
+		AddVote(committee, testGovActionId(0), common.VotingProcedure{Vote: common.GovVoteYes}).
+		AddScriptVote(scriptVoter, testGovActionId(0), common.VotingProcedure{Vote: common.GovVoteNo}, redeemer, nil)
+	if got := a.estimatedWitnessCount(nil); got != 3 {
+		t.Errorf("expected the wallet and two key voters to sign, got %d witnesses", got)
+	}
+	a, err := a.Complete()
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	redeemers := a.GetTx().WitnessSet.WsRedeemers.Redeemers
+	if len(redeemers) != 1 {
+		t.Fatalf("expected 1 redeemer, got %d", len(redeemers))
+	}
+	got, ok := redeemers[common.RedeemerKey{Tag: common.RedeemerTagVoting, Index: 1}]
+	if !ok {
+		t.Fatalf("expected a voting redeemer at index 1, got %v", redeemers)
+	}
+	if got.ExUnits.Memory < 1_000 || got.ExUnits.Steps < 2_000 {
+		t.Errorf("expected the evaluated budget, got %+v", got.ExUnits)
+	}
+	if len(a.GetTx().Body.TxVotingProcedures) != 3 {
+		t.Errorf("expected 3 voters, got %d", len(a.GetTx().Body.TxVotingProcedures))
+	}
+}
+
 func TestAddProposal(t *testing.T) {
 	a := newGovernanceTestApollo(t)
 	proposal := testInfoProposal(t, 2_000_000, "https://example.com/proposal")
@@ -168,6 +218,52 @@ func TestAddMultipleProposals(t *testing.T) {
 	}
 }
 
+func TestProposeGovernanceAction(t *testing.T) {
+	rewardAddr, err := RewardAddressFromCredential(testCredential(0x11), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anchor := *testGovAnchor("https://example.com/withdrawal.json")
+	withdrawal := &common.TreasuryWithdrawalGovAction{
+		Withdrawals: map[*common.Address]uint64{&rewardAddr: 1_000_000_000},
+	}
+	for name, a := range map[string]*Apollo{
+		"zero deposit":   newGovernanceTestApollo(t).ProposeGovernanceAction(0, rewardAddr, withdrawal, anchor),
+		"base address":   newGovernanceTestApollo(t).ProposeGovernanceAction(50_000_000, testAddress(t), withdrawal, anchor),
+		"unknown action": newGovernanceTestApollo(t).ProposeGovernanceAction(50_000_000, rewardAddr, nil, anchor),
+	} {
+		if a.err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	a, err := newGovernanceTestApollo(t).
+		ProposeGovernanceAction(50_000_000, rewardAddr, withdrawal, anchor).
+		Complete()
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	body := a.GetTx().Body
+	if len(body.TxProposalProcedures) != 1 {
+		t.Fatalf("expected 1 proposal, got %d", len(body.TxProposalProcedures))
+	}
+	proposal := body.TxProposalProcedures[0]
+	if proposal.PPDeposit != 50_000_000 || proposal.PPRewardAccount.String() != rewardAddr.String() || proposal.PPAnchor.Url != anchor.Url {
+		t.Fatalf("unexpected proposal %+v", proposal)
+	}
+	wantType := uint(common.GovActionTypeTreasuryWithdrawal)
+	if proposal.PPGovAction.Type != wantType || withdrawal.Type != wantType {
+		t.Fatalf("expected action type %d, got %d", wantType, proposal.PPGovAction.Type)
+	}
+	var outputs uint64
+	for _, out := range body.TxOutputs {
+		outputs += out.OutputAmount.Amount
+	}
+	if outputs+body.TxFee+50_000_000 != 100_000_000 {
+		t.Fatalf("deposit not balanced: outputs %d, fee %d", outputs, body.TxFee)
+	}
+}
+
 func TestVotingAndProposalFieldsNilByDefault(t *testing.T) {
 	a := newGovernanceTestApollo(t)
 
@@ -294,7 +390,9 @@ func TestAuthorizeCommitteeHotKey(t *testing.T) {
 	a := newGovernanceTestApollo(t)
 	cold := testCredential(0x11)
 	hot := testCredential(0x22)
-	a.AuthorizeCommitteeHotKey(cold, hot)
+	if _, err := a.AuthorizeCommitteeHotKey(cold, hot); err != nil {
+		t.Fatal(err)
+	}
 
 	cert := requireCertificate[*common.AuthCommitteeHotCertificate](t, a, common.CertificateTypeAuthCommitteeHot)
 	if cert.ColdCredential.Credential != cold.Credential {
@@ -305,10 +403,56 @@ func TestAuthorizeCommitteeHotKey(t *testing.T) {
 	}
 }
 
+func TestAuthorizeCommitteeHotKeyResolvesAddress(t *testing.T) {
+	a := newGovernanceTestApollo(t)
+	addr := testAddress(t)
+	if _, err := a.AuthorizeCommitteeHotKey(addr, validTestAddrBech32); err != nil {
+		t.Fatal(err)
+	}
+
+	cert := requireCertificate[*common.AuthCommitteeHotCertificate](t, a, common.CertificateTypeAuthCommitteeHot)
+	if cert.ColdCredential.Credential != addr.StakeKeyHash() {
+		t.Fatal("cold credential not resolved from address")
+	}
+	if cert.HotCredential.Credential != addr.StakeKeyHash() {
+		t.Fatal("hot credential not resolved from bech32 address")
+	}
+}
+
+func TestAuthorizeCommitteeHotKeyInvalidCredential(t *testing.T) {
+	a := newGovernanceTestApollo(t)
+	if _, err := a.AuthorizeCommitteeHotKey(testCredential(0x11), 42); err == nil {
+		t.Fatal("expected error for unsupported hot credential type")
+	}
+	if len(a.certificates) != 0 {
+		t.Fatalf("expected no certificate, got %d", len(a.certificates))
+	}
+}
+
+func TestCommitteeColdWitnessesCountedOnce(t *testing.T) {
+	a := newGovernanceTestApollo(t)
+	cold := testCredential(0x11)
+	if _, err := a.AuthorizeCommitteeHotKey(cold, testCredential(0x22)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ResignCommitteeColdKey(cold, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(a.committeeColdWitnesses()); got != 1 {
+		t.Fatalf("expected 1 cold key witness, got %d", got)
+	}
+	a.AddRequiredSigner(common.Blake2b224(cold.Credential))
+	if got := len(a.committeeColdWitnesses()); got != 0 {
+		t.Fatalf("expected required signer to cover cold key, got %d", got)
+	}
+}
+
 func TestResignCommitteeColdKey(t *testing.T) {
 	a := newGovernanceTestApollo(t)
 	cold := testCredential(0x33)
-	a.ResignCommitteeColdKey(cold, testGovAnchor("https://example.com/resign.json"))
+	if _, err := a.ResignCommitteeColdKey(cold, testGovAnchor("https://example.com/resign.json")); err != nil {
+		t.Fatal(err)
+	}
 
 	cert := requireCertificate[*common.ResignCommitteeColdCertificate](t, a, common.CertificateTypeResignCommitteeCold)
 	if cert.ColdCredential.Credential != cold.Credential {
@@ -322,7 +466,9 @@ func TestResignCommitteeColdKey(t *testing.T) {
 func TestResignCommitteeColdKeyNoAnchor(t *testing.T) {
 	a := newGovernanceTestApollo(t)
 	cold := testCredential(0x44)
-	a.ResignCommitteeColdKey(cold, nil)
+	if _, err := a.ResignCommitteeColdKey(&cold, nil); err != nil {
+		t.Fatal(err)
+	}
 
 	cert := requireCertificate[*common.ResignCommitteeColdCertificate](t, a, common.CertificateTypeResignCommitteeCold)
 	if cert.ColdCredential.Credential != cold.Credential {
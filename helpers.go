@@ -13,6 +13,8 @@ import (
 	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 	"github.com/blinklabs-io/gouroboros/ledger/mary"
+
+	"github.com/Salvionied/apollo/v2/plutusencoder"
 )
 
 // Value represents an amount of ADA (in lovelace) with optional native assets.
@@ -279,6 +281,30 @@ func MultiAssetIsEmpty(m *common.MultiAsset[common.MultiAssetTypeOutput]) bool {
 	return true
 }
 
+// DatumFromUTxO decodes the inline datum of utxo into v using the
+// plutusencoder struct tags. It fails if the output carries only a datum hash
+// or no datum at all.
+func DatumFromUTxO(utxo common.Utxo, v any) error {
+	if utxo.Output == nil {
+		return errors.New("UTxO has no output")
+	}
+	datum := utxo.Output.Datum()
+	if datum == nil {
+		if utxo.Output.DatumHash() != nil {
+			return fmt.Errorf("UTxO %s has a datum hash but no inline datum", utxoRef(utxo))
+		}
+		return fmt.Errorf("UTxO %s has no datum", utxoRef(utxo))
+	}
+	pd, err := plutusencoder.FromDatum(*datum)
+	if err != nil {
+		return fmt.Errorf("UTxO %s has an unreadable inline datum: %w", utxoRef(utxo), err)
+	}
+	if err := plutusencoder.UnmarshalPlutus(pd, v); err != nil {
+		return fmt.Errorf("failed to unmarshal inline datum: %w", err)
+	}
+	return nil
+}
+
 // NewDatumOptionHash creates a BabbageTransactionOutputDatumOption with a datum hash.
 func NewDatumOptionHash(hash common.Blake2b256) (*babbage.BabbageTransactionOutputDatumOption, error) {
 	cborBytes, err := cbor.Encode([]any{0, hash})
@@ -592,6 +618,39 @@ func MinLovelacePostAlonzo(output *babbage.BabbageTransactionOutput, coinsPerUtx
 	return coinsPerUtxoByte * sizeFactor, nil
 }
 
+// MinLovelaceForOutput returns the minimum lovelace for output under the given
+// coins-per-UTxO-byte protocol parameter, without needing a chain context. The
+// output is measured as encoded, so inline datums, datum hashes, and reference
+// scripts all count toward its size. The lovelace amount is part of the
+// encoding, so the output is sized holding the larger of its own lovelace and
+// the minimum, as Calculator.MinAda and Payment.EnsureMinUTXO do.
+func MinLovelaceForOutput(output babbage.BabbageTransactionOutput, coinsPerUtxoByte uint64) (int64, error) {
+	if coinsPerUtxoByte > math.MaxInt64 {
+		return 0, fmt.Errorf("minimum lovelace calculation overflows: coins_per_utxo_byte=%d", coinsPerUtxoByte)
+	}
+	return minLovelaceHolding(output, int64(coinsPerUtxoByte))
+}
+
+// minLovelaceHolding returns the minimum lovelace of output once it holds the
+// larger of its own lovelace and that minimum. Raising the lovelace can
+// lengthen its encoding and so the minimum, which settles within 1-2 passes.
+func minLovelaceHolding(output babbage.BabbageTransactionOutput, coinsPerUtxoByte int64) (int64, error) {
+	for range 3 {
+		minCoin, err := MinLovelacePostAlonzo(&output, coinsPerUtxoByte)
+		if err != nil {
+			return 0, err
+		}
+		if minCoin < 0 {
+			return 0, fmt.Errorf("invalid min UTxO: %d", minCoin)
+		}
+		if output.OutputAmount.Amount >= uint64(minCoin) {
+			return minCoin, nil
+		}
+		output.OutputAmount.Amount = uint64(minCoin)
+	}
+	return 0, errors.New("min UTxO did not converge after 3 iterations")
+}
+
 // --- ScriptRef Constructors ---
 
 // NewScriptRef creates a ScriptRef by detecting the script type automatically.
@@ -668,6 +727,46 @@ func GetStakeCredentialFromAddress(addr common.Address) (common.Credential, erro
 	}
 }
 
+// RewardAddressFromAddress returns the reward (stake) address for the staking
+// credential of addr, on the same network: header type 14 for a key-hash
+// credential or 15 for a script-hash credential.
+func RewardAddressFromAddress(addr common.Address) (common.Address, error) {
+	cred, err := GetStakeCredentialFromAddress(addr)
+	if err != nil {
+		return common.Address{}, err
+	}
+	addrBytes, err := addr.Bytes()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to encode address: %w", err)
+	}
+	if len(addrBytes) == 0 {
+		return common.Address{}, errors.New("address is empty")
+	}
+	return RewardAddressFromCredential(cred, addrBytes[0]&0x0f)
+}
+
+// RewardAddressFromCredential returns the reward (stake) address of a stake
+// credential on network (0 for testnets, 1 for mainnet), for callers that
+// hold a credential rather than a full address, such as script stake.
+func RewardAddressFromCredential(cred common.Credential, network uint8) (common.Address, error) {
+	if network > 0x0f {
+		return common.Address{}, fmt.Errorf("invalid network id %d: must fit in 4 bits", network)
+	}
+	var addrType byte
+	switch cred.CredType {
+	case common.CredentialTypeAddrKeyHash:
+		addrType = byte(common.AddressTypeNoneKey)
+	case common.CredentialTypeScriptHash:
+		addrType = byte(common.AddressTypeNoneScript)
+	default:
+		return common.Address{}, fmt.Errorf("unsupported stake credential type %d", cred.CredType)
+	}
+	raw := make([]byte, 0, 1+common.Blake2b224Size)
+	raw = append(raw, addrType<<4|network)
+	raw = append(raw, common.Blake2b224(cred.Credential).Bytes()...)
+	return common.NewAddressFromBytes(raw)
+}
+
 // MultiAssetFromMap creates a MultiAsset from a policy->asset->quantity map.
 func MultiAssetFromMap(data map[common.Blake2b224]map[cbor.ByteString]*big.Int) *common.MultiAsset[common.MultiAssetTypeOutput] {
 	if len(data) == 0 {
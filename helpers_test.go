@@ -1,12 +1,18 @@
 package apollo
 
 import (
+	"bytes"
+	"math"
 	"math/big"
 	"strings"
 	"testing"
 
 	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
+	plutigoData "github.com/blinklabs-io/plutigo/data"
+
+	"github.com/Salvionied/apollo/v2/plutusencoder"
 )
 
 func testPolicyId(b byte) common.Blake2b224 {
@@ -367,6 +373,92 @@ func TestMinLovelacePostAlonzo(t *testing.T) {
 	}
 }
 
+func TestMinLovelaceForOutput(t *testing.T) {
+	addr := testAddress(t)
+	output := NewBabbageOutputSimple(addr, 2_000_000)
+
+	// A 57-byte base address with a 5-byte coin encodes to 67 bytes:
+	// (67 + 160) * 4310 = 978370 lovelace.
+	got, err := MinLovelaceForOutput(output, 4310)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 978_370 {
+		t.Errorf("expected 978370, got %d", got)
+	}
+
+	var hash common.Blake2b256
+	datumOpt, err := NewDatumOptionHash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output.DatumOption = datumOpt
+	withDatum, err := MinLovelaceForOutput(output, 4310)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withDatum <= got {
+		t.Errorf("expected datum hash to raise min lovelace above %d, got %d", got, withDatum)
+	}
+
+	if _, err := MinLovelaceForOutput(output, 0); err == nil {
+		t.Error("expected error for zero coins_per_utxo_byte")
+	}
+	if _, err := MinLovelaceForOutput(output, math.MaxUint64); err == nil {
+		t.Error("expected overflow error")
+	}
+}
+
+type testTypedDatum struct {
+	_      struct{} `plutusType:"DefList" plutusConstr:"0"`
+	Owner  []byte   `plutusType:"Bytes"`
+	Amount int64    `plutusType:"Int"`
+}
+
+func mustMarshalPlutus(t *testing.T, v any) plutigoData.PlutusData {
+	t.Helper()
+	pd, err := plutusencoder.MarshalPlutus(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pd
+}
+
+func TestDatumFromUTxO(t *testing.T) {
+	want := testTypedDatum{Owner: []byte{0xab, 0xcd}, Amount: 42}
+	inline, err := NewDatumOptionInline(&common.Datum{Data: mustMarshalPlutus(t, &want)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxo := makeAssetTestUtxo(t, common.Blake2b256{0x01}, 0, 2_000_000, nil)
+	output, ok := utxo.Output.(*babbage.BabbageTransactionOutput)
+	if !ok {
+		t.Fatalf("unexpected output type %T", utxo.Output)
+	}
+	output.DatumOption = inline
+
+	var got testTypedDatum
+	if err := DatumFromUTxO(utxo, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Amount != want.Amount || !bytes.Equal(got.Owner, want.Owner) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	hashOpt, err := NewDatumOptionHash(common.Blake2b256{0x02})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output.DatumOption = hashOpt
+	if err := DatumFromUTxO(utxo, &got); err == nil || !strings.Contains(err.Error(), "datum hash") {
+		t.Errorf("expected datum hash error, got %v", err)
+	}
+	output.DatumOption = nil
+	if err := DatumFromUTxO(utxo, &got); err == nil {
+		t.Error("expected error for a UTxO without a datum")
+	}
+}
+
 func TestNewNativeScriptPubkey(t *testing.T) {
 	var keyHash common.Blake2b224
 	keyHash[0] = 0xaa
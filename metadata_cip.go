@@ -0,0 +1,400 @@
+package apollo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// CIPStandard is a metadata standard ValidateCIP can check.
+type CIPStandard int
+
+const (
+	// CIP25 is NFT metadata under label 721.
+	CIP25 CIPStandard = iota
+	// CIP20 is a transaction message under label 674.
+	CIP20
+	// CIP27 is collection royalties under label 777.
+	CIP27
+)
+
+// Label returns the metadata label the standard lives under.
+func (s CIPStandard) Label() uint64 {
+	switch s {
+	case CIP25:
+		return 721
+	case CIP20:
+		return 674
+	case CIP27:
+		return 777
+	default:
+		return 0
+	}
+}
+
+func (s CIPStandard) String() string {
+	switch s {
+	case CIP25:
+		return "CIP-25"
+	case CIP20:
+		return "CIP-20"
+	case CIP27:
+		return "CIP-27"
+	default:
+		return fmt.Sprintf("CIPStandard(%d)", int(s))
+	}
+}
+
+// ValidateCIP checks that the metadata set on the builder has the label,
+// required fields and value types of a CIP standard, so wallets and
+// marketplaces will recognize it once on chain. All issues are reported at
+// once, joined with errors.Join. It does not repeat the generic checks of
+// ValidateMetadata.
+func (a *Apollo) ValidateCIP(standard CIPStandard) error {
+	label := standard.Label()
+	if label == 0 {
+		return fmt.Errorf("unsupported metadata standard %s", standard)
+	}
+	if a.auxiliaryData == nil {
+		return fmt.Errorf("%s: transaction has no metadata", standard)
+	}
+	raw, ok := a.auxiliaryData.metadata[label]
+	if !ok {
+		return fmt.Errorf("%s: metadata has no label %d", standard, label)
+	}
+	v := &cipValidator{standard: standard}
+	root := cipValue(raw)
+	path := fmt.Sprint(label)
+	switch standard {
+	case CIP25:
+		v.cip25(root, path)
+	case CIP20:
+		v.cip20(root, path)
+	case CIP27:
+		v.cip27(root, path)
+	}
+	return errors.Join(v.errs...)
+}
+
+type cipValidator struct {
+	standard CIPStandard
+	errs     []error
+}
+
+func (v *cipValidator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s: %s", v.standard, path, fmt.Sprintf(format, args...)))
+}
+
+// cip25 checks NFT metadata: policies mapping asset names to asset metadata
+// with a name and an image, keyed by text in version 1 and by bytes in
+// version 2.
+func (v *cipValidator) cip25(root any, path string) {
+	policies, ok := root.([]cipPair)
+	if !ok {
+		v.fail(path, "must be a map of policy IDs")
+		return
+	}
+	version := int64(1)
+	if raw, ok := cipLookup(policies, "version"); ok {
+		n, isInt := raw.(*big.Int)
+		if !isInt || !n.IsInt64() || (n.Int64() != 1 && n.Int64() != 2) {
+			v.fail(path+".version", "must be 1 or 2")
+		} else {
+			version = n.Int64()
+		}
+	}
+	count := 0
+	for _, policy := range policies {
+		if policy.key == "version" {
+			continue
+		}
+		count++
+		policyPath := path + "." + cipKeyString(policy.key)
+		var policyLen int
+		switch key := policy.key.(type) {
+		case string:
+			if version == 2 {
+				v.fail(policyPath, "version 2 policy IDs must be bytes")
+			}
+			decoded, err := hex.DecodeString(key)
+			if err != nil {
+				v.fail(policyPath, "policy ID is not hex")
+				continue
+			}
+			policyLen = len(decoded)
+		case []byte:
+			if version == 1 {
+				v.fail(policyPath, "version 1 policy IDs must be hex text")
+			}
+			policyLen = len(key)
+		default:
+			v.fail(policyPath, "policy ID must be text or bytes")
+			continue
+		}
+		if policyLen != common.Blake2b224Size {
+			v.fail(policyPath, "policy ID must be %d bytes, got %d", common.Blake2b224Size, policyLen)
+		}
+		assets, ok := policy.value.([]cipPair)
+		if !ok || len(assets) == 0 {
+			v.fail(policyPath, "must be a non-empty map of asset names")
+			continue
+		}
+		for _, asset := range assets {
+			assetPath := policyPath + "." + cipKeyString(asset.key)
+			switch key := asset.key.(type) {
+			case string:
+				if version == 2 {
+					v.fail(assetPath, "version 2 asset names must be bytes")
+				}
+			case []byte:
+				if version == 1 {
+					v.fail(assetPath, "version 1 asset names must be text")
+				}
+				if len(key) > 32 {
+					v.fail(assetPath, "asset name is longer than 32 bytes")
+				}
+			default:
+				v.fail(assetPath, "asset name must be text or bytes")
+				continue
+			}
+			v.cip25Asset(asset.value, assetPath)
+		}
+	}
+	if count == 0 {
+		v.fail(path, "has no policy entries")
+	}
+}
+
+func (v *cipValidator) cip25Asset(value any, path string) {
+	fields, ok := value.([]cipPair)
+	if !ok {
+		v.fail(path, "asset metadata must be a map")
+		return
+	}
+	if name, ok := cipLookup(fields, "name"); !ok {
+		v.fail(path, "missing required field %q", "name")
+	} else if _, isText := name.(string); !isText {
+		v.fail(path+".name", "must be text")
+	}
+	if image, ok := cipLookup(fields, "image"); !ok {
+		v.fail(path, "missing required field %q", "image")
+	} else if _, isText := cipText(image); !isText {
+		v.fail(path+".image", "must be text or a list of text chunks")
+	}
+	if mediaType, ok := cipLookup(fields, "mediaType"); ok {
+		if s, isText := mediaType.(string); !isText || !strings.HasPrefix(s, "image/") {
+			v.fail(path+".mediaType", "must be an image/* media type")
+		}
+	}
+	if description, ok := cipLookup(fields, "description"); ok {
+		if _, isText := cipText(description); !isText {
+			v.fail(path+".description", "must be text or a list of text chunks")
+		}
+	}
+	files, ok := cipLookup(fields, "files")
+	if !ok {
+		return
+	}
+	list, ok := files.([]any)
+	if !ok {
+		v.fail(path+".files", "must be a list")
+		return
+	}
+	for i, file := range list {
+		filePath := fmt.Sprintf("%s.files[%d]", path, i)
+		details, ok := file.([]cipPair)
+		if !ok {
+			v.fail(filePath, "must be a map")
+			continue
+		}
+		if mediaType, ok := cipLookup(details, "mediaType"); !ok {
+			v.fail(filePath, "missing required field %q", "mediaType")
+		} else if _, isText := mediaType.(string); !isText {
+			v.fail(filePath+".mediaType", "must be text")
+		}
+		if src, ok := cipLookup(details, "src"); !ok {
+			v.fail(filePath, "missing required field %q", "src")
+		} else if _, isText := cipText(src); !isText {
+			v.fail(filePath+".src", "must be text or a list of text chunks")
+		}
+		if name, ok := cipLookup(details, "name"); ok {
+			if _, isText := name.(string); !isText {
+				v.fail(filePath+".name", "must be text")
+			}
+		}
+	}
+}
+
+// cip20 checks a transaction message: a map whose "msg" is a list of text.
+func (v *cipValidator) cip20(root any, path string) {
+	fields, ok := root.([]cipPair)
+	if !ok {
+		v.fail(path, "must be a map")
+		return
+	}
+	msg, ok := cipLookup(fields, "msg")
+	if !ok {
+		v.fail(path, "missing required field %q", "msg")
+		return
+	}
+	lines, ok := msg.([]any)
+	if !ok || len(lines) == 0 {
+		v.fail(path+".msg", "must be a non-empty list of text")
+		return
+	}
+	for i, line := range lines {
+		if _, isText := line.(string); !isText {
+			v.fail(fmt.Sprintf("%s.msg[%d]", path, i), "must be text")
+		}
+	}
+}
+
+// cip27 checks collection royalties: a rate between 0 and 1 as decimal text
+// ("pct" in the original version of the standard) and a payment address,
+// which may be split into text chunks.
+func (v *cipValidator) cip27(root any, path string) {
+	fields, ok := root.([]cipPair)
+	if !ok {
+		v.fail(path, "must be a map")
+		return
+	}
+	rateKey := "rate"
+	rate, ok := cipLookup(fields, rateKey)
+	if !ok {
+		rateKey = "pct"
+		rate, ok = cipLookup(fields, rateKey)
+	}
+	if !ok {
+		v.fail(path, "missing required field %q", "rate")
+	} else if s, isText := rate.(string); !isText {
+		v.fail(path+"."+rateKey, "must be decimal text")
+	} else if r, valid := new(big.Rat).SetString(s); !valid || r.Sign() < 0 || r.Cmp(big.NewRat(1, 1)) > 0 {
+		v.fail(path+"."+rateKey, "must be a decimal between 0 and 1, got %q", s)
+	}
+	addr, ok := cipLookup(fields, "addr")
+	if !ok {
+		v.fail(path, "missing required field %q", "addr")
+		return
+	}
+	text, isText := cipText(addr)
+	if !isText {
+		v.fail(path+".addr", "must be text or a list of text chunks")
+		return
+	}
+	if _, err := common.NewAddress(text); err != nil {
+		v.fail(path+".addr", "invalid address: %v", err)
+	}
+}
+
+// cipPair is a metadata map entry after cipValue normalization.
+type cipPair struct {
+	key   any
+	value any
+}
+
+// cipValue normalizes builder metadata to string, []byte, *big.Int, []any and
+// []cipPair. It converts v with convertMetadatum, so it accepts every Go and
+// ledger type the builder does; issues in the value are left to
+// ValidateMetadata.
+func cipValue(v any) any {
+	m, _ := convertMetadatum(v, "")
+	if m == nil {
+		return v
+	}
+	return cipMetadatum(m)
+}
+
+// cipMetadatum normalizes a ledger metadatum for cipValue.
+func cipMetadatum(m common.TransactionMetadatum) any {
+	switch tv := m.(type) {
+	case *common.MetaText:
+		if tv != nil {
+			return cipMetadatum(*tv)
+		}
+	case *common.MetaBytes:
+		if tv != nil {
+			return cipMetadatum(*tv)
+		}
+	case *common.MetaInt:
+		if tv != nil {
+			return cipMetadatum(*tv)
+		}
+	case *common.MetaList:
+		if tv != nil {
+			return cipMetadatum(*tv)
+		}
+	case *common.MetaMap:
+		if tv != nil {
+			return cipMetadatum(*tv)
+		}
+	case common.MetaText:
+		return tv.Value
+	case common.MetaBytes:
+		return tv.Value
+	case common.MetaInt:
+		return tv.Value
+	case common.MetaList:
+		items := make([]any, 0, len(tv.Items))
+		for _, item := range tv.Items {
+			items = append(items, cipMetadatum(item))
+		}
+		return items
+	case common.MetaMap:
+		pairs := make([]cipPair, 0, len(tv.Pairs))
+		for _, pair := range tv.Pairs {
+			pairs = append(pairs, cipPair{key: cipMetadatum(pair.Key), value: cipMetadatum(pair.Value)})
+		}
+		return pairs
+	}
+	return m
+}
+
+// cipLookup returns the value of the first entry with a text key.
+func cipLookup(pairs []cipPair, key string) (any, bool) {
+	for _, pair := range pairs {
+		if k, ok := pair.key.(string); ok && k == key {
+			return pair.value, true
+		}
+	}
+	return nil, false
+}
+
+// cipText joins text or a list of text chunks, the form CIPs use for strings
+// longer than the 64-byte metadata limit.
+func cipText(v any) (string, bool) {
+	switch tv := v.(type) {
+	case string:
+		return tv, true
+	case []any:
+		if len(tv) == 0 {
+			return "", false
+		}
+		var sb strings.Builder
+		for _, chunk := range tv {
+			s, ok := chunk.(string)
+			if !ok {
+				return "", false
+			}
+			sb.WriteString(s)
+		}
+		return sb.String(), true
+	default:
+		return "", false
+	}
+}
+
+// cipKeyString renders a map key for error paths.
+func cipKeyString(key any) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return "0x" + hex.EncodeToString(k)
+	default:
+		return fmt.Sprint(k)
+	}
+}
@@ -0,0 +1,116 @@
+package apollo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestValidateCIP(t *testing.T) {
+	policy := strings.Repeat("ab", 28)
+	tests := []struct {
+		name     string
+		standard CIPStandard
+		json     string
+		wantErrs []string
+	}{
+		{
+			name:     "valid CIP-25",
+			standard: CIP25,
+			json: `{"721": {"` + policy + `": {"Token1": {
+				"name": "Token 1",
+				"image": ["ipfs://QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79", "ojWnPbdG"],
+				"mediaType": "image/png",
+				"files": [{"name": "hi-res", "mediaType": "image/png", "src": "ipfs://Qm"}]
+			}}, "version": 1}}`,
+		},
+		{
+			name:     "CIP-25 missing fields",
+			standard: CIP25,
+			json: `{"721": {"abcd": {"Token1": {
+				"name": 7,
+				"files": [{"src": "ipfs://Qm"}]
+			}}}}`,
+			wantErrs: []string{"policy ID must be 28 bytes", `.name: must be text`, `missing required field "image"`, `files[0]: missing required field "mediaType"`},
+		},
+		{
+			name:     "CIP-25 version 2 needs byte keys",
+			standard: CIP25,
+			json:     `{"721": {"` + policy + `": {"Token1": {"name": "T", "image": "ipfs://Qm"}}, "version": 2}}`,
+			wantErrs: []string{"version 2 policy IDs must be bytes", "version 2 asset names must be bytes"},
+		},
+		{
+			name:     "missing label",
+			standard: CIP25,
+			json:     `{"674": {"msg": ["hello"]}}`,
+			wantErrs: []string{"metadata has no label 721"},
+		},
+		{
+			name:     "valid CIP-20",
+			standard: CIP20,
+			json:     `{"674": {"msg": ["Invoice 42", "Thanks!"]}}`,
+		},
+		{
+			name:     "CIP-20 message must be a list",
+			standard: CIP20,
+			json:     `{"674": {"msg": "hello"}}`,
+			wantErrs: []string{"must be a non-empty list of text"},
+		},
+		{
+			name:     "valid CIP-27",
+			standard: CIP27,
+			json:     `{"777": {"rate": "0.05", "addr": ["` + validTestAddrBech32[:40] + `", "` + validTestAddrBech32[40:] + `"]}}`,
+		},
+		{
+			name:     "CIP-27 rate out of range",
+			standard: CIP27,
+			json:     `{"777": {"rate": "1.5", "addr": "not-an-address"}}`,
+			wantErrs: []string{"must be a decimal between 0 and 1", "invalid address"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, err := New(setupFixedContext()).SetShelleyMetadataFromJSON([]byte(test.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = a.ValidateCIP(test.standard)
+			if len(test.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range test.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCIPLedgerMetadatum(t *testing.T) {
+	msg := &common.MetaList{Items: []common.TransactionMetadatum{
+		&common.MetaText{Value: "Invoice 42"},
+		common.MetaText{Value: "Thanks!"},
+	}}
+	a := New(setupFixedContext()).SetShelleyMetadata(map[uint64]any{
+		674: &common.MetaMap{Pairs: []common.MetaPair{
+			{Key: &common.MetaText{Value: "msg"}, Value: msg},
+		}},
+	})
+	if err := a.ValidateCIP(CIP20); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.SetShelleyMetadata(map[uint64]any{
+		674: map[string]any{"msg": &common.MetaText{Value: "hello"}},
+	})
+	if err := a.ValidateCIP(CIP20); err == nil {
+		t.Fatal("expected a non-list message to be rejected")
+	}
+}
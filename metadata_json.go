@@ -10,6 +10,8 @@ import (
 	"math/big"
 	"sort"
 	"strings"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
 )
 
 const metadataStringMaxBytes = 64
@@ -345,3 +347,61 @@ func validateMetadataBytes(value []byte, path string) error {
 	}
 	return nil
 }
+
+// detailedMetadataJSON renders metadata using the detailed schema accepted by
+// ShelleyMetadataFromJSONWithSchema, so it round-trips without loss.
+func detailedMetadataJSON(metadata map[uint64]any) ([]byte, error) {
+	object := make(map[string]any, len(metadata))
+	for label, value := range metadata {
+		md, err := toMetadatum(value)
+		if err != nil {
+			return nil, fmt.Errorf("metadata key %d: %w", label, err)
+		}
+		detailed, err := detailedMetadataValue(md)
+		if err != nil {
+			return nil, fmt.Errorf("metadata key %d: %w", label, err)
+		}
+		object[new(big.Int).SetUint64(label).String()] = detailed
+	}
+	return json.Marshal(object)
+}
+
+func detailedMetadataValue(md common.TransactionMetadatum) (any, error) {
+	switch v := md.(type) {
+	case common.MetaInt:
+		if v.Value == nil {
+			return nil, errors.New("nil metadata integer")
+		}
+		return map[string]any{"int": json.Number(v.Value.String())}, nil
+	case common.MetaText:
+		return map[string]any{"string": v.Value}, nil
+	case common.MetaBytes:
+		return map[string]any{"bytes": hex.EncodeToString(v.Value)}, nil
+	case common.MetaList:
+		items := make([]any, 0, len(v.Items))
+		for idx, item := range v.Items {
+			detailed, err := detailedMetadataValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("list index %d: %w", idx, err)
+			}
+			items = append(items, detailed)
+		}
+		return map[string]any{"list": items}, nil
+	case common.MetaMap:
+		entries := make([]any, 0, len(v.Pairs))
+		for idx, pair := range v.Pairs {
+			key, err := detailedMetadataValue(pair.Key)
+			if err != nil {
+				return nil, fmt.Errorf("map entry %d key: %w", idx, err)
+			}
+			value, err := detailedMetadataValue(pair.Value)
+			if err != nil {
+				return nil, fmt.Errorf("map entry %d value: %w", idx, err)
+			}
+			entries = append(entries, map[string]any{"k": key, "v": value})
+		}
+		return map[string]any{"map": entries}, nil
+	default:
+		return nil, fmt.Errorf("unsupported metadatum type %T", md)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"math/big"
 	"strings"
 	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
 )
 
 func TestShelleyMetadataFromJSONNoSchemaSuccess(t *testing.T) {
@@ -361,3 +363,43 @@ func metadataMapKeysEqual(a any, b any) bool {
 		return false
 	}
 }
+
+func TestValidateMetadataReportsAllIssues(t *testing.T) {
+	a := New(nil).SetShelleyMetadata(map[uint64]any{
+		674: map[string]any{
+			"msg":  []any{strings.Repeat("x", 65), "ok"},
+			"bad":  map[int]any{1: "unsupported key type"},
+			"blob": make([]byte, 65),
+		},
+		721: 3.14,
+	})
+	err := a.ValidateMetadata()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %T", err)
+	}
+	if got := len(joined.Unwrap()); got != 4 {
+		t.Fatalf("expected 4 issues, got %d: %v", got, err)
+	}
+	for _, want := range []string{`metadata key 674."msg"[0]`, "map[int]interface", `metadata key 674."blob"`, "metadata key 721: unsupported metadata value type float64"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestValidateMetadataAcceptsSupportedTypes(t *testing.T) {
+	a := New(nil).SetShelleyMetadata(map[uint64]any{
+		1: map[uint64]any{2: []any{"text", []byte{0x01}, int64(-5), uint64(7)}},
+		3: MetadataMap{{Key: []byte{0xde, 0xad}, Value: common.MetaText{Value: "v"}}},
+	})
+	if err := a.ValidateMetadata(); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(nil).ValidateMetadata(); err != nil {
+		t.Fatalf("expected no error without metadata, got %v", err)
+	}
+}
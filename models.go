@@ -129,6 +129,14 @@ func NewPayment(receiver string, lovelace int64, units []Unit) (*Payment, error)
 	}, nil
 }
 
+// PaymentRecord is one row of a batch payment import: a bech32 receiver, a
+// lovelace amount, and the native assets to send.
+type PaymentRecord struct {
+	Address  string
+	Lovelace int64
+	Units    []Unit
+}
+
 // NewPaymentFromValue creates a Payment from an Address and Value.
 // It returns an error if a native-asset quantity exceeds the int64 range,
 // rather than silently truncating or saturating it to a wrong value.
@@ -220,43 +228,32 @@ func (p *Payment) ToValue() (Value, error) {
 	return v, nil
 }
 
-// EnsureMinUTXO ensures the payment meets the minimum UTxO requirement.
-// It iterates because raising Lovelace can increase the CBOR-encoded output size,
-// which in turn may require a slightly higher min UTxO. Converges in 1-2 iterations.
+// EnsureMinUTXO ensures the payment meets the minimum UTxO requirement,
+// raising Lovelace to the amount MinLovelaceForOutput reports for its output.
 func (p *Payment) EnsureMinUTXO(cc backend.ChainContext) error {
-	if len(p.Units) == 0 && p.Lovelace >= constants.MinLovelace && p.Datum == nil && len(p.DatumHash) == 0 && p.ScriptRef == nil {
+	return p.ensureMinUTXO(cc, constants.MinLovelace)
+}
+
+// ensureMinUTXO is EnsureMinUTXO with a configurable shortcut: a plain
+// ADA-only payment of at least minLovelace is accepted without consulting the
+// protocol parameters. A zero minLovelace always consults them.
+func (p *Payment) ensureMinUTXO(cc backend.ChainContext, minLovelace int64) error {
+	if minLovelace > 0 && len(p.Units) == 0 && p.Lovelace >= minLovelace && p.Datum == nil && len(p.DatumHash) == 0 && p.ScriptRef == nil {
 		return nil
 	}
 	pp, err := cc.ProtocolParams()
 	if err != nil {
 		return fmt.Errorf("failed to get protocol params: %w", err)
 	}
-	for range 3 {
-		txOut, err := p.ToTxOut()
-		if err != nil {
-			return fmt.Errorf("failed to build tx output: %w", err)
-		}
-		coins, err := MinLovelacePostAlonzo(txOut, pp.CoinsPerUtxoByteValue())
-		if err != nil {
-			return fmt.Errorf("failed to compute min UTxO: %w", err)
-		}
-		if p.Lovelace >= coins {
-			return nil
-		}
-		p.Lovelace = coins
-	}
-	// If we exhausted iterations without converging, verify one final time.
 	txOut, err := p.ToTxOut()
 	if err != nil {
 		return fmt.Errorf("failed to build tx output: %w", err)
 	}
-	coins, err := MinLovelacePostAlonzo(txOut, pp.CoinsPerUtxoByteValue())
+	coins, err := minLovelaceHolding(*txOut, pp.CoinsPerUtxoByteValue())
 	if err != nil {
 		return fmt.Errorf("failed to compute min UTxO: %w", err)
 	}
-	if p.Lovelace < coins {
-		return fmt.Errorf("min UTxO did not converge after 3 iterations: need %d, have %d", coins, p.Lovelace)
-	}
+	p.Lovelace = max(p.Lovelace, coins)
 	return nil
 }
 
@@ -0,0 +1,40 @@
+package plutusencoder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/plutigo/data"
+)
+
+// ToDatum wraps pd as a gouroboros datum, e.g. to attach the result of
+// MarshalPlutus to a transaction.
+func ToDatum(pd data.PlutusData) (common.Datum, error) {
+	if pd == nil {
+		return common.Datum{}, errors.New("plutus data is required")
+	}
+	return common.Datum{Data: pd}, nil
+}
+
+// FromDatum returns the plutus data of d, e.g. to pass a datum read from a
+// UTxO to UnmarshalPlutus. A datum decoded without its data is decoded again
+// from its CBOR.
+func FromDatum(d common.Datum) (data.PlutusData, error) {
+	if d.Data != nil {
+		return d.Data, nil
+	}
+	datumCbor := d.Cbor()
+	if len(datumCbor) == 0 {
+		return nil, errors.New("datum is empty")
+	}
+	var decoded common.Datum
+	if _, err := cbor.Decode(datumCbor, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode datum: %w", err)
+	}
+	if decoded.Data == nil {
+		return nil, errors.New("datum has no plutus data")
+	}
+	return decoded.Data, nil
+}
@@ -0,0 +1,49 @@
+package plutusencoder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestDatumRoundTrip(t *testing.T) {
+	original := SimpleDatum{Amount: 42, Name: []byte("hello")}
+	pd, err := MarshalPlutus(&original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datum, err := ToDatum(pd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A datum decoded from CBOR, as read from a UTxO, converts back too.
+	encoded, err := cbor.Encode(&datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded common.Datum
+	if _, err := cbor.Decode(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := FromDatum(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result SimpleDatum
+	if err := UnmarshalPlutus(got, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Amount != original.Amount || !bytes.Equal(result.Name, original.Name) {
+		t.Errorf("expected %+v, got %+v", original, result)
+	}
+
+	if _, err := ToDatum(nil); err == nil {
+		t.Error("expected error for nil plutus data")
+	}
+	if _, err := FromDatum(common.Datum{}); err == nil {
+		t.Error("expected error for an empty datum")
+	}
+}
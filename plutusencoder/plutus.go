@@ -134,17 +134,81 @@ func marshalMap(val reflect.Value, typ reflect.Type, constrTag uint, hasConstr b
 }
 
 func marshalField(fieldVal reflect.Value, field reflect.StructField) (data.PlutusData, error) {
+	if field.Tag.Get("plutusType") == "Option" {
+		return marshalOption(fieldVal, field)
+	}
+	return marshalFieldValue(fieldVal, field)
+}
+
+// marshalOption encodes a pointer field as a Plutus Maybe: nil as None
+// (Constr 1 []) and a value as Some (Constr 0 [value]).
+func marshalOption(fieldVal reflect.Value, field reflect.StructField) (data.PlutusData, error) {
+	if fieldVal.Kind() != reflect.Pointer {
+		return nil, fmt.Errorf("option field %s must be a pointer, got %s", field.Name, fieldVal.Kind())
+	}
+	if fieldVal.IsNil() {
+		return data.NewConstr(1), nil
+	}
+	inner, err := optionValueField(field)
+	if err != nil {
+		return nil, err
+	}
+	value, err := marshalFieldValue(fieldVal, inner)
+	if err != nil {
+		return nil, err
+	}
+	return data.NewConstr(0, value), nil
+}
+
+// optionValueField returns field retagged with the plutusType of the value
+// inside Some, inferred from the type the option pointer points to: integers
+// as Int, *big.Int as BigInt, bool as Bool, string as StringBytes, []byte and
+// [N]byte as Bytes, other slices as DefList and structs as nested data.
+func optionValueField(field reflect.StructField) (reflect.StructField, error) {
+	elem := field.Type.Elem()
+	var plutusType string
+	switch {
+	case elem == reflect.TypeFor[big.Int]():
+		plutusType = "BigInt"
+	case isByteSliceOrArray(elem):
+		plutusType = "Bytes"
+	default:
+		switch elem.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			plutusType = "Int"
+		case reflect.Bool:
+			plutusType = "Bool"
+		case reflect.String:
+			plutusType = "StringBytes"
+		case reflect.Slice:
+			plutusType = "DefList"
+		case reflect.Struct:
+		default:
+			return field, fmt.Errorf("option field %s has unsupported value type %s", field.Name, elem)
+		}
+	}
+	inner := field
+	inner.Tag = reflect.StructTag(fmt.Sprintf("plutusType:%q", plutusType))
+	return inner, nil
+}
+
+func marshalFieldValue(fieldVal reflect.Value, field reflect.StructField) (data.PlutusData, error) {
 	plutusType := field.Tag.Get("plutusType")
 
 	// BigInt handles nil *big.Int directly, so dispatch before pointer dereference
 	if plutusType == "BigInt" {
 		return marshalBigInt(fieldVal)
 	}
+	// Unit marker fields carry no value, so the field itself is never read
+	if plutusType == "Unit" {
+		return data.NewConstr(0), nil
+	}
 
 	// Dereference pointers
 	for fieldVal.Kind() == reflect.Pointer {
 		if fieldVal.IsNil() {
-			return nil, fmt.Errorf("nil pointer for field %s", field.Name)
+			return nil, fmt.Errorf("nil pointer for field %s (tag it plutusType:\"Option\" to encode nil as None)", field.Name)
 		}
 		fieldVal = fieldVal.Elem()
 	}
@@ -215,12 +279,23 @@ func marshalBigInt(val reflect.Value) (data.PlutusData, error) {
 }
 
 func marshalBytes(val reflect.Value) (data.PlutusData, error) {
-	if val.Kind() != reflect.Slice || val.Type().Elem().Kind() != reflect.Uint8 {
-		return nil, fmt.Errorf("bytes tag requires []byte, got %s", val.Type())
+	if !isByteSliceOrArray(val.Type()) {
+		return nil, fmt.Errorf("bytes tag requires []byte or [N]byte, got %s", val.Type())
+	}
+	if val.Kind() == reflect.Array {
+		// Arrays are not always addressable, so copy instead of val.Bytes()
+		b := make([]byte, val.Len())
+		reflect.Copy(reflect.ValueOf(b), val)
+		return data.NewByteString(b), nil
 	}
 	return data.NewByteString(val.Bytes()), nil
 }
 
+// isByteSliceOrArray reports whether t is a []byte or a fixed-size [N]byte.
+func isByteSliceOrArray(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
 func marshalStringBytes(val reflect.Value) (data.PlutusData, error) {
 	if val.Kind() != reflect.String {
 		return nil, fmt.Errorf("StringBytes tag requires string, got %s", val.Kind())
@@ -589,12 +664,52 @@ func isOptionalField(field reflect.StructField) (bool, error) {
 }
 
 func unmarshalField(pd data.PlutusData, fieldVal reflect.Value, field reflect.StructField) error {
+	if field.Tag.Get("plutusType") == "Option" {
+		return unmarshalOption(pd, fieldVal, field)
+	}
+	return unmarshalFieldValue(pd, fieldVal, field)
+}
+
+// unmarshalOption decodes a Plutus Maybe into a pointer field, leaving it nil
+// for None.
+func unmarshalOption(pd data.PlutusData, fieldVal reflect.Value, field reflect.StructField) error {
+	if fieldVal.Kind() != reflect.Pointer {
+		return fmt.Errorf("option field %s must be a pointer, got %s", field.Name, fieldVal.Kind())
+	}
+	constr, ok := pd.(*data.Constr)
+	if !ok {
+		return fmt.Errorf("expected Constr for option, got %T", pd)
+	}
+	switch {
+	case constr.Tag == 1 && len(constr.Fields) == 0:
+		fieldVal.Set(reflect.Zero(fieldVal.Type()))
+		return nil
+	case constr.Tag == 0 && len(constr.Fields) == 1:
+		inner, err := optionValueField(field)
+		if err != nil {
+			return err
+		}
+		value := reflect.New(fieldVal.Type()).Elem()
+		if err := unmarshalFieldValue(constr.Fields[0], value, inner); err != nil {
+			return err
+		}
+		fieldVal.Set(value)
+		return nil
+	default:
+		return fmt.Errorf("expected Constr 0 with 1 field or Constr 1 with none for option, got Constr %d with %d fields", constr.Tag, len(constr.Fields))
+	}
+}
+
+func unmarshalFieldValue(pd data.PlutusData, fieldVal reflect.Value, field reflect.StructField) error {
 	plutusType := field.Tag.Get("plutusType")
 
 	// BigInt handles *big.Int directly, so dispatch before pointer dereference
 	if plutusType == "BigInt" {
 		return unmarshalBigInt(pd, fieldVal)
 	}
+	if plutusType == "Unit" {
+		return unmarshalUnit(pd)
+	}
 
 	// Dereference / allocate pointers
 	for fieldVal.Kind() == reflect.Pointer {
@@ -755,13 +870,32 @@ func unmarshalBytes(pd data.PlutusData, fieldVal reflect.Value) error {
 	if !ok {
 		return fmt.Errorf("expected ByteString, got %T", pd)
 	}
-	if fieldVal.Kind() != reflect.Slice || fieldVal.Type().Elem().Kind() != reflect.Uint8 {
-		return fmt.Errorf("bytes tag requires []byte, got %s", fieldVal.Type())
+	if !isByteSliceOrArray(fieldVal.Type()) {
+		return fmt.Errorf("bytes tag requires []byte or [N]byte, got %s", fieldVal.Type())
+	}
+	if fieldVal.Kind() == reflect.Array {
+		if len(bs.Inner) != fieldVal.Len() {
+			return fmt.Errorf("expected %d bytes for %s, got %d", fieldVal.Len(), fieldVal.Type(), len(bs.Inner))
+		}
+		reflect.Copy(fieldVal, reflect.ValueOf(bs.Inner))
+		return nil
 	}
 	fieldVal.SetBytes(append([]byte(nil), bs.Inner...))
 	return nil
 }
 
+// unmarshalUnit validates that pd is the unit value Constr 0 [].
+func unmarshalUnit(pd data.PlutusData) error {
+	constr, ok := pd.(*data.Constr)
+	if !ok {
+		return fmt.Errorf("expected Constr for Unit, got %T", pd)
+	}
+	if constr.Tag != 0 || len(constr.Fields) != 0 {
+		return fmt.Errorf("expected Constr 0 [] for Unit, got Constr %d with %d fields", constr.Tag, len(constr.Fields))
+	}
+	return nil
+}
+
 func unmarshalStringBytes(pd data.PlutusData, fieldVal reflect.Value) error {
 	bs, ok := pd.(*data.ByteString)
 	if !ok {
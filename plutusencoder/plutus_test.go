@@ -1,6 +1,7 @@
 package plutusencoder
 
 import (
+	"bytes"
 	"math/big"
 	"strings"
 	"testing"
@@ -723,3 +724,159 @@ func TestRoundTripNegativeBigInt(t *testing.T) {
 		t.Errorf("round-trip failed: expected %s, got %s", negVal.String(), decoded.Value.String())
 	}
 }
+
+func TestRoundTripFixedSizeBytes(t *testing.T) {
+	type FixedBytesDatum struct {
+		_      struct{} `plutusType:"DefList" plutusConstr:"0"`
+		Pkh    [28]byte `plutusType:"Bytes"`
+		TxHash [32]byte `plutusType:"Bytes"`
+	}
+
+	var original FixedBytesDatum
+	for i := range original.Pkh {
+		original.Pkh[i] = byte(i + 1)
+	}
+	for i := range original.TxHash {
+		original.TxHash[i] = byte(0xff - i)
+	}
+
+	pd, err := MarshalPlutus(original)
+	if err != nil {
+		t.Fatalf("MarshalPlutus failed: %v", err)
+	}
+	constr, ok := pd.(*data.Constr)
+	if !ok {
+		t.Fatalf("expected Constr, got %T", pd)
+	}
+	pkh, ok := constr.Fields[0].(*data.ByteString)
+	if !ok {
+		t.Fatalf("expected ByteString, got %T", constr.Fields[0])
+	}
+	if string(pkh.Inner) != string(original.Pkh[:]) {
+		t.Errorf("expected %x, got %x", original.Pkh, pkh.Inner)
+	}
+
+	var decoded FixedBytesDatum
+	if err := UnmarshalPlutus(pd, &decoded); err != nil {
+		t.Fatalf("UnmarshalPlutus failed: %v", err)
+	}
+	if decoded != original {
+		t.Errorf("round-trip failed: expected %+v, got %+v", original, decoded)
+	}
+
+	// A ByteString of the wrong length must not fit a fixed-size array
+	short := data.NewConstr(0, data.NewByteString(make([]byte, 27)), data.NewByteString(make([]byte, 32)))
+	err = UnmarshalPlutus(short, &decoded)
+	if err == nil || !strings.Contains(err.Error(), "expected 28 bytes") {
+		t.Errorf("expected length mismatch error, got %v", err)
+	}
+}
+
+func TestRoundTripUnitField(t *testing.T) {
+	type UnitDatum struct {
+		_      struct{} `plutusType:"DefList" plutusConstr:"0"`
+		Amount int64    `plutusType:"Int"`
+		Marker struct{} `plutusType:"Unit"`
+	}
+
+	pd, err := MarshalPlutus(&UnitDatum{Amount: 7})
+	if err != nil {
+		t.Fatalf("MarshalPlutus failed: %v", err)
+	}
+	constr, ok := pd.(*data.Constr)
+	if !ok {
+		t.Fatalf("expected Constr, got %T", pd)
+	}
+	unit, ok := constr.Fields[1].(*data.Constr)
+	if !ok {
+		t.Fatalf("expected Constr, got %T", constr.Fields[1])
+	}
+	if unit.Tag != 0 || len(unit.Fields) != 0 {
+		t.Errorf("expected Constr 0 [], got Constr %d with %d fields", unit.Tag, len(unit.Fields))
+	}
+
+	var decoded UnitDatum
+	if err := UnmarshalPlutus(pd, &decoded); err != nil {
+		t.Fatalf("UnmarshalPlutus failed: %v", err)
+	}
+	if decoded.Amount != 7 {
+		t.Errorf("expected 7, got %d", decoded.Amount)
+	}
+
+	notUnit := data.NewConstr(0, data.NewInteger(big.NewInt(7)), data.NewConstr(1))
+	if err := UnmarshalPlutus(notUnit, &decoded); err == nil {
+		t.Error("expected error for non-unit marker value")
+	}
+}
+
+func TestRoundTripOptionField(t *testing.T) {
+	type Inner struct {
+		_     struct{} `plutusType:"DefList" plutusConstr:"0"`
+		Value int64    `plutusType:"Int"`
+	}
+	type OptionDatum struct {
+		_        struct{} `plutusType:"DefList" plutusConstr:"0"`
+		Deadline *int64   `plutusType:"Option"`
+		Amount   *big.Int `plutusType:"Option"`
+		Inner    *Inner   `plutusType:"Option"`
+		Owner    *[]byte  `plutusType:"Option"`
+	}
+
+	deadline := int64(1000)
+	owner := []byte{0xab}
+	original := OptionDatum{Deadline: &deadline, Inner: &Inner{Value: 5}, Owner: &owner}
+	pd, err := MarshalPlutus(&original)
+	if err != nil {
+		t.Fatalf("MarshalPlutus failed: %v", err)
+	}
+	constr, ok := pd.(*data.Constr)
+	if !ok {
+		t.Fatalf("expected Constr, got %T", pd)
+	}
+	some, ok := constr.Fields[0].(*data.Constr)
+	if !ok || some.Tag != 0 || len(some.Fields) != 1 {
+		t.Fatalf("expected Some, got %v", constr.Fields[0])
+	}
+	none, ok := constr.Fields[1].(*data.Constr)
+	if !ok || none.Tag != 1 || len(none.Fields) != 0 {
+		t.Fatalf("expected None, got %v", constr.Fields[1])
+	}
+
+	decoded := OptionDatum{Amount: big.NewInt(3)}
+	if err := UnmarshalPlutus(pd, &decoded); err != nil {
+		t.Fatalf("UnmarshalPlutus failed: %v", err)
+	}
+	if decoded.Deadline == nil || *decoded.Deadline != deadline {
+		t.Errorf("expected deadline %d, got %v", deadline, decoded.Deadline)
+	}
+	if decoded.Amount != nil {
+		t.Errorf("expected no amount, got %s", decoded.Amount)
+	}
+	if decoded.Inner == nil || decoded.Inner.Value != 5 {
+		t.Errorf("expected inner value 5, got %+v", decoded.Inner)
+	}
+	if decoded.Owner == nil || !bytes.Equal(*decoded.Owner, owner) {
+		t.Errorf("expected owner %x, got %v", owner, decoded.Owner)
+	}
+
+	notOption := data.NewConstr(0, data.NewConstr(2), data.NewConstr(1), data.NewConstr(1), data.NewConstr(1))
+	if err := UnmarshalPlutus(notOption, &decoded); err == nil {
+		t.Error("expected error for a Constr that is not an option")
+	}
+
+	type NotPointer struct {
+		_     struct{} `plutusType:"DefList" plutusConstr:"0"`
+		Value int64    `plutusType:"Option"`
+	}
+	if _, err := MarshalPlutus(&NotPointer{}); err == nil {
+		t.Error("expected error for a non-pointer option field")
+	}
+
+	type Untagged struct {
+		_     struct{} `plutusType:"DefList" plutusConstr:"0"`
+		Value *int64   `plutusType:"Int"`
+	}
+	if _, err := MarshalPlutus(&Untagged{}); err == nil || !strings.Contains(err.Error(), `plutusType:"Option"`) {
+		t.Errorf("expected a nil pointer error pointing at the Option tag, got %v", err)
+	}
+}
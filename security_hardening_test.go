@@ -10,6 +10,7 @@ import (
 
 	"github.com/blinklabs-io/bursa"
 	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 
 	"github.com/Salvionied/apollo/v2/backend/fixed"
@@ -281,6 +282,72 @@ func TestCompleteBurnWithoutTokensFailsClosed(t *testing.T) {
 	}
 }
 
+func TestCompleteBurnReportsHeldQuantity(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+
+	policyHex := strings.Repeat("aa", 28)
+	var policyId common.Blake2b224
+	for i := range policyId {
+		policyId[i] = 0xaa
+	}
+	assets := common.NewMultiAsset[common.MultiAssetTypeOutput](
+		map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput{
+			policyId: {cbor.NewByteString([]byte("tok")): big.NewInt(3)},
+		})
+	var txHash common.Blake2b256
+	txHash[0] = 0x01
+	cc.AddUtxo(addr, makeAssetTestUtxo(t, txHash, 0, 10_000_000, &assets))
+
+	p, err := NewPayment(validTestAddrBech32, 2_000_000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := New(cc).SetWallet(NewExternalWallet(addr)).AddPayment(p).SetTtl(50000000)
+	a = a.Mint(NewUnit(policyHex, "746f6b", -5), nil, nil)
+	_, err = a.Complete()
+	want := "cannot burn 5 of asset " + policyHex + ".746f6b: only 3 held"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+}
+
+func TestCheckNoAssetBurn(t *testing.T) {
+	addr := testAddress(t)
+	policyHex := strings.Repeat("aa", 28)
+	var policyId common.Blake2b224
+	for i := range policyId {
+		policyId[i] = 0xaa
+	}
+	assets := common.NewMultiAsset[common.MultiAssetTypeOutput](
+		map[common.Blake2b224]map[cbor.ByteString]common.MultiAssetTypeOutput{
+			policyId: {cbor.NewByteString([]byte("tok")): big.NewInt(5)},
+		})
+	var txHash common.Blake2b256
+	txHash[0] = 0x01
+	inputs := []common.Utxo{makeAssetTestUtxo(t, txHash, 0, 10_000_000, &assets)}
+
+	adaOnly := NewBabbageOutputSimple(addr, 9_800_000)
+	withTokens := NewBabbageOutputSimple(addr, 9_800_000)
+	withTokens.OutputAmount.Assets = CloneMultiAsset(&assets)
+
+	a := New(setupFixedContext())
+	err := a.checkNoAssetBurn(inputs, []babbage.BabbageTransactionOutput{adaOnly})
+	want := "asset " + policyHex + ".746f6b would be burned without a mint entry"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+	if err := a.checkNoAssetBurn(inputs, []babbage.BabbageTransactionOutput{withTokens}); err != nil {
+		t.Fatalf("tokens carried to an output: %v", err)
+	}
+
+	// A negative mint entry accounts for the tokens leaving the outputs.
+	a = a.Mint(NewUnit(policyHex, "746f6b", -5), nil, nil)
+	if err := a.checkNoAssetBurn(inputs, []babbage.BabbageTransactionOutput{adaOnly}); err != nil {
+		t.Fatalf("tokens burned by the mint: %v", err)
+	}
+}
+
 // --- Execution-unit evaluation fail-closed behavior ---
 
 type fakeEvalContext struct {
@@ -0,0 +1,285 @@
+package apollo
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// SignerRole is the capacity in which a key witnesses a transaction.
+type SignerRole int
+
+const (
+	// SignerRolePayment is a payment key spending an input.
+	SignerRolePayment SignerRole = iota
+	// SignerRoleStake is a stake key authorizing a certificate or withdrawal.
+	SignerRoleStake
+	// SignerRoleCommittee is a constitutional committee cold or hot key.
+	SignerRoleCommittee
+	// SignerRoleDRep is a DRep key.
+	SignerRoleDRep
+	// SignerRolePool is a stake pool operator key.
+	SignerRolePool
+)
+
+func (r SignerRole) String() string {
+	switch r {
+	case SignerRolePayment:
+		return "payment"
+	case SignerRoleStake:
+		return "stake"
+	case SignerRoleCommittee:
+		return "committee"
+	case SignerRoleDRep:
+		return "drep"
+	case SignerRolePool:
+		return "pool"
+	default:
+		return fmt.Sprintf("SignerRole(%d)", int(r))
+	}
+}
+
+// SigningElement is the part of a transaction that needs a signature.
+type SigningElement int
+
+const (
+	SigningElementInput SigningElement = iota
+	SigningElementCollateral
+	SigningElementCertificate
+	SigningElementWithdrawal
+	SigningElementVote
+	SigningElementRequiredSigner
+)
+
+func (e SigningElement) String() string {
+	switch e {
+	case SigningElementInput:
+		return "input"
+	case SigningElementCollateral:
+		return "collateral"
+	case SigningElementCertificate:
+		return "certificate"
+	case SigningElementWithdrawal:
+		return "withdrawal"
+	case SigningElementVote:
+		return "vote"
+	case SigningElementRequiredSigner:
+		return "required signer"
+	default:
+		return fmt.Sprintf("SigningElement(%d)", int(e))
+	}
+}
+
+// SigningRequirement is one key that must sign the transaction and the reason
+// it must.
+type SigningRequirement struct {
+	KeyHash common.Blake2b224
+	Role    SignerRole
+	Element SigningElement
+	// Ref identifies the element: the UTxO reference of an input or
+	// collateral, the index of a certificate, or the reward address of a
+	// withdrawal. It is empty for votes and required signers.
+	Ref string
+}
+
+// SigningRequirements lists the keys that must sign the completed transaction,
+// each with its role and the element that needs it, grouped as inputs,
+// collateral, certificates, withdrawals, votes, then required signers. Inputs,
+// collateral, certificates and required signers keep their body order; the
+// body holds withdrawals and votes in maps, so withdrawals are sorted by
+// reward address and votes by voter key hash. A key appears once per element
+// it authorizes. Script credentials need no
+// signature and are omitted, including the keys a native script may demand;
+// Byron inputs, which need bootstrap witnesses, are omitted too. Inputs are
+// resolved from the builder's UTxOs, falling back to the chain context.
+func (a *Apollo) SigningRequirements() ([]SigningRequirement, error) {
+	if a.tx == nil {
+		return nil, errors.New("transaction not built")
+	}
+	body := &a.tx.Body
+	known := a.knownUtxos()
+
+	var reqs []SigningRequirement
+	inputs := []struct {
+		element SigningElement
+		items   []common.TransactionInput
+	}{
+		{SigningElementInput, txInputs(body.TxInputs.Items())},
+		{SigningElementCollateral, txInputs(body.TxCollateral.Items())},
+	}
+	for _, group := range inputs {
+		for _, input := range group.items {
+			utxo, err := a.resolveTxInput(input, known)
+			if err != nil {
+				return nil, err
+			}
+			if hash, ok := vkeyPaymentHash(utxo); ok {
+				reqs = append(reqs, SigningRequirement{
+					KeyHash: hash,
+					Role:    SignerRolePayment,
+					Element: group.element,
+					Ref:     utxoRef(utxo),
+				})
+			}
+		}
+	}
+
+	for i, cert := range body.TxCertificates {
+		ref := strconv.Itoa(i)
+		for _, signer := range certificateSigners(cert) {
+			reqs = append(reqs, SigningRequirement{
+				KeyHash: signer.hash,
+				Role:    signer.role,
+				Element: SigningElementCertificate,
+				Ref:     ref,
+			})
+		}
+	}
+
+	var withdrawals []SigningRequirement
+	for addr := range body.TxWithdrawals {
+		if addr == nil {
+			continue
+		}
+		cred, err := GetStakeCredentialFromAddress(*addr)
+		if err != nil || cred.CredType != common.CredentialTypeAddrKeyHash {
+			continue
+		}
+		withdrawals = append(withdrawals, SigningRequirement{
+			KeyHash: common.Blake2b224(cred.Credential),
+			Role:    SignerRoleStake,
+			Element: SigningElementWithdrawal,
+			Ref:     addr.String(),
+		})
+	}
+	slices.SortFunc(withdrawals, func(x, y SigningRequirement) int {
+		return cmp.Compare(x.Ref, y.Ref)
+	})
+	reqs = append(reqs, withdrawals...)
+
+	var votes []SigningRequirement
+	for voter := range body.TxVotingProcedures {
+		if voter == nil {
+			continue
+		}
+		var role SignerRole
+		switch voter.Type {
+		case common.VoterTypeConstitutionalCommitteeHotKeyHash:
+			role = SignerRoleCommittee
+		case common.VoterTypeDRepKeyHash:
+			role = SignerRoleDRep
+		case common.VoterTypeStakingPoolKeyHash:
+			role = SignerRolePool
+		default:
+			continue
+		}
+		votes = append(votes, SigningRequirement{
+			KeyHash: common.Blake2b224(voter.Hash),
+			Role:    role,
+			Element: SigningElementVote,
+		})
+	}
+	slices.SortFunc(votes, func(x, y SigningRequirement) int {
+		return bytes.Compare(x.KeyHash[:], y.KeyHash[:])
+	})
+	reqs = append(reqs, votes...)
+
+	var stakeHash common.Blake2b224
+	if a.wallet != nil {
+		stakeHash = a.wallet.StakePubKeyHash()
+	}
+	for _, hash := range body.TxRequiredSigners.Items() {
+		role := SignerRolePayment
+		if hash == stakeHash && stakeHash != (common.Blake2b224{}) {
+			role = SignerRoleStake
+		}
+		reqs = append(reqs, SigningRequirement{
+			KeyHash: hash,
+			Role:    role,
+			Element: SigningElementRequiredSigner,
+		})
+	}
+	return reqs, nil
+}
+
+// knownUtxos indexes the builder's UTxOs by reference.
+func (a *Apollo) knownUtxos() map[string]common.Utxo {
+	known := make(map[string]common.Utxo)
+	for _, set := range [][]common.Utxo{a.preselectedUtxos, a.utxos, a.collaterals} {
+		for _, utxo := range set {
+			known[utxoRef(utxo)] = utxo
+		}
+	}
+	return known
+}
+
+// resolveTxInput finds the UTxO spent by an input among the builder's UTxOs,
+// or asks the chain context for it.
+func (a *Apollo) resolveTxInput(input common.TransactionInput, known map[string]common.Utxo) (common.Utxo, error) {
+	ref := utxoRef(common.Utxo{Id: input})
+	if utxo, ok := known[ref]; ok && utxo.Output != nil {
+		return utxo, nil
+	}
+	utxo, err := a.Context.UtxoByRef(input.Id(), input.Index())
+	if err != nil {
+		return common.Utxo{}, fmt.Errorf("failed to resolve input %s: %w", ref, err)
+	}
+	if utxo == nil || utxo.Output == nil {
+		return common.Utxo{}, fmt.Errorf("input %s not found", ref)
+	}
+	return *utxo, nil
+}
+
+type certificateSigner struct {
+	hash common.Blake2b224
+	role SignerRole
+}
+
+// certificateSigners returns the key hashes that must sign a certificate.
+func certificateSigners(cert common.CertificateWrapper) []certificateSigner {
+	keyHash := func(cred common.Credential, role SignerRole) []certificateSigner {
+		if cred.CredType != common.CredentialTypeAddrKeyHash {
+			return nil
+		}
+		return []certificateSigner{{hash: common.Blake2b224(cred.Credential), role: role}}
+	}
+	if cred, ok := certificateStakeCredential(cert); ok {
+		return keyHash(cred, SignerRoleStake)
+	}
+	switch c := cert.Certificate.(type) {
+	case *common.AuthCommitteeHotCertificate:
+		return keyHash(c.ColdCredential, SignerRoleCommittee)
+	case *common.ResignCommitteeColdCertificate:
+		return keyHash(c.ColdCredential, SignerRoleCommittee)
+	case *common.RegistrationDrepCertificate:
+		return keyHash(c.DrepCredential, SignerRoleDRep)
+	case *common.DeregistrationDrepCertificate:
+		return keyHash(c.DrepCredential, SignerRoleDRep)
+	case *common.UpdateDrepCertificate:
+		return keyHash(c.DrepCredential, SignerRoleDRep)
+	case *common.PoolRetirementCertificate:
+		return []certificateSigner{{hash: common.Blake2b224(c.PoolKeyHash), role: SignerRolePool}}
+	case *common.PoolRegistrationCertificate:
+		signers := []certificateSigner{{hash: common.Blake2b224(c.Operator), role: SignerRolePool}}
+		for _, owner := range c.PoolOwners {
+			signers = append(signers, certificateSigner{hash: common.Blake2b224(owner), role: SignerRoleStake})
+		}
+		return signers
+	default:
+		return nil
+	}
+}
+
+// txInputs converts decoded Shelley inputs to the common input interface.
+func txInputs[T common.TransactionInput](items []T) []common.TransactionInput {
+	inputs := make([]common.TransactionInput, 0, len(items))
+	for _, item := range items {
+		inputs = append(inputs, item)
+	}
+	return inputs
+}
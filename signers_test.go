@@ -0,0 +1,60 @@
+package apollo
+
+import (
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestSigningRequirements(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, w.Address(), 10_000_000, 0x01, 0)
+
+	var extra common.Blake2b224
+	extra[0] = 0x42
+	a := New(cc).SetWallet(w).PayToAddress(testAddress(t), 2_000_000).AddRequiredSigner(extra)
+	if _, err := a.SigningRequirements(); err == nil {
+		t.Fatal("expected an error before Complete")
+	}
+	a.AddWithdrawal(w.Address(), 500_000, nil, nil)
+	a, err = a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqs, err := a.SigningRequirements()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reward, err := w.RewardAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SigningRequirement{
+		{KeyHash: w.PubKeyHash(), Role: SignerRolePayment, Element: SigningElementInput, Ref: utxoRef(common.Utxo{Id: a.GetTx().Body.TxInputs.Items()[0]})},
+		{KeyHash: w.StakePubKeyHash(), Role: SignerRoleStake, Element: SigningElementWithdrawal, Ref: reward.String()},
+		{KeyHash: extra, Role: SignerRolePayment, Element: SigningElementRequiredSigner},
+	}
+	if len(reqs) != len(want) {
+		t.Fatalf("expected %d requirements, got %d: %+v", len(want), len(reqs), reqs)
+	}
+	for i := range want {
+		if reqs[i] != want[i] {
+			t.Errorf("requirement %d: got %+v, want %+v", i, reqs[i], want[i])
+		}
+	}
+}
+
+func TestCertificateSigners(t *testing.T) {
+	cred := testCredential(0x07)
+	cc := setupFixedContext()
+	a := New(cc).RegisterDRep(cred, 500_000_000, nil)
+	signers := certificateSigners(a.certificates[0])
+	if len(signers) != 1 || signers[0].role != SignerRoleDRep || signers[0].hash != common.Blake2b224(cred.Credential) {
+		t.Fatalf("expected one DRep signer, got %+v", signers)
+	}
+}
@@ -0,0 +1,228 @@
+package apollo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Salvionied/apollo/v2/backend"
+)
+
+// Network magics of the networks with a well-known SlotConfig.
+const (
+	mainnetNetworkMagic = 764824073
+	preprodNetworkMagic = 1
+	previewNetworkMagic = 2
+)
+
+// SlotConverter converts between slots and times with a chain's era history,
+// so slots before the Shelley era, with their longer Byron slots, resolve
+// correctly too. The last era is taken to continue indefinitely.
+type SlotConverter struct {
+	history backend.EraHistory
+}
+
+// NewSlotConverter validates history and returns a converter for it. Eras
+// must be in chain order with positive slot lengths.
+func NewSlotConverter(history backend.EraHistory) (*SlotConverter, error) {
+	if len(history.Eras) == 0 {
+		return nil, errors.New("era history has no eras")
+	}
+	for i, era := range history.Eras {
+		if era.SlotLength <= 0 {
+			return nil, fmt.Errorf("era %d has a non-positive slot length", i)
+		}
+		if i > 0 {
+			prev := history.Eras[i-1]
+			if era.StartSlot < prev.StartSlot || era.StartTime < prev.StartTime {
+				return nil, fmt.Errorf("era %d starts before era %d", i, i-1)
+			}
+		}
+	}
+	return &SlotConverter{history: backend.EraHistory{
+		SystemStart: history.SystemStart,
+		Eras:        slices.Clone(history.Eras),
+	}}, nil
+}
+
+// SlotConverterFromContext builds a SlotConverter from the era history of cc,
+// which must implement backend.EraHistoryProvider. The error wraps
+// backend.ErrUnsupported when cc cannot report its era history.
+func SlotConverterFromContext(cc backend.ChainContext) (*SlotConverter, error) {
+	provider, ok := cc.(backend.EraHistoryProvider)
+	if !ok {
+		return nil, fmt.Errorf("chain context cannot report its era history: %w", backend.ErrUnsupported)
+	}
+	history, err := provider.EraHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get era history: %w", err)
+	}
+	if history == nil {
+		return nil, errors.New("chain context returned no era history")
+	}
+	return NewSlotConverter(*history)
+}
+
+// SlotToTime returns the start time of slot.
+func (c *SlotConverter) SlotToTime(slot uint64) (time.Time, error) {
+	return c.history.SlotToTime(slot)
+}
+
+// TimeToSlot returns the slot containing t.
+func (c *SlotConverter) TimeToSlot(t time.Time) (uint64, error) {
+	return c.history.TimeToSlot(t)
+}
+
+// slotConfig returns the slot configuration used to convert times to slots
+// when the chain context reports no era history: a well-known one matched by
+// the genesis network magic, else one starting at the genesis system start
+// with the genesis slot length, which suits networks without a Byron era.
+func (a *Apollo) slotConfig() (SlotConfig, error) {
+	gp, err := a.Context.GenesisParams()
+	if err != nil {
+		return SlotConfig{}, fmt.Errorf("failed to get genesis params: %w", err)
+	}
+	switch gp.NetworkMagic {
+	case mainnetNetworkMagic:
+		return MainnetSlotConfig, nil
+	case preprodNetworkMagic:
+		return PreprodSlotConfig, nil
+	case previewNetworkMagic:
+		return PreviewSlotConfig, nil
+	}
+	if gp.SystemStart <= 0 || gp.SlotLength <= 0 {
+		return SlotConfig{}, errors.New("genesis params lack the system start and slot length to convert times to slots")
+	}
+	return SlotConfig{
+		ZeroTime:   time.Unix(gp.SystemStart, 0),
+		SlotLength: time.Duration(gp.SlotLength) * time.Second,
+	}, nil
+}
+
+// slotConverter returns the converter the time-based validity helpers use:
+// one over the chain context's era history when it reports one, else a
+// single-era one over slotConfig. Both come from the chain context alone; the
+// slot config given to SetLocalEvaluation only sets the times scripts see.
+func (a *Apollo) slotConverter() (*SlotConverter, error) {
+	converter, err := SlotConverterFromContext(a.Context)
+	if !errors.Is(err, backend.ErrUnsupported) {
+		return converter, err
+	}
+	slots, err := a.slotConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewSlotConverter(backend.EraHistory{
+		SystemStart: slots.ZeroTime,
+		Eras:        []backend.EraSummary{{StartSlot: slots.ZeroSlot, SlotLength: slots.SlotLength}},
+	})
+}
+
+// slotAt returns the slot containing t.
+func (a *Apollo) slotAt(t time.Time) (int64, error) {
+	converter, err := a.slotConverter()
+	if err != nil {
+		return 0, err
+	}
+	slot, err := converter.TimeToSlot(t)
+	if err != nil {
+		return 0, err
+	}
+	if slot > math.MaxInt64 {
+		return 0, fmt.Errorf("slot %d overflows int64", slot)
+	}
+	return int64(slot), nil
+}
+
+// chainNow returns the current slot and its start time. "Now" is always the
+// chain tip, not the local clock: an interval starting there is already open
+// at the node that reported the tip, however far the local clock drifts. The
+// chain context must report its tip.
+func (a *Apollo) chainNow() (int64, time.Time, error) {
+	if !backend.Supports(a.Context, backend.CapabilityTip) {
+		return 0, time.Time{}, fmt.Errorf("chain context does not support %s", backend.CapabilityTip)
+	}
+	tip, err := a.Context.Tip()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to get chain tip: %w", err)
+	}
+	if tip > math.MaxInt64 {
+		return 0, time.Time{}, fmt.Errorf("chain tip %d overflows int64", tip)
+	}
+	converter, err := a.slotConverter()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	now, err := converter.SlotToTime(tip)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return int64(tip), now, nil
+}
+
+// intervalFor returns a validity interval starting at the chain tip and
+// ending d later, at least one slot long.
+func (a *Apollo) intervalFor(d time.Duration) (int64, int64, error) {
+	if d <= 0 {
+		return 0, 0, fmt.Errorf("duration must be positive, got %s", d)
+	}
+	start, now, err := a.chainNow()
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := a.slotAt(now.Add(d))
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, max(end, start+1), nil
+}
+
+// SetValidityFromTime sets the validity start to the slot containing t, so
+// the transaction is invalid before it.
+func (a *Apollo) SetValidityFromTime(t time.Time) *Apollo {
+	slot, err := a.slotAt(t)
+	if err != nil {
+		a.setErrOnce(fmt.Errorf("SetValidityFromTime: %w", err))
+		return a
+	}
+	a.ValidityStart = slot
+	return a
+}
+
+// SetTtlFromTime sets the TTL to the slot containing t, so the transaction is
+// invalid from that slot on.
+func (a *Apollo) SetTtlFromTime(t time.Time) *Apollo {
+	slot, err := a.slotAt(t)
+	if err != nil {
+		a.setErrOnce(fmt.Errorf("SetTtlFromTime: %w", err))
+		return a
+	}
+	a.Ttl = slot
+	return a
+}
+
+// SetTtlFromDuration sets the TTL to the slot d after the chain tip, at least
+// one slot past it.
+func (a *Apollo) SetTtlFromDuration(d time.Duration) *Apollo {
+	_, end, err := a.intervalFor(d)
+	if err != nil {
+		a.setErrOnce(fmt.Errorf("SetTtlFromDuration: %w", err))
+		return a
+	}
+	a.Ttl = end
+	return a
+}
+
+// ValidFor sets the validity interval to start at the chain tip and end d
+// later, e.g. ValidFor(10*time.Minute). It is the builder-chain form of
+// SetDefaultValidityInterval.
+func (a *Apollo) ValidFor(d time.Duration) *Apollo {
+	start, end, err := a.intervalFor(d)
+	if err != nil {
+		a.setErrOnce(fmt.Errorf("ValidFor: %w", err))
+		return a
+	}
+	return a.SetValidityInterval(start, end)
+}
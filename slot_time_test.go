@@ -0,0 +1,203 @@
+package apollo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Salvionied/apollo/v2/backend"
+	"github.com/Salvionied/apollo/v2/backend/cache"
+	"github.com/Salvionied/apollo/v2/backend/fixed"
+)
+
+// genesisContext reports custom genesis parameters on top of the fixed test
+// context.
+type genesisContext struct {
+	*fixed.FixedChainContext
+	gp backend.GenesisParameters
+}
+
+func (c *genesisContext) GenesisParams() (backend.GenesisParameters, error) {
+	return c.gp, nil
+}
+
+func TestTimeBasedValidity(t *testing.T) {
+	// Two-second slots from the system start; the tip is 2000 seconds in.
+	cc := &tipContext{FixedChainContext: setupFixedContext(), tip: 1_000, slotLength: 2}
+	now := time.Unix(tipContextSystemStart, 0).Add(2_000 * time.Second)
+
+	a := New(cc).ValidFor(10 * time.Minute)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.ValidityStart != 1_000 || a.Ttl != 1_300 {
+		t.Fatalf("expected interval [1000, 1300), got [%d, %d)", a.ValidityStart, a.Ttl)
+	}
+
+	// The duration helpers count from the same tip as
+	// SetDefaultValidityInterval.
+	b, err := New(cc).SetDefaultValidityInterval(600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ValidityStart != a.ValidityStart || b.Ttl != a.Ttl {
+		t.Fatalf("expected SetDefaultValidityInterval to match ValidFor, got [%d, %d)", b.ValidityStart, b.Ttl)
+	}
+
+	a = New(cc).
+		SetValidityFromTime(now.Add(time.Minute)).
+		SetTtlFromDuration(time.Hour)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.ValidityStart != 1_030 || a.Ttl != 2_800 {
+		t.Fatalf("expected interval [1030, 2800), got [%d, %d)", a.ValidityStart, a.Ttl)
+	}
+
+	// Well-known networks use their slot config.
+	preprodNow := PreprodSlotConfig.ZeroTime.Add(1_000 * time.Second)
+	a = New(setupFixedContext()).SetTtlFromTime(preprodNow)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.Ttl != 87_400 {
+		t.Fatalf("expected TTL 87400, got %d", a.Ttl)
+	}
+
+	// Other networks count from the genesis system start.
+	gc := &genesisContext{
+		FixedChainContext: setupFixedContext(),
+		gp:                backend.GenesisParameters{NetworkMagic: 42, SystemStart: preprodNow.Unix() - 100, SlotLength: 2},
+	}
+	a = New(gc).SetTtlFromTime(preprodNow.Add(20 * time.Second))
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.Ttl != 60 {
+		t.Fatalf("expected TTL 60, got %d", a.Ttl)
+	}
+
+	gc.gp.SystemStart = 0
+	if err := New(gc).SetTtlFromTime(preprodNow).err; err == nil || !strings.Contains(err.Error(), "system start") {
+		t.Errorf("expected an error without a system start, got %v", err)
+	}
+	if err := New(setupFixedContext()).ValidFor(time.Minute).err; err == nil || !strings.Contains(err.Error(), "does not support") {
+		t.Errorf("expected an error without a chain tip, got %v", err)
+	}
+	if err := New(cc).SetTtlFromDuration(0).err; err == nil {
+		t.Error("expected an error for a non-positive duration")
+	}
+	if err := New(setupFixedContext()).SetValidityFromTime(PreprodSlotConfig.ZeroTime.Add(-time.Second)).err; err == nil {
+		t.Error("expected an error for a time before the zero slot")
+	}
+}
+
+// mainnetEraHistory is mainnet's history through the start of Shelley, after
+// which every era keeps one-second slots.
+var mainnetEraHistory = backend.EraHistory{
+	SystemStart: time.Unix(1506203091, 0),
+	Eras: []backend.EraSummary{
+		{StartSlot: 0, StartTime: 0, SlotLength: 20 * time.Second},
+		{StartSlot: 4492800, StartTime: 89856000 * time.Second, SlotLength: time.Second},
+	},
+}
+
+// eraContext reports an era history on top of a tipContext.
+type eraContext struct {
+	*tipContext
+	history backend.EraHistory
+}
+
+func (c *eraContext) EraHistory() (*backend.EraHistory, error) {
+	return &c.history, nil
+}
+
+func TestSlotConverter(t *testing.T) {
+	c, err := NewSlotConverter(mainnetEraHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, slot := range []uint64{0, 100, 4492799, 4492800, 150_000_000} {
+		got, err := c.SlotToTime(slot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slot >= 4492800 {
+			want, err := MainnetSlotConfig.SlotToTime(slot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("slot %d: got %s, want %s", slot, got, want)
+			}
+		}
+		back, err := c.TimeToSlot(got.Add(500 * time.Millisecond))
+		if err != nil || back != slot {
+			t.Errorf("slot %d round-trips to %d, %v", slot, back, err)
+		}
+	}
+	if got, _ := c.SlotToTime(100); !got.Equal(mainnetEraHistory.SystemStart.Add(2000 * time.Second)) {
+		t.Errorf("expected Byron slots to last 20 seconds, got %s", got)
+	}
+	if _, err := c.TimeToSlot(mainnetEraHistory.SystemStart.Add(-time.Second)); err == nil {
+		t.Error("expected an error before the system start")
+	}
+
+	// Networks that skipped early eras report them with no length.
+	preview, err := NewSlotConverter(backend.EraHistory{
+		SystemStart: PreviewSlotConfig.ZeroTime,
+		Eras: []backend.EraSummary{
+			{SlotLength: 20 * time.Second},
+			{SlotLength: time.Second},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot, _ := preview.TimeToSlot(PreviewSlotConfig.ZeroTime.Add(time.Hour)); slot != 3600 {
+		t.Errorf("expected slot 3600, got %d", slot)
+	}
+
+	if _, err := NewSlotConverter(backend.EraHistory{}); err == nil {
+		t.Error("expected an error for an empty history")
+	}
+	if _, err := SlotConverterFromContext(setupFixedContext()); err == nil {
+		t.Error("expected an error for a context without era history")
+	}
+}
+
+func TestValidForUsesEraHistory(t *testing.T) {
+	cc := &eraContext{
+		tipContext: &tipContext{FixedChainContext: setupFixedContext(), tip: 4493800, slotLength: 2},
+		history:    mainnetEraHistory,
+	}
+	a := New(cc).ValidFor(time.Minute)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.ValidityStart != 4493800 || a.Ttl != 4493860 {
+		t.Fatalf("expected interval [4493800, 4493860), got [%d, %d)", a.ValidityStart, a.Ttl)
+	}
+
+	// The local evaluation slot config does not override the era history,
+	// which the cache forwards.
+	a = New(cache.NewCachedChainContext(cc, time.Minute)).
+		SetLocalEvaluation(PreviewSlotConfig).
+		ValidFor(time.Minute)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.ValidityStart != 4493800 || a.Ttl != 4493860 {
+		t.Fatalf("expected interval [4493800, 4493860) through the cache, got [%d, %d)", a.ValidityStart, a.Ttl)
+	}
+
+	// A cache over a context without era history falls back to the genesis
+	// slot config.
+	a = New(cache.NewCachedChainContext(cc.tipContext, time.Minute)).ValidFor(10 * time.Minute)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	if a.ValidityStart != 4493800 || a.Ttl != 4494100 {
+		t.Fatalf("expected interval [4493800, 4494100) from the genesis slot config, got [%d, %d)", a.ValidityStart, a.Ttl)
+	}
+}
@@ -1,10 +1,14 @@
 package apollo
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 
+	"github.com/Salvionied/apollo/v2/backend"
+	"github.com/Salvionied/apollo/v2/backend/cache"
 	"github.com/Salvionied/apollo/v2/backend/fixed"
 )
 
@@ -374,7 +378,7 @@ func TestCertificateDepositAdjustment(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	adj := a.certificateDepositAdjustment(StakeDeposit)
+	adj := a.certificateDepositAdjustment(certificateDeposits{stake: StakeDeposit})
 	if adj != StakeDeposit {
 		t.Errorf("expected deposit of %d, got %d", StakeDeposit, adj)
 	}
@@ -384,7 +388,7 @@ func TestCertificateDepositAdjustment(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	adj = a.certificateDepositAdjustment(StakeDeposit)
+	adj = a.certificateDepositAdjustment(certificateDeposits{stake: StakeDeposit})
 	if adj != 0 {
 		t.Errorf("expected net 0 (reg+dereg), got %d", adj)
 	}
@@ -400,12 +404,78 @@ func TestCertificateDepositAdjustmentDeregOnly(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	adj := a.certificateDepositAdjustment(StakeDeposit)
+	adj := a.certificateDepositAdjustment(certificateDeposits{stake: StakeDeposit})
 	if adj != -StakeDeposit {
 		t.Errorf("expected deposit refund of %d, got %d", -StakeDeposit, adj)
 	}
 }
 
+func TestDepositSummary(t *testing.T) {
+	cc := setupFixedContext()
+	tAddr := testAddress(t)
+	cred := common.Credential{CredType: 0, Credential: tAddr.StakeKeyHash()}
+	other := common.Credential{CredType: 0, Credential: common.Blake2b224{0x01}}
+
+	a, err := New(cc).RegisterStake(&cred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err = a.DeregisterStake(&other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.RegisterDRep(cred, 500_000_000, nil)
+
+	deposits, refunds, err := a.DepositSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deposits != 2_000_000+500_000_000 {
+		t.Errorf("expected deposits of %d, got %d", 2_000_000+500_000_000, deposits)
+	}
+	if refunds != 2_000_000 {
+		t.Errorf("expected refunds of %d, got %d", 2_000_000, refunds)
+	}
+
+	if deposits, refunds, err := New(cc).DepositSummary(); err != nil || deposits != 0 || refunds != 0 {
+		t.Errorf("expected empty summary, got %d/%d/%v", deposits, refunds, err)
+	}
+}
+
+func TestPoolRegistrationDeposit(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 1_000_000_000, 0x01, 0)
+	var operator common.Blake2b224
+	operator[0] = 0x01
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		RegisterPool(common.PoolRegistrationCertificate{Operator: operator, Pledge: 1_000_000, Cost: 340_000_000}).
+		PayToAddress(addr, 2_000_000).
+		SetTtl(50000000)
+
+	deposits, refunds, err := a.DepositSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deposits != 500_000_000 || refunds != 0 {
+		t.Fatalf("expected a 500000000 pool deposit, got %d/%d", deposits, refunds)
+	}
+
+	a, err = a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := a.GetTx().Body
+	var outputs uint64
+	for _, out := range body.TxOutputs {
+		outputs += out.Amount().Uint64()
+	}
+	if outputs+body.TxFee+500_000_000 != 1_000_000_000 {
+		t.Fatalf("outputs %d + fee %d + pool deposit do not balance the 1000000000 input", outputs, body.TxFee)
+	}
+}
+
 func TestGetStakeCredentialFromAddress(t *testing.T) {
 	addr := testAddress(t)
 	cred, err := GetStakeCredentialFromAddress(addr)
@@ -420,6 +490,140 @@ func TestGetStakeCredentialFromAddress(t *testing.T) {
 	}
 }
 
+func TestRewardAddressFromAddress(t *testing.T) {
+	addr := testAddress(t)
+	reward, err := NewExternalWallet(addr).RewardAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reward.Type() != common.AddressTypeNoneKey {
+		t.Errorf("expected reward address type %d, got %d", common.AddressTypeNoneKey, reward.Type())
+	}
+	if reward.StakeKeyHash() != addr.StakeKeyHash() {
+		t.Error("expected reward address to carry the wallet stake key hash")
+	}
+
+	var raw [29]byte
+	raw[0] = 0x60
+	enterprise, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RewardAddressFromAddress(enterprise); err == nil {
+		t.Error("expected error for address without staking credential")
+	}
+}
+
+func TestRewardAddressFromCredential(t *testing.T) {
+	addr := testAddress(t)
+	cred, err := GetStakeCredentialFromAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromAddr, err := RewardAddressFromAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	netId, _ := addressNetworkId(addr)
+	reward, err := RewardAddressFromCredential(cred, netId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reward.String() != fromAddr.String() {
+		t.Errorf("expected %s, got %s", fromAddr.String(), reward.String())
+	}
+
+	script := common.Credential{CredType: common.CredentialTypeScriptHash, Credential: cred.Credential}
+	scriptReward, err := RewardAddressFromCredential(script, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scriptReward.Type() != common.AddressTypeNoneScript {
+		t.Errorf("expected reward address type %d, got %d", common.AddressTypeNoneScript, scriptReward.Type())
+	}
+	if got, _ := addressNetworkId(scriptReward); got != 1 {
+		t.Errorf("expected network 1, got %d", got)
+	}
+
+	if _, err := RewardAddressFromCredential(cred, 16); err == nil {
+		t.Error("expected error for a network id wider than 4 bits")
+	}
+	if _, err := RewardAddressFromCredential(common.Credential{CredType: 7}, 1); err == nil {
+		t.Error("expected error for an unknown credential type")
+	}
+}
+
+func TestAddWithdrawalByCredential(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	cred, err := GetStakeCredentialFromAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reward, err := RewardAddressFromCredential(cred, cc.NetworkId())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := New(cc).AddWithdrawalByCredential(cred, 1_000_000, nil, nil)
+	if a.err != nil {
+		t.Fatal(a.err)
+	}
+	wd, ok := a.withdrawals[reward.String()]
+	if !ok || wd.Amount != 1_000_000 {
+		t.Fatalf("expected withdrawal of 1000000 from %s, got %+v", reward.String(), a.withdrawals)
+	}
+
+	a = New(cc).AddWithdrawalByCredential(common.Credential{CredType: 7}, 1_000_000, nil, nil)
+	if a.err == nil {
+		t.Error("expected error for an unknown credential type")
+	}
+}
+
+func TestWithdrawAllFromWallet(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	w := NewExternalWallet(addr)
+	reward, err := w.RewardAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc.SetRewardBalance(reward, 1_234_567)
+
+	a, err := New(cc).SetWallet(w).WithdrawAllFromWallet(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, ok := a.withdrawals[reward.String()]
+	if !ok || wd.Amount != 1_234_567 {
+		t.Fatalf("expected withdrawal of 1234567 from %s, got %+v", reward.String(), a.withdrawals)
+	}
+
+	// A stake account without rewards adds nothing.
+	a, err = New(setupFixedContext()).SetWallet(w).WithdrawAllFromWallet(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.withdrawals) != 0 {
+		t.Errorf("expected no withdrawals, got %d", len(a.withdrawals))
+	}
+
+	// The cache forwards reward balances.
+	a, err = New(cache.NewCachedChainContext(cc, time.Minute)).SetWallet(w).WithdrawAllFromWallet(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wd := a.withdrawals[reward.String()]; wd.Amount != 1_234_567 {
+		t.Fatalf("expected withdrawal of 1234567 through the cache, got %+v", a.withdrawals)
+	}
+
+	// Embedding the interface hides the fixed context's RewardBalance.
+	plain := struct{ backend.ChainContext }{cc}
+	if _, err := New(plain).SetWallet(w).WithdrawAllFromWallet(nil, nil); !errors.Is(err, backend.ErrUnsupported) {
+		t.Errorf("expected an unsupported error, got %v", err)
+	}
+}
+
 func TestGetStakeCredentialFromWallet(t *testing.T) {
 	cc := setupFixedContext()
 	addr := testAddress(t)
@@ -477,3 +681,146 @@ func TestCompleteWithStakeRegistration(t *testing.T) {
 		t.Errorf("expected 1 certificate in tx body, got %d", len(tx.Body.TxCertificates))
 	}
 }
+
+func TestCertificateOrderPreservedInBody(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+	cred := common.Credential{CredType: 0, Credential: addr.StakeKeyHash()}
+
+	registration, err := New(cc).RegisterStake(&cred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Add the delegation first, then insert the registration ahead of it.
+	a, err := New(cc).SetWallet(NewExternalWallet(addr)).SetTtl(50000000).DelegateStake(&cred, testPolicyId(0x42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.InsertCertificate(0, registration.certificates[0]).PayToAddress(addr, 2_000_000)
+
+	a, err = a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs := a.GetTx().Body.TxCertificates
+	if len(certs) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(certs))
+	}
+	if certs[0].Type != uint(common.CertificateTypeStakeRegistration) ||
+		certs[1].Type != uint(common.CertificateTypeStakeDelegation) {
+		t.Fatalf("expected registration before delegation, got types %d, %d", certs[0].Type, certs[1].Type)
+	}
+}
+
+func TestInsertCertificateOutOfRange(t *testing.T) {
+	a := New(setupFixedContext()).InsertCertificate(1, common.CertificateWrapper{})
+	if a.err == nil {
+		t.Fatal("expected error for out-of-range index")
+	}
+	if len(a.certificates) != 0 {
+		t.Fatalf("expected no certificates, got %d", len(a.certificates))
+	}
+}
+
+func TestSignAddsStakeWitnessForWithdrawal(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, w.Address(), 10_000_000, 0x01, 0)
+
+	a := New(cc).SetWallet(w).PayToAddress(testAddress(t), 2_000_000).SetTtl(50000000)
+	a.AddWithdrawal(w.Address(), 500_000, nil, nil)
+	a, err = a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err = a.Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signers := make(map[common.Blake2b224]bool)
+	for _, witness := range a.GetTx().WitnessSet.VkeyWitnesses.Items() {
+		signers[common.Blake2b224Hash(witness.Vkey)] = true
+	}
+	if len(signers) != 2 || !signers[w.PubKeyHash()] || !signers[w.StakePubKeyHash()] {
+		t.Fatalf("expected payment and stake witnesses, got %d signers", len(signers))
+	}
+}
+
+func TestSignAddsStakeWitnessForMarkedInput(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, w.Address(), 10_000_000, 0x01, 0)
+	utxos, err := cc.Utxos(w.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := New(cc).SetWallet(w).AddInputWithStakeWitness(utxos[0]).PayToAddress(testAddress(t), 2_000_000)
+	a, err = a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := a.GetTx().Body.TxRequiredSigners.Items()
+	if len(signers) != 1 || signers[0] != w.StakePubKeyHash() {
+		t.Fatalf("required signers = %v, want the stake key hash", signers)
+	}
+	a, err = a.Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(a.GetTx().WitnessSet.VkeyWitnesses.Items()); got != 2 {
+		t.Fatalf("witness count = %d, want 2", got)
+	}
+}
+
+func TestSignWithoutStakeRequirementAddsOnlyPaymentWitness(t *testing.T) {
+	w, err := NewBursaWallet(testMnemonic(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, w.Address(), 10_000_000, 0x01, 0)
+
+	a, err := New(cc).SetWallet(w).PayToAddress(testAddress(t), 2_000_000).Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err = a.Sign()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(a.GetTx().WitnessSet.VkeyWitnesses.Items()); got != 1 {
+		t.Fatalf("witness count = %d, want 1", got)
+	}
+}
+
+func TestAddInputWithStakeWitnessRejectsEnterpriseAddress(t *testing.T) {
+	var raw [29]byte
+	raw[0] = 0x60 // enterprise address, testnet
+	addr, err := common.NewAddressFromBytes(raw[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := setupFixedContext()
+	addTestUtxo(cc, addr, 5_000_000, 0x02, 0)
+	utxos, err := cc.Utxos(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := New(cc).AddInputWithStakeWitness(utxos[0])
+	if a.err == nil {
+		t.Fatal("expected error for address without stake credential")
+	}
+	if len(a.preselectedUtxos) != 0 {
+		t.Fatal("rejected input must not be added")
+	}
+}
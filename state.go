@@ -0,0 +1,709 @@
+package apollo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+)
+
+// builderStateVersion identifies the SaveState format.
+const builderStateVersion = 1
+
+// builderState is the portable JSON form of a pre-Complete builder. Ledger
+// objects are stored as hex-encoded CBOR, addresses as bech32, and metadata in
+// the detailed metadata JSON schema.
+type builderState struct {
+	Version            int                      `json:"version"`
+	Payments           []paymentState           `json:"payments,omitempty"`
+	Utxos              []utxoState              `json:"utxos,omitempty"`
+	PreselectedUtxos   []utxoState              `json:"preselected_utxos,omitempty"`
+	InputAddresses     []string                 `json:"input_addresses,omitempty"`
+	Collaterals        []utxoState              `json:"collaterals,omitempty"`
+	ReferenceInputs    []refInputState          `json:"reference_inputs,omitempty"`
+	Datums             []string                 `json:"datums,omitempty"`
+	RequiredSigners    []string                 `json:"required_signers,omitempty"`
+	StakeWitnessHashes []string                 `json:"stake_witness_hashes,omitempty"`
+	Scripts            []string                 `json:"scripts,omitempty"`
+	Redeemers          map[string]redeemerState `json:"redeemers,omitempty"`
+	StakeRedeemers     map[string]redeemerState `json:"stake_redeemers,omitempty"`
+	MintRedeemers      map[string]redeemerState `json:"mint_redeemers,omitempty"`
+	VoteRedeemers      map[string]redeemerState `json:"vote_redeemers,omitempty"`
+	Mint               []Unit                   `json:"mint,omitempty"`
+	AssetFloors        []Unit                   `json:"asset_floors,omitempty"`
+	Certificates       []string                 `json:"certificates,omitempty"`
+	Withdrawals        []withdrawalState        `json:"withdrawals,omitempty"`
+	Metadata           json.RawMessage          `json:"metadata,omitempty"`
+	VotingProcedures   string                   `json:"voting_procedures,omitempty"`
+	ProposalProcedures []string                 `json:"proposal_procedures,omitempty"`
+	ChangeAddress      string                   `json:"change_address,omitempty"`
+	ChangeSplits       []changeSplitState       `json:"change_splits,omitempty"`
+	Config             builderConfigState       `json:"config"`
+}
+
+type builderConfigState struct {
+	Fee                int64              `json:"fee,omitempty"`
+	FeePadding         int64              `json:"fee_padding,omitempty"`
+	ForceFee           bool               `json:"force_fee,omitempty"`
+	Ttl                int64              `json:"ttl,omitempty"`
+	ValidityStart      int64              `json:"validity_start,omitempty"`
+	CollateralAmount   int64              `json:"collateral_amount,omitempty"`
+	CurrentTreasury    int64              `json:"current_treasury,omitempty"`
+	TreasuryDonation   int64              `json:"treasury_donation,omitempty"`
+	IsEstimateRequired bool               `json:"is_estimate_required,omitempty"`
+	EstimateExUnits    bool               `json:"estimate_ex_units"`
+	ExMemoryBuffer     float64            `json:"ex_memory_buffer"`
+	ExStepBuffer       float64            `json:"ex_step_buffer"`
+	UTxOLoadLimit      int                `json:"utxo_load_limit,omitempty"`
+	MetadataSizeLimit  int                `json:"metadata_size_limit,omitempty"`
+	DedupRefScripts    bool               `json:"dedup_reference_scripts,omitempty"`
+	ResolveScripts     bool               `json:"resolve_scripts,omitempty"`
+	CollateralInputs   bool               `json:"collateral_from_inputs,omitempty"`
+	ExpectedSigners    int                `json:"expected_signers,omitempty"`
+	ExUnitSafety       float64            `json:"ex_unit_safety_factor,omitempty"`
+	ChangeAssets       int                `json:"change_asset_strategy,omitempty"`
+	CompactChange      bool               `json:"compact_change_on_oversize,omitempty"`
+	Sweep              bool               `json:"sweep,omitempty"`
+	MaxRedeemerExUnits []redeemerCapState `json:"max_redeemer_ex_units,omitempty"`
+	LocalEvaluation    *slotConfigState   `json:"local_evaluation,omitempty"`
+	Fallbacks          *BuilderConfig     `json:"fallbacks,omitempty"`
+}
+
+type paymentState struct {
+	Receiver  string `json:"receiver"`
+	Lovelace  int64  `json:"lovelace"`
+	Units     []Unit `json:"units,omitempty"`
+	Datum     string `json:"datum,omitempty"`
+	DatumHash string `json:"datum_hash,omitempty"`
+	IsInline  bool   `json:"is_inline,omitempty"`
+	ScriptRef string `json:"script_ref,omitempty"`
+}
+
+type utxoState struct {
+	TxHash string `json:"tx_hash"`
+	Index  uint32 `json:"index"`
+	Output string `json:"output"`
+}
+
+type refInputState struct {
+	TxHash string `json:"tx_hash"`
+	Index  uint32 `json:"index"`
+}
+
+type redeemerState struct {
+	Tag    uint8  `json:"tag"`
+	Data   string `json:"data"`
+	Memory int64  `json:"memory"`
+	Steps  int64  `json:"steps"`
+}
+
+type redeemerCapState struct {
+	Tag    uint8  `json:"tag"`
+	Index  uint32 `json:"index"`
+	Memory int64  `json:"memory"`
+	Steps  int64  `json:"steps"`
+}
+
+// slotConfigState stores a SlotConfig with times in POSIX milliseconds.
+type slotConfigState struct {
+	ZeroTime   int64  `json:"zero_time_ms"`
+	ZeroSlot   uint64 `json:"zero_slot"`
+	SlotLength int64  `json:"slot_length_ms"`
+}
+
+type changeSplitState struct {
+	Address string `json:"address"`
+	Weight  int    `json:"weight"`
+}
+
+type withdrawalState struct {
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+}
+
+// SaveState serializes the pre-Complete builder configuration (payments,
+// inputs, collateral, scripts, datums, redeemers and their execution unit
+// caps, mints, certificates, withdrawals, governance, metadata, local
+// evaluation, and fee/validity settings) to JSON so construction can be resumed later with
+// LoadState.
+//
+// The chain context, wallet, coin selector, and evaluation witness providers
+// are not serialized and must be supplied again. Only *Payment payments can be
+// saved, and a builder that has already completed or recorded an error is
+// rejected.
+func (a *Apollo) SaveState() ([]byte, error) {
+	if a.err != nil {
+		return nil, fmt.Errorf("cannot save builder with pending error: %w", a.err)
+	}
+	if a.tx != nil {
+		return nil, errors.New("cannot save builder state after Complete()")
+	}
+	state := builderState{
+		Version:     builderStateVersion,
+		Mint:        slices.Clone(a.mint),
+		AssetFloors: slices.Clone(a.assetFloors),
+		Config: builderConfigState{
+			Fee:                a.Fee,
+			FeePadding:         a.FeePadding,
+			ForceFee:           a.forceFee,
+			Ttl:                a.Ttl,
+			ValidityStart:      a.ValidityStart,
+			CollateralAmount:   a.collateralAmount,
+			CurrentTreasury:    a.currentTreasury,
+			TreasuryDonation:   a.treasuryDonation,
+			IsEstimateRequired: a.isEstimateRequired,
+			EstimateExUnits:    a.estimateExUnits,
+			ExMemoryBuffer:     a.exMemoryBuffer,
+			ExStepBuffer:       a.exStepBuffer,
+			UTxOLoadLimit:      a.utxoLoadLimit,
+			MetadataSizeLimit:  a.metadataSizeLimit,
+			DedupRefScripts:    a.dedupReferenceScripts,
+			ResolveScripts:     a.resolveScripts,
+			CollateralInputs:   a.collateralFromInputs,
+			ExpectedSigners:    a.expectedSigners,
+			ExUnitSafety:       a.exUnitSafetyFactor,
+			ChangeAssets:       int(a.changeAssetStrategy),
+			CompactChange:      a.compactOversizedChange,
+			Sweep:              a.sweep,
+			MaxRedeemerExUnits: a.saveRedeemerCaps(),
+			LocalEvaluation:    saveSlotConfig(a.localEvaluation),
+			Fallbacks:          &a.config,
+		},
+	}
+
+	for i, p := range a.payments {
+		payment, ok := p.(*Payment)
+		if !ok {
+			return nil, fmt.Errorf("payment %d: unsupported payment type %T", i, p)
+		}
+		ps, err := savePayment(payment)
+		if err != nil {
+			return nil, fmt.Errorf("payment %d: %w", i, err)
+		}
+		state.Payments = append(state.Payments, ps)
+	}
+
+	var err error
+	if state.Utxos, err = saveUtxos(a.utxos); err != nil {
+		return nil, err
+	}
+	if state.PreselectedUtxos, err = saveUtxos(a.preselectedUtxos); err != nil {
+		return nil, err
+	}
+	// Auto-selected collateral is chosen again by Complete(); only caller-pinned
+	// collateral is part of the configuration.
+	if !a.collateralAutoSelected {
+		if state.Collaterals, err = saveUtxos(a.collaterals); err != nil {
+			return nil, err
+		}
+	}
+	for _, addr := range a.inputAddresses {
+		state.InputAddresses = append(state.InputAddresses, addr.String())
+	}
+	for _, ref := range a.referenceInputs {
+		state.ReferenceInputs = append(state.ReferenceInputs, refInputState{
+			TxHash: hex.EncodeToString(ref.TxId.Bytes()),
+			Index:  ref.OutputIndex,
+		})
+	}
+	for i := range a.datums {
+		encoded, err := encodeStateCbor(&a.datums[i])
+		if err != nil {
+			return nil, fmt.Errorf("datum %d: %w", i, err)
+		}
+		state.Datums = append(state.Datums, encoded)
+	}
+	for _, signer := range a.requiredSigners {
+		state.RequiredSigners = append(state.RequiredSigners, hex.EncodeToString(signer.Bytes()))
+	}
+	for _, hash := range a.stakeWitnessHashes {
+		state.StakeWitnessHashes = append(state.StakeWitnessHashes, hex.EncodeToString(hash.Bytes()))
+	}
+	if state.Scripts, err = a.saveScripts(); err != nil {
+		return nil, err
+	}
+	if state.Redeemers, err = saveRedeemers(a.redeemers); err != nil {
+		return nil, err
+	}
+	if state.StakeRedeemers, err = saveRedeemers(a.stakeRedeemers); err != nil {
+		return nil, err
+	}
+	if state.MintRedeemers, err = saveRedeemers(a.mintRedeemers); err != nil {
+		return nil, err
+	}
+	if state.VoteRedeemers, err = saveRedeemers(a.voteRedeemers); err != nil {
+		return nil, err
+	}
+	for i := range a.certificates {
+		encoded, err := encodeStateCbor(&a.certificates[i])
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", i, err)
+		}
+		state.Certificates = append(state.Certificates, encoded)
+	}
+	for _, key := range a.sortedWithdrawalKeys() {
+		wd := a.withdrawals[key]
+		state.Withdrawals = append(state.Withdrawals, withdrawalState{
+			Address: wd.Address.String(),
+			Amount:  wd.Amount,
+		})
+	}
+	if a.auxiliaryData != nil {
+		if state.Metadata, err = detailedMetadataJSON(a.auxiliaryData.metadata); err != nil {
+			return nil, fmt.Errorf("metadata: %w", err)
+		}
+	}
+	if len(a.votingProcedures) > 0 {
+		if state.VotingProcedures, err = encodeStateCbor(a.votingProcedures); err != nil {
+			return nil, fmt.Errorf("voting procedures: %w", err)
+		}
+	}
+	for i := range a.proposalProcedures {
+		encoded, err := encodeStateCbor(&a.proposalProcedures[i])
+		if err != nil {
+			return nil, fmt.Errorf("proposal procedure %d: %w", i, err)
+		}
+		state.ProposalProcedures = append(state.ProposalProcedures, encoded)
+	}
+	if a.changeAddress != nil {
+		state.ChangeAddress = a.changeAddress.String()
+	}
+	for _, split := range a.changeSplits {
+		state.ChangeSplits = append(state.ChangeSplits, changeSplitState{
+			Address: split.Address.String(),
+			Weight:  split.Weight,
+		})
+	}
+	return json.Marshal(state)
+}
+
+// LoadState reconstructs a builder from data produced by SaveState. The
+// returned builder is new and uses a's chain context, wallet, coin selector,
+// and evaluation witness providers; a itself is left unchanged.
+func (a *Apollo) LoadState(data []byte) (*Apollo, error) {
+	var state builderState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return a, fmt.Errorf("failed to decode builder state: %w", err)
+	}
+	if state.Version != builderStateVersion {
+		return a, fmt.Errorf("unsupported builder state version %d", state.Version)
+	}
+
+	b := New(a.Context)
+	b.wallet = a.wallet
+	b.coinSelector = a.coinSelector
+	b.evaluationWitnessProviders = slices.Clone(a.evaluationWitnessProviders)
+	b.Fee = state.Config.Fee
+	b.FeePadding = state.Config.FeePadding
+	b.forceFee = state.Config.ForceFee
+	b.Ttl = state.Config.Ttl
+	b.ValidityStart = state.Config.ValidityStart
+	b.collateralAmount = state.Config.CollateralAmount
+	b.currentTreasury = state.Config.CurrentTreasury
+	b.treasuryDonation = state.Config.TreasuryDonation
+	b.isEstimateRequired = state.Config.IsEstimateRequired
+	b.estimateExUnits = state.Config.EstimateExUnits
+	b.dedupReferenceScripts = state.Config.DedupRefScripts
+	b.resolveScripts = state.Config.ResolveScripts
+	b.collateralFromInputs = state.Config.CollateralInputs
+	b.SetExpectedSigners(state.Config.ExpectedSigners)
+	b.sweep = state.Config.Sweep
+	b.SetExUnitBuffers(state.Config.ExMemoryBuffer, state.Config.ExStepBuffer)
+	b.SetUTxOLoadLimit(state.Config.UTxOLoadLimit)
+	b.SetMetadataSizeLimit(state.Config.MetadataSizeLimit)
+	b.SetChangeAssetStrategy(ChangeAssetStrategy(state.Config.ChangeAssets))
+	b.compactOversizedChange = state.Config.CompactChange
+	if state.Config.ExUnitSafety != 0 {
+		b.SetExUnitSafetyFactor(state.Config.ExUnitSafety)
+	}
+	for _, limit := range state.Config.MaxRedeemerExUnits {
+		b.SetMaxExUnitsPerRedeemer(
+			common.RedeemerKey{Tag: common.RedeemerTag(limit.Tag), Index: limit.Index},
+			common.ExUnits{Memory: limit.Memory, Steps: limit.Steps},
+		)
+	}
+	if slots := state.Config.LocalEvaluation; slots != nil {
+		b.SetLocalEvaluation(SlotConfig{
+			ZeroTime:   time.UnixMilli(slots.ZeroTime),
+			ZeroSlot:   slots.ZeroSlot,
+			SlotLength: time.Duration(slots.SlotLength) * time.Millisecond,
+		})
+	}
+	if state.Config.Fallbacks != nil {
+		b.SetConfig(*state.Config.Fallbacks)
+	}
+	if b.err != nil {
+		return a, b.err
+	}
+
+	for i, ps := range state.Payments {
+		payment, err := loadPayment(ps)
+		if err != nil {
+			return a, fmt.Errorf("payment %d: %w", i, err)
+		}
+		b.payments = append(b.payments, payment)
+	}
+	var err error
+	if b.utxos, err = loadUtxos(state.Utxos); err != nil {
+		return a, err
+	}
+	if b.preselectedUtxos, err = loadUtxos(state.PreselectedUtxos); err != nil {
+		return a, err
+	}
+	if b.collaterals, err = loadUtxos(state.Collaterals); err != nil {
+		return a, err
+	}
+	for _, bech32 := range state.InputAddresses {
+		addr, err := common.NewAddress(bech32)
+		if err != nil {
+			return a, fmt.Errorf("invalid input address: %w", err)
+		}
+		b.inputAddresses = append(b.inputAddresses, addr)
+	}
+	for _, ref := range state.ReferenceInputs {
+		if _, err := b.AddReferenceInput(ref.TxHash, int(ref.Index)); err != nil {
+			return a, err
+		}
+	}
+	for i, encoded := range state.Datums {
+		var datum common.Datum
+		if err := decodeStateCbor(encoded, &datum); err != nil {
+			return a, fmt.Errorf("datum %d: %w", i, err)
+		}
+		b.datums = append(b.datums, datum)
+	}
+	if b.requiredSigners, err = loadKeyHashes(state.RequiredSigners); err != nil {
+		return a, fmt.Errorf("required signers: %w", err)
+	}
+	if b.stakeWitnessHashes, err = loadKeyHashes(state.StakeWitnessHashes); err != nil {
+		return a, fmt.Errorf("stake witness hashes: %w", err)
+	}
+	for i, encoded := range state.Scripts {
+		var ref common.ScriptRef
+		if err := decodeStateCbor(encoded, &ref); err != nil {
+			return a, fmt.Errorf("script %d: %w", i, err)
+		}
+		b.AttachScript(ref.Script)
+	}
+	if b.err != nil {
+		return a, b.err
+	}
+	if b.redeemers, err = loadRedeemers(state.Redeemers); err != nil {
+		return a, err
+	}
+	if b.stakeRedeemers, err = loadRedeemers(state.StakeRedeemers); err != nil {
+		return a, err
+	}
+	if b.mintRedeemers, err = loadRedeemers(state.MintRedeemers); err != nil {
+		return a, err
+	}
+	if b.voteRedeemers, err = loadRedeemers(state.VoteRedeemers); err != nil {
+		return a, err
+	}
+	b.mint = slices.Clone(state.Mint)
+	for _, floor := range state.AssetFloors {
+		b.SetAssetFloor(floor.PolicyId, floor.Name, floor.Quantity)
+	}
+	if b.err != nil {
+		return a, b.err
+	}
+	for i, encoded := range state.Certificates {
+		var cert common.CertificateWrapper
+		if err := decodeStateCbor(encoded, &cert); err != nil {
+			return a, fmt.Errorf("certificate %d: %w", i, err)
+		}
+		b.certificates = append(b.certificates, cert)
+	}
+	for _, ws := range state.Withdrawals {
+		addr, err := common.NewAddress(ws.Address)
+		if err != nil {
+			return a, fmt.Errorf("invalid withdrawal address: %w", err)
+		}
+		b.withdrawals[addr.String()] = withdrawalEntry{Address: addr, Amount: ws.Amount}
+	}
+	if len(state.Metadata) > 0 {
+		metadata, err := ShelleyMetadataFromJSONWithSchema(state.Metadata, MetadataJSONDetailedSchema)
+		if err != nil {
+			return a, fmt.Errorf("metadata: %w", err)
+		}
+		b.SetShelleyMetadata(metadata)
+	}
+	if state.VotingProcedures != "" {
+		var votes common.VotingProcedures
+		if err := decodeStateCbor(state.VotingProcedures, &votes); err != nil {
+			return a, fmt.Errorf("voting procedures: %w", err)
+		}
+		b.votingProcedures = votes
+	}
+	for i, encoded := range state.ProposalProcedures {
+		var proposal conway.ConwayProposalProcedure
+		if err := decodeStateCbor(encoded, &proposal); err != nil {
+			return a, fmt.Errorf("proposal procedure %d: %w", i, err)
+		}
+		b.proposalProcedures = append(b.proposalProcedures, proposal)
+	}
+	if state.ChangeAddress != "" {
+		addr, err := common.NewAddress(state.ChangeAddress)
+		if err != nil {
+			return a, fmt.Errorf("invalid change address: %w", err)
+		}
+		b.changeAddress = &addr
+	}
+	if len(state.ChangeSplits) > 0 {
+		addrs := make([]common.Address, 0, len(state.ChangeSplits))
+		weights := make([]int, 0, len(state.ChangeSplits))
+		for _, split := range state.ChangeSplits {
+			addr, err := common.NewAddress(split.Address)
+			if err != nil {
+				return a, fmt.Errorf("invalid change split address: %w", err)
+			}
+			addrs = append(addrs, addr)
+			weights = append(weights, split.Weight)
+		}
+		if b.SetChangeAddresses(addrs, weights); b.err != nil {
+			return a, b.err
+		}
+	}
+	return b, nil
+}
+
+func (a *Apollo) saveScripts() ([]string, error) {
+	scripts := make([]common.Script, 0, len(a.scriptHashes))
+	for _, s := range a.nativescripts {
+		scripts = append(scripts, s)
+	}
+	for _, s := range a.v1scripts {
+		scripts = append(scripts, s)
+	}
+	for _, s := range a.v2scripts {
+		scripts = append(scripts, s)
+	}
+	for _, s := range a.v3scripts {
+		scripts = append(scripts, s)
+	}
+	encoded := make([]string, 0, len(scripts))
+	for i, script := range scripts {
+		ref, err := NewScriptRef(script)
+		if err != nil {
+			return nil, fmt.Errorf("script %d: %w", i, err)
+		}
+		s, err := encodeStateCbor(ref)
+		if err != nil {
+			return nil, fmt.Errorf("script %d: %w", i, err)
+		}
+		encoded = append(encoded, s)
+	}
+	return encoded, nil
+}
+
+// saveRedeemerCaps returns the per-redeemer execution unit caps ordered by
+// redeemer key.
+func (a *Apollo) saveRedeemerCaps() []redeemerCapState {
+	var caps []redeemerCapState
+	for _, key := range a.redeemerCapKeys() {
+		limit := a.maxRedeemerExUnits[key]
+		caps = append(caps, redeemerCapState{
+			Tag:    uint8(key.Tag),
+			Index:  key.Index,
+			Memory: limit.Memory,
+			Steps:  limit.Steps,
+		})
+	}
+	return caps
+}
+
+func saveSlotConfig(slots *SlotConfig) *slotConfigState {
+	if slots == nil {
+		return nil
+	}
+	return &slotConfigState{
+		ZeroTime:   slots.ZeroTime.UnixMilli(),
+		ZeroSlot:   slots.ZeroSlot,
+		SlotLength: slots.SlotLength.Milliseconds(),
+	}
+}
+
+func savePayment(p *Payment) (paymentState, error) {
+	ps := paymentState{
+		Receiver: p.Receiver.String(),
+		Lovelace: p.Lovelace,
+		Units:    slices.Clone(p.Units),
+		IsInline: p.IsInline,
+	}
+	if p.Datum != nil {
+		encoded, err := encodeStateCbor(p.Datum)
+		if err != nil {
+			return paymentState{}, fmt.Errorf("datum: %w", err)
+		}
+		ps.Datum = encoded
+	}
+	if len(p.DatumHash) > 0 {
+		ps.DatumHash = hex.EncodeToString(p.DatumHash)
+	}
+	if p.ScriptRef != nil {
+		encoded, err := encodeStateCbor(p.ScriptRef)
+		if err != nil {
+			return paymentState{}, fmt.Errorf("script ref: %w", err)
+		}
+		ps.ScriptRef = encoded
+	}
+	return ps, nil
+}
+
+func loadPayment(ps paymentState) (*Payment, error) {
+	addr, err := common.NewAddress(ps.Receiver)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receiver address: %w", err)
+	}
+	p := &Payment{
+		Receiver: addr,
+		Lovelace: ps.Lovelace,
+		Units:    slices.Clone(ps.Units),
+		IsInline: ps.IsInline,
+	}
+	if ps.Datum != "" {
+		var datum common.Datum
+		if err := decodeStateCbor(ps.Datum, &datum); err != nil {
+			return nil, fmt.Errorf("datum: %w", err)
+		}
+		p.Datum = &datum
+	}
+	if ps.DatumHash != "" {
+		if p.DatumHash, err = hex.DecodeString(ps.DatumHash); err != nil {
+			return nil, fmt.Errorf("invalid datum hash hex: %w", err)
+		}
+	}
+	if ps.ScriptRef != "" {
+		var ref common.ScriptRef
+		if err := decodeStateCbor(ps.ScriptRef, &ref); err != nil {
+			return nil, fmt.Errorf("script ref: %w", err)
+		}
+		p.ScriptRef = &ref
+	}
+	return p, nil
+}
+
+func saveUtxos(utxos []common.Utxo) ([]utxoState, error) {
+	result := make([]utxoState, 0, len(utxos))
+	for _, utxo := range utxos {
+		encoded, err := encodeStateCbor(utxo.Output)
+		if err != nil {
+			return nil, fmt.Errorf("UTxO %s: %w", utxoRef(utxo), err)
+		}
+		result = append(result, utxoState{
+			TxHash: hex.EncodeToString(utxo.Id.Id().Bytes()),
+			Index:  utxo.Id.Index(),
+			Output: encoded,
+		})
+	}
+	return result, nil
+}
+
+func loadUtxos(states []utxoState) ([]common.Utxo, error) {
+	if len(states) == 0 {
+		return nil, nil
+	}
+	result := make([]common.Utxo, 0, len(states))
+	for _, us := range states {
+		hashBytes, err := hex.DecodeString(us.TxHash)
+		if err != nil || len(hashBytes) != common.Blake2b256Size {
+			return nil, fmt.Errorf("invalid UTxO tx hash %q", us.TxHash)
+		}
+		var txId common.Blake2b256
+		copy(txId[:], hashBytes)
+		var output babbage.BabbageTransactionOutput
+		if err := decodeStateCbor(us.Output, &output); err != nil {
+			return nil, fmt.Errorf("UTxO %s#%d output: %w", us.TxHash, us.Index, err)
+		}
+		result = append(result, common.Utxo{
+			Id: shelley.ShelleyTransactionInput{
+				TxId:        txId,
+				OutputIndex: us.Index,
+			},
+			Output: &output,
+		})
+	}
+	return result, nil
+}
+
+func saveRedeemers(entries map[string]redeemerEntry) (map[string]redeemerState, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]redeemerState, len(entries))
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		entry := entries[key]
+		data, err := encodeStateCbor(&entry.Data)
+		if err != nil {
+			return nil, fmt.Errorf("redeemer %s: %w", key, err)
+		}
+		result[key] = redeemerState{
+			Tag:    uint8(entry.Tag),
+			Data:   data,
+			Memory: entry.ExUnits.Memory,
+			Steps:  entry.ExUnits.Steps,
+		}
+	}
+	return result, nil
+}
+
+func loadRedeemers(states map[string]redeemerState) (map[string]redeemerEntry, error) {
+	result := make(map[string]redeemerEntry, len(states))
+	for key, rs := range states {
+		var data common.Datum
+		if err := decodeStateCbor(rs.Data, &data); err != nil {
+			return nil, fmt.Errorf("redeemer %s: %w", key, err)
+		}
+		result[key] = redeemerEntry{
+			Tag:     common.RedeemerTag(rs.Tag),
+			Data:    data,
+			ExUnits: common.ExUnits{Memory: rs.Memory, Steps: rs.Steps},
+		}
+	}
+	return result, nil
+}
+
+func loadKeyHashes(hexHashes []string) ([]common.Blake2b224, error) {
+	if len(hexHashes) == 0 {
+		return nil, nil
+	}
+	result := make([]common.Blake2b224, 0, len(hexHashes))
+	for _, h := range hexHashes {
+		b, err := hex.DecodeString(h)
+		if err != nil || len(b) != common.Blake2b224Size {
+			return nil, fmt.Errorf("invalid key hash %q", h)
+		}
+		var hash common.Blake2b224
+		copy(hash[:], b)
+		result = append(result, hash)
+	}
+	return result, nil
+}
+
+func encodeStateCbor(v any) (string, error) {
+	b, err := cbor.Encode(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode CBOR: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func decodeStateCbor(encoded string, dest any) error {
+	b, err := hex.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid hex: %w", err)
+	}
+	if _, err := cbor.Decode(b, dest); err != nil {
+		return fmt.Errorf("failed to decode CBOR: %w", err)
+	}
+	return nil
+}
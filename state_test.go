@@ -0,0 +1,132 @@
+package apollo
+
+import (
+	"bytes"
+	"maps"
+	"reflect"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestSaveLoadStateRoundTrip(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+	addTestUtxo(cc, addr, 5_000_000, 0x02, 0)
+	utxos, err := cc.Utxos(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewExternalWallet(addr)
+
+	original := New(cc).
+		SetWallet(w).
+		AddInput(utxos[0]).
+		PayToAddress(addr, 2_000_000).
+		AddWithdrawal(addr, 250_000, nil, nil).
+		SetShelleyMetadata(map[uint64]any{674: map[string]any{"msg": []any{"hello", int64(42)}}}).
+		SetFeePadding(1_000).
+		SetValidityStart(100).
+		SetTtl(50000000).
+		SetExUnitBuffers(0.5, 0.25).
+		SetLocalEvaluation(PreviewSlotConfig).
+		SetMaxExUnitsPerRedeemer(common.RedeemerKey{Tag: common.RedeemerTagMint, Index: 1}, common.ExUnits{Memory: 1_000, Steps: 2_000}).
+		SetMaxExUnitsPerRedeemer(common.RedeemerKey{Tag: common.RedeemerTagSpend, Index: 0}, common.ExUnits{Memory: 3_000, Steps: 4_000})
+
+	data, err := original.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New(cc).SetWallet(w).LoadState(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Ttl != 50000000 || restored.ValidityStart != 100 || restored.FeePadding != 1_000 {
+		t.Fatalf("config not restored: ttl=%d start=%d padding=%d", restored.Ttl, restored.ValidityStart, restored.FeePadding)
+	}
+	if restored.exMemoryBuffer != 0.5 || restored.exStepBuffer != 0.25 {
+		t.Fatalf("ex unit buffers not restored: %v/%v", restored.exMemoryBuffer, restored.exStepBuffer)
+	}
+
+	if !maps.Equal(restored.maxRedeemerExUnits, original.maxRedeemerExUnits) {
+		t.Fatalf("redeemer caps not restored: %v", restored.maxRedeemerExUnits)
+	}
+
+	if slots := restored.localEvaluation; slots == nil || !slots.ZeroTime.Equal(PreviewSlotConfig.ZeroTime) ||
+		slots.ZeroSlot != PreviewSlotConfig.ZeroSlot || slots.SlotLength != PreviewSlotConfig.SlotLength {
+		t.Fatalf("local evaluation not restored: %+v", slots)
+	}
+
+	// Saving the restored builder must yield the same state.
+	again, err := restored.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Fatalf("state changed across round trip:\n%s\n%s", data, again)
+	}
+
+	built, err := original.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := restored.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := built.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rebuilt.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatal("restored builder produced a different transaction")
+	}
+}
+
+func TestLoadStateRestoresMint(t *testing.T) {
+	cc := setupFixedContext()
+	unit := NewUnit("00000000000000000000000000000000000000000000000000000000", "token", 5)
+	a := New(cc).Mint(unit, nil, nil)
+
+	data, err := a.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := New(cc).LoadState(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.mint, []Unit{unit}) {
+		t.Fatalf("expected mint %v, got %v", []Unit{unit}, restored.mint)
+	}
+}
+
+func TestSaveStateAfterCompleteFails(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	a, err := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		PayToAddress(addr, 2_000_000).
+		SetTtl(50000000).
+		Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SaveState(); err == nil {
+		t.Fatal("expected error saving state after Complete()")
+	}
+}
+
+func TestLoadStateRejectsUnknownVersion(t *testing.T) {
+	a := New(setupFixedContext())
+	if _, err := a.LoadState([]byte(`{"version":99,"config":{}}`)); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
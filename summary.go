@@ -0,0 +1,140 @@
+package apollo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
+)
+
+// BuiltTxSummary is a high-level view of a decoded transaction, intended for
+// explorers, indexers and logging. It is unrelated to backend.TxSummary, which
+// identifies a transaction that touched an address.
+type BuiltTxSummary struct {
+	TxId           common.Blake2b256
+	Inputs         int
+	Outputs        []BuiltTxSummaryOutput
+	Fee            uint64
+	Mint           *common.MultiAsset[common.MultiAssetTypeMint]
+	Certificates   []common.CertificateType
+	Withdrawals    map[string]uint64
+	HasScripts     bool
+	MetadataLabels []uint64
+}
+
+// BuiltTxSummaryOutput is an output address and the value paid to it.
+type BuiltTxSummaryOutput struct {
+	Address string
+	Value   Value
+}
+
+// Summarize decodes a Conway transaction from CBOR and summarizes it. Unlike
+// the builder accessors it works on any transaction, not just one built here.
+// HasScripts reports scripts or redeemers in the witness set; scripts spent
+// only through reference inputs cannot be seen without chain data.
+func Summarize(txCbor []byte) (*BuiltTxSummary, error) {
+	if len(txCbor) == 0 {
+		return nil, errors.New("empty transaction CBOR")
+	}
+	var tx conway.ConwayTransaction
+	if _, err := cbor.Decode(txCbor, &tx); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	// The ID is the hash of the body bytes as submitted, which a re-encoding
+	// of a body from another builder need not reproduce.
+	var items []cbor.RawMessage
+	if _, err := cbor.Decode(txCbor, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, errors.New("transaction has no body")
+	}
+	txId := common.Blake2b256Hash(items[0])
+
+	summary := &BuiltTxSummary{
+		TxId:    txId,
+		Inputs:  len(tx.Body.TxInputs.Items()),
+		Outputs: make([]BuiltTxSummaryOutput, 0, len(tx.Body.TxOutputs)),
+		Fee:     tx.Body.TxFee,
+		Mint:    tx.Body.TxMint,
+	}
+	for i := range tx.Body.TxOutputs {
+		output := &tx.Body.TxOutputs[i]
+		value := Value{}
+		if amt := output.Amount(); amt != nil {
+			if !amt.IsUint64() {
+				return nil, fmt.Errorf("output %d amount exceeds uint64 range", i)
+			}
+			value.Coin = amt.Uint64()
+		}
+		if output.Assets() != nil {
+			value.Assets = CloneMultiAsset(output.Assets())
+		}
+		summary.Outputs = append(summary.Outputs, BuiltTxSummaryOutput{
+			Address: output.Address().String(),
+			Value:   value,
+		})
+	}
+	for _, cert := range tx.Body.TxCertificates {
+		summary.Certificates = append(summary.Certificates, common.CertificateType(cert.Type))
+	}
+	if len(tx.Body.TxWithdrawals) > 0 {
+		summary.Withdrawals = make(map[string]uint64, len(tx.Body.TxWithdrawals))
+		for addr, amount := range tx.Body.TxWithdrawals {
+			if addr == nil {
+				continue
+			}
+			summary.Withdrawals[addr.String()] += amount
+		}
+	}
+
+	ws := tx.WitnessSet
+	summary.HasScripts = len(ws.WsNativeScripts.Items()) > 0 ||
+		len(ws.WsPlutusV1Scripts.Items()) > 0 ||
+		len(ws.WsPlutusV2Scripts.Items()) > 0 ||
+		len(ws.WsPlutusV3Scripts.Items()) > 0 ||
+		len(ws.WsRedeemers.Redeemers) > 0
+
+	summary.MetadataLabels = metadataLabels(tx.TxMetadata)
+	return summary, nil
+}
+
+// metadataLabels returns the sorted top-level labels of transaction metadata.
+func metadataLabels(md any) []uint64 {
+	var pairs []common.MetaPair
+	switch m := md.(type) {
+	case *common.MetaMap:
+		if m == nil {
+			return nil
+		}
+		pairs = m.Pairs
+	case common.MetaMap:
+		pairs = m.Pairs
+	default:
+		return nil
+	}
+	labels := make([]uint64, 0, len(pairs))
+	for _, pair := range pairs {
+		var key common.MetaInt
+		switch k := pair.Key.(type) {
+		case common.MetaInt:
+			key = k
+		case *common.MetaInt:
+			if k == nil {
+				continue
+			}
+			key = *k
+		default:
+			continue
+		}
+		if key.Value == nil || !key.Value.IsUint64() {
+			continue
+		}
+		labels = append(labels, key.Value.Uint64())
+	}
+	slices.Sort(labels)
+	return labels
+}
@@ -0,0 +1,96 @@
+package apollo
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestSummarize(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+
+	p, err := NewPayment(validTestAddrBech32, 2_000_000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		AddPayment(p).
+		SetTtl(50000000)
+	a.SetShelleyMetadata(map[uint64]any{
+		721: "nft",
+		674: "msg",
+	})
+	a, err = a.Complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txCbor, err := a.GetTxCbor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := Summarize(txCbor)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	txId, err := a.builtTxId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.TxId != txId {
+		t.Errorf("expected tx id %s, got %s", txId, summary.TxId)
+	}
+	if summary.Inputs != 1 {
+		t.Errorf("expected 1 input, got %d", summary.Inputs)
+	}
+	if summary.Fee != a.GetTx().Body.TxFee {
+		t.Errorf("expected fee %d, got %d", a.GetTx().Body.TxFee, summary.Fee)
+	}
+	if len(summary.Outputs) != len(a.GetTx().Body.TxOutputs) {
+		t.Fatalf("expected %d outputs, got %d", len(a.GetTx().Body.TxOutputs), len(summary.Outputs))
+	}
+	if summary.Outputs[0].Address != validTestAddrBech32 || summary.Outputs[0].Value.Coin != 2_000_000 {
+		t.Errorf("unexpected first output %+v", summary.Outputs[0])
+	}
+	if summary.HasScripts {
+		t.Error("expected no scripts")
+	}
+	if !slices.Equal(summary.MetadataLabels, []uint64{674, 721}) {
+		t.Errorf("expected metadata labels [674 721], got %v", summary.MetadataLabels)
+	}
+
+	// A body encoded differently, here as an indefinite-length map, keeps
+	// the ID of its own bytes.
+	var items []cbor.RawMessage
+	if _, err := cbor.Decode(txCbor, &items); err != nil {
+		t.Fatal(err)
+	}
+	body := items[0]
+	if body[0] < 0xa1 || body[0] > 0xb7 {
+		t.Fatalf("unexpected body map header %#x", body[0])
+	}
+	items[0] = append(append([]byte{0xbf}, body[1:]...), 0xff)
+	indefCbor, err := cbor.Encode(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err = Summarize(indefCbor)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if summary.TxId != common.Blake2b256Hash(items[0]) {
+		t.Errorf("expected the hash of the raw body, got %s", summary.TxId)
+	}
+
+	if _, err := Summarize(nil); err == nil {
+		t.Error("expected error for empty CBOR")
+	}
+	if _, err := Summarize([]byte{0xff}); err == nil {
+		t.Error("expected error for invalid CBOR")
+	}
+}
@@ -0,0 +1,160 @@
+package apollo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// DefaultUTxOLockTTL is how long Complete reserves its inputs when
+// SetUTxOLocker is given no TTL: long enough for a submitted transaction to
+// reach a block, after which the backend no longer reports the inputs.
+const DefaultUTxOLockTTL = 5 * time.Minute
+
+// ErrUTxOLocked is returned by Complete when an input is reserved by another
+// builder sharing the same UTxOLocker.
+var ErrUTxOLocked = errors.New("UTxO is locked by another builder")
+
+// UTxOLocker reserves UTxOs across builders, so concurrent transactions do not
+// select the same inputs while earlier ones wait in the mempool. UTxOs are
+// identified as "txhash#index". Implementations must be safe for concurrent
+// use; MemoryUTxOLocker serves a single process, and a shared store can back
+// one spanning several.
+type UTxOLocker interface {
+	// Lock reserves refs for owner until ttl has passed. If another owner
+	// holds any of refs, it reserves none and returns ErrUTxOLocked.
+	Lock(owner string, refs []string, ttl time.Duration) error
+	// IsLocked reports whether ref is reserved by an owner other than owner.
+	IsLocked(owner string, ref string) bool
+	// Release frees every UTxO reserved by owner.
+	Release(owner string)
+}
+
+// MemoryUTxOLocker is an in-process UTxOLocker. Expired reservations are
+// dropped as they are encountered.
+type MemoryUTxOLocker struct {
+	mu    sync.Mutex
+	locks map[string]utxoLock
+	now   func() time.Time
+}
+
+type utxoLock struct {
+	owner   string
+	expires time.Time
+}
+
+// NewMemoryUTxOLocker creates an empty in-process UTxO locker.
+func NewMemoryUTxOLocker() *MemoryUTxOLocker {
+	return &MemoryUTxOLocker{
+		locks: make(map[string]utxoLock),
+		now:   time.Now,
+	}
+}
+
+// Lock reserves refs for owner until ttl has passed, extending the
+// reservations owner already holds.
+func (l *MemoryUTxOLocker) Lock(owner string, refs []string, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	var held []string
+	for _, ref := range refs {
+		if lock, ok := l.locks[ref]; ok && lock.owner != owner && now.Before(lock.expires) {
+			held = append(held, ref)
+		}
+	}
+	if len(held) > 0 {
+		return fmt.Errorf("%w: %s", ErrUTxOLocked, strings.Join(held, ", "))
+	}
+	for _, ref := range refs {
+		l.locks[ref] = utxoLock{owner: owner, expires: now.Add(ttl)}
+	}
+	return nil
+}
+
+// IsLocked reports whether ref is reserved by an owner other than owner.
+func (l *MemoryUTxOLocker) IsLocked(owner string, ref string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock, ok := l.locks[ref]
+	if !ok {
+		return false
+	}
+	if !l.now().Before(lock.expires) {
+		delete(l.locks, ref)
+		return false
+	}
+	return lock.owner != owner
+}
+
+// Release frees every UTxO reserved by owner.
+func (l *MemoryUTxOLocker) Release(owner string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ref, lock := range l.locks {
+		if lock.owner == owner {
+			delete(l.locks, ref)
+		}
+	}
+}
+
+// SetUTxOLocker shares locker with other builders. Coin selection skips UTxOs
+// they have reserved, and Complete reserves the transaction's inputs for ttl,
+// or DefaultUTxOLockTTL when ttl is not positive. The reservation is released
+// when Submit fails, or explicitly with ReleaseUTxOLocks.
+func (a *Apollo) SetUTxOLocker(locker UTxOLocker, ttl time.Duration) *Apollo {
+	if ttl <= 0 {
+		ttl = DefaultUTxOLockTTL
+	}
+	a.utxoLocker = locker
+	a.utxoLockTTL = ttl
+	if locker != nil && a.utxoLockOwner == "" {
+		owner, err := newUTxOLockOwner()
+		if err != nil {
+			a.setErrOnce(err)
+			return a
+		}
+		a.utxoLockOwner = owner
+	}
+	return a
+}
+
+// ReleaseUTxOLocks frees the inputs this builder reserved, e.g. when its
+// transaction is abandoned without being submitted.
+func (a *Apollo) ReleaseUTxOLocks() {
+	if a.utxoLocker != nil {
+		a.utxoLocker.Release(a.utxoLockOwner)
+	}
+}
+
+// lockedByOther reports whether another builder sharing the locker has
+// reserved ref.
+func (a *Apollo) lockedByOther(ref string) bool {
+	return a.utxoLocker != nil && a.utxoLocker.IsLocked(a.utxoLockOwner, ref)
+}
+
+// lockInputs reserves the transaction's inputs in the shared locker.
+func (a *Apollo) lockInputs(inputs []common.Utxo) error {
+	if a.utxoLocker == nil || a.noUTxOLocks {
+		return nil
+	}
+	refs := make([]string, 0, len(inputs))
+	for _, utxo := range inputs {
+		refs = append(refs, utxoRef(utxo))
+	}
+	return a.utxoLocker.Lock(a.utxoLockOwner, refs, a.utxoLockTTL)
+}
+
+func newUTxOLockOwner() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fmt.Errorf("failed to generate UTxO lock owner: %w", err)
+	}
+	return hex.EncodeToString(id[:]), nil
+}
@@ -0,0 +1,104 @@
+package apollo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryUTxOLocker(t *testing.T) {
+	l := NewMemoryUTxOLocker()
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	if err := l.Lock("a", []string{"tx#0", "tx#1"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Lock("b", []string{"tx#1", "tx#2"}, time.Minute); !errors.Is(err, ErrUTxOLocked) {
+		t.Fatalf("expected ErrUTxOLocked, got %v", err)
+	}
+	if l.IsLocked("a", "tx#2") || l.IsLocked("b", "tx#2") {
+		t.Error("expected a failed lock to reserve nothing")
+	}
+	if !l.IsLocked("b", "tx#0") || l.IsLocked("a", "tx#0") {
+		t.Error("expected tx#0 to be locked for other owners only")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if l.IsLocked("b", "tx#0") {
+		t.Error("expected the lock to expire")
+	}
+	if err := l.Lock("b", []string{"tx#1"}, time.Minute); err != nil {
+		t.Fatalf("expected an expired lock to be taken over, got %v", err)
+	}
+	l.Release("b")
+	if l.IsLocked("a", "tx#1") {
+		t.Error("expected Release to free the owner's locks")
+	}
+}
+
+func TestSetUTxOLocker(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+	addTestUtxo(cc, addr, 10_000_000, 0x02, 0)
+	locker := NewMemoryUTxOLocker()
+
+	build := func() (*Apollo, error) {
+		return New(cc).
+			SetWallet(NewExternalWallet(addr)).
+			SetUTxOLocker(locker, 0).
+			PayToAddress(addr, 3_000_000).
+			SetTtl(50000000).
+			Complete()
+	}
+	first, err := build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstInputs := first.GetTx().Body.TxInputs.Items()
+	secondInputs := second.GetTx().Body.TxInputs.Items()
+	if len(firstInputs) != 1 || len(secondInputs) != 1 || firstInputs[0].Id() == secondInputs[0].Id() {
+		t.Fatalf("expected the builders to spend different UTxOs, got %v and %v", firstInputs, secondInputs)
+	}
+	if _, err := build(); err == nil {
+		t.Fatal("expected coin selection to fail with every UTxO locked")
+	}
+
+	// The fixed context cannot submit, so the failed submission releases the
+	// first builder's input for the next one.
+	if _, err := first.Submit(); err == nil {
+		t.Fatal("expected submission to fail")
+	}
+	third, err := build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.GetTx().Body.TxInputs.Items()[0].Id() != firstInputs[0].Id() {
+		t.Error("expected the released UTxO to be selected")
+	}
+}
+
+func TestProjectedOutputCountReservesNoInputs(t *testing.T) {
+	cc := setupFixedContext()
+	addr := testAddress(t)
+	addTestUtxo(cc, addr, 10_000_000, 0x01, 0)
+	locker := NewMemoryUTxOLocker()
+
+	a := New(cc).
+		SetWallet(NewExternalWallet(addr)).
+		SetUTxOLocker(locker, 0).
+		PayToAddress(addr, 3_000_000).
+		SetTtl(50000000)
+	if _, err := a.ProjectedOutputCount(); err != nil {
+		t.Fatal(err)
+	}
+	// The only UTxO must still be free for the builder itself.
+	if _, err := a.Complete(); err != nil {
+		t.Fatalf("Complete after ProjectedOutputCount: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package apollo
 import (
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/blinklabs-io/bursa"
 	"github.com/blinklabs-io/bursa/bip32"
@@ -21,12 +22,38 @@ type Wallet interface {
 	StakePubKeyHash() common.Blake2b224
 }
 
+// StakeSigner is implemented by wallets that hold the staking key of their
+// address. Sign uses it to add the stake witness demanded by certificates,
+// withdrawals, and inputs added with AddInputWithStakeWitness.
+type StakeSigner interface {
+	// SignTxBodyWithStakeKey signs a transaction body hash with the staking key.
+	SignTxBodyWithStakeKey(txBodyHash common.Blake2b256) (common.VkeyWitness, error)
+}
+
+// TxSigner is implemented by wallets that sign a whole transaction rather
+// than its body hash, such as CIP30Wallet. Sign passes them the transaction
+// CBOR and adds the vkey witnesses they return.
+type TxSigner interface {
+	// SignTx signs a transaction and returns the wallet's vkey witnesses.
+	SignTx(txCbor []byte) ([]common.VkeyWitness, error)
+}
+
+// RewardAddressProvider is implemented by wallets that can derive the reward
+// (stake) address of their staking credential. It is kept separate from
+// Wallet so custom wallet implementations are not forced to add a method.
+type RewardAddressProvider interface {
+	// RewardAddress returns the wallet's reward address.
+	RewardAddress() (common.Address, error)
+}
+
 // BursaWallet wraps bursa key derivation for HD wallet functionality.
 type BursaWallet struct {
 	mnemonic   string
 	address    common.Address
 	paymentKey bip32.XPrv
 	stakeKey   bip32.XPrv
+	// accountKey is the CIP-1852 account key DeriveAddresses derives from.
+	accountKey bip32.XPrv
 }
 
 // NewBursaWallet creates a new wallet from a mnemonic string.
@@ -102,6 +129,7 @@ func NewBursaWalletWithPassphrase(mnemonic string, passphrase string, opts ...bu
 		address:    addr,
 		paymentKey: paymentKey,
 		stakeKey:   stakeKey,
+		accountKey: accountKey,
 	}, nil
 }
 
@@ -114,6 +142,47 @@ func NewBursaWalletGenerate(opts ...bursa.WalletOption) (*BursaWallet, error) {
 	return NewBursaWallet(mnemonic, opts...)
 }
 
+// NewWalletFromAccountKey creates a wallet from a CIP-1852 account-level
+// extended private key (m/1852'/1815'/account'), such as one exported by a
+// hardware wallet, without needing the mnemonic. The payment (role 0) and
+// stake (role 2) keys at index 0 form a base address on network (0 for
+// testnets, 1 for mainnet). The returned wallet has no mnemonic.
+func NewWalletFromAccountKey(accountXprv []byte, network uint8) (Wallet, error) {
+	if len(accountXprv) != 96 {
+		return nil, fmt.Errorf("invalid account extended private key length: expected 96 bytes, got %d", len(accountXprv))
+	}
+	if network > 0x0f {
+		return nil, fmt.Errorf("invalid network id %d: must fit in 4 bits", network)
+	}
+	accountKey := bip32.XPrv(accountXprv)
+	paymentKey, err := bursa.GetPaymentKey(accountKey, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive payment key: %w", err)
+	}
+	stakeKey, err := bursa.GetStakeKey(accountKey, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive stake key: %w", err)
+	}
+
+	paymentHash := common.Blake2b224Hash(paymentKey.Public().PublicKey())
+	stakeHash := common.Blake2b224Hash(stakeKey.Public().PublicKey())
+	raw := make([]byte, 0, 1+2*common.Blake2b224Size)
+	raw = append(raw, byte(common.AddressTypeKeyKey)<<4|network)
+	raw = append(raw, paymentHash.Bytes()...)
+	raw = append(raw, stakeHash.Bytes()...)
+	addr, err := common.NewAddressFromBytes(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build wallet address: %w", err)
+	}
+
+	return &BursaWallet{
+		address:    addr,
+		paymentKey: paymentKey,
+		stakeKey:   stakeKey,
+		accountKey: accountKey,
+	}, nil
+}
+
 func (w *BursaWallet) Address() common.Address {
 	return w.address
 }
@@ -125,6 +194,15 @@ func (w *BursaWallet) SignTxBody(txBodyHash common.Blake2b256) (common.VkeyWitne
 	}, nil
 }
 
+// SignTxBodyWithStakeKey signs a transaction body hash with the wallet's
+// staking key.
+func (w *BursaWallet) SignTxBodyWithStakeKey(txBodyHash common.Blake2b256) (common.VkeyWitness, error) {
+	return common.VkeyWitness{
+		Vkey:      w.stakeKey.Public().PublicKey(),
+		Signature: w.stakeKey.Sign(txBodyHash.Bytes()),
+	}, nil
+}
+
 // EvaluationWitnesses provides payment and stake witnesses required by a
 // preliminary transaction evaluation.
 func (w *BursaWallet) EvaluationWitnesses(
@@ -159,7 +237,52 @@ func (w *BursaWallet) StakePubKeyHash() common.Blake2b224 {
 	return common.Blake2b224Hash(pubKey)
 }
 
-// Mnemonic returns the mnemonic for this wallet.
+// RewardAddress returns the reward address of the wallet's staking key.
+func (w *BursaWallet) RewardAddress() (common.Address, error) {
+	return RewardAddressFromAddress(w.address)
+}
+
+// DeriveAddresses derives count base addresses of the wallet's account,
+// starting at address index startIndex, for gap-limit scanning of HD wallet
+// funds. Payment keys come from the external chain (role 0), or the internal
+// change chain (role 1) when change is set; every address shares the wallet's
+// stake credential and network.
+func (w *BursaWallet) DeriveAddresses(startIndex, count int, change bool) ([]common.Address, error) {
+	if len(w.accountKey) != 96 {
+		return nil, errors.New("wallet has no account key to derive addresses from")
+	}
+	if startIndex < 0 || count < 0 || startIndex > math.MaxInt32-count {
+		return nil, fmt.Errorf("invalid address index range: start %d, count %d", startIndex, count)
+	}
+	network, ok := addressNetworkId(w.address)
+	if !ok {
+		return nil, errors.New("wallet address has no network id")
+	}
+	role := uint32(0)
+	if change {
+		role = 1
+	}
+	stakeHash := w.StakePubKeyHash()
+	chain := w.accountKey.Derive(role)
+	addrs := make([]common.Address, 0, count)
+	for i := startIndex; i < startIndex+count; i++ {
+		paymentKey := chain.Derive(uint32(i)) //nolint:gosec // bounded by MaxInt32 above
+		paymentHash := common.Blake2b224Hash(paymentKey.Public().PublicKey())
+		raw := make([]byte, 0, 1+2*common.Blake2b224Size)
+		raw = append(raw, byte(common.AddressTypeKeyKey)<<4|network)
+		raw = append(raw, paymentHash.Bytes()...)
+		raw = append(raw, stakeHash.Bytes()...)
+		addr, err := common.NewAddressFromBytes(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build address %d: %w", i, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// Mnemonic returns the mnemonic for this wallet, or an empty string for a
+// wallet created from an account key.
 func (w *BursaWallet) Mnemonic() string {
 	return w.mnemonic
 }
@@ -254,3 +377,9 @@ func (w *ExternalWallet) PubKeyHash() common.Blake2b224 {
 func (w *ExternalWallet) StakePubKeyHash() common.Blake2b224 {
 	return w.address.StakeKeyHash()
 }
+
+// RewardAddress returns the reward address for the staking credential of the
+// wallet's address.
+func (w *ExternalWallet) RewardAddress() (common.Address, error) {
+	return RewardAddressFromAddress(w.address)
+}
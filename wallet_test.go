@@ -3,6 +3,7 @@ package apollo
 import (
 	"testing"
 
+	"github.com/blinklabs-io/bursa"
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 )
 
@@ -20,3 +21,106 @@ func (compatibilityWallet) StakePubKeyHash() common.Blake2b224 { return common.B
 func TestCustomWalletSatisfiesUnchangedWalletInterface(t *testing.T) {
 	var _ Wallet = compatibilityWallet{}
 }
+
+func TestNewWalletFromAccountKeyMatchesMnemonicWallet(t *testing.T) {
+	mnemonic := testMnemonic(t)
+	fromMnemonic, err := NewBursaWallet(mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootKey, err := bursa.GetRootKeyFromMnemonic(mnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountKey, err := bursa.GetAccountKey(rootKey, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWalletFromAccountKey(accountKey, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotAddr, wantAddr := w.Address(), fromMnemonic.Address()
+	if gotAddr.String() != wantAddr.String() {
+		t.Fatalf("address = %s, want %s", gotAddr.String(), wantAddr.String())
+	}
+	if w.PubKeyHash() != fromMnemonic.PubKeyHash() || w.StakePubKeyHash() != fromMnemonic.StakePubKeyHash() {
+		t.Fatal("derived keys differ from the mnemonic wallet")
+	}
+
+	var hash common.Blake2b256
+	hash[0] = 0x42
+	got, err := w.SignTxBody(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := fromMnemonic.SignTxBody(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Signature) != string(want.Signature) {
+		t.Error("signature differs from the mnemonic wallet")
+	}
+
+	if _, err := NewWalletFromAccountKey(make([]byte, 64), 1); err == nil {
+		t.Error("expected error for a 64-byte key")
+	}
+	if _, err := NewWalletFromAccountKey(accountKey, 16); err == nil {
+		t.Error("expected error for a network id above 15")
+	}
+}
+
+func TestDeriveAddresses(t *testing.T) {
+	mnemonic := testMnemonic(t)
+	w, err := NewBursaWallet(mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	external, err := w.DeriveAddresses(0, 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(external) != 3 {
+		t.Fatalf("expected 3 addresses, got %d", len(external))
+	}
+	if external[0].String() != w.Address().String() {
+		t.Errorf("index 0 = %s, want the wallet address %s", external[0].String(), w.Address().String())
+	}
+
+	// Index 1 pays to the key a wallet at address index 1 signs with.
+	second, err := NewBursaWallet(mnemonic, bursa.WithAddressID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if external[1].PaymentKeyHash() != second.PubKeyHash() {
+		t.Error("index 1 payment key differs from the wallet at address index 1")
+	}
+	seen := make(map[string]bool)
+	for _, addr := range external {
+		if addr.StakeKeyHash() != w.StakePubKeyHash() {
+			t.Errorf("%s does not share the wallet stake key", addr.String())
+		}
+		seen[addr.String()] = true
+	}
+	if len(seen) != 3 {
+		t.Error("expected distinct addresses")
+	}
+
+	change, err := w.DeriveAddresses(1, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, addr := range change {
+		if seen[addr.String()] {
+			t.Errorf("change address %d repeats an external address", i)
+		}
+	}
+
+	if _, err := w.DeriveAddresses(-1, 2, false); err == nil {
+		t.Error("expected error for a negative start index")
+	}
+	if got, err := w.DeriveAddresses(5, 0, false); err != nil || len(got) != 0 {
+		t.Errorf("expected no addresses for a zero count, got %d (%v)", len(got), err)
+	}
+}